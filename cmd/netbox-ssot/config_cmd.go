@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/src-doo/netbox-ssot/internal/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// runSubcommand runs the subcommand given in args (without the program
+// name), and returns its exit code. False is returned if args don't
+// start with a subcommand.
+func runSubcommand(args []string, stdout, stderr io.Writer) (int, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch args[0] {
+	case "validate":
+		return validateCmd(args[1:], stdout, stderr), true
+	case "config":
+		if len(args) < 2 || args[1] != "show" {
+			fmt.Fprintln(stderr, "usage: netbox-ssot config show [-config config.yaml]")
+			return 2, true
+		}
+		return configShowCmd(args[2:], stdout, stderr), true
+	}
+	return 0, false
+}
+
+// validateCmd parses the config, and prints all validation errors and
+// lint warnings. It returns non-zero exit code if the config is invalid.
+func validateCmd(args []string, stdout, stderr io.Writer) int {
+	config, exitCode := parseSubcommandConfig("validate", args, stderr)
+	if config == nil {
+		return exitCode
+	}
+	warnings := parser.Lint(config)
+	for _, warning := range warnings {
+		fmt.Fprintf(stdout, "warning: %s\n", warning)
+	}
+	fmt.Fprintf(stdout, "Config is valid (%d warnings)\n", len(warnings))
+	return 0
+}
+
+// configShowCmd prints the parsed config with all defaults filled in.
+// Secrets are always marshaled masked.
+func configShowCmd(args []string, stdout, stderr io.Writer) int {
+	config, exitCode := parseSubcommandConfig("config show", args, stderr)
+	if config == nil {
+		return exitCode
+	}
+	encoder := yaml.NewEncoder(stdout)
+	defer encoder.Close()
+	if err := encoder.Encode(config); err != nil {
+		fmt.Fprintf(stderr, "encode config: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// parseSubcommandConfig parses flags of the subcommand and the config file
+// they point to. On failure errors are printed, and nil config is returned
+// together with the exit code.
+func parseSubcommandConfig(name string, args []string, stderr io.Writer) (*parser.Config, int) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	path := flags.String("config", "config.yaml", "Path to the configuration file")
+	if err := flags.Parse(args); err != nil {
+		return nil, 2 //nolint:mnd
+	}
+	config, err := parser.ParseConfig(*path)
+	if err != nil {
+		var validationErrors parser.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			fmt.Fprintf(stderr, "Config %s has %d errors:\n", *path, len(validationErrors))
+			for _, validationErr := range validationErrors {
+				fmt.Fprintf(stderr, "  - %s\n", validationErr)
+			}
+		} else {
+			fmt.Fprintf(stderr, "Parser: %s\n", err)
+		}
+		return nil, 1
+	}
+	return config, 0
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/logger"
+	"github.com/src-doo/netbox-ssot/internal/metrics"
+	"github.com/src-doo/netbox-ssot/internal/netbox/inventory"
+	"github.com/src-doo/netbox-ssot/internal/parser"
+	"github.com/src-doo/netbox-ssot/internal/report"
+	"github.com/src-doo/netbox-ssot/internal/schedule"
+)
+
+// runDaemon keeps netbox-ssot running and syncs each source according to
+// its schedule, until ctx is canceled (on SIGINT or SIGTERM). Sources that are due
+// at the same time are synced together, and runs never overlap.
+//
+// Before each run netbox inventory is refreshed with objects changed since
+// the previous run. Once per daemon.fullRefreshInterval (and after objects
+// were hard deleted) inventory is fully reinitialized instead, and all
+// sources are synced, so orphaned objects can be cleaned up.
+func runDaemon(
+	ctx context.Context,
+	ssotLogger *logger.Logger,
+	config *parser.Config,
+	netboxInventory *inventory.NetboxInventory,
+) error {
+	schedules := make([]schedule.Schedule, len(config.Sources))
+	// Zero time means that source won't be run anymore.
+	nextRuns := make([]time.Time, len(config.Sources))
+	startTime := time.Now()
+	for i := range config.Sources {
+		scheduleExpr := config.Sources[i].Schedule
+		if scheduleExpr == "" {
+			scheduleExpr = config.Daemon.Schedule
+		}
+		sourceSchedule, err := schedule.Parse(scheduleExpr)
+		if err != nil {
+			return fmt.Errorf("%s.schedule: %s", config.Sources[i].Name, err)
+		}
+		schedules[i] = sourceSchedule
+		// All sources are synced on startup
+		nextRuns[i] = startTime
+	}
+
+	if *metricsAddress != "" {
+		stopMetricsServer := startMetricsServer(ctx, ssotLogger, *metricsAddress)
+		defer stopMetricsServer()
+	}
+
+	ssotLogger.Infof(ctx, "Running in daemon mode with %d sources", len(config.Sources))
+	for {
+		nextRun, ok := earliestRun(nextRuns)
+		if !ok {
+			return fmt.Errorf("no source is scheduled to run anymore")
+		}
+		ssotLogger.Infof(ctx, "Next run scheduled at %s", nextRun.Format(time.RFC3339))
+		timer := time.NewTimer(time.Until(nextRun))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			ssotLogger.Info(ctx, "Received shutdown signal, stopping daemon")
+			return nil
+		case <-timer.C:
+		}
+
+		fullRun := netboxInventory.NeedsFullInit(config.Daemon.FullRefreshInterval)
+		now := time.Now()
+		dueSources := make([]*parser.SourceConfig, 0, len(config.Sources))
+		for i := range config.Sources {
+			if nextRuns[i].IsZero() {
+				continue
+			}
+			if !fullRun && nextRuns[i].After(now) {
+				continue
+			}
+			dueSources = append(dueSources, &config.Sources[i])
+			nextRuns[i] = schedules[i].Next(now)
+			if nextRuns[i].IsZero() {
+				ssotLogger.Warningf(
+					ctx,
+					"Schedule of source %s has no more activations, source won't be synced anymore",
+					config.Sources[i].Name,
+				)
+			}
+		}
+		runScheduledSources(ctx, ssotLogger, config, netboxInventory, dueSources, fullRun)
+		writeMetricsFile(ctx, ssotLogger)
+	}
+}
+
+// startMetricsServer starts serving prometheus metrics on address in
+// background. Returned function gracefully stops the server.
+func startMetricsServer(ctx context.Context, ssotLogger *logger.Logger, address string) func() {
+	server := metrics.NewServer(metrics.Default, address)
+	go func() {
+		ssotLogger.Infof(ctx, "Serving metrics on %s/metrics", address)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ssotLogger.Errorf(ctx, "metrics server: %s", err)
+		}
+	}()
+	return func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), metricsServerShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			ssotLogger.Errorf(ctx, "shutdown metrics server: %s", err)
+		}
+	}
+}
+
+// metricsServerShutdownTimeout is the time given to the metrics server to
+// finish serving in-flight requests on shutdown.
+const metricsServerShutdownTimeout = 5 * time.Second
+
+// runScheduledSources performs a single daemon run of the given sources.
+// Errors are only logged, so the daemon keeps running.
+func runScheduledSources(
+	ctx context.Context,
+	ssotLogger *logger.Logger,
+	config *parser.Config,
+	netboxInventory *inventory.NetboxInventory,
+	sourceConfigs []*parser.SourceConfig,
+	fullRun bool,
+) {
+	startTime := time.Now()
+	if *reportFile != "" {
+		netboxInventory.Report = report.New(startTime)
+	}
+	success := false
+	defer func() {
+		writeReportFile(ctx, ssotLogger, netboxInventory.Report, success)
+	}()
+
+	var err error
+	if fullRun {
+		ssotLogger.Info(ctx, "Starting full initialization of netbox inventory")
+		err = netboxInventory.Init()
+	} else {
+		ssotLogger.Info(ctx, "Refreshing netbox inventory with objects changed since the last run")
+		err = netboxInventory.Refresh()
+	}
+	if err != nil {
+		ssotLogger.Errorf(ctx, "initialize netbox inventory: %s", err)
+		return
+	}
+
+	encounteredErrors, err := runSources(ctx, ssotLogger, sourceConfigs, netboxInventory)
+	if err != nil {
+		ssotLogger.Error(ctx, err)
+		return
+	}
+	for source, err := range encounteredErrors {
+		ssotLogger.Warningf(ctx, "%s syncing of source %s failed with: %v", constants.WarningSign, source, err)
+	}
+	if ctx.Err() != nil {
+		ssotLogger.Warning(ctx, "Received shutdown signal, skipping removing orphaned objects")
+		return
+	}
+
+	// Objects of sources that failed or were not synced in this run
+	// would be treated as orphans, so they are protected.
+	ssotLogger.Info(ctx, "Cleaning up orphaned objects...")
+	err = netboxInventory.DeleteOrphans(
+		ctx,
+		config.Netbox.RemoveOrphans,
+		protectedSources(config, sourceConfigs, encounteredErrors),
+	)
+	if err != nil {
+		ssotLogger.Error(ctx, err)
+		return
+	}
+	ssotLogger.Infof(ctx, "%s Successfully removed orphans", constants.CheckMark)
+	success = len(encounteredErrors) == 0
+
+	// Only changes of successful runs are merged without a review
+	if success {
+		if err := netboxInventory.MergeBranch(); err != nil {
+			ssotLogger.Error(ctx, err)
+			success = false
+		}
+	}
+
+	ssotLogger.Infof(
+		ctx,
+		"%s Syncing of %d sources took %s",
+		constants.Rocket,
+		len(sourceConfigs),
+		time.Since(startTime).Round(time.Second),
+	)
+}
+
+// earliestRun returns the earliest of the scheduled runs. Zero times are
+// ignored, and false is returned if there is no scheduled run.
+func earliestRun(nextRuns []time.Time) (time.Time, bool) {
+	var earliest time.Time
+	for _, nextRun := range nextRuns {
+		if nextRun.IsZero() {
+			continue
+		}
+		if earliest.IsZero() || nextRun.Before(earliest) {
+			earliest = nextRun
+		}
+	}
+	return earliest, !earliest.IsZero()
+}
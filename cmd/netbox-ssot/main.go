@@ -2,21 +2,50 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"sort"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/src-doo/netbox-ssot/internal/constants"
 	"github.com/src-doo/netbox-ssot/internal/logger"
+	"github.com/src-doo/netbox-ssot/internal/metrics"
 	"github.com/src-doo/netbox-ssot/internal/netbox/inventory"
+	"github.com/src-doo/netbox-ssot/internal/netbox/service"
 	"github.com/src-doo/netbox-ssot/internal/parser"
+	"github.com/src-doo/netbox-ssot/internal/report"
 	"github.com/src-doo/netbox-ssot/internal/source"
 	"github.com/src-doo/netbox-ssot/internal/source/common"
 )
 
 var configPath = flag.String("config", "config.yaml", "Path to the configuration file")
+var dryRun = flag.Bool("dry-run", false, "Record all changes as a plan instead of sending them to Netbox")
+var planFile = flag.String("plan-file", "", "Path to the file where the dry-run plan is written in JSON format")
+var daemon = flag.Bool("daemon", false, "Keep running and sync sources according to their schedules")
+var metricsAddress = flag.String(
+	"metrics-address",
+	"",
+	"Address (e.g. :9090) on which prometheus metrics are served on /metrics in daemon mode",
+)
+var metricsFile = flag.String(
+	"metrics-file",
+	"",
+	"Path to the file where prometheus metrics are written after each run",
+)
+var reportFile = flag.String(
+	"report-file",
+	"",
+	"Path to the file where the run report is written (YAML if it ends with .yaml or .yml, JSON otherwise)",
+)
+
+// exitCodeOrphanLimitExceeded is the exit code used when orphans were not
+// deleted, because netbox.maxOrphans or netbox.maxOrphansPercent was exceeded.
+const exitCodeOrphanLimitExceeded = 3
 
 // Build variables provided with ldflags.
 var (
@@ -26,6 +55,10 @@ var (
 )
 
 func main() {
+	if exitCode, ok := runSubcommand(os.Args[1:], os.Stdout, os.Stderr); ok {
+		os.Exit(exitCode)
+	}
+
 	// Print build information
 	fmt.Printf("Running version %s built on %s (commit %s)\n\n", version, date, commit)
 
@@ -40,9 +73,11 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Create our main context
-	mainCtx := context.Background()
-	mainCtx = context.WithValue(mainCtx, constants.CtxSourceKey, "main")
+	// Create our main context. It is canceled on SIGINT or SIGTERM,
+	// which stops all sources and requests to Netbox.
+	baseCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	mainCtx := context.WithValue(baseCtx, constants.CtxSourceKey, "main")
 
 	// Initialize Logger
 	ssotLogger, err := logger.New(config.Logger.Dest, config.Logger.Level)
@@ -59,83 +94,107 @@ func main() {
 		ssotLogger.Errorf(mainCtx, "inventoryLogger: %s", err)
 		os.Exit(1)
 	}
-	inventoryCtx := context.WithValue(context.Background(), constants.CtxSourceKey, "inventory")
+	inventoryCtx := context.WithValue(baseCtx, constants.CtxSourceKey, "inventory")
 	netboxInventory := inventory.NewNetboxInventory(inventoryCtx, inventoryLogger, config.Netbox)
+	if *dryRun {
+		ssotLogger.Info(mainCtx, "Running in dry-run mode, no changes will be sent to Netbox")
+		netboxInventory.Plan = service.NewPlan()
+	}
 	ssotLogger.Debug(mainCtx, "Netbox inventory: ", netboxInventory)
 
+	if *daemon {
+		if *dryRun {
+			ssotLogger.Error(mainCtx, "dry-run mode can't be used in daemon mode")
+			os.Exit(1)
+		}
+		err = runDaemon(mainCtx, ssotLogger, config, netboxInventory)
+		if err != nil {
+			ssotLogger.Error(mainCtx, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *metricsAddress != "" {
+		ssotLogger.Warning(mainCtx, "-metrics-address is only used in daemon mode, use -metrics-file instead")
+	}
+
+	if *reportFile != "" {
+		netboxInventory.Report = report.New(startTime)
+	}
+
+	// exitFailed writes the metrics and report files of the failed run,
+	// and exits with the code. It is used by all exit paths of the run,
+	// because metrics are most needed for failed runs.
+	exitFailed := func(code int) {
+		writeMetricsFile(mainCtx, ssotLogger)
+		writeReportFile(mainCtx, ssotLogger, netboxInventory.Report, false)
+		os.Exit(code)
+	}
+
 	ssotLogger.Info(mainCtx, "Starting initializing netbox inventory")
 	err = netboxInventory.Init()
 	if err != nil {
 		ssotLogger.Error(mainCtx, err)
-		os.Exit(1)
+		exitFailed(1)
 	}
 	ssotLogger.Debug(mainCtx, "Netbox inventory initialized: ", netboxInventory)
 
-	// Variable to store if the run was successful. If it wasn't we don't remove orphans.
-	successfullRun := true
-	// Variable to store failed sourcesFalse
-	encounteredErrors := map[string]error{}
-
 	// Go through all sources and sync data
-	var wg sync.WaitGroup
-	for i := range config.Sources {
-		sourceConfig := &config.Sources[i]
-		ssotLogger.Info(mainCtx, "Processing source ", sourceConfig.Name, "...")
-		sourceCtx := context.WithValue(mainCtx, constants.CtxSourceKey, sourceConfig.Name)
-		source, err := source.NewSource(sourceCtx, sourceConfig, ssotLogger, netboxInventory)
-		if err != nil {
-			ssotLogger.Error(sourceCtx, err)
-			os.Exit(1)
-		}
-		ssotLogger.Infof(sourceCtx, "Successfully created source %s", constants.CheckMark)
-		ssotLogger.Debugf(sourceCtx, "Source content: %s", source)
-		wg.Add(1)
-		// Run each source in parallel
-		go func(sourceCtx context.Context, source common.Source) {
-			defer wg.Done()
-			sourceName, ok := sourceCtx.Value(constants.CtxSourceKey).(string)
-			if !ok {
-				ssotLogger.Errorf(sourceCtx, "source ctx value is not set")
-				return
-			}
-			// Source initialization
-			ssotLogger.Info(sourceCtx, "Initializing source")
-			err = source.Init()
-			if err != nil {
-				ssotLogger.Error(sourceCtx, err)
-				successfullRun = false
-				encounteredErrors[sourceName] = err
-				return
-			}
-			ssotLogger.Infof(sourceCtx, "Successfully initialized source %s", constants.CheckMark)
+	encounteredErrors, err := runSources(mainCtx, ssotLogger, allSources(config), netboxInventory)
+	if err != nil {
+		ssotLogger.Error(mainCtx, err)
+		exitFailed(1)
+	}
+	// Variable to store if the run was successful
+	successfullRun := len(encounteredErrors) == 0
 
-			// Source synchronization
-			ssotLogger.Info(sourceCtx, "Syncing source...")
-			err = source.Sync(netboxInventory)
-			if err != nil {
-				successfullRun = false
-				ssotLogger.Error(sourceCtx, err)
-				encounteredErrors[sourceName] = err
-				return
+	if mainCtx.Err() != nil {
+		ssotLogger.Warning(mainCtx, "Received shutdown signal, skipping removing orphaned objects")
+		exitFailed(1)
+	}
+
+	// Orphan manager cleanup. Orphans of failed sources are protected.
+	ssotLogger.Info(mainCtx, "Cleaning up orphaned objects...")
+	err = netboxInventory.DeleteOrphans(
+		mainCtx,
+		config.Netbox.RemoveOrphans,
+		protectedSources(config, allSources(config), encounteredErrors),
+	)
+	if err != nil {
+		ssotLogger.Error(mainCtx, err)
+		// The plan shows the changes, that exceeded the orphan limits
+		if *dryRun {
+			if err := printPlan(netboxInventory.Plan, *planFile); err != nil {
+				ssotLogger.Errorf(mainCtx, "print plan: %s", err)
 			}
-			ssotLogger.Infof(sourceCtx, "Source synced successfully %s", constants.CheckMark)
-		}(sourceCtx, source)
+		}
+		if errors.Is(err, inventory.ErrOrphanLimitExceeded) {
+			exitFailed(exitCodeOrphanLimitExceeded)
+		}
+		exitFailed(1)
 	}
-	wg.Wait()
+	ssotLogger.Infof(mainCtx, "%s Successfully removed orphans", constants.CheckMark)
 
-	// Orphan manager cleanup on successful run and if enabled
+	// Only changes of successful runs are merged without a review
 	if successfullRun {
-		ssotLogger.Info(mainCtx, "Cleaning up orphaned objects...")
-		err = netboxInventory.DeleteOrphans(config.Netbox.RemoveOrphans)
-		if err != nil {
+		if err := netboxInventory.MergeBranch(); err != nil {
 			ssotLogger.Error(mainCtx, err)
-			os.Exit(1)
+			successfullRun = false
+		}
+	}
+
+	if *dryRun {
+		err = printPlan(netboxInventory.Plan, *planFile)
+		if err != nil {
+			ssotLogger.Errorf(mainCtx, "print plan: %s", err)
+			exitFailed(1)
 		}
-		ssotLogger.Infof(mainCtx, "%s Successfully removed orphans", constants.CheckMark)
-	} else {
-		ssotLogger.Info(mainCtx, "Skipping removing orphaned objects because run failed...")
 	}
 
+	writeMetricsFile(mainCtx, ssotLogger)
+	writeReportFile(mainCtx, ssotLogger, netboxInventory.Report, successfullRun)
+
 	duration := time.Since(startTime)
 	minutes := int(duration.Minutes())
 	seconds := int((duration - time.Duration(minutes)*time.Minute).Seconds())
@@ -154,3 +213,249 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// allSources returns pointers to all sources from the config.
+func allSources(config *parser.Config) []*parser.SourceConfig {
+	sourceConfigs := make([]*parser.SourceConfig, 0, len(config.Sources))
+	for i := range config.Sources {
+		sourceConfigs = append(sourceConfigs, &config.Sources[i])
+	}
+	return sourceConfigs
+}
+
+// protectedSources returns sorted names of sources, whose orphaned objects
+// must not be deleted, because they weren't synced successfully in this run.
+// These are sources that failed, and sources that were not run at all.
+func protectedSources(
+	config *parser.Config,
+	sourceConfigs []*parser.SourceConfig,
+	encounteredErrors map[string]error,
+) []string {
+	synced := make(map[string]bool, len(sourceConfigs))
+	for _, sourceConfig := range sourceConfigs {
+		if _, failed := encounteredErrors[sourceConfig.Name]; !failed {
+			synced[sourceConfig.Name] = true
+		}
+	}
+	protected := []string{}
+	for _, sourceConfig := range config.Sources {
+		if !synced[sourceConfig.Name] {
+			protected = append(protected, sourceConfig.Name)
+		}
+	}
+	sort.Strings(protected)
+	return protected
+}
+
+// runSources creates all given sources and then initializes and syncs them
+// in parallel. It returns errors of sources that failed, indexed by source name.
+// Error is returned if any of the sources can't be created.
+func runSources(
+	ctx context.Context,
+	ssotLogger *logger.Logger,
+	sourceConfigs []*parser.SourceConfig,
+	netboxInventory *inventory.NetboxInventory,
+) (map[string]error, error) {
+	sources := make([]common.Source, 0, len(sourceConfigs))
+	sourceCtxs := make([]context.Context, 0, len(sourceConfigs))
+	for _, sourceConfig := range sourceConfigs {
+		ssotLogger.Info(ctx, "Processing source ", sourceConfig.Name, "...")
+		sourceCtx := context.WithValue(ctx, constants.CtxSourceKey, sourceConfig.Name)
+		source, err := source.NewSource(sourceCtx, sourceConfig, ssotLogger, netboxInventory)
+		if err != nil {
+			err = fmt.Errorf("create source %s: %s", sourceConfig.Name, err)
+			if netboxInventory.Report != nil {
+				netboxInventory.Report.RecordSource(sourceConfig.Name, sourceConfig.Type, time.Now(), 0, 0, err)
+			}
+			return nil, err
+		}
+		ssotLogger.Infof(sourceCtx, "Successfully created source %s", constants.CheckMark)
+		ssotLogger.Debugf(sourceCtx, "Source content: %s", source)
+		sources = append(sources, source)
+		sourceCtxs = append(sourceCtxs, sourceCtx)
+	}
+
+	// Variable to store failed sources
+	encounteredErrors := map[string]error{}
+	var errorsLock sync.Mutex
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		// Run each source in parallel
+		go func(sourceCtx context.Context, sourceConfig *parser.SourceConfig, source common.Source) {
+			defer wg.Done()
+			sourceName := sourceConfig.Name
+			sourceStart := time.Now()
+			if sourceConfig.Timeout > 0 {
+				var cancel context.CancelFunc
+				sourceCtx, cancel = context.WithTimeout(sourceCtx, sourceConfig.Timeout)
+				defer cancel()
+			}
+			initDuration, syncDuration, err := initAndSyncSource(
+				sourceCtx,
+				ssotLogger,
+				sourceName,
+				source,
+				netboxInventory,
+			)
+			if err != nil && errors.Is(sourceCtx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("source timed out after %s: %s", sourceConfig.Timeout, err)
+			}
+			if netboxInventory.Report != nil {
+				netboxInventory.Report.RecordSource(
+					sourceName,
+					sourceConfig.Type,
+					sourceStart,
+					initDuration,
+					syncDuration,
+					err,
+				)
+			}
+			metrics.SourceLastRunTimestamp.WithLabelValues(sourceName).Set(float64(time.Now().Unix()))
+			if err != nil {
+				ssotLogger.Error(sourceCtx, err)
+				metrics.SourceLastRunSuccess.WithLabelValues(sourceName).Set(0)
+				errorsLock.Lock()
+				encounteredErrors[sourceName] = err
+				errorsLock.Unlock()
+				return
+			}
+			metrics.SourceLastRunSuccess.WithLabelValues(sourceName).Set(1)
+		}(sourceCtxs[i], sourceConfigs[i], source)
+	}
+	wg.Wait()
+	return encounteredErrors, nil
+}
+
+// initAndSyncSource initializes the source and syncs it with the netbox inventory.
+// It returns durations of both phases. It returns as soon as sourceCtx is done.
+func initAndSyncSource(
+	sourceCtx context.Context,
+	ssotLogger *logger.Logger,
+	sourceName string,
+	source common.Source,
+	netboxInventory *inventory.NetboxInventory,
+) (initDuration time.Duration, syncDuration time.Duration, err error) {
+	// Source initialization
+	ssotLogger.Info(sourceCtx, "Initializing source")
+	phaseStart := time.Now()
+	err = initSource(sourceCtx, ssotLogger, sourceName, source)
+	initDuration = time.Since(phaseStart)
+	metrics.SourceDuration.WithLabelValues(sourceName, metrics.PhaseInit).Set(initDuration.Seconds())
+	if err != nil {
+		return initDuration, 0, err
+	}
+	ssotLogger.Infof(sourceCtx, "Successfully initialized source %s", constants.CheckMark)
+
+	// Source synchronization
+	ssotLogger.Info(sourceCtx, "Syncing source...")
+	phaseStart = time.Now()
+	err = source.Sync(sourceCtx, netboxInventory)
+	if err == nil {
+		// Send writes that are still queued, so the source is only
+		// successful if all of its objects are written to Netbox
+		err = netboxInventory.FlushWrites(sourceCtx)
+	}
+	syncDuration = time.Since(phaseStart)
+	metrics.SourceDuration.WithLabelValues(sourceName, metrics.PhaseSync).Set(syncDuration.Seconds())
+	if err != nil {
+		return initDuration, syncDuration, err
+	}
+	ssotLogger.Infof(sourceCtx, "Source synced successfully %s", constants.CheckMark)
+	return initDuration, syncDuration, nil
+}
+
+// abandonedInits holds done channels of initializations of sources by their
+// names, that were abandoned by initSource. Channels are closed once the
+// initialization returns.
+var (
+	abandonedInitsMu sync.Mutex
+	abandonedInits   = make(map[string]chan struct{})
+)
+
+// initSource initializes the source. Some of the client libraries used by
+// sources don't honour contexts, so initialization is abandoned when ctx is
+// done, to not block the whole run on a hung source. This is safe because
+// initialization doesn't touch netbox inventory. Sync can't be abandoned the
+// same way, but it stops on the first inventory call after ctx is done.
+//
+// Abandoned initialization keeps its goroutine and connections until it
+// returns, so the source is not initialized again until then (e.g. in the
+// next run of daemon mode), and at most one initialization per source leaks.
+func initSource(ctx context.Context, ssotLogger *logger.Logger, sourceName string, source common.Source) error {
+	abandonedInitsMu.Lock()
+	if done, ok := abandonedInits[sourceName]; ok {
+		select {
+		case <-done:
+			delete(abandonedInits, sourceName)
+		default:
+			abandonedInitsMu.Unlock()
+			return errors.New("previous initialization of the source is still running")
+		}
+	}
+	abandonedInitsMu.Unlock()
+
+	errCh := make(chan error, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		errCh <- source.Init(ctx)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		ssotLogger.Warningf(
+			ctx,
+			"Initialization of the source doesn't stop after the context is done. "+
+				"It is left running, and the source is skipped until it returns",
+		)
+		abandonedInitsMu.Lock()
+		abandonedInits[sourceName] = done
+		abandonedInitsMu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// writeMetricsFile writes all metrics to the file set with the
+// -metrics-file flag. Errors are only logged.
+func writeMetricsFile(ctx context.Context, ssotLogger *logger.Logger) {
+	if *metricsFile == "" {
+		return
+	}
+	if err := metrics.WriteFile(metrics.Default, *metricsFile); err != nil {
+		ssotLogger.Errorf(ctx, "write metrics file: %s", err)
+	}
+}
+
+// writeReportFile finishes the run report and writes it to the file set
+// with the -report-file flag. Errors are only logged.
+func writeReportFile(ctx context.Context, ssotLogger *logger.Logger, runReport *report.Report, success bool) {
+	if runReport == nil || *reportFile == "" {
+		return
+	}
+	runReport.Finish(time.Now(), success)
+	if err := runReport.WriteFile(*reportFile); err != nil {
+		ssotLogger.Errorf(ctx, "write report file: %s", err)
+	}
+}
+
+// printPlan prints the plan recorded in dry-run mode in text format to stdout.
+// JSON representation of the plan is written to planFile, or to stdout if
+// planFile is not set.
+func printPlan(plan *service.Plan, planFile string) error {
+	err := plan.WriteText(os.Stdout)
+	if err != nil {
+		return fmt.Errorf("write text plan: %s", err)
+	}
+	if planFile == "" {
+		fmt.Println()
+		return plan.WriteJSON(os.Stdout)
+	}
+	file, err := os.Create(planFile)
+	if err != nil {
+		return fmt.Errorf("create plan file: %s", err)
+	}
+	defer file.Close()
+	return plan.WriteJSON(file)
+}
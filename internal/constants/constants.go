@@ -1,5 +1,7 @@
 package constants
 
+import "time"
+
 type SourceType string
 
 const (
@@ -101,6 +103,28 @@ var SourceTypeTagColorMap = map[SourceType]string{
 const (
 	// API timeout in seconds.
 	DefaultAPITimeout = 15
+	// Number of retries of failed idempotent API requests.
+	DefaultAPIMaxRetries = 3
+	// Number of objects fetched in a single page.
+	DefaultAPIPageSize = 250
+	// Number of pages of the same object type fetched concurrently.
+	DefaultAPIPageWorkers = 4
+	// Maximum age of the state file in days, before objects are collected from scratch.
+	DefaultStateMaxAgeDays = 30
+)
+
+// Placeholders replaced in netbox.branch with the time of the run.
+const (
+	// BranchDatePlaceholder is replaced with the date of the run (e.g. 2025-01-31).
+	BranchDatePlaceholder = "{{date}}"
+	// BranchTimePlaceholder is replaced with the time of the run (e.g. 143000).
+	BranchTimePlaceholder = "{{time}}"
+)
+
+// Defaults for daemon mode.
+const (
+	DefaultDaemonSchedule                          = "1h"
+	DefaultDaemonFullRefreshInterval time.Duration = 24 * time.Hour
 )
 
 // Magic numbers for dealing with bytes.
@@ -169,6 +193,10 @@ const (
 	CustomFieldArpEntryDescription = "Was this IP collected from ARP table"
 )
 
+// FieldOwnerManual is the owner of fields (see netbox.fieldOwnership),
+// that are maintained manually in Netbox, and only filled by sources when empty.
+const FieldOwnerManual = "manual"
+
 // Device Role constants.
 const (
 	DeviceRoleFirewall            = "Firewall"
@@ -222,6 +250,7 @@ const (
 	ContentTypeDcimLocation             ContentType = "dcim.location"
 	ContentTypeDcimManufacturer         ContentType = "dcim.manufacturer"
 	ContentTypeDcimPlatform             ContentType = "dcim.platform"
+	ContentTypeDcimRack                 ContentType = "dcim.rack"
 	ContentTypeDcimRegion               ContentType = "dcim.region"
 	ContentTypeDcimSite                 ContentType = "dcim.site"
 	ContentTypeDcimSiteGroup            ContentType = "dcim.sitegroup"
@@ -306,8 +335,16 @@ const (
 	WirelessLANGroupsAPIPath APIPath = "/api/wireless/wireless-lan-groups/"
 
 	// Extras paths.
-	CustomFieldsAPIPath APIPath = "/api/extras/custom-fields/"
-	TagsAPIPath         APIPath = "/api/extras/tags/"
+	CustomFieldsAPIPath   APIPath = "/api/extras/custom-fields/"
+	TagsAPIPath           APIPath = "/api/extras/tags/"
+	JournalEntriesAPIPath APIPath = "/api/extras/journal-entries/"
+
+	// Core paths.
+	ObjectChangesAPIPath APIPath = "/api/core/object-changes/"
+
+	// Branching plugin paths.
+	BranchesAPIPath      APIPath = "/api/plugins/branching/branches/"
+	BranchChangesAPIPath APIPath = "/api/plugins/branching/changes/"
 )
 
 var Arch2Bit = map[string]string{
@@ -340,3 +377,27 @@ const (
 	MaxSerialNumberLength = 50
 	MaxAssetTagLength     = 50
 )
+
+// PlaceholderSerialNumbers are uppercase serial numbers, that are reported
+// by vendors instead of real serial numbers. Devices are never matched by
+// them (see netbox.deviceMatching.serialNumber).
+var PlaceholderSerialNumbers = map[string]bool{
+	"N/A":                    true,
+	"NA":                     true,
+	"NONE":                   true,
+	"NULL":                   true,
+	"UNKNOWN":                true,
+	"NOT SPECIFIED":          true,
+	"NOT AVAILABLE":          true,
+	"NOT APPLICABLE":         true,
+	"DEFAULT STRING":         true,
+	"TO BE FILLED BY O.E.M.": true,
+	"SYSTEM SERIAL NUMBER":   true,
+	"CHASSIS SERIAL NUMBER":  true,
+	"SERIAL NUMBER":          true,
+	"0123456789":             true,
+	"123456789":              true,
+	"INVALID":                true,
+	"EMPTY":                  true,
+	"-":                      true,
+}
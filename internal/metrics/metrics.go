@@ -0,0 +1,38 @@
+// Package metrics holds the prometheus registry with all netbox-ssot metrics,
+// that can be exposed over HTTP (/metrics endpoint) or written to a file
+// in prometheus text format (e.g. for node exporter's textfile collector).
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// readHeaderTimeout for the metrics server.
+const readHeaderTimeout = 10 * time.Second
+
+// WriteFile atomically writes all metrics of the registry in the prometheus
+// text format to the file at path, so collectors never read partially
+// written file.
+func WriteFile(registry prometheus.Gatherer, path string) error {
+	if err := prometheus.WriteToTextfile(path, registry); err != nil {
+		return fmt.Errorf("write metrics file: %s", err)
+	}
+	return nil
+}
+
+// NewServer returns http server, that serves all metrics of the
+// registry on /metrics.
+func NewServer(registry prometheus.Gatherer, address string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	return &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestWriteFile(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_gauge", Help: "Test gauge."}, []string{"source"})
+	registry.MustRegister(gauge)
+	gauge.WithLabelValues(`quoted"source`).Set(5) //nolint:mnd
+	path := filepath.Join(t.TempDir(), "netbox-ssot.prom")
+
+	if err := WriteFile(registry, path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read metrics file: %s", err)
+	}
+	want := "# HELP test_gauge Test gauge.\n# TYPE test_gauge gauge\ntest_gauge{source=\"quoted\\\"source\"} 5\n"
+	if string(got) != want {
+		t.Errorf("metrics file = %q, want %q", got, want)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("read metrics dir: %s", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temporary files were not removed: %v", entries)
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	requests := prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "test_requests_total", Help: "Test requests."},
+		[]string{"code"},
+	)
+	registry.MustRegister(requests)
+	requests.WithLabelValues("200").Inc()
+
+	server := httptest.NewServer(NewServer(registry, "").Handler)
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("get metrics: %s", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read metrics: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status code = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if !bytes.Contains(body, []byte(`test_requests_total{code="200"} 1`)) {
+		t.Errorf("body = %s, missing series", body)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	// All metrics must be gatherable, e.g. have consistent label names
+	SourceLastRunSuccess.WithLabelValues("vmware").Set(1)
+	if _, err := Default.Gather(); err != nil {
+		t.Errorf("Gather() error = %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Default is the registry holding all netbox-ssot metrics.
+var Default = prometheus.NewRegistry()
+
+// factory registers all netbox-ssot metrics to the Default registry.
+var factory = promauto.With(Default)
+
+// Labels used for the netbox-ssot metrics.
+const (
+	// PhaseInit is the phase label value for source initialization.
+	PhaseInit = "init"
+	// PhaseSync is the phase label value for source synchronization.
+	PhaseSync = "sync"
+
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+
+	OrphanActionTagged  = "tagged"
+	OrphanActionDeleted = "deleted"
+
+	// StatusCodeError is used as status code label value for requests,
+	// that didn't receive any response.
+	StatusCodeError = "error"
+)
+
+// apiRequestBuckets are histogram buckets (in seconds) for netbox api latencies.
+var apiRequestBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+var (
+	// SourceDuration is the duration of the last init or sync of each source.
+	SourceDuration = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "netbox_ssot_source_duration_seconds",
+			Help: "Duration of the last source init or sync phase in seconds.",
+		},
+		[]string{"source", "phase"},
+	)
+	// SourceLastRunSuccess is 1 if the last run of the source was successful, 0 otherwise.
+	SourceLastRunSuccess = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "netbox_ssot_source_last_run_success",
+			Help: "Whether the last run of the source was successful (1) or not (0).",
+		},
+		[]string{"source"},
+	)
+	// SourceLastRunTimestamp is the unix timestamp of the last finished run of the source.
+	SourceLastRunTimestamp = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "netbox_ssot_source_last_run_timestamp_seconds",
+			Help: "Unix timestamp of the last finished run of the source.",
+		},
+		[]string{"source"},
+	)
+	// ObjectChanges counts objects created, updated and deleted in Netbox.
+	ObjectChanges = factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "netbox_ssot_object_changes_total",
+			Help: "Number of objects created, updated or deleted in Netbox, by source and object type.",
+		},
+		[]string{"source", "object_type", "action"},
+	)
+	// APIRequests counts requests sent to the Netbox API.
+	APIRequests = factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "netbox_ssot_netbox_requests_total",
+			Help: "Number of requests sent to the Netbox API, by method and status code.",
+		},
+		[]string{"method", "code"},
+	)
+	// APIRetries counts retries of failed requests sent to the Netbox API.
+	APIRetries = factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "netbox_ssot_netbox_request_retries_total",
+			Help: "Number of retries of failed requests sent to the Netbox API, by method.",
+		},
+		[]string{"method"},
+	)
+	// APIRequestDuration observes latencies of the requests sent to the Netbox API.
+	APIRequestDuration = factory.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "netbox_ssot_netbox_request_duration_seconds",
+			Help:    "Latency of the requests sent to the Netbox API in seconds.",
+			Buckets: apiRequestBuckets,
+		},
+		[]string{"method"},
+	)
+	// Orphans counts orphaned objects, that were tagged as orphans or deleted.
+	// Objects are counted by their owning source, which is empty for objects
+	// shared by all sources.
+	Orphans = factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "netbox_ssot_orphans_total",
+			Help: "Number of orphaned objects tagged as orphans or deleted, by source and object type.",
+		},
+		[]string{"source", "object_type", "action"},
+	)
+)
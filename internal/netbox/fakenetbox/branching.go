@@ -0,0 +1,162 @@
+package fakenetbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+)
+
+// branchHeader is the header used by netbox-branching plugin to select
+// the branch that a request is applied to.
+const branchHeader = "X-NetBox-Branch"
+
+// branchingAPIPrefix is the prefix of api paths of netbox-branching plugin.
+const branchingAPIPrefix = "/api/plugins/branching/"
+
+// Statuses of branches served by the fake Netbox.
+const (
+	BranchStatusNew    = "new"
+	BranchStatusReady  = "ready"
+	BranchStatusMerged = "merged"
+)
+
+// Branch is a branch of netbox-branching plugin. Branches of the fake
+// Netbox don't isolate objects: changes made in a branch are applied to
+// the same objects as changes made in main, and are only counted as
+// changes of the branch.
+type Branch struct {
+	ID       int
+	Name     string
+	SchemaID string
+	Status   string
+	// Changes is the number of changes made in the branch.
+	Changes int
+}
+
+// AddBranch stores a branch with the given name and status, and returns it.
+// It is used to prepare existing branches in tests.
+func (s *Server) AddBranch(name string, status string) Branch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.createBranch(name, status)
+}
+
+// Branches returns all branches, ordered by their ids.
+func (s *Server) Branches() []Branch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]Branch, 0, len(s.branches))
+	for _, branch := range s.branches {
+		result = append(result, *branch)
+	}
+	return result
+}
+
+func (s *Server) createBranch(name string, status string) *Branch {
+	id := len(s.branches) + 1
+	branch := &Branch{ID: id, Name: name, SchemaID: fmt.Sprintf("%08d", id), Status: status}
+	s.branches = append(s.branches, branch)
+	return branch
+}
+
+// serveBranching serves requests of netbox-branching plugin. Created
+// branches are provisioned after the first status check, and merged
+// immediately.
+func (s *Server) serveBranching(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case r.URL.Path == string(constants.BranchesAPIPath) && r.Method == http.MethodGet:
+		results := make([]map[string]interface{}, 0)
+		for _, branch := range s.branches {
+			if !r.URL.Query().Has("name") || branch.Name == r.URL.Query().Get("name") {
+				results = append(results, renderBranch(branch))
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"count": len(results), "results": results})
+	case r.URL.Path == string(constants.BranchesAPIPath) && r.Method == http.MethodPost:
+		var body struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == "" {
+			writeError(w, http.StatusBadRequest, errors.New("branch name is required"))
+			return
+		}
+		writeJSON(w, http.StatusCreated, renderBranch(s.createBranch(body.Name, BranchStatusNew)))
+	case r.URL.Path == string(constants.BranchChangesAPIPath) && r.Method == http.MethodGet:
+		changes := 0
+		for _, branch := range s.branches {
+			if strconv.Itoa(branch.ID) == r.URL.Query().Get("branch_id") {
+				changes = branch.Changes
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"count": changes, "results": []interface{}{}})
+	default:
+		s.serveBranch(w, r)
+	}
+}
+
+// serveBranch serves requests of a single branch, in format
+// branches/<id>/ and branches/<id>/merge/.
+func (s *Server) serveBranch(w http.ResponseWriter, r *http.Request) {
+	rest, ok := strings.CutPrefix(r.URL.Path, string(constants.BranchesAPIPath))
+	if !ok {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+	idPart, action, _ := strings.Cut(strings.TrimSuffix(rest, "/"), "/")
+	id, err := strconv.Atoi(idPart)
+	if err != nil || id <= 0 || id > len(s.branches) {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+	branch := s.branches[id-1]
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		if branch.Status == BranchStatusNew {
+			branch.Status = BranchStatusReady
+		}
+		writeJSON(w, http.StatusOK, renderBranch(branch))
+	case action == "merge" && r.Method == http.MethodPost:
+		if branch.Status != BranchStatusReady {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("branch %s is %s", branch.Name, branch.Status))
+			return
+		}
+		branch.Status = BranchStatusMerged
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{"id": branch.ID, "status": "pending"})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+	}
+}
+
+// requestBranch returns the branch selected by the branch header of the
+// request, or nil for requests made in main.
+func (s *Server) requestBranch(r *http.Request) (*Branch, error) {
+	schemaID := r.Header.Get(branchHeader)
+	if schemaID == "" {
+		return nil, nil
+	}
+	for _, branch := range s.branches {
+		if branch.SchemaID == schemaID {
+			if branch.Status != BranchStatusReady {
+				return nil, fmt.Errorf("branch %s is %s", branch.Name, branch.Status)
+			}
+			return branch, nil
+		}
+	}
+	return nil, fmt.Errorf("branch with schema id %s doesn't exist", schemaID)
+}
+
+func renderBranch(branch *Branch) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        branch.ID,
+		"name":      branch.Name,
+		"schema_id": branch.SchemaID,
+		"status":    map[string]interface{}{"value": branch.Status, "label": branch.Status},
+	}
+}
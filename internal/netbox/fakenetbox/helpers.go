@@ -0,0 +1,99 @@
+package fakenetbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/mapper"
+	"github.com/src-doo/netbox-ssot/internal/parser"
+	"github.com/src-doo/netbox-ssot/internal/utils"
+)
+
+// Config returns Netbox configuration with default values, that points to the server.
+func (s *Server) Config() *parser.NetboxConfig {
+	serverURL, _ := url.Parse(s.URL)
+	port, _ := strconv.Atoi(serverURL.Port())
+	return &parser.NetboxConfig{
+		APIToken:      "fakenetbox",
+		Hostname:      serverURL.Hostname(),
+		Port:          port,
+		HTTPScheme:    "http",
+		Timeout:       constants.DefaultAPITimeout,
+		PageSize:      constants.DefaultAPIPageSize,
+		PageWorkers:   constants.DefaultAPIPageWorkers,
+		RemoveOrphans: true,
+		Tag:           constants.SsotTagName,
+		TagColor:      constants.SsotTagColor,
+	}
+}
+
+// Add stores the object in the server, like it was created with the
+// API, and returns the created object. It is used to prepare the
+// existing Netbox state in tests.
+func Add[T any](s *Server, object *T) (*T, error) {
+	path, err := pathOf[T]()
+	if err != nil {
+		return nil, err
+	}
+	body, err := utils.NetboxJSONMarshal(object)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %T: %s", object, err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("unmarshal %T: %s", object, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, err := s.createObject(path, fields)
+	if err != nil {
+		return nil, fmt.Errorf("create %T: %s", object, err)
+	}
+	return decode[T](s.render(path, id, nil))
+}
+
+// Objects returns all objects of type T stored in the server,
+// ordered by their ids.
+func Objects[T any](s *Server) ([]T, error) {
+	path, err := pathOf[T]()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]T, 0, len(s.objects[path]))
+	for _, id := range s.ids(path) {
+		object, err := decode[T](s.render(path, id, nil))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *object)
+	}
+	return result, nil
+}
+
+func pathOf[T any]() (constants.APIPath, error) {
+	var dummy T
+	path, ok := mapper.Type2Path[reflect.TypeOf(dummy)]
+	if !ok {
+		return "", fmt.Errorf("path not found for type %T", dummy)
+	}
+	return path, nil
+}
+
+func decode[T any](rendered map[string]interface{}) (*T, error) {
+	body, err := json.Marshal(rendered)
+	if err != nil {
+		return nil, err
+	}
+	var object T
+	if err := json.Unmarshal(body, &object); err != nil {
+		return nil, fmt.Errorf("unmarshal %T: %s", object, err)
+	}
+	return &object, nil
+}
@@ -0,0 +1,119 @@
+package fakenetbox
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/mapper"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+)
+
+// fieldKind determines how a field is stored and rendered.
+type fieldKind int
+
+const (
+	// fieldScalar is stored and rendered as received.
+	fieldScalar fieldKind = iota
+	// fieldChoice is stored as choice value, and rendered as {value, label}.
+	fieldChoice
+	// fieldReference is stored as id of the referenced object,
+	// and rendered as the brief referenced object.
+	fieldReference
+	// fieldReferenceList is stored as list of ids of the referenced objects,
+	// and rendered as a list of brief referenced objects.
+	fieldReferenceList
+)
+
+type field struct {
+	kind fieldKind
+	// path is the api path of referenced objects. It is empty for
+	// objects, that aren't served by the fake Netbox.
+	path constants.APIPath
+}
+
+// briefFields are fields of referenced objects, that are rendered in
+// nested objects. They match the brief representation used by Netbox.
+var briefFields = []string{"name", "slug", "model", "address", "mac_address", "vid", "color", "description"}
+
+// schemas hold json fields of all object types served by the fake Netbox.
+var schemas = func() map[constants.APIPath]map[string]field {
+	result := make(map[constants.APIPath]map[string]field, len(mapper.Path2Type))
+	for path, objectType := range mapper.Path2Type {
+		result[path] = schemaOf(objectType)
+	}
+	return result
+}()
+
+// contentTypes map api paths to content types of their objects.
+var contentTypes = func() map[constants.APIPath]constants.ContentType {
+	result := make(map[constants.APIPath]constants.ContentType, len(mapper.Path2Type))
+	for path, objectType := range mapper.Path2Type {
+		object, ok := reflect.New(objectType).Interface().(interface {
+			GetObjectType() constants.ContentType
+		})
+		if ok {
+			result[path] = object.GetObjectType()
+		}
+	}
+	return result
+}()
+
+// schemaOf returns all json fields of objectType, including
+// the fields of embedded structs.
+func schemaOf(objectType reflect.Type) map[string]field {
+	fields := make(map[string]field)
+	for i := 0; i < objectType.NumField(); i++ {
+		structField := objectType.Field(i)
+		if structField.Anonymous && structField.Type.Kind() == reflect.Struct {
+			for name, f := range schemaOf(structField.Type) {
+				fields[name] = f
+			}
+			continue
+		}
+		name, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+		if name == "" || name == "-" || name == "id" || !structField.IsExported() {
+			continue
+		}
+		fields[name] = fieldOf(structField.Type)
+	}
+	return fields
+}
+
+func fieldOf(fieldType reflect.Type) field {
+	fieldType = indirectType(fieldType)
+	switch fieldType.Kind() {
+	case reflect.Struct:
+		if isChoice(fieldType) {
+			return field{kind: fieldChoice}
+		}
+		if hasID(fieldType) {
+			return field{kind: fieldReference, path: mapper.Type2Path[fieldType]}
+		}
+	case reflect.Slice:
+		elemType := indirectType(fieldType.Elem())
+		if elemType.Kind() == reflect.Struct && hasID(elemType) {
+			return field{kind: fieldReferenceList, path: mapper.Type2Path[elemType]}
+		}
+	}
+	return field{kind: fieldScalar}
+}
+
+// isChoice returns true for structs that embed objects.Choice.
+func isChoice(structType reflect.Type) bool {
+	return structType.NumField() > 0 && structType.Field(0).Type == reflect.TypeOf(objects.Choice{})
+}
+
+// hasID returns true for structs that have an ID field,
+// either directly or through an embedded struct.
+func hasID(structType reflect.Type) bool {
+	_, ok := structType.FieldByName("ID")
+	return ok
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
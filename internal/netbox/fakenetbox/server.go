@@ -0,0 +1,643 @@
+// Package fakenetbox implements an in-memory Netbox, that serves the REST API
+// endpoints of all object types in mapper.Type2Path. It is used for end-to-end
+// tests of the inventory and the sources, and can be used to reproduce bugs
+// without a real Netbox.
+//
+// The fake Netbox supports pagination, bulk writes, bulk deletes, the fields
+// query param and basic filtering (by id, field values, ids of referenced
+// objects and last update time). Every write is recorded in the changelog.
+// Branches of netbox-branching plugin are supported as well (see Branch).
+package fakenetbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+)
+
+// DefaultVersion is the Netbox version reported by the fake Netbox.
+const DefaultVersion = "4.2.3"
+
+// defaultPageSize is used for list requests without the limit query param.
+const defaultPageSize = 50
+
+// errNotFound is returned for objects that don't exist.
+var errNotFound = errors.New("not found")
+
+// Server is an in-memory Netbox served over HTTP.
+type Server struct {
+	*httptest.Server
+	// Version is the Netbox version returned by /api/status/.
+	Version string
+
+	mu sync.Mutex
+	// objects are all stored objects, indexed by their api path and id.
+	// Referenced objects are stored only with their ids,
+	// and choices only with their values.
+	objects map[constants.APIPath]map[int]*object
+	// lastIDs are ids of the last created objects of each api path.
+	lastIDs map[constants.APIPath]int
+	// branches are branches of netbox-branching plugin, ordered by their ids.
+	branches []*Branch
+	// branch is the branch of the request being served. It is nil for
+	// requests made in main.
+	branch *Branch
+	// requests are all received requests.
+	requests []Request
+}
+
+// Request is a request received by the fake Netbox.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+}
+
+type object struct {
+	fields      map[string]interface{}
+	lastUpdated time.Time
+}
+
+// NewServer starts a new empty fake Netbox. It should be closed with Close.
+func NewServer() *Server {
+	s := &Server{
+		Version: DefaultVersion,
+		objects: make(map[constants.APIPath]map[int]*object),
+		lastIDs: make(map[constants.APIPath]int),
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Requests returns all requests received by the server, in the order
+// they were received.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// ServeHTTP serves requests of Netbox's REST API.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone()})
+	s.mu.Unlock()
+	if strings.HasPrefix(r.URL.Path, branchingAPIPrefix) {
+		s.serveBranching(w, r)
+		return
+	}
+	if strings.TrimSuffix(r.URL.Path, "/") == "/api/status" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"netbox-version": s.Version})
+		return
+	}
+	path, id, ok := parsePath(r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+	if path == constants.ObjectChangesAPIPath && r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("changelog is read-only"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	branch, err := s.requestBranch(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.branch = branch
+	defer func() { s.branch = nil }()
+	switch {
+	case id == 0 && r.Method == http.MethodGet:
+		s.list(w, r, path)
+	case id == 0 && r.Method == http.MethodPost:
+		s.create(w, r, path)
+	case id == 0 && r.Method == http.MethodPatch:
+		s.bulkPatch(w, r, path)
+	case id == 0 && r.Method == http.MethodDelete:
+		s.bulkDelete(w, r, path)
+	case r.Method == http.MethodGet:
+		s.get(w, r, path, id)
+	case r.Method == http.MethodPatch:
+		s.patch(w, r, path, id)
+	case r.Method == http.MethodDelete:
+		s.delete(w, path, id)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+	}
+}
+
+// parsePath returns api path and id of the requested object.
+// For list endpoints id is 0.
+func parsePath(requestPath string) (constants.APIPath, int, bool) {
+	for path := range schemas {
+		if !strings.HasPrefix(requestPath, string(path)) {
+			continue
+		}
+		rest := strings.TrimSuffix(strings.TrimPrefix(requestPath, string(path)), "/")
+		if rest == "" {
+			return path, 0, true
+		}
+		id, err := strconv.Atoi(rest)
+		if err != nil || id <= 0 {
+			return "", 0, false
+		}
+		return path, id, true
+	}
+	return "", 0, false
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, path constants.APIPath) {
+	query := r.URL.Query()
+	limit, offset := defaultPageSize, 0
+	var err error
+	if query.Has("limit") {
+		if limit, err = strconv.Atoi(query.Get("limit")); err != nil || limit < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %s", query.Get("limit")))
+			return
+		}
+	}
+	if query.Has("offset") {
+		if offset, err = strconv.Atoi(query.Get("offset")); err != nil || offset < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid offset %s", query.Get("offset")))
+			return
+		}
+	}
+
+	matching := make([]int, 0, len(s.objects[path]))
+	for _, id := range s.ids(path) {
+		ok, err := s.matches(path, s.objects[path][id], query)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if ok {
+			matching = append(matching, id)
+		}
+	}
+	// Limit 0 returns all objects
+	end := len(matching)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	results := make([]map[string]interface{}, 0)
+	for i := offset; i < end; i++ {
+		results = append(results, s.render(path, matching[i], query))
+	}
+
+	var next, previous *string
+	if end < len(matching) {
+		next = pageURL(r, end)
+	}
+	if offset > 0 && limit > 0 {
+		previous = pageURL(r, max(offset-limit, 0))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"count":    len(matching),
+		"next":     next,
+		"previous": previous,
+		"results":  results,
+	})
+}
+
+// pageURL returns url of the page of the list request r, that starts at offset.
+func pageURL(r *http.Request, offset int) *string {
+	query := r.URL.Query()
+	query.Set("offset", strconv.Itoa(offset))
+	pageURL := (&url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path, RawQuery: query.Encode()}).String()
+	return &pageURL
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request, path constants.APIPath, id int) {
+	if s.objects[path][id] == nil {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.render(path, id, r.URL.Query()))
+}
+
+// create creates a single object, or a list of objects.
+func (s *Server) create(w http.ResponseWriter, r *http.Request, path constants.APIPath) {
+	body, isList, err := decodeBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	ids := make([]int, 0, len(body))
+	for _, fields := range body {
+		id, err := s.createObject(path, fields)
+		if err != nil {
+			s.rollback(path, ids)
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		ids = append(ids, id)
+	}
+	s.respond(w, r, http.StatusCreated, path, ids, isList)
+}
+
+// bulkPatch updates a list of objects. Each object must contain its id.
+func (s *Server) bulkPatch(w http.ResponseWriter, r *http.Request, path constants.APIPath) {
+	body, _, err := decodeBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	ids := make([]int, 0, len(body))
+	for _, fields := range body {
+		id, ok := referenceID(fields["id"])
+		if !ok || s.objects[path][id] == nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("object with id %v doesn't exist", fields["id"]))
+			return
+		}
+		ids = append(ids, id)
+	}
+	// All objects are validated first, so the request is atomic like in Netbox
+	normalized := make([]map[string]interface{}, 0, len(body))
+	for i, fields := range body {
+		delete(fields, "id")
+		normalizedFields, err := s.normalize(path, fields)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("object %d: %s", ids[i], err))
+			return
+		}
+		normalized = append(normalized, normalizedFields)
+	}
+	for i, fields := range normalized {
+		s.updateObject(path, ids[i], fields)
+	}
+	s.respond(w, r, http.StatusOK, path, ids, true)
+}
+
+// bulkDelete deletes a list of objects, in format [{"id": 1}, {"id": 2}].
+func (s *Server) bulkDelete(w http.ResponseWriter, r *http.Request, path constants.APIPath) {
+	body, _, err := decodeBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	ids := make([]int, 0, len(body))
+	for _, fields := range body {
+		id, ok := referenceID(fields["id"])
+		if !ok || s.objects[path][id] == nil {
+			writeError(w, http.StatusNotFound, fmt.Errorf("object with id %v doesn't exist", fields["id"]))
+			return
+		}
+		ids = append(ids, id)
+	}
+	for _, id := range ids {
+		s.deleteObject(path, id)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) patch(w http.ResponseWriter, r *http.Request, path constants.APIPath, id int) {
+	if s.objects[path][id] == nil {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+	body, isList, err := decodeBody(r)
+	if err != nil || isList {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %v", err))
+		return
+	}
+	fields, err := s.normalize(path, body[0])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.updateObject(path, id, fields)
+	s.respond(w, r, http.StatusOK, path, []int{id}, false)
+}
+
+func (s *Server) delete(w http.ResponseWriter, path constants.APIPath, id int) {
+	if s.objects[path][id] == nil {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+	s.deleteObject(path, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respond writes rendered objects with the given ids.
+func (s *Server) respond(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	path constants.APIPath,
+	ids []int,
+	isList bool,
+) {
+	if !isList {
+		writeJSON(w, status, s.render(path, ids[0], r.URL.Query()))
+		return
+	}
+	results := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		results = append(results, s.render(path, id, r.URL.Query()))
+	}
+	writeJSON(w, status, results)
+}
+
+// createObject stores a new object with the given fields, and returns its id.
+func (s *Server) createObject(path constants.APIPath, fields map[string]interface{}) (int, error) {
+	delete(fields, "id")
+	normalized, err := s.normalize(path, fields)
+	if err != nil {
+		return 0, err
+	}
+	s.lastIDs[path]++
+	id := s.lastIDs[path]
+	normalized["id"] = id
+	if s.objects[path] == nil {
+		s.objects[path] = make(map[int]*object)
+	}
+	s.objects[path][id] = &object{fields: normalized, lastUpdated: time.Now()}
+	s.recordChange(path, id, objects.ObjectChangeActionCreate)
+	return id, nil
+}
+
+// updateObject updates fields of the existing object. Custom fields
+// are merged with the existing ones, like Netbox does.
+func (s *Server) updateObject(path constants.APIPath, id int, fields map[string]interface{}) {
+	existing := s.objects[path][id]
+	for name, value := range fields {
+		customFields, ok := value.(map[string]interface{})
+		existingCustomFields, existingOk := existing.fields[name].(map[string]interface{})
+		if name == "custom_fields" && ok && existingOk {
+			for label, customFieldValue := range customFields {
+				existingCustomFields[label] = customFieldValue
+			}
+			continue
+		}
+		existing.fields[name] = value
+	}
+	existing.lastUpdated = time.Now()
+	s.recordChange(path, id, objects.ObjectChangeActionUpdate)
+}
+
+func (s *Server) deleteObject(path constants.APIPath, id int) {
+	delete(s.objects[path], id)
+	s.recordChange(path, id, objects.ObjectChangeActionDelete)
+}
+
+// rollback removes objects created by a failed bulk create.
+func (s *Server) rollback(path constants.APIPath, ids []int) {
+	for _, id := range ids {
+		s.deleteObject(path, id)
+	}
+}
+
+// recordChange adds an entry to the changelog. Changes made in a branch
+// are also counted as changes of the branch.
+func (s *Server) recordChange(path constants.APIPath, id int, action objects.ObjectChangeAction) {
+	if s.branch != nil {
+		s.branch.Changes++
+	}
+	changelogPath := constants.ObjectChangesAPIPath
+	if s.objects[changelogPath] == nil {
+		s.objects[changelogPath] = make(map[int]*object)
+	}
+	s.lastIDs[changelogPath]++
+	now := time.Now()
+	s.objects[changelogPath][s.lastIDs[changelogPath]] = &object{
+		fields: map[string]interface{}{
+			"id":                  s.lastIDs[changelogPath],
+			"time":                now.UTC().Format(time.RFC3339Nano),
+			"action":              action.Value,
+			"changed_object_type": string(contentTypes[path]),
+			"changed_object_id":   id,
+		},
+		lastUpdated: now,
+	}
+}
+
+// normalize converts received fields into their stored form.
+// References must point to existing objects.
+func (s *Server) normalize(path constants.APIPath, fields map[string]interface{}) (map[string]interface{}, error) {
+	normalized := make(map[string]interface{}, len(fields))
+	for name, value := range fields {
+		f := schemas[path][name]
+		switch {
+		case value == nil:
+			normalized[name] = nil
+		case f.kind == fieldChoice:
+			choiceValue, err := normalizeChoice(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", name, err)
+			}
+			normalized[name] = choiceValue
+		case f.kind == fieldReference:
+			id, err := s.normalizeReference(f.path, value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", name, err)
+			}
+			normalized[name] = id
+		case f.kind == fieldReferenceList:
+			values, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: expected a list, got %v", name, value)
+			}
+			ids := make([]int, 0, len(values))
+			for _, v := range values {
+				id, err := s.normalizeReference(f.path, v)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %s", name, err)
+				}
+				ids = append(ids, id)
+			}
+			normalized[name] = ids
+		default:
+			normalized[name] = value
+		}
+	}
+	return normalized, nil
+}
+
+func normalizeChoice(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case map[string]interface{}:
+		if choiceValue, ok := v["value"].(string); ok {
+			return choiceValue, nil
+		}
+	}
+	return "", fmt.Errorf("invalid choice %v", value)
+}
+
+// normalizeReference returns id of the referenced object. References
+// are received as ids, or as objects with ids.
+func (s *Server) normalizeReference(path constants.APIPath, value interface{}) (int, error) {
+	id, ok := referenceID(value)
+	if !ok {
+		return 0, fmt.Errorf("invalid reference %v", value)
+	}
+	if path != "" && s.objects[path][id] == nil {
+		return 0, fmt.Errorf("referenced object %s%d/ doesn't exist", path, id)
+	}
+	return id, nil
+}
+
+func referenceID(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case float64:
+		return int(v), v > 0 && v == float64(int(v))
+	case map[string]interface{}:
+		return referenceID(v["id"])
+	}
+	return 0, false
+}
+
+// render returns the object in the form returned by Netbox. When
+// the fields query param is set, only the requested fields are returned.
+func (s *Server) render(path constants.APIPath, id int, query url.Values) map[string]interface{} {
+	stored := s.objects[path][id]
+	var requested []string
+	if query.Get("fields") != "" {
+		requested = strings.Split(query.Get("fields"), ",")
+	}
+	rendered := make(map[string]interface{}, len(stored.fields)+1)
+	if requested == nil || slices.Contains(requested, "last_updated") {
+		rendered["last_updated"] = stored.lastUpdated.UTC().Format(time.RFC3339Nano)
+	}
+	for name, value := range stored.fields {
+		if requested != nil && name != "id" && !slices.Contains(requested, name) {
+			continue
+		}
+		f := schemas[path][name]
+		switch {
+		case value == nil:
+			rendered[name] = nil
+		case f.kind == fieldChoice:
+			rendered[name] = map[string]interface{}{"value": value, "label": value}
+		case f.kind == fieldReference:
+			rendered[name] = s.renderBrief(f.path, value.(int))
+		case f.kind == fieldReferenceList:
+			references := make([]interface{}, 0)
+			for _, referenceID := range value.([]int) {
+				references = append(references, s.renderBrief(f.path, referenceID))
+			}
+			rendered[name] = references
+		default:
+			rendered[name] = value
+		}
+	}
+	return rendered
+}
+
+// renderBrief returns the brief representation of a referenced object.
+func (s *Server) renderBrief(path constants.APIPath, id int) map[string]interface{} {
+	brief := map[string]interface{}{"id": id}
+	stored := s.objects[path][id]
+	if stored == nil {
+		return brief
+	}
+	for _, name := range briefFields {
+		if value, ok := stored.fields[name]; ok && schemas[path][name].kind == fieldScalar {
+			brief[name] = value
+		}
+	}
+	return brief
+}
+
+// matches returns true if the object matches all filters in query.
+// Unknown filters and query params that aren't filters (e.g. limit)
+// are ignored.
+func (s *Server) matches(path constants.APIPath, obj *object, query url.Values) (bool, error) {
+	for key, values := range query {
+		f, isField := schemas[path][key]
+		referenceName, isReferenceFilter := strings.CutSuffix(key, "_id")
+		isReferenceFilter = isReferenceFilter && schemas[path][referenceName].kind == fieldReference
+		var ok bool
+		var err error
+		switch {
+		case key == "id":
+			ok = slices.Contains(values, strconv.Itoa(obj.fields["id"].(int)))
+		case key == "last_updated__gte":
+			ok, err = timeAfter(obj.lastUpdated, values[0])
+		case path == constants.ObjectChangesAPIPath && key == "time_after":
+			changeTime, _ := time.Parse(time.RFC3339Nano, obj.fields["time"].(string))
+			ok, err = timeAfter(changeTime, values[0])
+		case isReferenceFilter:
+			ok = slices.Contains(values, fmt.Sprint(obj.fields[referenceName]))
+		case isField && (f.kind == fieldScalar || f.kind == fieldChoice):
+			ok = slices.Contains(values, fmt.Sprint(obj.fields[key]))
+		default:
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("filter %s: %s", key, err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// timeAfter returns true if t is not before the time in RFC3339 format.
+func timeAfter(t time.Time, value string) (bool, error) {
+	after, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return false, err
+	}
+	return !t.Before(after), nil
+}
+
+// ids returns ids of all objects of the given path in ascending order.
+func (s *Server) ids(path constants.APIPath) []int {
+	ids := make([]int, 0, len(s.objects[path]))
+	for id := range s.objects[path] {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// decodeBody decodes a single object or a list of objects from the
+// request body. It also returns whether the body was a list.
+func decodeBody(r *http.Request) ([]map[string]interface{}, bool, error) {
+	var body interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, false, fmt.Errorf("decode body: %s", err)
+	}
+	switch v := body.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{v}, false, nil
+	case []interface{}:
+		result := make([]map[string]interface{}, 0, len(v))
+		for _, item := range v {
+			fields, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, true, fmt.Errorf("expected list of objects, got %v", item)
+			}
+			result = append(result, fields)
+		}
+		return result, true, nil
+	}
+	return nil, false, fmt.Errorf("unexpected body %v", body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]interface{}{"detail": err.Error()})
+}
@@ -0,0 +1,224 @@
+package fakenetbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/logger"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/netbox/service"
+)
+
+func newTestClient(s *Server) *service.NetboxClient {
+	return &service.NetboxClient{
+		HTTPClient: &http.Client{},
+		Logger:     &logger.Logger{Logger: log.New(os.Stdout, "", log.LstdFlags)},
+		BaseURL:    s.URL,
+		Timeout:    constants.DefaultAPITimeout,
+		PageSize:   2,
+	}
+}
+
+func TestServer_GetVersion(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Version = "4.3.1"
+	version, err := service.GetVersion(context.Background(), newTestClient(s))
+	if err != nil {
+		t.Fatalf("GetVersion() error = %v", err)
+	}
+	if version != "4.3.1" {
+		t.Errorf("GetVersion() = %s, want 4.3.1", version)
+	}
+}
+
+func TestServer_CRUD(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	ctx := context.Background()
+	client := newTestClient(s)
+
+	tag, err := service.Create(ctx, client, &objects.Tag{Name: "netbox-ssot", Slug: "netbox-ssot"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	site, err := service.Create(ctx, client, &objects.Site{Name: "site", Slug: "site"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	devices := make([]*objects.Device, 0)
+	for i := 1; i <= 5; i++ {
+		device, err := service.Create(ctx, client, &objects.Device{
+			NetboxObject: objects.NetboxObject{Tags: []*objects.Tag{tag}},
+			Name:         fmt.Sprintf("device%d", i),
+			Site:         site,
+			Status:       &objects.DeviceStatusActive,
+		})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		devices = append(devices, device)
+	}
+	want := &objects.Device{
+		NetboxObject: objects.NetboxObject{
+			ID:   1,
+			Tags: []*objects.Tag{{ID: 1, Name: "netbox-ssot", Slug: "netbox-ssot"}},
+		},
+		Name:   "device1",
+		Site:   &objects.Site{NetboxObject: objects.NetboxObject{ID: 1}, Name: "site", Slug: "site"},
+		Status: &objects.DeviceStatus{Choice: objects.Choice{Value: "active", Label: "active"}},
+	}
+	if !reflect.DeepEqual(devices[0], want) {
+		t.Errorf("Create() = %v, want %v", devices[0], want)
+	}
+
+	// Devices are fetched in pages of 2
+	allDevices, err := service.GetAll[objects.Device](ctx, client, "")
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if len(allDevices) != len(devices) || allDevices[4].Name != "device5" {
+		t.Errorf("GetAll() = %v, want all devices", allDevices)
+	}
+
+	patched, err := service.Patch[objects.Device](ctx, client, 2, map[string]interface{}{"serial": "1234"})
+	if err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+	if patched.SerialNumber != "1234" || patched.Name != "device2" {
+		t.Errorf("Patch() = %v, want device2 with serial 1234", patched)
+	}
+
+	if err := client.DeleteObject(ctx, devices[0]); err != nil {
+		t.Fatalf("DeleteObject() error = %v", err)
+	}
+	if err := client.BulkDeleteObjects(ctx, constants.DevicesAPIPath, map[int]bool{2: true, 3: true}); err != nil {
+		t.Fatalf("BulkDeleteObjects() error = %v", err)
+	}
+	remaining, err := Objects[objects.Device](s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 2 || remaining[0].ID != 4 || remaining[1].ID != 5 {
+		t.Errorf("remaining devices = %v, want devices 4 and 5", remaining)
+	}
+
+	deletions, err := service.GetAll[objects.ObjectChange](ctx, client, "&action=delete")
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if len(deletions) != 3 || deletions[0].ChangedObjectType != constants.ContentTypeDcimDevice ||
+		deletions[0].ChangedObjectID != 1 {
+		t.Errorf("GetAll() = %v, want deletions of devices 1, 2 and 3", deletions)
+	}
+}
+
+func TestServer_InvalidReference(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	_, err := service.Create(context.Background(), newTestClient(s), &objects.Device{
+		Name: "device",
+		Site: &objects.Site{NetboxObject: objects.NetboxObject{ID: 7}},
+	})
+	if err == nil {
+		t.Errorf("Create() of device with missing site succeeded")
+	}
+	if devices, _ := Objects[objects.Device](s); len(devices) != 0 {
+		t.Errorf("device with missing site was created: %v", devices)
+	}
+}
+
+func TestServer_Filters(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	site1, err := Add(s, &objects.Site{Name: "site1", Slug: "site1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	site2, err := Add(s, &objects.Site{Name: "site2", Slug: "site2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, device := range []*objects.Device{
+		{Name: "device1", Site: site1, Status: &objects.DeviceStatusActive},
+		{Name: "device2", Site: site2, Status: &objects.DeviceStatusOffline},
+		{Name: "device3", Site: site2, Status: &objects.DeviceStatusActive},
+	} {
+		if _, err := Add(s, device); err != nil {
+			t.Fatal(err)
+		}
+	}
+	future := url.QueryEscape(time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	past := url.QueryEscape(time.Now().Add(-time.Hour).UTC().Format(time.RFC3339))
+
+	tests := []struct {
+		name      string
+		query     string
+		wantNames []string
+	}{
+		{name: "no filter", query: "", wantNames: []string{"device1", "device2", "device3"}},
+		{name: "id", query: "id=1&id=3", wantNames: []string{"device1", "device3"}},
+		{name: "field", query: "name=device2", wantNames: []string{"device2"}},
+		{name: "choice", query: "status=active", wantNames: []string{"device1", "device3"}},
+		{name: "reference", query: "site_id=2", wantNames: []string{"device2", "device3"}},
+		{name: "combined", query: "site_id=2&status=active", wantNames: []string{"device3"}},
+		{name: "updated since", query: "last_updated__gte=" + past, wantNames: []string{"device1", "device2", "device3"}},
+		{name: "updated in future", query: "last_updated__gte=" + future, wantNames: []string{}},
+		{name: "unknown filter", query: "foo=bar", wantNames: []string{"device1", "device2", "device3"}},
+		{name: "pagination", query: "limit=1&offset=1", wantNames: []string{"device2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := http.Get(fmt.Sprintf("%s%s?%s", s.URL, constants.DevicesAPIPath, tt.query))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer response.Body.Close()
+			var page service.Response[objects.Device]
+			if err := json.NewDecoder(response.Body).Decode(&page); err != nil {
+				t.Fatal(err)
+			}
+			names := make([]string, 0)
+			for _, device := range page.Results {
+				names = append(names, device.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("%s returned %v, want %v", tt.query, names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestServer_Fields(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	if _, err := Add(s, &objects.Site{
+		NetboxObject: objects.NetboxObject{Description: "description"},
+		Name:         "site",
+		Slug:         "site",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	response, err := http.Get(s.URL + string(constants.SitesAPIPath) + "?fields=id,name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	var page service.Response[map[string]interface{}]
+	if err := json.NewDecoder(response.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]interface{}{{"id": float64(1), "name": "site"}}
+	if !reflect.DeepEqual(page.Results, want) {
+		t.Errorf("results = %v, want %v", page.Results, want)
+	}
+}
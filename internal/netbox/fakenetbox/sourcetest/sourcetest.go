@@ -0,0 +1,70 @@
+// Package sourcetest runs sources against the fake Netbox (see fakenetbox)
+// in end-to-end tests of the sources. It is a separate package, because
+// fakenetbox is used by tests of the inventory, so it can't import it.
+package sourcetest
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/logger"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox"
+	"github.com/src-doo/netbox-ssot/internal/netbox/inventory"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/parser"
+	"github.com/src-doo/netbox-ssot/internal/source/common"
+	"github.com/src-doo/netbox-ssot/internal/utils"
+)
+
+// SyncFunc syncs the source with config into the inventory.
+type SyncFunc func(ctx context.Context, nbi *inventory.NetboxInventory, config common.Config) error
+
+// Run runs netbox-ssot with a single source against the fake Netbox server,
+// like a scheduled run does: it initializes the inventory from the server,
+// adds tags of the source, syncs the source with sync, flushes its queued
+// writes and deletes orphans. The server can then be checked for objects
+// of the source (see fakenetbox.Objects), that are left after the run.
+func Run(t *testing.T, server *fakenetbox.Server, sourceConfig *parser.SourceConfig, sync SyncFunc) {
+	t.Helper()
+	testLogger := &logger.Logger{Logger: log.New(os.Stdout, "", log.LstdFlags)}
+	ctx := context.WithValue(context.Background(), constants.CtxSourceKey, sourceConfig.Name)
+	nbi := inventory.NewNetboxInventory(ctx, testLogger, server.Config())
+	if err := nbi.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if sourceConfig.Tag == "" {
+		sourceConfig.Tag = "Source: " + sourceConfig.Name
+	}
+	sourceNameTag, err := nbi.AddTag(ctx, &objects.Tag{
+		Name: sourceConfig.Tag,
+		Slug: utils.Slugify("source-" + sourceConfig.Name),
+	})
+	if err != nil {
+		t.Fatalf("AddTag() error = %v", err)
+	}
+	sourceTypeTag, err := nbi.AddTag(ctx, &objects.Tag{
+		Name: string(sourceConfig.Type),
+		Slug: utils.Slugify("type-" + string(sourceConfig.Type)),
+	})
+	if err != nil {
+		t.Fatalf("AddTag() error = %v", err)
+	}
+	config := common.Config{
+		Logger:        testLogger,
+		SourceConfig:  sourceConfig,
+		SourceNameTag: sourceNameTag,
+		SourceTypeTag: sourceTypeTag,
+	}
+	if err := sync(ctx, nbi, config); err != nil {
+		t.Fatalf("sync error = %v", err)
+	}
+	if err := nbi.FlushWrites(ctx); err != nil {
+		t.Fatalf("FlushWrites() error = %v", err)
+	}
+	if err := nbi.DeleteOrphans(ctx, true, nil); err != nil {
+		t.Fatalf("DeleteOrphans() error = %v", err)
+	}
+}
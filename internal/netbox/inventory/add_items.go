@@ -7,43 +7,184 @@ import (
 
 	"github.com/src-doo/netbox-ssot/internal/constants"
 	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
-	"github.com/src-doo/netbox-ssot/internal/netbox/service"
-	"github.com/src-doo/netbox-ssot/internal/utils"
+	"github.com/src-doo/netbox-ssot/internal/netbox/version"
+	"github.com/src-doo/netbox-ssot/internal/report"
 )
 
-// AddTag adds the newTag from source sourceName to the local inventory.
-func (nbi *NetboxInventory) AddTag(ctx context.Context, newTag *objects.Tag) (*objects.Tag, error) {
-	nbi.tagsLock.Lock()
-	defer nbi.tagsLock.Unlock()
-	if _, ok := nbi.tagsIndexByName[newTag.Name]; ok {
-		oldTag := nbi.tagsIndexByName[newTag.Name]
-		diffMap, err := utils.JSONDiffMapExceptID(newTag, oldTag, false, nbi.SourcePriority)
-		if err != nil {
+// addSpec holds the metadata of an object type, that addItem needs
+// besides the index of the type. Nil addSpec is the default for types
+// without hooks.
+type addSpec[K comparable, T objects.OrphanItem] struct {
+	// sourceName adds the source name custom field to added objects.
+	sourceName bool
+	// matchSourceID matches objects by their source ids before their keys,
+	// so objects that are renamed or moved on the source (e.g. a vm moved
+	// to another cluster) are patched instead of created again.
+	matchSourceID bool
+	// keyFields are json fields of the key of objects matched by source id,
+	// and fields that must stay consistent with it (e.g. the host of a vm).
+	// They aren't patched, when the object was renamed or moved on the source,
+	// but its new key is already taken by another object.
+	keyFields []string
+	// prepare is called before the object is added, e.g. to truncate fields
+	// that are too long. The object is not added, if it returns an error.
+	prepare func(object T) error
+	// key returns the key of the object, for indexes without a key function.
+	// It is called before the index is locked (see ipAddressKey).
+	key func(object T) (K, error)
+}
+
+// addItem adds newObject from the source to the index. Objects with the same
+// key as newObject (or the same source id or secondary key, see addSpec and
+// AddSecondaryIndex) are patched with the fields that differ, otherwise newObject
+// is created. Added objects get the ssot tag, and objects tracked by the orphan
+// manager are removed from it, because they still exist in the sources.
+// It returns the object that is stored in the index.
+func addItem[T any, K comparable, PT interface {
+	*T
+	objects.OrphanItem
+}](
+	ctx context.Context,
+	nbi *NetboxInventory,
+	index *Index[K, PT],
+	newObject PT,
+	spec *addSpec[K, PT],
+) (PT, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if spec == nil {
+		spec = &addSpec[K, PT]{}
+	}
+	if netboxObject := newObject.GetNetboxObject(); netboxObject != nil {
+		netboxObject.AddTag(nbi.SsotTag)
+		if spec.sourceName {
+			addSourceNameCustomField(ctx, netboxObject)
+		}
+		if index.orphanManager != nil {
+			netboxObject.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
+		}
+	}
+	if spec.prepare != nil {
+		if err := spec.prepare(newObject); err != nil {
 			return nil, err
 		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
+	}
+	if err := nbi.flushFullWrites(ctx, newObject); err != nil {
+		return nil, err
+	}
+
+	// store is called by the write queue with patched objects
+	store := func(object *T) { index.Upsert(object) }
+	var key K
+	if spec.key != nil {
+		var err error
+		key, err = spec.key(newObject)
+		if err != nil {
+			return nil, fmt.Errorf("key of %s: %s", newObject, err)
+		}
+		store = func(object *T) { index.UpsertKey(key, object) }
+	}
+
+	index.Lock()
+	defer index.Unlock()
+	if spec.key == nil {
+		key = index.Key(newObject)
+	}
+	// The same object can be created by another goroutine in the meantime,
+	// e.g. while it waits for its bulk create request
+	for {
+		pending, ok := index.LookupPending(key, newObject)
+		if !ok {
+			break
+		}
+		index.Unlock()
+		<-pending
+		index.Lock()
+	}
+	oldObject, ok := index.Lookup(key)
+	// keyOwner is another object, that has the key of the object matched by source id
+	var keyOwner PT
+	if spec.matchSourceID {
+		if sourceObject, found := index.LookupBySourceID(newObject); found {
+			if ok && sourceObject.GetID() != oldObject.GetID() {
+				keyOwner = oldObject
+			}
+			oldObject, ok = sourceObject, true
+		}
+	}
+	if !ok {
+		// Objects reported by multiple sources under different keys
+		// (e.g. devices with fqdns and short names), see AddSecondaryIndex
+		secondaryObject, indexName, found, ambiguous := index.LookupSecondary(newObject)
+		for _, match := range ambiguous {
+			nbi.Logger.Warningf(
 				ctx,
-				"Tag %s already exists in Netbox but is out of date. Patching it... ",
-				newTag.Name,
+				"%s matches multiple existing objects %v by %s, so it isn't matched by %s",
+				newObject,
+				match.objects,
+				match.index,
+				match.index,
 			)
-			patchedTag, err := service.Patch[objects.Tag](ctx, nbi.NetboxAPI, oldTag.ID, diffMap)
-			if err != nil {
-				return nil, err
-			}
-			nbi.tagsIndexByName[newTag.Name] = patchedTag
-		} else {
-			nbi.Logger.Debugf(ctx, "Tag %s already exists in Netbox and is up to date...", newTag.Name)
 		}
-	} else {
-		nbi.Logger.Debugf(ctx, "Tag %s does not exist in Netbox. Creating it...", newTag.Name)
-		createdTag, err := service.Create(ctx, nbi.NetboxAPI, newTag)
+		if found {
+			nbi.Logger.Debugf(ctx, "%s matches existing %s by %s", newObject, secondaryObject, indexName)
+			oldObject, ok = secondaryObject, true
+		}
+	}
+	if !ok {
+		nbi.Logger.Debugf(ctx, "%s does not exist in Netbox. Creating it...", newObject)
+		createdObject, err := create(ctx, nbi, index, key, newObject)
 		if err != nil {
 			return nil, err
 		}
-		nbi.tagsIndexByName[newTag.Name] = createdTag
+		nbi.recordChange(ctx, createdObject, report.ActionCreated)
+		index.UpsertKey(key, createdObject)
+		return createdObject, nil
 	}
-	return nbi.tagsIndexByName[newTag.Name], nil
+
+	if index.orphanManager != nil {
+		index.orphanManager.RemoveItem(oldObject)
+	}
+	diffMap, err := nbi.diffMap(newObject, oldObject, false)
+	if err != nil {
+		return nil, err
+	}
+	if keyOwner != nil {
+		// Netbox rejects duplicate keys, so the object keeps its key until
+		// keyOwner is removed (e.g. as an orphan), or renamed on its source
+		nbi.Logger.Warningf(
+			ctx,
+			"%s matches %s by source id, but its key is taken by %s. Patching it without fields %v...",
+			newObject,
+			oldObject,
+			keyOwner,
+			spec.keyFields,
+		)
+		for _, field := range spec.keyFields {
+			delete(diffMap, field)
+		}
+	}
+	if len(diffMap) == 0 {
+		nbi.Logger.Debugf(ctx, "%s already exists in Netbox and is up to date...", newObject)
+		nbi.recordChange(ctx, oldObject, report.ActionUnchanged)
+		return oldObject, nil
+	}
+	nbi.Logger.Debugf(ctx, "%s already exists in Netbox but is out of date. Patching it...", newObject)
+	patchedObject, err := patch(ctx, nbi, (*T)(oldObject), (*T)(newObject), oldObject.GetID(), diffMap, store)
+	if err != nil {
+		return nil, err
+	}
+	nbi.recordChange(ctx, patchedObject, report.ActionUpdated)
+	// Object matched by source id or secondary key keeps its key,
+	// unless it was renamed by the patch
+	store(patchedObject)
+	return patchedObject, nil
+}
+
+// AddTag adds the newTag from source sourceName to the local inventory.
+func (nbi *NetboxInventory) AddTag(ctx context.Context, newTag *objects.Tag) (*objects.Tag, error) {
+	return addItem(ctx, nbi, nbi.tags, newTag, nil)
 }
 
 // AddTenants adds a new tenant to the local netbox inventory.
@@ -51,43 +192,7 @@ func (nbi *NetboxInventory) AddTenant(
 	ctx context.Context,
 	newTenant *objects.Tenant,
 ) (*objects.Tenant, error) {
-	newTenant.NetboxObject.AddTag(nbi.SsotTag)
-	nbi.tenantsLock.Lock()
-	defer nbi.tenantsLock.Unlock()
-	if _, ok := nbi.tenantsIndexByName[newTenant.Name]; ok {
-		oldTenant := nbi.tenantsIndexByName[newTenant.Name]
-		diffMap, err := utils.JSONDiffMapExceptID(newTenant, oldTenant, false, nbi.SourcePriority)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Tenant %s already exists in Netbox but is out of date. Patching it...",
-				newTenant.Name,
-			)
-			patchedTenant, err := service.Patch[objects.Tenant](
-				ctx,
-				nbi.NetboxAPI,
-				oldTenant.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.tenantsIndexByName[newTenant.Name] = patchedTenant
-		} else {
-			nbi.Logger.Debugf(ctx, "Tenant %s already exists in Netbox and is up to date...", newTenant.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "Tenant %s does not exist in Netbox. Creating it...", newTenant.Name)
-		createdTag, err := service.Create(ctx, nbi.NetboxAPI, newTenant)
-		if err != nil {
-			return nil, err
-		}
-		nbi.tenantsIndexByName[newTenant.Name] = createdTag
-	}
-	return nbi.tenantsIndexByName[newTenant.Name], nil
+	return addItem(ctx, nbi, nbi.tenants, newTenant, nil)
 }
 
 // AddSite adds a site to the local netbox inventory.
@@ -95,38 +200,7 @@ func (nbi *NetboxInventory) AddSite(
 	ctx context.Context,
 	newSite *objects.Site,
 ) (*objects.Site, error) {
-	newSite.NetboxObject.AddTag(nbi.SsotTag)
-	nbi.sitesLock.Lock()
-	defer nbi.sitesLock.Unlock()
-	if _, ok := nbi.sitesIndexByName[newSite.Name]; ok {
-		oldSite := nbi.sitesIndexByName[newSite.Name]
-		diffMap, err := utils.JSONDiffMapExceptID(newSite, oldSite, false, nbi.SourcePriority)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Site %s already exists in Netbox but is out of date. Patching it... ",
-				newSite.Name,
-			)
-			patchedSite, err := service.Patch[objects.Site](ctx, nbi.NetboxAPI, oldSite.ID, diffMap)
-			if err != nil {
-				return nil, err
-			}
-			nbi.sitesIndexByName[newSite.Name] = patchedSite
-		} else {
-			nbi.Logger.Debugf(ctx, "Site %s already exists in Netbox and is up to date...", newSite.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "Site %s does not exist in Netbox. Creating it...", newSite.Name)
-		createdContact, err := service.Create(ctx, nbi.NetboxAPI, newSite)
-		if err != nil {
-			return nil, err
-		}
-		nbi.sitesIndexByName[newSite.Name] = createdContact
-	}
-	return nbi.sitesIndexByName[newSite.Name], nil
+	return addItem(ctx, nbi, nbi.sites, newSite, nil)
 }
 
 // AddSiteGroup adds a SiteGroup to the local netbox inventory.
@@ -134,48 +208,7 @@ func (nbi *NetboxInventory) AddSiteGroup(
 	ctx context.Context,
 	newSiteGroup *objects.SiteGroup,
 ) (*objects.SiteGroup, error) {
-	newSiteGroup.NetboxObject.AddTag(nbi.SsotTag)
-	nbi.siteGroupsLock.Lock()
-	defer nbi.sitesLock.Unlock()
-	if _, ok := nbi.siteGroupsIndexByName[newSiteGroup.Name]; ok {
-		oldSiteGroup := nbi.siteGroupsIndexByName[newSiteGroup.Name]
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newSiteGroup,
-			oldSiteGroup,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"SiteGroup %s already exists in Netbox but is out of date. Patching it...",
-				newSiteGroup.Name,
-			)
-			patchedSiteGroup, err := service.Patch[objects.SiteGroup](
-				ctx,
-				nbi.NetboxAPI,
-				oldSiteGroup.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.siteGroupsIndexByName[newSiteGroup.Name] = patchedSiteGroup
-		} else {
-			nbi.Logger.Debugf(ctx, "SiteGroup %s already exists in Netbox and is up to date...", newSiteGroup.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "SiteGroup %s does not exist in Netbox. Creating it...", newSiteGroup.Name)
-		createdSiteGroup, err := service.Create(ctx, nbi.NetboxAPI, newSiteGroup)
-		if err != nil {
-			return nil, err
-		}
-		nbi.siteGroupsIndexByName[newSiteGroup.Name] = createdSiteGroup
-	}
-	return nbi.siteGroupsIndexByName[newSiteGroup.Name], nil
+	return addItem(ctx, nbi, nbi.siteGroups, newSiteGroup, nil)
 }
 
 // AddContactRole adds the newContactRole to the local netbox inventory.
@@ -183,49 +216,9 @@ func (nbi *NetboxInventory) AddContactRole(
 	ctx context.Context,
 	newContactRole *objects.ContactRole,
 ) (*objects.ContactRole, error) {
-	newContactRole.NetboxObject.AddTag(nbi.SsotTag)
-	addSourceNameCustomField(ctx, &newContactRole.NetboxObject)
-	nbi.contactRolesLock.Lock()
-	defer nbi.contactRolesLock.Unlock()
-	if _, ok := nbi.contactRolesIndexByName[newContactRole.Name]; ok {
-		oldContactRole := nbi.contactRolesIndexByName[newContactRole.Name]
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newContactRole,
-			oldContactRole,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Contact role %s already exists in Netbox but is out of date. Patching it...",
-				newContactRole.Name,
-			)
-			patchedContactRole, err := service.Patch[objects.ContactRole](
-				ctx,
-				nbi.NetboxAPI,
-				oldContactRole.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.contactRolesIndexByName[newContactRole.Name] = patchedContactRole
-		} else {
-			nbi.Logger.Debugf(ctx, "Contact role %s already exists in Netbox and is up to date...", newContactRole.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "Contact role %s does not exist in Netbox. Creating it...", newContactRole.Name)
-		newContactRole, err := service.Create(ctx, nbi.NetboxAPI, newContactRole)
-		if err != nil {
-			return nil, err
-		}
-		nbi.contactRolesIndexByName[newContactRole.Name] = newContactRole
-	}
-	return nbi.contactRolesIndexByName[newContactRole.Name], nil
+	return addItem(ctx, nbi, nbi.contactRoles, newContactRole, &addSpec[string, *objects.ContactRole]{
+		sourceName: true,
+	})
 }
 
 // AddContactGroup adds contact group to the local netbox inventory.
@@ -233,48 +226,7 @@ func (nbi *NetboxInventory) AddContactGroup(
 	ctx context.Context,
 	newContactGroup *objects.ContactGroup,
 ) (*objects.ContactGroup, error) {
-	newContactGroup.NetboxObject.AddTag(nbi.SsotTag)
-	nbi.contactGroupsLock.Lock()
-	defer nbi.contactGroupsLock.Unlock()
-	if _, ok := nbi.contactGroupsIndexByName[newContactGroup.Name]; ok {
-		oldContactGroup := nbi.contactGroupsIndexByName[newContactGroup.Name]
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newContactGroup,
-			oldContactGroup,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Contact group %s already exists in Netbox but is out of date. Patching it...",
-				newContactGroup.Name,
-			)
-			patchedContactGroup, err := service.Patch[objects.ContactGroup](
-				ctx,
-				nbi.NetboxAPI,
-				oldContactGroup.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.contactGroupsIndexByName[newContactGroup.Name] = patchedContactGroup
-		} else {
-			nbi.Logger.Debugf(ctx, "Contact group %s already exists in Netbox and is up to date...", newContactGroup.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "Contact group %s does not exist in Netbox. Creating it...", newContactGroup.Name)
-		newContactGroup, err := service.Create(ctx, nbi.NetboxAPI, newContactGroup)
-		if err != nil {
-			return nil, err
-		}
-		nbi.contactGroupsIndexByName[newContactGroup.Name] = newContactGroup
-	}
-	return nbi.contactGroupsIndexByName[newContactGroup.Name], nil
+	return addItem(ctx, nbi, nbi.contactGroups, newContactGroup, nil)
 }
 
 // AddContact adds a contact to the local netbox inventory.
@@ -282,108 +234,15 @@ func (nbi *NetboxInventory) AddContact(
 	ctx context.Context,
 	newContact *objects.Contact,
 ) (*objects.Contact, error) {
-	newContact.NetboxObject.AddTag(nbi.SsotTag)
-	newContact.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.contactsLock.Lock()
-	defer nbi.contactsLock.Unlock()
-	if _, ok := nbi.contactsIndexByName[newContact.Name]; ok {
-		oldContact := nbi.contactsIndexByName[newContact.Name]
-		nbi.OrphanManager.RemoveItem(oldContact)
-		diffMap, err := utils.JSONDiffMapExceptID(newContact, oldContact, false, nbi.SourcePriority)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Contact %s already exists in Netbox but is out of date. Patching it...",
-				newContact.Name,
-			)
-			patchedContact, err := service.Patch[objects.Contact](
-				ctx,
-				nbi.NetboxAPI,
-				oldContact.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.contactsIndexByName[newContact.Name] = patchedContact
-		} else {
-			nbi.Logger.Debugf(ctx, "Contact %s already exists in Netbox and is up to date...", newContact.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "Contact %s does not exist in Netbox. Creating it...", newContact.Name)
-		createdContact, err := service.Create(ctx, nbi.NetboxAPI, newContact)
-		if err != nil {
-			return nil, err
-		}
-		nbi.contactsIndexByName[newContact.Name] = createdContact
-	}
-	return nbi.contactsIndexByName[newContact.Name], nil
+	return addItem(ctx, nbi, nbi.contacts, newContact, nil)
 }
 
 // AddContact assignment adds a contact assignment to the local netbox inventory.
-// TODO: Make index check less code and more universal, checking each level is ugly.
 func (nbi *NetboxInventory) AddContactAssignment(
 	ctx context.Context,
 	newCA *objects.ContactAssignment,
 ) (*objects.ContactAssignment, error) {
-	newCA.NetboxObject.AddTag(nbi.SsotTag)
-	newCA.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.contactAssignmentsLock.Lock()
-	defer nbi.contactAssignmentsLock.Unlock()
-	if nbi.contactAssignmentsIndex[newCA.ModelType] == nil {
-		nbi.contactAssignmentsIndex[newCA.ModelType] = make(
-			map[int]map[int]map[int]*objects.ContactAssignment,
-		)
-	}
-	if nbi.contactAssignmentsIndex[newCA.ModelType][newCA.ObjectID] == nil {
-		nbi.contactAssignmentsIndex[newCA.ModelType][newCA.ObjectID] = make(
-			map[int]map[int]*objects.ContactAssignment,
-		)
-	}
-	if nbi.contactAssignmentsIndex[newCA.ModelType][newCA.ObjectID][newCA.Contact.ID] == nil {
-		nbi.contactAssignmentsIndex[newCA.ModelType][newCA.ObjectID][newCA.Contact.ID] = make(
-			map[int]*objects.ContactAssignment,
-		)
-	}
-	newCA.Tags = append(newCA.Tags, nbi.SsotTag)
-	if _, ok := nbi.contactAssignmentsIndex[newCA.ModelType][newCA.ObjectID][newCA.Contact.ID][newCA.Role.ID]; ok {
-		oldCA := nbi.contactAssignmentsIndex[newCA.ModelType][newCA.ObjectID][newCA.Contact.ID][newCA.Role.ID]
-		nbi.OrphanManager.RemoveItem(oldCA)
-		diffMap, err := utils.JSONDiffMapExceptID(newCA, oldCA, false, nbi.SourcePriority)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"ContactAssignment %d already exists in Netbox but is out of date. Patching it...",
-				newCA.ID,
-			)
-			patchedCA, err := service.Patch[objects.ContactAssignment](
-				ctx,
-				nbi.NetboxAPI,
-				oldCA.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.contactAssignmentsIndex[newCA.ModelType][newCA.ObjectID][newCA.Contact.ID][newCA.Role.ID] = patchedCA
-		} else {
-			nbi.Logger.Debugf(ctx, "ContactAssignment %d already exists in Netbox and is up to date...", newCA.ID)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "ContactAssignment %s does not exist in Netbox. Creating it...", newCA)
-		newCA, err := service.Create(ctx, nbi.NetboxAPI, newCA)
-		if err != nil {
-			return nil, err
-		}
-		nbi.contactAssignmentsIndex[newCA.ModelType][newCA.ObjectID][newCA.Contact.ID][newCA.Role.ID] = newCA
-	}
-	return nbi.contactAssignmentsIndex[newCA.ModelType][newCA.ObjectID][newCA.Contact.ID][newCA.Role.ID], nil
+	return addItem(ctx, nbi, nbi.contactAssignments, newCA, nil)
 }
 
 // AddCustomField adds a custom field to the Netbox inventory.
@@ -395,42 +254,12 @@ func (nbi *NetboxInventory) AddCustomField(
 	ctx context.Context,
 	newCf *objects.CustomField,
 ) (*objects.CustomField, error) {
-	nbi.customFieldsLock.Lock()
-	defer nbi.customFieldsLock.Unlock()
-	if _, ok := nbi.customFieldsIndexByName[newCf.Name]; ok {
-		oldCustomField := nbi.customFieldsIndexByName[newCf.Name]
-		diffMap, err := utils.JSONDiffMapExceptID(newCf, oldCustomField, false, nbi.SourcePriority)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Custom field %s already exists in Netbox but is out of date. Patching it...",
-				newCf.Name,
-			)
-			patchedCf, err := service.Patch[objects.CustomField](
-				ctx,
-				nbi.NetboxAPI,
-				oldCustomField.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.customFieldsIndexByName[newCf.Name] = patchedCf
-		} else {
-			nbi.Logger.Debugf(ctx, "Custom field %s already exists in Netbox and is up to date...", newCf.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "Custom field %s does not exist in Netbox. Creating it...", newCf.Name)
-		createdCf, err := service.Create(ctx, nbi.NetboxAPI, newCf)
-		if err != nil {
-			return nil, err
-		}
-		nbi.customFieldsIndexByName[createdCf.Name] = createdCf
-	}
-	return nbi.customFieldsIndexByName[newCf.Name], nil
+	return addItem(ctx, nbi, nbi.customFields, newCf, &addSpec[string, *objects.CustomField]{
+		prepare: func(cf *objects.CustomField) error {
+			cf.ObjectTypes = nbi.Capabilities.SupportedObjectTypes(cf.ObjectTypes)
+			return nil
+		},
+	})
 }
 
 // AddClusterGroup adds a new cluster group to the Netbox inventory.
@@ -442,47 +271,9 @@ func (nbi *NetboxInventory) AddClusterGroup(
 	ctx context.Context,
 	newCg *objects.ClusterGroup,
 ) (*objects.ClusterGroup, error) {
-	newCg.NetboxObject.AddTag(nbi.SsotTag)
-	addSourceNameCustomField(ctx, &newCg.NetboxObject)
-	newCg.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.clusterGroupsLock.Lock()
-	defer nbi.clusterGroupsLock.Unlock()
-	if _, ok := nbi.clusterGroupsIndexByName[newCg.Name]; ok {
-		oldCg := nbi.clusterGroupsIndexByName[newCg.Name]
-		nbi.OrphanManager.RemoveItem(oldCg)
-		diffMap, err := utils.JSONDiffMapExceptID(newCg, oldCg, false, nbi.SourcePriority)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Cluster group %s already exists in Netbox but is out of date. Patching it...",
-				newCg.Name,
-			)
-			patchedCg, err := service.Patch[objects.ClusterGroup](
-				ctx,
-				nbi.NetboxAPI,
-				oldCg.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.clusterGroupsIndexByName[newCg.Name] = patchedCg
-		} else {
-			nbi.Logger.Debugf(ctx, "Cluster group %s already exists in Netbox and is up to date...", newCg.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "Cluster group %s does not exist in Netbox. Creating it...", newCg.Name)
-		newCg, err := service.Create(ctx, nbi.NetboxAPI, newCg)
-		if err != nil {
-			return nil, err
-		}
-		nbi.clusterGroupsIndexByName[newCg.Name] = newCg
-	}
-	// Delete id from orphan manager
-	return nbi.clusterGroupsIndexByName[newCg.Name], nil
+	return addItem(ctx, nbi, nbi.clusterGroups, newCg, &addSpec[string, *objects.ClusterGroup]{
+		sourceName: true,
+	})
 }
 
 // AddClusterType adds a new cluster type to the Netbox inventory.
@@ -495,59 +286,7 @@ func (nbi *NetboxInventory) AddClusterType(
 	ctx context.Context,
 	newClusterType *objects.ClusterType,
 ) (*objects.ClusterType, error) {
-	newClusterType.NetboxObject.AddTag(nbi.SsotTag)
-	newClusterType.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.clusterTypesLock.Lock()
-	defer nbi.clusterTypesLock.Unlock()
-	if _, ok := nbi.clusterTypesIndexByName[newClusterType.Name]; ok {
-		oldClusterType := nbi.clusterTypesIndexByName[newClusterType.Name]
-		nbi.OrphanManager.RemoveItem(oldClusterType)
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newClusterType,
-			oldClusterType,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Cluster type %s already exists in Netbox but is out of date. Patching it...",
-				newClusterType.Name,
-			)
-			patchedClusterType, err := service.Patch[objects.ClusterType](
-				ctx,
-				nbi.NetboxAPI,
-				oldClusterType.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.clusterTypesIndexByName[newClusterType.Name] = patchedClusterType
-			return patchedClusterType, nil
-		}
-		nbi.Logger.Debugf(
-			ctx,
-			"Cluster type %s already exists in Netbox and is up to date...",
-			newClusterType.Name,
-		)
-		existingClusterType := nbi.clusterTypesIndexByName[newClusterType.Name]
-		return existingClusterType, nil
-	}
-	nbi.Logger.Debugf(
-		ctx,
-		"Cluster type %s does not exist in Netbox. Creating it...",
-		newClusterType.Name,
-	)
-	newClusterType, err := service.Create(ctx, nbi.NetboxAPI, newClusterType)
-	if err != nil {
-		return nil, err
-	}
-	nbi.clusterTypesIndexByName[newClusterType.Name] = newClusterType
-	return newClusterType, nil
+	return addItem(ctx, nbi, nbi.clusterTypes, newClusterType, nil)
 }
 
 // AddCluster adds a new cluster to the Netbox inventory.
@@ -560,47 +299,9 @@ func (nbi *NetboxInventory) AddCluster(
 	ctx context.Context,
 	newCluster *objects.Cluster,
 ) (*objects.Cluster, error) {
-	newCluster.NetboxObject.AddTag(nbi.SsotTag)
-	addSourceNameCustomField(ctx, &newCluster.NetboxObject)
-	newCluster.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.clustersLock.Lock()
-	defer nbi.clustersLock.Unlock()
-	if _, ok := nbi.clustersIndexByName[newCluster.Name]; ok {
-		// Remove id from orphan manager, because it still exists in the sources
-		oldCluster := nbi.clustersIndexByName[newCluster.Name]
-		nbi.OrphanManager.RemoveItem(oldCluster)
-		diffMap, err := utils.JSONDiffMapExceptID(newCluster, oldCluster, false, nbi.SourcePriority)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Cluster %s already exists in Netbox but is out of date. Patching it...",
-				newCluster.Name,
-			)
-			patchedCluster, err := service.Patch[objects.Cluster](
-				ctx,
-				nbi.NetboxAPI,
-				oldCluster.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.clustersIndexByName[newCluster.Name] = patchedCluster
-		} else {
-			nbi.Logger.Debugf(ctx, "Cluster %s already exists in Netbox and is up to date...", newCluster.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "Cluster %s does not exist in Netbox. Creating it...", newCluster.Name)
-		createdCluster, err := service.Create(ctx, nbi.NetboxAPI, newCluster)
-		if err != nil {
-			return nil, err
-		}
-		nbi.clustersIndexByName[createdCluster.Name] = createdCluster
-	}
-	return nbi.clustersIndexByName[newCluster.Name], nil
+	return addItem(ctx, nbi, nbi.clusters, newCluster, &addSpec[string, *objects.Cluster]{
+		sourceName: true,
+	})
 }
 
 // AddDeviceRole adds a new device role to the Netbox inventory.
@@ -612,51 +313,7 @@ func (nbi *NetboxInventory) AddDeviceRole(
 	ctx context.Context,
 	newDeviceRole *objects.DeviceRole,
 ) (*objects.DeviceRole, error) {
-	newDeviceRole.NetboxObject.AddTag(nbi.SsotTag)
-	newDeviceRole.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.deviceRolesLock.Lock()
-	defer nbi.deviceRolesLock.Unlock()
-	if _, ok := nbi.deviceRolesIndexByName[newDeviceRole.Name]; ok {
-		// Remove id from orphan manager, because it still exists in the sources
-		oldDeviceRole := nbi.deviceRolesIndexByName[newDeviceRole.Name]
-		nbi.OrphanManager.RemoveItem(oldDeviceRole)
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newDeviceRole,
-			oldDeviceRole,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Device role %s already exists in Netbox but is out of date. Patching it...",
-				newDeviceRole.Name,
-			)
-			patchedDeviceRole, err := service.Patch[objects.DeviceRole](
-				ctx,
-				nbi.NetboxAPI,
-				oldDeviceRole.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.deviceRolesIndexByName[newDeviceRole.Name] = patchedDeviceRole
-		} else {
-			nbi.Logger.Debugf(ctx, "Device role %s already exists in Netbox and is up to date...", newDeviceRole.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "Device role %s does not exist in Netbox. Creating it...", newDeviceRole.Name)
-		newDeviceRole, err := service.Create(ctx, nbi.NetboxAPI, newDeviceRole)
-		if err != nil {
-			return nil, err
-		}
-		nbi.deviceRolesIndexByName[newDeviceRole.Name] = newDeviceRole
-	}
-	return nbi.deviceRolesIndexByName[newDeviceRole.Name], nil
+	return addItem(ctx, nbi, nbi.deviceRoles, newDeviceRole, nil)
 }
 
 // AddManufacturer adds a new manufacturer to the Netbox inventory.
@@ -669,51 +326,7 @@ func (nbi *NetboxInventory) AddManufacturer(
 	ctx context.Context,
 	newManufacturer *objects.Manufacturer,
 ) (*objects.Manufacturer, error) {
-	newManufacturer.NetboxObject.AddTag(nbi.SsotTag)
-	newManufacturer.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.manufacturersLock.Lock()
-	defer nbi.manufacturersLock.Unlock()
-	if _, ok := nbi.manufacturersIndexByName[newManufacturer.Name]; ok {
-		// Remove id from orphan manager, because it still exists in the sources
-		oldManufacturer := nbi.manufacturersIndexByName[newManufacturer.Name]
-		nbi.OrphanManager.RemoveItem(oldManufacturer)
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newManufacturer,
-			oldManufacturer,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Manufacturer %s already exists in Netbox but is out of date. Patching it...",
-				newManufacturer.Name,
-			)
-			patchedManufacturer, err := service.Patch[objects.Manufacturer](
-				ctx,
-				nbi.NetboxAPI,
-				oldManufacturer.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.manufacturersIndexByName[newManufacturer.Name] = patchedManufacturer
-		} else {
-			nbi.Logger.Debugf(ctx, "Manufacturer %s already exists in Netbox and is up to date...", newManufacturer.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "Manufacturer %s does not exist in Netbox. Creating it...", newManufacturer.Name)
-		newManufacturer, err := service.Create(ctx, nbi.NetboxAPI, newManufacturer)
-		if err != nil {
-			return nil, err
-		}
-		nbi.manufacturersIndexByName[newManufacturer.Name] = newManufacturer
-	}
-	return nbi.manufacturersIndexByName[newManufacturer.Name], nil
+	return addItem(ctx, nbi, nbi.manufacturers, newManufacturer, nil)
 }
 
 // AddDeviceType adds a new device type to the Netbox inventory.
@@ -725,50 +338,7 @@ func (nbi *NetboxInventory) AddDeviceType(
 	ctx context.Context,
 	newDeviceType *objects.DeviceType,
 ) (*objects.DeviceType, error) {
-	newDeviceType.NetboxObject.AddTag(nbi.SsotTag)
-	newDeviceType.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.deviceTypesLock.Lock()
-	defer nbi.deviceTypesLock.Unlock()
-	if _, ok := nbi.deviceTypesIndexByModel[newDeviceType.Model]; ok {
-		oldDeviceType := nbi.deviceTypesIndexByModel[newDeviceType.Model]
-		nbi.OrphanManager.RemoveItem(oldDeviceType)
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newDeviceType,
-			oldDeviceType,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Device type %s already exists in Netbox but is out of date. Patching it...",
-				newDeviceType.Model,
-			)
-			patchedDeviceType, err := service.Patch[objects.DeviceType](
-				ctx,
-				nbi.NetboxAPI,
-				oldDeviceType.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.deviceTypesIndexByModel[newDeviceType.Model] = patchedDeviceType
-		} else {
-			nbi.Logger.Debugf(ctx, "Device type %s already exists in Netbox and is up to date...", newDeviceType.Model)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "Device type %s does not exist in Netbox. Creating it...", newDeviceType.Model)
-		newDeviceType, err := service.Create(ctx, nbi.NetboxAPI, newDeviceType)
-		if err != nil {
-			return nil, err
-		}
-		nbi.deviceTypesIndexByModel[newDeviceType.Model] = newDeviceType
-	}
-	return nbi.deviceTypesIndexByModel[newDeviceType.Model], nil
+	return addItem(ctx, nbi, nbi.deviceTypes, newDeviceType, nil)
 }
 
 // AddPlatform adds a new platform to the Netbox inventory.
@@ -780,51 +350,7 @@ func (nbi *NetboxInventory) AddPlatform(
 	ctx context.Context,
 	newPlatform *objects.Platform,
 ) (*objects.Platform, error) {
-	newPlatform.NetboxObject.AddTag(nbi.SsotTag)
-	newPlatform.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.platformsLock.Lock()
-	defer nbi.platformsLock.Unlock()
-	if _, ok := nbi.platformsIndexByName[newPlatform.Name]; ok {
-		// Remove id from orphan manager, because it still exists in the sources
-		oldPlatform := nbi.platformsIndexByName[newPlatform.Name]
-		nbi.OrphanManager.RemoveItem(oldPlatform)
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newPlatform,
-			oldPlatform,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Platform %s already exists in Netbox but is out of date. Patching it...",
-				newPlatform.Name,
-			)
-			patchedPlatform, err := service.Patch[objects.Platform](
-				ctx,
-				nbi.NetboxAPI,
-				oldPlatform.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.platformsIndexByName[newPlatform.Name] = patchedPlatform
-		} else {
-			nbi.Logger.Debugf(ctx, "Platform %s already exists in Netbox and is up to date...", newPlatform.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "Platform %s does not exist in Netbox. Creating it...", newPlatform.Name)
-		newPlatform, err := service.Create(ctx, nbi.NetboxAPI, newPlatform)
-		if err != nil {
-			return nil, err
-		}
-		nbi.platformsIndexByName[newPlatform.Name] = newPlatform
-	}
-	return nbi.platformsIndexByName[newPlatform.Name], nil
+	return addItem(ctx, nbi, nbi.platforms, newPlatform, nil)
 }
 
 // AddRackRole adds a new rack role to the Netbox inventory.
@@ -836,55 +362,18 @@ func (nbi *NetboxInventory) AddDevice(
 	ctx context.Context,
 	newDevice *objects.Device,
 ) (*objects.Device, error) {
-	newDevice.NetboxObject.AddTag(nbi.SsotTag)
-	addSourceNameCustomField(ctx, &newDevice.NetboxObject)
-	nbi.applyDeviceFieldLengthLimitations(newDevice)
-	newDevice.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.devicesLock.Lock()
-	defer nbi.devicesLock.Unlock()
-	if newDevice.Site == nil {
-		return nil, fmt.Errorf("device %s is not assigned to a site, but it should be", newDevice)
-	}
-	if _, ok := nbi.devicesIndexByNameAndSiteID[newDevice.Name][newDevice.Site.ID]; ok {
-		oldDevice := nbi.devicesIndexByNameAndSiteID[newDevice.Name][newDevice.Site.ID]
-		nbi.OrphanManager.RemoveItem(oldDevice)
-		diffMap, err := utils.JSONDiffMapExceptID(newDevice, oldDevice, false, nbi.SourcePriority)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Device %s already exists in Netbox but is out of date. Patching it...",
-				newDevice.Name,
-			)
-			patchedDevice, err := service.Patch[objects.Device](
-				ctx,
-				nbi.NetboxAPI,
-				oldDevice.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
+	return addItem(ctx, nbi, nbi.devices, newDevice, &addSpec[deviceIndexKey, *objects.Device]{
+		sourceName:    true,
+		matchSourceID: true,
+		keyFields:     []string{"name", "site", "location", "cluster"},
+		prepare: func(device *objects.Device) error {
+			nbi.applyDeviceFieldLengthLimitations(device)
+			if device.Site == nil {
+				return fmt.Errorf("device %s is not assigned to a site, but it should be", device)
 			}
-			nbi.devicesIndexByNameAndSiteID[newDevice.Name][newDevice.Site.ID] = patchedDevice
-			nbi.devicesIndexByID[patchedDevice.ID] = patchedDevice
-		} else {
-			nbi.Logger.Debugf(ctx, "Device %s already exists in Netbox and is up to date...", newDevice.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "Device %s does not exist in Netbox. Creating it...", newDevice.Name)
-		newDevice, err := service.Create(ctx, nbi.NetboxAPI, newDevice)
-		if err != nil {
-			return nil, err
-		}
-		if nbi.devicesIndexByNameAndSiteID[newDevice.Name] == nil {
-			nbi.devicesIndexByNameAndSiteID[newDevice.Name] = make(map[int]*objects.Device)
-		}
-		nbi.devicesIndexByNameAndSiteID[newDevice.Name][newDevice.Site.ID] = newDevice
-		nbi.devicesIndexByID[newDevice.ID] = newDevice
-	}
-	return nbi.devicesIndexByNameAndSiteID[newDevice.Name][newDevice.Site.ID], nil
+			return nil
+		},
+	})
 }
 
 // AddVirtualDeviceContext adds new virtual device context to the local inventory.
@@ -896,55 +385,21 @@ func (nbi *NetboxInventory) AddVirtualDeviceContext(
 	ctx context.Context,
 	newVDC *objects.VirtualDeviceContext,
 ) (*objects.VirtualDeviceContext, error) {
-	newVDC.NetboxObject.AddTag(nbi.SsotTag)
-	addSourceNameCustomField(ctx, &newVDC.NetboxObject)
-	newVDC.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.virtualDeviceContextsLock.Lock()
-	defer nbi.virtualDeviceContextsLock.Unlock()
-	if newVDC.Device == nil {
-		return nil, fmt.Errorf(
-			"VirtualDeviceContext %s is not assigned to a device, but it should be",
-			newVDC,
-		)
-	}
-	if _, ok := nbi.virtualDeviceContextsIndex[newVDC.Name][newVDC.Device.ID]; ok {
-		oldVDC := nbi.virtualDeviceContextsIndex[newVDC.Name][newVDC.Device.ID]
-		nbi.OrphanManager.RemoveItem(oldVDC)
-		diffMap, err := utils.JSONDiffMapExceptID(newVDC, oldVDC, false, nbi.SourcePriority)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"VirtualDeviceContext %s already exists in Netbox but is out of date. Patching it...",
-				newVDC.Name,
-			)
-			patchedVDC, err := service.Patch[objects.VirtualDeviceContext](
-				ctx,
-				nbi.NetboxAPI,
-				oldVDC.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.virtualDeviceContextsIndex[newVDC.Name][newVDC.Device.ID] = patchedVDC
-		} else {
-			nbi.Logger.Debugf(ctx, "VirtualDeviceContext %s already exists in Netbox and is up to date...", newVDC.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "VirtualDeviceContext %s does not exist in Netbox. Creating it...", newVDC.Name)
-		newDevice, err := service.Create(ctx, nbi.NetboxAPI, newVDC)
-		if err != nil {
-			return nil, err
-		}
-		if nbi.virtualDeviceContextsIndex[newDevice.Name] == nil {
-			nbi.virtualDeviceContextsIndex[newDevice.Name] = make(map[int]*objects.VirtualDeviceContext)
-		}
-		nbi.virtualDeviceContextsIndex[newDevice.Name][newDevice.Device.ID] = newDevice
-	}
-	return nbi.virtualDeviceContextsIndex[newVDC.Name][newVDC.Device.ID], nil
+	return addItem(
+		ctx,
+		nbi,
+		nbi.virtualDeviceContexts,
+		newVDC,
+		&addSpec[deviceChildIndexKey, *objects.VirtualDeviceContext]{
+			sourceName: true,
+			prepare: func(vdc *objects.VirtualDeviceContext) error {
+				if vdc.Device == nil {
+					return fmt.Errorf("VirtualDeviceContext %s is not assigned to a device, but it should be", vdc)
+				}
+				return nil
+			},
+		},
+	)
 }
 
 // AddVlanGroup adds a new vlan group to the Netbox inventory.
@@ -956,51 +411,7 @@ func (nbi *NetboxInventory) AddVlanGroup(
 	ctx context.Context,
 	newVlanGroup *objects.VlanGroup,
 ) (*objects.VlanGroup, error) {
-	newVlanGroup.NetboxObject.AddTag(nbi.SsotTag)
-	newVlanGroup.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.vlanGroupsLock.Lock()
-	defer nbi.vlanGroupsLock.Unlock()
-	if _, ok := nbi.vlanGroupsIndexByName[newVlanGroup.Name]; ok {
-		// Remove id from orphan manager, because it still exists in the sources
-		oldVlanGroup := nbi.vlanGroupsIndexByName[newVlanGroup.Name]
-		nbi.OrphanManager.RemoveItem(oldVlanGroup)
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newVlanGroup,
-			oldVlanGroup,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"VlanGroup %s already exists in Netbox but is out of date. Patching it...",
-				newVlanGroup.Name,
-			)
-			patchedVlanGroup, err := service.Patch[objects.VlanGroup](
-				ctx,
-				nbi.NetboxAPI,
-				oldVlanGroup.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.vlanGroupsIndexByName[newVlanGroup.Name] = patchedVlanGroup
-		} else {
-			nbi.Logger.Debugf(ctx, "VlanGroup %s already exists in Netbox and is up to date...", newVlanGroup.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "VlanGroup %s does not exist in Netbox. Creating it...", newVlanGroup.Name)
-		newVlan, err := service.Create(ctx, nbi.NetboxAPI, newVlanGroup)
-		if err != nil {
-			return nil, err
-		}
-		nbi.vlanGroupsIndexByName[newVlan.Name] = newVlan
-	}
-	return nbi.vlanGroupsIndexByName[newVlanGroup.Name], nil
+	return addItem(ctx, nbi, nbi.vlanGroups, newVlanGroup, nil)
 }
 
 // AddVlan adds a new vlan to the Netbox inventory.
@@ -1011,45 +422,9 @@ func (nbi *NetboxInventory) AddVlan(
 	ctx context.Context,
 	newVlan *objects.Vlan,
 ) (*objects.Vlan, error) {
-	newVlan.NetboxObject.AddTag(nbi.SsotTag)
-	addSourceNameCustomField(ctx, &newVlan.NetboxObject)
-	newVlan.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.vlansLock.Lock()
-	defer nbi.vlansLock.Unlock()
-	if _, ok := nbi.vlansIndexByVlanGroupIDAndVID[newVlan.Group.ID][newVlan.Vid]; ok {
-		// Remove id from orphan manager, because it still exists in the sources
-		oldVlan := nbi.vlansIndexByVlanGroupIDAndVID[newVlan.Group.ID][newVlan.Vid]
-		nbi.OrphanManager.RemoveItem(oldVlan)
-		diffMap, err := utils.JSONDiffMapExceptID(newVlan, oldVlan, false, nbi.SourcePriority)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Vlan %s already exists in Netbox but is out of date. Patching it...",
-				newVlan.Name,
-			)
-			patchedVlan, err := service.Patch[objects.Vlan](ctx, nbi.NetboxAPI, oldVlan.ID, diffMap)
-			if err != nil {
-				return nil, err
-			}
-			nbi.vlansIndexByVlanGroupIDAndVID[newVlan.Group.ID][newVlan.Vid] = patchedVlan
-		} else {
-			nbi.Logger.Debugf(ctx, "Vlan %s already exists in Netbox and is up to date...", newVlan.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "Vlan %s does not exist in Netbox. Creating it...", newVlan.Name)
-		newVlan, err := service.Create(ctx, nbi.NetboxAPI, newVlan)
-		if err != nil {
-			return nil, err
-		}
-		if nbi.vlansIndexByVlanGroupIDAndVID[newVlan.Group.ID] == nil {
-			nbi.vlansIndexByVlanGroupIDAndVID[newVlan.Group.ID] = make(map[int]*objects.Vlan)
-		}
-		nbi.vlansIndexByVlanGroupIDAndVID[newVlan.Group.ID][newVlan.Vid] = newVlan
-	}
-	return nbi.vlansIndexByVlanGroupIDAndVID[newVlan.Group.ID][newVlan.Vid], nil
+	return addItem(ctx, nbi, nbi.vlans, newVlan, &addSpec[vlanIndexKey, *objects.Vlan]{
+		sourceName: true,
+	})
 }
 
 // AddInterface adds a new interface to the Netbox inventory.
@@ -1061,69 +436,15 @@ func (nbi *NetboxInventory) AddInterface(
 	ctx context.Context,
 	newInterface *objects.Interface,
 ) (*objects.Interface, error) {
-	newInterface.NetboxObject.AddTag(nbi.SsotTag)
-	addSourceNameCustomField(ctx, &newInterface.NetboxObject)
-	newInterface.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	if len(newInterface.Name) > constants.MaxInterfaceNameLength {
-		newInterface.Name = newInterface.Name[:constants.MaxInterfaceNameLength]
-	}
-	nbi.interfacesLock.Lock()
-	defer nbi.interfacesLock.Unlock()
-	if _, ok := nbi.interfacesIndexByDeviceIDAndName[newInterface.Device.ID][newInterface.Name]; ok {
-		oldInterface := nbi.interfacesIndexByDeviceIDAndName[newInterface.Device.ID][newInterface.Name]
-		nbi.OrphanManager.RemoveItem(oldInterface)
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newInterface,
-			oldInterface,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Interface %s/%s already exists in Netbox but is out of date. Patching it...",
-				newInterface.Device.Name,
-				newInterface.Name,
-			)
-			patchedInterface, err := service.Patch[objects.Interface](
-				ctx,
-				nbi.NetboxAPI,
-				oldInterface.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
+	return addItem(ctx, nbi, nbi.interfaces, newInterface, &addSpec[deviceChildIndexKey, *objects.Interface]{
+		sourceName: true,
+		prepare: func(iface *objects.Interface) error {
+			if len(iface.Name) > constants.MaxInterfaceNameLength {
+				iface.Name = iface.Name[:constants.MaxInterfaceNameLength]
 			}
-			nbi.interfacesIndexByDeviceIDAndName[newInterface.Device.ID][newInterface.Name] = patchedInterface
-			nbi.interfacesIndexByID[patchedInterface.ID] = patchedInterface
-		} else {
-			nbi.Logger.Debugf(
-				ctx,
-				"Interface %s/%s already exists in Netbox and is up to date...",
-				newInterface.Device.Name, newInterface.Name,
-			)
-		}
-	} else {
-		nbi.Logger.Debugf(
-			ctx,
-			"Interface %s/%s does not exist in Netbox. Creating it...",
-			newInterface.Device.Name, newInterface.Name,
-		)
-		newInterface, err := service.Create(ctx, nbi.NetboxAPI, newInterface)
-		if err != nil {
-			return nil, err
-		}
-		if nbi.interfacesIndexByDeviceIDAndName[newInterface.Device.ID] == nil {
-			nbi.interfacesIndexByDeviceIDAndName[newInterface.Device.ID] = make(map[string]*objects.Interface)
-		}
-		nbi.interfacesIndexByDeviceIDAndName[newInterface.Device.ID][newInterface.Name] = newInterface
-		nbi.interfacesIndexByID[newInterface.ID] = newInterface
-		return newInterface, nil
-	}
-	return nbi.interfacesIndexByDeviceIDAndName[newInterface.Device.ID][newInterface.Name], nil
+			return nil
+		},
+	})
 }
 
 // AddVM adds a new virtual machine to the Netbox inventory.
@@ -1132,54 +453,17 @@ func (nbi *NetboxInventory) AddInterface(
 // If the virtual machine already exists in Netbox, it checks if it is up to date and patches it if necessary.
 // If the virtual machine does not exist, it creates a new one.
 func (nbi *NetboxInventory) AddVM(ctx context.Context, newVM *objects.VM) (*objects.VM, error) {
-	newVM.NetboxObject.AddTag(nbi.SsotTag)
-	addSourceNameCustomField(ctx, &newVM.NetboxObject)
-	newVM.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.vmsLock.Lock()
-	defer nbi.vmsLock.Unlock()
-	newVMClusterID := -1
-	if newVM.Cluster != nil {
-		newVMClusterID = newVM.Cluster.ID
-	}
-	if len(newVM.Name) > constants.MaxVMNameLength {
-		newVM.Name = newVM.Name[:constants.MaxVMNameLength]
-	}
-	if oldVM, ok := nbi.vmsIndexByNameAndClusterID[newVM.Name][newVMClusterID]; ok {
-		nbi.OrphanManager.RemoveItem(oldVM)
-		diffMap, err := utils.JSONDiffMapExceptID(newVM, oldVM, false, nbi.SourcePriority)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"VM %s already exists in Netbox but is out of date. Patching it...",
-				newVM,
-			)
-			patchedVM, err := service.Patch[objects.VM](ctx, nbi.NetboxAPI, oldVM.ID, diffMap)
-			if err != nil {
-				nbi.Logger.Errorf(ctx, "Error while patching %s : %s", newVM.Name, err)
-				return nil, err
+	return addItem(ctx, nbi, nbi.vms, newVM, &addSpec[vmIndexKey, *objects.VM]{
+		sourceName:    true,
+		matchSourceID: true,
+		keyFields:     []string{"name", "cluster", "site", "device"},
+		prepare: func(vm *objects.VM) error {
+			if len(vm.Name) > constants.MaxVMNameLength {
+				vm.Name = vm.Name[:constants.MaxVMNameLength]
 			}
-			nbi.vmsIndexByNameAndClusterID[newVM.Name][newVMClusterID] = patchedVM
-			nbi.vmsIndexByID[patchedVM.ID] = patchedVM
-		} else {
-			nbi.Logger.Debugf(ctx, "VM %s already exists in Netbox and is up to date...", newVM)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "VM %s does not exist in Netbox. Creating it...", newVM)
-		newVM, err := service.Create(ctx, nbi.NetboxAPI, newVM)
-		if err != nil {
-			return nil, err
-		}
-		if nbi.vmsIndexByNameAndClusterID[newVM.Name] == nil {
-			nbi.vmsIndexByNameAndClusterID[newVM.Name] = make(map[int]*objects.VM)
-		}
-		nbi.vmsIndexByNameAndClusterID[newVM.Name][newVMClusterID] = newVM
-		nbi.vmsIndexByID[newVM.ID] = newVM
-		return newVM, nil
-	}
-	return nbi.vmsIndexByNameAndClusterID[newVM.Name][newVMClusterID], nil
+			return nil
+		},
+	})
 }
 
 // AddVMInterface adds a new virtual machine interface to the Netbox inventory.
@@ -1191,59 +475,15 @@ func (nbi *NetboxInventory) AddVMInterface(
 	ctx context.Context,
 	newVMInterface *objects.VMInterface,
 ) (*objects.VMInterface, error) {
-	newVMInterface.NetboxObject.AddTag(nbi.SsotTag)
-	addSourceNameCustomField(ctx, &newVMInterface.NetboxObject)
-	newVMInterface.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.vmInterfacesLock.Lock()
-	defer nbi.vmInterfacesLock.Unlock()
-	if len(newVMInterface.Name) > constants.MaxVMInterfaceNameLength {
-		newVMInterface.Name = newVMInterface.Name[:constants.MaxVMInterfaceNameLength]
-	}
-	if _, ok := nbi.vmInterfacesIndexByVMIdAndName[newVMInterface.VM.ID][newVMInterface.Name]; ok {
-		oldVMIface := nbi.vmInterfacesIndexByVMIdAndName[newVMInterface.VM.ID][newVMInterface.Name]
-		nbi.OrphanManager.RemoveItem(oldVMIface)
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newVMInterface,
-			oldVMIface,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"VM interface %s already exists in Netbox but is out of date. Patching it...",
-				newVMInterface.Name,
-			)
-			patchedVMInterface, err := service.Patch[objects.VMInterface](
-				ctx,
-				nbi.NetboxAPI,
-				oldVMIface.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
+	return addItem(ctx, nbi, nbi.vmInterfaces, newVMInterface, &addSpec[vmChildIndexKey, *objects.VMInterface]{
+		sourceName: true,
+		prepare: func(vmIface *objects.VMInterface) error {
+			if len(vmIface.Name) > constants.MaxVMInterfaceNameLength {
+				vmIface.Name = vmIface.Name[:constants.MaxVMInterfaceNameLength]
 			}
-			nbi.vmInterfacesIndexByVMIdAndName[newVMInterface.VM.ID][newVMInterface.Name] = patchedVMInterface
-			nbi.vmInterfacesIndexByID[patchedVMInterface.ID] = patchedVMInterface
-		} else {
-			nbi.Logger.Debugf(ctx, "VM interface %s already exists in Netbox and is up to date...", newVMInterface.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "VM interface %s does not exist in Netbox. Creating it...", newVMInterface.Name)
-		newVMInterface, err := service.Create(ctx, nbi.NetboxAPI, newVMInterface)
-		if err != nil {
-			return nil, err
-		}
-		if nbi.vmInterfacesIndexByVMIdAndName[newVMInterface.VM.ID] == nil {
-			nbi.vmInterfacesIndexByVMIdAndName[newVMInterface.VM.ID] = make(map[string]*objects.VMInterface)
-		}
-		nbi.vmInterfacesIndexByVMIdAndName[newVMInterface.VM.ID][newVMInterface.Name] = newVMInterface
-		nbi.vmInterfacesIndexByID[newVMInterface.ID] = newVMInterface
-	}
-	return nbi.vmInterfacesIndexByVMIdAndName[newVMInterface.VM.ID][newVMInterface.Name], nil
+			return nil
+		},
+	})
 }
 
 // AddIPAddress adds a new IP address to the Netbox inventory.
@@ -1255,66 +495,10 @@ func (nbi *NetboxInventory) AddIPAddress(
 	ctx context.Context,
 	newIPAddress *objects.IPAddress,
 ) (*objects.IPAddress, error) {
-	newIPAddress.NetboxObject.AddTag(nbi.SsotTag)
-	addSourceNameCustomField(ctx, &newIPAddress.NetboxObject)
-	newIPAddress.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-
-	// Get index values with helper function.
-	objType, objName, ifaceName, err := nbi.getIndexValuesForIPAddress(newIPAddress)
-	if err != nil {
-		return nil, fmt.Errorf("get index values for ip address %+v: %s", newIPAddress, err)
-	}
-	// Ensure index is not nil.
-	nbi.verifyIPAddressIndexExists(objType, objName, ifaceName)
-
-	nbi.ipAddressesLock.Lock()
-	defer nbi.ipAddressesLock.Unlock()
-	if _, ok := nbi.ipAddressesIndex[objType][objName][ifaceName][newIPAddress.Address]; ok {
-		oldIPAddress := nbi.ipAddressesIndex[objType][objName][ifaceName][newIPAddress.Address]
-		nbi.OrphanManager.RemoveItem(oldIPAddress)
-
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newIPAddress,
-			oldIPAddress,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"IP address %s already exists in Netbox but is out of date. Patching it...",
-				newIPAddress.Address,
-			)
-			patchedIPAddress, err := service.Patch[objects.IPAddress](
-				ctx,
-				nbi.NetboxAPI,
-				oldIPAddress.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.ipAddressesIndex[objType][objName][ifaceName][newIPAddress.Address] = patchedIPAddress
-			return patchedIPAddress, nil
-		}
-		nbi.Logger.Debugf(
-			ctx,
-			"IP address %s already exists in Netbox and is up to date...",
-			newIPAddress.Address,
-		)
-	} else {
-		nbi.Logger.Debugf(ctx, "IP address %s does not exist in Netbox. Creating it...", newIPAddress.Address)
-		newIPAddress, err := service.Create(ctx, nbi.NetboxAPI, newIPAddress)
-		if err != nil {
-			return nil, err
-		}
-		nbi.ipAddressesIndex[objType][objName][ifaceName][newIPAddress.Address] = newIPAddress
-		return newIPAddress, nil
-	}
-	return nbi.ipAddressesIndex[objType][objName][ifaceName][newIPAddress.Address], nil
+	return addItem(ctx, nbi, nbi.ipAddresses, newIPAddress, &addSpec[addressIndexKey, *objects.IPAddress]{
+		sourceName: true,
+		key:        nbi.ipAddressKey,
+	})
 }
 
 // AddMACAddress adds a new MAC address to the Netbox inventory.
@@ -1322,74 +506,23 @@ func (nbi *NetboxInventory) AddIPAddress(
 // returns the created or updated MAC address object and an error, if any.
 // If the MAC address already exists in Netbox, it checks if it is up to date and patches it if necessary.
 // If the MAC address does not exist, it creates a new one.
+// Netbox releases without MAC address objects only get primary MAC addresses
+// of interfaces, so the MAC address is returned without being added.
 func (nbi *NetboxInventory) AddMACAddress(
 	ctx context.Context,
 	newMACAddress *objects.MACAddress,
 ) (*objects.MACAddress, error) {
-	newMACAddress.NetboxObject.AddTag(nbi.SsotTag)
-	newMACAddress.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-
-	// Get index values with helper function.
-	objType, objName, ifaceName, err := nbi.getIndexValuesForMACAddress(newMACAddress)
-	if err != nil {
-		return nil, fmt.Errorf("get index values for mac address %+v: %s", newMACAddress, err)
-	}
-
-	// ensure index is not nil
-	nbi.verifyMACAddressIndexExists(objType, objName, ifaceName)
-
-	// ensure MAC address is uppercase
-	newMACAddress.MAC = strings.ToUpper(newMACAddress.MAC)
-
-	nbi.macAddressesLock.Lock()
-	defer nbi.macAddressesLock.Unlock()
-	if _, ok := nbi.macAddressesIndex[objType][objName][ifaceName][newMACAddress.MAC]; ok {
-		oldMACAddress := nbi.macAddressesIndex[objType][objName][ifaceName][newMACAddress.MAC]
-		nbi.OrphanManager.RemoveItem(oldMACAddress)
-
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newMACAddress,
-			oldMACAddress,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"MAC address %s already exists in Netbox but is out of date. Patching it...",
-				newMACAddress.MAC,
-			)
-			patchedMACAddress, err := service.Patch[objects.MACAddress](
-				ctx,
-				nbi.NetboxAPI,
-				oldMACAddress.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.macAddressesIndex[objType][objName][ifaceName][newMACAddress.MAC] = patchedMACAddress
-			return patchedMACAddress, nil
-		}
-		nbi.Logger.Debugf(
-			ctx,
-			"MAC address %s already exists in Netbox and is up to date...",
-			newMACAddress.MAC,
-		)
-	} else {
-		nbi.Logger.Debugf(ctx, "MAC address %s does not exist in Netbox. Creating it...", newMACAddress.MAC)
-		newMACAddress, err := service.Create(ctx, nbi.NetboxAPI, newMACAddress)
-		if err != nil {
-			return nil, err
-		}
-		nbi.macAddressesIndex[objType][objName][ifaceName][newMACAddress.MAC] = newMACAddress
+	if !nbi.Capabilities.Has(version.MACAddressObjects) {
 		return newMACAddress, nil
 	}
-	return nbi.macAddressesIndex[objType][objName][ifaceName][newMACAddress.MAC], nil
+	return addItem(ctx, nbi, nbi.macAddresses, newMACAddress, &addSpec[addressIndexKey, *objects.MACAddress]{
+		prepare: func(macAddress *objects.MACAddress) error {
+			// ensure MAC address is uppercase
+			macAddress.MAC = strings.ToUpper(macAddress.MAC)
+			return nil
+		},
+		key: nbi.macAddressKey,
+	})
 }
 
 // AddPrefix adds a new prefix to the Netbox inventory.
@@ -1401,52 +534,9 @@ func (nbi *NetboxInventory) AddPrefix(
 	ctx context.Context,
 	newPrefix *objects.Prefix,
 ) (*objects.Prefix, error) {
-	newPrefix.NetboxObject.AddTag(nbi.SsotTag)
-	newPrefix.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.prefixesLock.Lock()
-	newPrefix.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	if newPrefix.NetboxObject.CustomFields == nil {
-		newPrefix.NetboxObject.CustomFields = make(map[string]interface{})
-	}
-	//nolint:forcetypeassert
-	newPrefix.NetboxObject.CustomFields[constants.CustomFieldSourceName] = ctx.Value(constants.CtxSourceKey).(string)
-	defer nbi.prefixesLock.Unlock()
-	if _, ok := nbi.prefixesIndexByPrefix[newPrefix.Prefix]; ok {
-		oldPrefix := nbi.prefixesIndexByPrefix[newPrefix.Prefix]
-		nbi.OrphanManager.RemoveItem(oldPrefix)
-		diffMap, err := utils.JSONDiffMapExceptID(newPrefix, oldPrefix, false, nbi.SourcePriority)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"Prefix %s already exists in Netbox but is out of date. Patching it...",
-				newPrefix.Prefix,
-			)
-			patchedPrefix, err := service.Patch[objects.Prefix](
-				ctx,
-				nbi.NetboxAPI,
-				oldPrefix.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.prefixesIndexByPrefix[newPrefix.Prefix] = patchedPrefix
-		} else {
-			nbi.Logger.Debugf(ctx, "IP address %s already exists in Netbox and is up to date...", newPrefix.Prefix)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "IP address %s does not exist in Netbox. Creating it...", newPrefix.Prefix)
-		newPrefix, err := service.Create(ctx, nbi.NetboxAPI, newPrefix)
-		if err != nil {
-			return nil, err
-		}
-		nbi.prefixesIndexByPrefix[newPrefix.Prefix] = newPrefix
-		return newPrefix, nil
-	}
-	return nbi.prefixesIndexByPrefix[newPrefix.Prefix], nil
+	return addItem(ctx, nbi, nbi.prefixes, newPrefix, &addSpec[string, *objects.Prefix]{
+		sourceName: true,
+	})
 }
 
 // AddWirelessLAN adds a new wireless LAN to the Netbox inventory.
@@ -1458,51 +548,7 @@ func (nbi *NetboxInventory) AddWirelessLAN(
 	ctx context.Context,
 	newWirelessLan *objects.WirelessLAN,
 ) (*objects.WirelessLAN, error) {
-	newWirelessLan.NetboxObject.AddTag(nbi.SsotTag)
-	newWirelessLan.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.wirelessLANsLock.Lock()
-	defer nbi.wirelessLANsLock.Unlock()
-	if _, ok := nbi.wirelessLANsIndexBySSID[newWirelessLan.SSID]; ok {
-		// Remove id from orphan manager, because it still exists in the sources
-		oldWirelessLan := nbi.wirelessLANsIndexBySSID[newWirelessLan.SSID]
-		nbi.OrphanManager.RemoveItem(oldWirelessLan)
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newWirelessLan,
-			oldWirelessLan,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"WirelessLAN %s already exists in Netbox but is out of date. Patching it...",
-				newWirelessLan.SSID,
-			)
-			patchedWirelessLan, err := service.Patch[objects.WirelessLAN](
-				ctx,
-				nbi.NetboxAPI,
-				oldWirelessLan.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.wirelessLANsIndexBySSID[newWirelessLan.SSID] = patchedWirelessLan
-		} else {
-			nbi.Logger.Debugf(ctx, "WirelessLAN %s already exists in Netbox and is up to date...", newWirelessLan.SSID)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "WirelessLAN %s does not exist in Netbox. Creating it...", newWirelessLan.SSID)
-		newWirelessLan, err := service.Create(ctx, nbi.NetboxAPI, newWirelessLan)
-		if err != nil {
-			return nil, err
-		}
-		nbi.wirelessLANsIndexBySSID[newWirelessLan.SSID] = newWirelessLan
-	}
-	return nbi.wirelessLANsIndexBySSID[newWirelessLan.SSID], nil
+	return addItem(ctx, nbi, nbi.wirelessLANs, newWirelessLan, nil)
 }
 
 // AddWirelessLANGroup adds a new wireless LAN group to the Netbox inventory.
@@ -1514,51 +560,7 @@ func (nbi *NetboxInventory) AddWirelessLANGroup(
 	ctx context.Context,
 	newWirelessLANGroup *objects.WirelessLANGroup,
 ) (*objects.WirelessLANGroup, error) {
-	newWirelessLANGroup.NetboxObject.AddTag(nbi.SsotTag)
-	newWirelessLANGroup.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	nbi.wirelessLANGroupsLock.Lock()
-	defer nbi.wirelessLANGroupsLock.Unlock()
-	if _, ok := nbi.wirelessLANGroupsIndexByName[newWirelessLANGroup.Name]; ok {
-		// Remove id from orphan manager, because it still exists in the sources
-		oldWirelessLANGroup := nbi.wirelessLANGroupsIndexByName[newWirelessLANGroup.Name]
-		nbi.OrphanManager.RemoveItem(oldWirelessLANGroup)
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newWirelessLANGroup,
-			oldWirelessLANGroup,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"WirelessLANGroup %s already exists in Netbox but is out of date. Patching it...",
-				newWirelessLANGroup.Name,
-			)
-			patchedWirelessLANGroup, err := service.Patch[objects.WirelessLANGroup](
-				ctx,
-				nbi.NetboxAPI,
-				oldWirelessLANGroup.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
-			}
-			nbi.wirelessLANGroupsIndexByName[newWirelessLANGroup.Name] = patchedWirelessLANGroup
-		} else {
-			nbi.Logger.Debugf(ctx, "WirelessLANGroup %s already exists in Netbox and is up to date...", newWirelessLANGroup.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "WirelessLANGroup %s does not exist in Netbox. Creating it...", newWirelessLANGroup.Name)
-		newWirelessLANGroup, err := service.Create(ctx, nbi.NetboxAPI, newWirelessLANGroup)
-		if err != nil {
-			return nil, err
-		}
-		nbi.wirelessLANGroupsIndexByName[newWirelessLANGroup.Name] = newWirelessLANGroup
-	}
-	return nbi.wirelessLANGroupsIndexByName[newWirelessLANGroup.Name], nil
+	return addItem(ctx, nbi, nbi.wirelessLANGroups, newWirelessLANGroup, nil)
 }
 
 // AddVirtualDisk adds a new virtual disk to the Netbox inventory.
@@ -1570,63 +572,21 @@ func (nbi *NetboxInventory) AddVirtualDisk(
 	ctx context.Context,
 	newVirtualDisk *objects.VirtualDisk,
 ) (*objects.VirtualDisk, error) {
-	newVirtualDisk.NetboxObject.AddTag(nbi.SsotTag)
-	addSourceNameCustomField(ctx, &newVirtualDisk.NetboxObject)
-	newVirtualDisk.SetCustomField(constants.CustomFieldOrphanLastSeenName, nil)
-	if len(newVirtualDisk.Name) > constants.MaxVirtualDiskNameLength {
-		nbi.Logger.Debugf(
-			nbi.Ctx,
-			"VirtualDisk name %s is too long, truncating to %d characters",
-			newVirtualDisk.Name,
-			constants.MaxVirtualDiskNameLength,
-		)
-		newVirtualDisk.Name = newVirtualDisk.Name[:constants.MaxVirtualDiskNameLength]
-	}
-	nbi.virtualDisksLock.Lock()
-	defer nbi.virtualDisksLock.Unlock()
-	if _, ok := nbi.virtualDisksIndexByVMIDAndName[newVirtualDisk.VM.ID][newVirtualDisk.Name]; ok {
-		oldVirtualDisk := nbi.virtualDisksIndexByVMIDAndName[newVirtualDisk.VM.ID][newVirtualDisk.Name]
-		nbi.OrphanManager.RemoveItem(oldVirtualDisk)
-		diffMap, err := utils.JSONDiffMapExceptID(
-			newVirtualDisk,
-			oldVirtualDisk,
-			false,
-			nbi.SourcePriority,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if len(diffMap) > 0 {
-			nbi.Logger.Debugf(
-				ctx,
-				"VirtualDisk %s already exists in Netbox but is out of date. Patching it...",
-				newVirtualDisk.Name,
-			)
-			patchedVirtualDisk, err := service.Patch[objects.VirtualDisk](
-				ctx,
-				nbi.NetboxAPI,
-				oldVirtualDisk.ID,
-				diffMap,
-			)
-			if err != nil {
-				return nil, err
+	return addItem(ctx, nbi, nbi.virtualDisks, newVirtualDisk, &addSpec[vmChildIndexKey, *objects.VirtualDisk]{
+		sourceName: true,
+		prepare: func(virtualDisk *objects.VirtualDisk) error {
+			if len(virtualDisk.Name) > constants.MaxVirtualDiskNameLength {
+				nbi.Logger.Debugf(
+					nbi.Ctx,
+					"VirtualDisk name %s is too long, truncating to %d characters",
+					virtualDisk.Name,
+					constants.MaxVirtualDiskNameLength,
+				)
+				virtualDisk.Name = virtualDisk.Name[:constants.MaxVirtualDiskNameLength]
 			}
-			nbi.virtualDisksIndexByVMIDAndName[newVirtualDisk.VM.ID][newVirtualDisk.Name] = patchedVirtualDisk
-		} else {
-			nbi.Logger.Debugf(ctx, "VirtualDisk %s already exists in Netbox and is up to date...", newVirtualDisk.Name)
-		}
-	} else {
-		nbi.Logger.Debugf(ctx, "VirtualDisk %s does not exist in Netbox. Creating it...", newVirtualDisk.Name)
-		newVirtualDisk, err := service.Create(ctx, nbi.NetboxAPI, newVirtualDisk)
-		if err != nil {
-			return nil, err
-		}
-		if nbi.virtualDisksIndexByVMIDAndName[newVirtualDisk.VM.ID] == nil {
-			nbi.virtualDisksIndexByVMIDAndName[newVirtualDisk.VM.ID] = make(map[string]*objects.VirtualDisk)
-		}
-		nbi.virtualDisksIndexByVMIDAndName[newVirtualDisk.VM.ID][newVirtualDisk.Name] = newVirtualDisk
-	}
-	return nbi.virtualDisksIndexByVMIDAndName[newVirtualDisk.VM.ID][newVirtualDisk.Name], nil
+			return nil
+		},
+	})
 }
 
 // Helper function that adds source name to custom field of the netbox object.
@@ -2,10 +2,12 @@ package inventory
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"testing"
 
 	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox"
 	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
 	"github.com/src-doo/netbox-ssot/internal/netbox/service"
 )
@@ -83,6 +85,8 @@ func TestNetboxInventory_AddTag(t *testing.T) {
 }
 
 func TestNetboxInventory_AddTenant(t *testing.T) {
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
 	type args struct {
 		ctx       context.Context
 		newTenant *objects.Tenant
@@ -121,6 +125,16 @@ func TestNetboxInventory_AddTenant(t *testing.T) {
 			},
 			want: MockExistingTenants["existing_tenant2"],
 		},
+		{
+			name: "Test add tenant with canceled context",
+			nbi:  MockInventory,
+			args: args{
+				ctx:       canceledCtx,
+				newTenant: &objects.Tenant{Name: "canceled tenant", Slug: "canceled_tenant"},
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	mockServer := service.CreateMockServer()
 	defer mockServer.Close()
@@ -562,7 +576,15 @@ func TestNetboxInventory_AddDevice(t *testing.T) {
 		want    *objects.Device
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "Device without site",
+			nbi:  MockInventory,
+			args: args{
+				ctx:       context.WithValue(context.Background(), constants.CtxSourceKey, "test"),
+				newDevice: &objects.Device{Name: "device without site"},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -690,6 +712,79 @@ func TestNetboxInventory_AddVM(t *testing.T) {
 	}
 }
 
+func TestNetboxInventory_AddVM_SourceID(t *testing.T) {
+	tests := []struct {
+		name     string
+		vmName   string
+		wantName string
+	}{
+		{name: "Renamed vm is patched", vmName: "vm3", wantName: "vm3"},
+		{name: "Vm renamed to a taken name keeps its name", vmName: "vm2", wantName: "vm1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fakenetbox.NewServer()
+			defer server.Close()
+			ssotTag, err := fakenetbox.Add(server, &objects.Tag{Name: constants.SsotTagName, Slug: "netbox-ssot"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			clusterType, err := fakenetbox.Add(server, &objects.ClusterType{Name: "vmware", Slug: "vmware"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cluster, err := fakenetbox.Add(server, &objects.Cluster{Name: "cluster", Type: clusterType})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, name := range []string{"vm1", "vm2"} {
+				vm := &objects.VM{
+					NetboxObject: objects.NetboxObject{Tags: []*objects.Tag{ssotTag}},
+					Name:         name,
+					Cluster:      cluster,
+				}
+				vm.SetCustomField(constants.CustomFieldSourceName, "vmware")
+				vm.SetCustomField(constants.CustomFieldSourceIDName, fmt.Sprintf("vm-%d", i+1))
+				if _, err := fakenetbox.Add(server, vm); err != nil {
+					t.Fatal(err)
+				}
+			}
+			ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "vmware")
+			nbi := NewNetboxInventory(ctx, MockInventory.Logger, server.Config())
+			if err := nbi.Init(); err != nil {
+				t.Fatalf("Init() error = %v", err)
+			}
+
+			newVM := &objects.VM{
+				NetboxObject: objects.NetboxObject{Description: "renamed"},
+				Name:         tt.vmName,
+				Cluster:      cluster,
+			}
+			newVM.SetCustomField(constants.CustomFieldSourceName, "vmware")
+			newVM.SetCustomField(constants.CustomFieldSourceIDName, "vm-1")
+			if _, err := nbi.AddVM(ctx, newVM); err != nil {
+				t.Fatalf("AddVM() error = %v", err)
+			}
+			if err := nbi.FlushWrites(ctx); err != nil {
+				t.Fatalf("FlushWrites() error = %v", err)
+			}
+			vms, err := fakenetbox.Objects[objects.VM](server)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(vms) != 2 {
+				t.Fatalf("vms = %v, want 2 vms", vms)
+			}
+			if vms[0].ID != 1 || vms[0].Name != tt.wantName || vms[0].Description != "renamed" {
+				t.Errorf("vm = %+v, want vm 1 named %s with the new description", vms[0], tt.wantName)
+			}
+			if vms[1].Name != "vm2" {
+				t.Errorf("vm = %+v, want vm2", vms[1])
+			}
+		})
+	}
+}
+
 func TestNetboxInventory_AddVMInterface(t *testing.T) {
 	type args struct {
 		ctx            context.Context
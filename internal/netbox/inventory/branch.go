@@ -0,0 +1,81 @@
+package inventory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/src-doo/netbox-ssot/internal/netbox/service"
+)
+
+// initBranch makes the inventory use the branch configured with netbox.branch
+// for the run started at runTime. The branch is created if it doesn't exist
+// yet. All requests are then sent to the branch, so objects are collected
+// from it, and all changes are made in it.
+//
+// Branches are not used in dry-run mode, because nothing is changed.
+func (nbi *NetboxInventory) initBranch(runTime time.Time) error {
+	nbi.branch = nil
+	nbi.branchName = ""
+	nbi.NetboxAPI.Branch = ""
+	if nbi.NetboxConfig.Branch == "" {
+		return nil
+	}
+	if nbi.Plan != nil {
+		nbi.Logger.Infof(nbi.Ctx, "Changes are planned against main, branches are not used in dry-run mode")
+		return nil
+	}
+	branchName := service.BranchName(nbi.NetboxConfig.Branch, runTime)
+	branch, err := nbi.NetboxAPI.UseBranch(nbi.Ctx, branchName)
+	if err != nil {
+		return fmt.Errorf("use branch %s: %s", branchName, err)
+	}
+	nbi.Logger.Infof(nbi.Ctx, "All changes will be made in branch %s", branch.Name)
+	nbi.branch = branch
+	nbi.branchName = branchName
+	return nil
+}
+
+// branchOutdated returns true when a different branch should be used than
+// the one used since the last Init, e.g. because the date in the branch name
+// changed, or because the branch was merged. After a merge a new branch
+// is used, because merged branches can't be used anymore.
+func (nbi *NetboxInventory) branchOutdated(now time.Time) bool {
+	if nbi.NetboxConfig == nil || nbi.NetboxConfig.Branch == "" || nbi.Plan != nil {
+		return false
+	}
+	return nbi.branch == nil || nbi.branchName != service.BranchName(nbi.NetboxConfig.Branch, now)
+}
+
+// MergeBranch merges the branch used by the inventory, when automatic
+// merging is enabled with netbox.branchMergeThreshold, and fewer changes
+// than the threshold were made in the branch. Otherwise the branch is left
+// for review. It should only be called after successful runs.
+func (nbi *NetboxInventory) MergeBranch() error {
+	if nbi.branch == nil || nbi.NetboxConfig.BranchMergeThreshold <= 0 {
+		return nil
+	}
+	changeCount, err := nbi.NetboxAPI.BranchChangeCount(nbi.Ctx, nbi.branch)
+	if err != nil {
+		return fmt.Errorf("count changes of branch %s: %s", nbi.branch.Name, err)
+	}
+	switch {
+	case changeCount == 0:
+		nbi.Logger.Infof(nbi.Ctx, "Branch %s has no changes to merge", nbi.branch.Name)
+		return nil
+	case changeCount >= nbi.NetboxConfig.BranchMergeThreshold:
+		nbi.Logger.Infof(
+			nbi.Ctx,
+			"Branch %s has %d changes, which is not below the merge threshold %d. Leaving it for review",
+			nbi.branch.Name,
+			changeCount,
+			nbi.NetboxConfig.BranchMergeThreshold,
+		)
+		return nil
+	}
+	if err := nbi.NetboxAPI.MergeBranch(nbi.Ctx, nbi.branch); err != nil {
+		return fmt.Errorf("merge branch %s: %s", nbi.branch.Name, err)
+	}
+	nbi.Logger.Infof(nbi.Ctx, "Merging %d changes of branch %s", changeCount, nbi.branch.Name)
+	nbi.branch = nil
+	return nil
+}
@@ -1,52 +1,98 @@
 package inventory
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/metrics"
 	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
-	"github.com/src-doo/netbox-ssot/internal/netbox/service"
 	"github.com/src-doo/netbox-ssot/internal/utils"
 )
 
-func (nbi *NetboxInventory) DeleteOrphans(hard bool) error {
+// ErrOrphanLimitExceeded is returned by DeleteOrphans, when more objects
+// were orphaned in a single run than allowed by netbox.maxOrphans or
+// netbox.maxOrphansPercent.
+var ErrOrphanLimitExceeded = errors.New("orphan limit exceeded")
+
+// DeleteOrphans soft or hard deletes all orphaned objects.
+//
+// Objects owned by protectedSources (e.g. sources that failed or weren't
+// synced in this run) are never deleted, because they were most likely not
+// seen only because the source wasn't synced. Objects without an owning
+// source (e.g. manufacturers, or objects created by netbox-ssot itself) may
+// be used by any source, so they are deleted only when no source is protected.
+//
+// If number of orphans exceeds the configured limits, no object is deleted
+// and ErrOrphanLimitExceeded is returned. Deletion stops once ctx is canceled.
+func (nbi *NetboxInventory) DeleteOrphans(ctx context.Context, hard bool, protectedSources []string) error {
+	ctx = context.WithValue(ctx, constants.CtxSourceKey, "orphanManager")
+	// Writes, that are still queued, belong to sources that failed, so they are dropped
+	for sourceName, count := range nbi.dropWrites() {
+		nbi.OrphanManager.Logger.Warningf(ctx, "Dropped %d queued patches of failed source %s", count, sourceName)
+	}
+	protected := make(map[string]bool, len(protectedSources))
+	for _, sourceName := range protectedSources {
+		protected[sourceName] = true
+	}
+	if err := nbi.checkOrphanLimits(ctx, hard, protected); err != nil {
+		return err
+	}
+	if len(protectedSources) > 0 {
+		nbi.OrphanManager.Logger.Infof(
+			ctx,
+			"Orphaned objects of sources %v and orphaned objects without source are protected from deletion",
+			protectedSources,
+		)
+	}
 	for i := 0; i < len(nbi.OrphanManager.OrphanObjectPriority); i++ {
 		deleteTypeStr := "soft"
 		if hard {
 			deleteTypeStr = "hard"
 		}
 		objectAPIPath := nbi.OrphanManager.OrphanObjectPriority[i]
-		id2orphanItem := nbi.OrphanManager.Items[objectAPIPath]
+		id2orphanItem := nbi.OrphanManager.unprotectedItems(objectAPIPath, protected)
 		if len(id2orphanItem) == 0 {
 			continue
 		}
 
 		nbi.OrphanManager.Logger.Infof(
-			nbi.Ctx,
+			ctx,
 			"Performing %s deletion of orphaned objects of type %s",
 			deleteTypeStr,
 			objectAPIPath,
 		)
 		nbi.OrphanManager.Logger.Debugf(
-			nbi.Ctx,
+			ctx,
 			"IDs of objects to be %s deleted: %v",
 			deleteTypeStr,
 			id2orphanItem,
 		)
 
 		for _, orphanItem := range id2orphanItem {
+			if ctx.Err() != nil {
+				return fmt.Errorf("delete orphans: %s", ctx.Err())
+			}
+			if nbi.Report != nil {
+				nbi.Report.RecordOrphan(objectAPIPath)
+			}
+			// Changes of orphaned objects are attributed to their owning source
+			itemCtx := context.WithValue(ctx, constants.CtxSourceKey, orphanItemSource(orphanItem))
 			if hard {
 				// Perform hard deletion
-				err := nbi.hardDelete(orphanItem)
+				err := nbi.hardDelete(itemCtx, orphanItem)
 				if err != nil {
-					nbi.OrphanManager.Logger.Errorf(nbi.Ctx, "hard delete object: %s", err)
+					nbi.OrphanManager.Logger.Errorf(ctx, "hard delete object: %s", err)
 					continue
 				}
 			} else {
-				err := nbi.softDelete(orphanItem)
+				err := nbi.softDelete(itemCtx, orphanItem)
 				if err != nil {
-					nbi.OrphanManager.Logger.Errorf(nbi.Ctx, "soft delete object: %s", err)
+					nbi.OrphanManager.Logger.Errorf(ctx, "soft delete object: %s", err)
 				}
 			}
 		}
@@ -55,16 +101,102 @@ func (nbi *NetboxInventory) DeleteOrphans(hard bool) error {
 	return nil
 }
 
-func (nbi *NetboxInventory) hardDelete(orphanItem objects.OrphanItem) error {
+// checkOrphanLimits checks that the number of objects orphaned in this run
+// doesn't exceed netbox.maxOrphans or netbox.maxOrphansPercent of managed
+// objects, neither for any object type, nor for any source. In soft delete mode
+// objects already marked as orphans in previous runs are not counted.
+func (nbi *NetboxInventory) checkOrphanLimits(ctx context.Context, hard bool, protectedSources map[string]bool) error {
+	maxOrphans := nbi.NetboxConfig.MaxOrphans
+	maxOrphansPercent := nbi.NetboxConfig.MaxOrphansPercent
+	if maxOrphans == 0 && maxOrphansPercent == 0 {
+		return nil
+	}
+
+	orphansByType := make(map[string]int)
+	orphansBySource := make(map[string]int)
+	for _, objectAPIPath := range nbi.OrphanManager.OrphanObjectPriority {
+		for _, orphanItem := range nbi.OrphanManager.unprotectedItems(objectAPIPath, protectedSources) {
+			if !hard && isMarkedAsOrphan(orphanItem) {
+				continue
+			}
+			orphansByType[string(objectAPIPath)]++
+			if sourceName := orphanItemSource(orphanItem); sourceName != "" {
+				orphansBySource[sourceName]++
+			}
+		}
+	}
+	managedByType := make(map[string]int)
+	managedBySource := make(map[string]int)
+	for objectAPIPath, id2item := range nbi.OrphanManager.managedItems {
+		managedByType[string(objectAPIPath)] = len(id2item)
+		for _, item := range id2item {
+			if sourceName := orphanItemSource(item); sourceName != "" {
+				managedBySource[sourceName]++
+			}
+		}
+	}
+
+	var violations []string
+	check := func(kind string, orphans map[string]int, managed map[string]int) {
+		for name, orphanCount := range orphans {
+			percent := float64(orphanCount) / float64(managed[name]) * 100 //nolint:mnd
+			if (maxOrphans > 0 && orphanCount > maxOrphans) ||
+				(maxOrphansPercent > 0 && percent > maxOrphansPercent) {
+				violations = append(violations, fmt.Sprintf(
+					"%s %s: %d of %d managed objects (%.1f%%) orphaned",
+					kind, name, orphanCount, managed[name], percent,
+				))
+			}
+		}
+	}
+	check("object type", orphansByType, managedByType)
+	check("source", orphansBySource, managedBySource)
+	if len(violations) == 0 {
+		return nil
+	}
+	sort.Strings(violations)
+	for _, violation := range violations {
+		nbi.OrphanManager.Logger.Errorf(ctx, "Refusing to delete orphans, %s", violation)
+	}
+	return fmt.Errorf(
+		"%w (maxOrphans: %d, maxOrphansPercent: %g): %s",
+		ErrOrphanLimitExceeded,
+		maxOrphans,
+		maxOrphansPercent,
+		strings.Join(violations, "; "),
+	)
+}
+
+// isMarkedAsOrphan returns true if the object was already marked as
+// orphan in one of the previous runs.
+func isMarkedAsOrphan(orphanItem objects.OrphanItem) bool {
+	netboxObject := orphanItem.GetNetboxObject()
+	return netboxObject.HasTagByName(constants.OrphanTagName) &&
+		netboxObject.GetCustomField(constants.CustomFieldOrphanLastSeenName) != nil
+}
+
+func (nbi *NetboxInventory) hardDelete(ctx context.Context, orphanItem objects.OrphanItem) error {
 	// Perform hard deletion
-	err := nbi.NetboxAPI.DeleteObject(nbi.Ctx, orphanItem)
+	err := nbi.NetboxAPI.DeleteObject(ctx, orphanItem)
 	if err != nil {
 		return fmt.Errorf("Failed deleting %s object: %s", orphanItem, err)
 	}
+	// Objects are only recorded in the plan in dry-run mode
+	if nbi.Plan == nil {
+		nbi.hardDeletedObjects++
+		metrics.Orphans.WithLabelValues(
+			orphanItemSource(orphanItem),
+			string(orphanItem.GetAPIPath()),
+			metrics.OrphanActionDeleted,
+		).Inc()
+		if nbi.Report != nil {
+			nbi.Report.RecordHardDelete(orphanItem.GetAPIPath(), orphanItem.GetID())
+		}
+	}
 	return nil
 }
 
-func (nbi *NetboxInventory) softDelete(orphanItem objects.OrphanItem) error {
+func (nbi *NetboxInventory) softDelete(ctx context.Context, orphanItem objects.OrphanItem) error {
 	// Perform soft deletion
 	// Add tag to the object to mark it as orphaned
 	todayDate := time.Now().Format(constants.CustomFieldOrphanLastSeenFormat)
@@ -80,66 +212,28 @@ func (nbi *NetboxInventory) softDelete(orphanItem objects.OrphanItem) error {
 			utils.StructToNetboxJSONMap(orphanItem.GetNetboxObject()),
 			[]string{"tags", "custom_fields"},
 		)
-		// Update object on the API
-		var err error
-		switch orphanItem.(type) {
-		case *objects.VlanGroup:
-			_, err = service.Patch[objects.VlanGroup](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.Prefix:
-			_, err = service.Patch[objects.Prefix](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.Vlan:
-			_, err = service.Patch[objects.Vlan](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.IPAddress:
-			_, err = service.Patch[objects.IPAddress](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.VirtualDeviceContext:
-			_, err = service.Patch[objects.VirtualDeviceContext](
-				nbi.OrphanManager.Ctx,
-				nbi.NetboxAPI,
+		if nbi.NetboxAPI.Plan != nil {
+			nbi.NetboxAPI.Plan.RecordSoftDelete(
+				ctx,
+				orphanItem.GetAPIPath(),
 				orphanItem.GetID(),
+				fmt.Sprintf("%v", orphanItem),
 				diffMap,
 			)
-		case *objects.Interface:
-			_, err = service.Patch[objects.Interface](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.VMInterface:
-			_, err = service.Patch[objects.VMInterface](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.VM:
-			_, err = service.Patch[objects.VM](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.Device:
-			_, err = service.Patch[objects.Device](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.Platform:
-			_, err = service.Patch[objects.Platform](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.DeviceType:
-			_, err = service.Patch[objects.DeviceType](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.Manufacturer:
-			_, err = service.Patch[objects.Manufacturer](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.DeviceRole:
-			_, err = service.Patch[objects.DeviceRole](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.ClusterType:
-			_, err = service.Patch[objects.ClusterType](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.Cluster:
-			_, err = service.Patch[objects.Cluster](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.ClusterGroup:
-			_, err = service.Patch[objects.ClusterGroup](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.ContactAssignment:
-			_, err = service.Patch[objects.ContactAssignment](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.Contact:
-			_, err = service.Patch[objects.Contact](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.WirelessLAN:
-			_, err = service.Patch[objects.WirelessLAN](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.WirelessLANGroup:
-			_, err = service.Patch[objects.WirelessLANGroup](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.MACAddress:
-			_, err = service.Patch[objects.MACAddress](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		case *objects.VirtualDisk:
-			_, err = service.Patch[objects.VirtualDisk](nbi.OrphanManager.Ctx, nbi.NetboxAPI, orphanItem.GetID(), diffMap)
-		default:
-			return fmt.Errorf("unsupported type for orphan item%T", orphanItem)
+			return nil
 		}
+		// Update object on the API
+		err := nbi.NetboxAPI.PatchObject(ctx, orphanItem, diffMap)
 		if err != nil {
 			return fmt.Errorf("failed updating %s object with orphan tag: %s", orphanItem, err)
 		}
+		metrics.Orphans.WithLabelValues(
+			orphanItemSource(orphanItem),
+			string(orphanItem.GetAPIPath()),
+			metrics.OrphanActionTagged,
+		).Inc()
 	} else {
-		nbi.Logger.Debugf(nbi.Ctx, "%s is already marked as orphan", orphanItem)
+		nbi.Logger.Debugf(ctx, "%s is already marked as orphan", orphanItem)
 		lastSeen, err := time.Parse(
 			constants.CustomFieldOrphanLastSeenFormat,
 			orphanItem.GetNetboxObject().GetCustomField(constants.CustomFieldOrphanLastSeenName).(string),
@@ -148,7 +242,7 @@ func (nbi *NetboxInventory) softDelete(orphanItem objects.OrphanItem) error {
 			return fmt.Errorf("failed parsing last seen date: %s", err)
 		}
 		if int((time.Since(lastSeen).Hours())/24) > nbi.NetboxConfig.RemoveOrphansAfterDays { //nolint:mnd
-			err := nbi.hardDelete(orphanItem)
+			err := nbi.hardDelete(ctx, orphanItem)
 			if err != nil {
 				return fmt.Errorf("failed deleting %s object: %s", orphanItem, err)
 			}
@@ -1,14 +1,22 @@
 package inventory
 
 import (
+	"context"
+	"errors"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/metrics"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox"
 	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/parser"
 )
 
 func TestNetboxInventory_DeleteOrphans(t *testing.T) {
 	type args struct {
-		hard bool
+		hard             bool
+		protectedSources []string
 	}
 	tests := []struct {
 		name    string
@@ -20,13 +28,72 @@ func TestNetboxInventory_DeleteOrphans(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := tt.nbi.DeleteOrphans(tt.args.hard); (err != nil) != tt.wantErr {
+			err := tt.nbi.DeleteOrphans(context.Background(), tt.args.hard, tt.args.protectedSources)
+			if (err != nil) != tt.wantErr {
 				t.Errorf("NetboxInventory.DeleteOrphans() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
+func TestNetboxInventory_DeleteOrphans_Canceled(t *testing.T) {
+	tests := []struct {
+		name         string
+		cancel       bool
+		wantErr      bool
+		wantExisting int
+	}{
+		{
+			name: "Orphans are deleted",
+		},
+		{
+			name:         "Canceled context stops deletion",
+			cancel:       true,
+			wantErr:      true,
+			wantExisting: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fakenetbox.NewServer()
+			defer server.Close()
+			ssotTag, err := fakenetbox.Add(server, &objects.Tag{Name: constants.SsotTagName, Slug: "netbox-ssot"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = fakenetbox.Add(server, &objects.Manufacturer{
+				NetboxObject: objects.NetboxObject{Tags: []*objects.Tag{ssotTag}},
+				Name:         "Orphan",
+				Slug:         "orphan",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "test")
+			nbi := NewNetboxInventory(ctx, MockInventory.Logger, server.Config())
+			if err := nbi.Init(); err != nil {
+				t.Fatalf("Init() error = %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(ctx)
+			if tt.cancel {
+				cancel()
+			}
+			defer cancel()
+			if err := nbi.DeleteOrphans(ctx, true, nil); (err != nil) != tt.wantErr {
+				t.Errorf("NetboxInventory.DeleteOrphans() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			manufacturers, err := fakenetbox.Objects[objects.Manufacturer](server)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(manufacturers) != tt.wantExisting {
+				t.Errorf("manufacturers = %v, want %d", manufacturers, tt.wantExisting)
+			}
+		})
+	}
+}
+
 func TestNetboxInventory_hardDelete(t *testing.T) {
 	type args struct {
 		orphanItem objects.OrphanItem
@@ -41,9 +108,142 @@ func TestNetboxInventory_hardDelete(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := tt.nbi.hardDelete(tt.args.orphanItem); (err != nil) != tt.wantErr {
+			if err := tt.nbi.hardDelete(context.Background(), tt.args.orphanItem); (err != nil) != tt.wantErr {
 				t.Errorf("NetboxInventory.hardDelete() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestNetboxInventory_checkOrphanLimits(t *testing.T) {
+	ssotTags := []*objects.Tag{{Name: constants.SsotTagName}}
+	orphanTags := []*objects.Tag{{Name: constants.SsotTagName}, {Name: constants.OrphanTagName}}
+	newVM := func(id int, sourceName string, tags []*objects.Tag) *objects.VM {
+		vm := &objects.VM{NetboxObject: objects.NetboxObject{ID: id, Tags: tags}}
+		vm.SetCustomField(constants.CustomFieldSourceName, sourceName)
+		if len(tags) > 1 {
+			vm.SetCustomField(constants.CustomFieldOrphanLastSeenName, "2024-01-01 00:00:00")
+		}
+		return vm
+	}
+	// vmware manages 4 vms, 2 of them were not seen in this run.
+	// ovirt manages 2 vms, 1 of them was not seen in this run,
+	// and 1 was already marked as orphan in one of the previous runs.
+	orphanManager := NewOrphanManager(MockInventory.Logger)
+	for _, vm := range []*objects.VM{
+		newVM(1, "vmware", ssotTags),
+		newVM(2, "vmware", ssotTags),
+		newVM(3, "vmware", ssotTags),
+		newVM(4, "vmware", ssotTags),
+		newVM(5, "ovirt", ssotTags),
+		newVM(6, "ovirt", orphanTags),
+	} {
+		orphanManager.AddItem(vm)
+	}
+	orphanManager.RemoveItem(newVM(1, "vmware", ssotTags))
+	orphanManager.RemoveItem(newVM(2, "vmware", ssotTags))
+
+	tests := []struct {
+		name              string
+		maxOrphans        int
+		maxOrphansPercent float64
+		hard              bool
+		protectedSources  map[string]bool
+		wantErr           string
+	}{
+		{
+			name: "No limits",
+			hard: true,
+		},
+		{
+			name:       "Soft delete doesn't count objects already marked as orphans",
+			maxOrphans: 3,
+		},
+		{
+			name:       "Hard delete exceeds max orphans per type",
+			maxOrphans: 3,
+			hard:       true,
+			wantErr: "orphan limit exceeded (maxOrphans: 3, maxOrphansPercent: 0): " +
+				"object type /api/virtualization/virtual-machines/: 4 of 6 managed objects (66.7%) orphaned",
+		},
+		{
+			name:              "Max orphans percent is not exceeded",
+			maxOrphansPercent: 50,
+		},
+		{
+			name:              "Max orphans percent per source and per type",
+			maxOrphansPercent: 40,
+			wantErr: "orphan limit exceeded (maxOrphans: 0, maxOrphansPercent: 40): " +
+				"object type /api/virtualization/virtual-machines/: 3 of 6 managed objects (50.0%) orphaned; " +
+				"source ovirt: 1 of 2 managed objects (50.0%) orphaned; " +
+				"source vmware: 2 of 4 managed objects (50.0%) orphaned",
+		},
+		{
+			name:              "Orphans of protected sources are not counted",
+			maxOrphansPercent: 40,
+			protectedSources:  map[string]bool{"ovirt": true},
+			wantErr: "orphan limit exceeded (maxOrphans: 0, maxOrphansPercent: 40): " +
+				"source vmware: 2 of 4 managed objects (50.0%) orphaned",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nbi := &NetboxInventory{
+				NetboxConfig: &parser.NetboxConfig{
+					MaxOrphans:        tt.maxOrphans,
+					MaxOrphansPercent: tt.maxOrphansPercent,
+				},
+				OrphanManager: orphanManager,
+				Ctx:           context.Background(),
+			}
+			err := nbi.checkOrphanLimits(context.Background(), tt.hard, tt.protectedSources)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("NetboxInventory.checkOrphanLimits() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrOrphanLimitExceeded) || err.Error() != tt.wantErr {
+				t.Errorf("NetboxInventory.checkOrphanLimits() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNetboxInventory_DeleteOrphans_Metrics(t *testing.T) {
+	server := fakenetbox.NewServer()
+	defer server.Close()
+	ssotTag, err := fakenetbox.Add(server, &objects.Tag{Name: constants.SsotTagName, Slug: "netbox-ssot"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manufacturer := &objects.Manufacturer{
+		NetboxObject: objects.NetboxObject{Tags: []*objects.Tag{ssotTag}},
+		Name:         "Orphan",
+		Slug:         "orphan",
+	}
+	manufacturer.SetCustomField(constants.CustomFieldSourceName, "vmware")
+	if _, err := fakenetbox.Add(server, manufacturer); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "test")
+	nbi := NewNetboxInventory(ctx, MockInventory.Logger, server.Config())
+	if err := nbi.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	path := string(constants.ManufacturersAPIPath)
+	orphans := metrics.Orphans.WithLabelValues("vmware", path, metrics.OrphanActionDeleted)
+	changes := metrics.ObjectChanges.WithLabelValues("vmware", path, metrics.ActionDelete)
+	orphansBefore, changesBefore := testutil.ToFloat64(orphans), testutil.ToFloat64(changes)
+	if err := nbi.DeleteOrphans(ctx, true, nil); err != nil {
+		t.Fatalf("NetboxInventory.DeleteOrphans() error = %v", err)
+	}
+	// Deleted orphans are counted by their owning source
+	if got := testutil.ToFloat64(orphans) - orphansBefore; got != 1 {
+		t.Errorf("deleted orphans of vmware = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(changes) - changesBefore; got != 1 {
+		t.Errorf("deleted objects of vmware = %v, want 1", got)
+	}
+}
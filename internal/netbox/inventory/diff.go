@@ -0,0 +1,48 @@
+package inventory
+
+import (
+	"reflect"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/mapper"
+	"github.com/src-doo/netbox-ssot/internal/utils"
+)
+
+// diffMap returns the diff map of newObject and existingObject (see
+// utils.JSONDiffMapExceptID), without fields that the connected Netbox
+// release doesn't support. Otherwise these fields would be patched on
+// every run, because they are never returned by Netbox.
+func (nbi *NetboxInventory) diffMap(
+	newObject interface{},
+	existingObject interface{},
+	resetFields bool,
+) (map[string]interface{}, error) {
+	diffMap, err := utils.JSONDiffMapExceptID(
+		newObject,
+		existingObject,
+		resetFields,
+		nbi.SourcePriority,
+		nbi.fieldOwners(newObject),
+	)
+	if err != nil {
+		return nil, err
+	}
+	objectPath, ok := mapper.Type2Path[reflect.Indirect(reflect.ValueOf(newObject)).Type()]
+	if !ok {
+		return diffMap, nil
+	}
+	for _, field := range nbi.Capabilities.UnsupportedFields(objectPath) {
+		delete(diffMap, field)
+	}
+	return diffMap, nil
+}
+
+// fieldOwners returns owners of fields of the object type of newObject,
+// configured in netbox.fieldOwnership.
+func (nbi *NetboxInventory) fieldOwners(newObject interface{}) map[string][]string {
+	object, ok := newObject.(interface{ GetObjectType() constants.ContentType })
+	if !ok {
+		return nil
+	}
+	return nbi.FieldOwnership[object.GetObjectType()]
+}
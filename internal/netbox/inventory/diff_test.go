@@ -0,0 +1,50 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/netbox/version"
+	"github.com/src-doo/netbox-ssot/internal/utils"
+)
+
+func TestNetboxInventory_diffMap(t *testing.T) {
+	existingInterface := &objects.Interface{NetboxObject: objects.NetboxObject{ID: 1}, Name: "eth0"}
+	newInterface := &objects.Interface{
+		NetboxObject:      objects.NetboxObject{Description: "interface"},
+		Name:              "eth0",
+		PrimaryMACAddress: &objects.MACAddress{NetboxObject: objects.NetboxObject{ID: 2}, MAC: "00:00:00:00:00:01"},
+	}
+	tests := []struct {
+		name         string
+		capabilities *version.Capabilities
+		want         map[string]interface{}
+	}{
+		{
+			name:         "All fields are supported",
+			capabilities: version.Of(version.Version{Major: 4, Minor: 2}),
+			want: map[string]interface{}{
+				"description":         "interface",
+				"primary_mac_address": utils.IDObject{ID: 2},
+			},
+		},
+		{
+			name:         "MAC address objects are not supported",
+			capabilities: version.Of(version.Version{Major: 4, Minor: 1}),
+			want:         map[string]interface{}{"description": "interface"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nbi := &NetboxInventory{Capabilities: tt.capabilities}
+			got, err := nbi.diffMap(newInterface, existingInterface, false)
+			if err != nil {
+				t.Fatalf("diffMap() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -9,9 +9,9 @@ import (
 // It returns nil if the Tag is not found.
 // This function is thread-safe.
 func (nbi *NetboxInventory) GetTag(tagName string) (*objects.Tag, bool) {
-	nbi.tagsLock.Lock()
-	defer nbi.tagsLock.Unlock()
-	tag, tagExists := nbi.tagsIndexByName[tagName]
+	nbi.tags.Lock()
+	defer nbi.tags.Unlock()
+	tag, tagExists := nbi.tags.Lookup(tagName)
 	if !tagExists {
 		return nil, false
 	}
@@ -22,9 +22,9 @@ func (nbi *NetboxInventory) GetTag(tagName string) (*objects.Tag, bool) {
 // It returns nil if the Manufacturer is not found.
 // This function is thread-safe.
 func (nbi *NetboxInventory) GetManufacturer(manufacturerName string) (*objects.Manufacturer, bool) {
-	nbi.manufacturersLock.Lock()
-	defer nbi.manufacturersLock.Unlock()
-	manufacturer, manufacturerExists := nbi.manufacturersIndexByName[manufacturerName]
+	nbi.manufacturers.Lock()
+	defer nbi.manufacturers.Unlock()
+	manufacturer, manufacturerExists := nbi.manufacturers.Lookup(manufacturerName)
 	if !manufacturerExists {
 		return nil, false
 	}
@@ -35,10 +35,9 @@ func (nbi *NetboxInventory) GetManufacturer(manufacturerName string) (*objects.M
 // It returns nil if the CustomField is not found.
 // This function is thread-safe.
 func (nbi *NetboxInventory) GetCustomField(customFieldName string) (*objects.CustomField, bool) {
-	nbi.customFieldsLock.Lock()
-	defer nbi.customFieldsLock.Unlock()
-
-	customField, customFieldExists := nbi.customFieldsIndexByName[customFieldName]
+	nbi.customFields.Lock()
+	defer nbi.customFields.Unlock()
+	customField, customFieldExists := nbi.customFields.Lookup(customFieldName)
 	if !customFieldExists {
 		return nil, false
 	}
@@ -49,15 +48,9 @@ func (nbi *NetboxInventory) GetCustomField(customFieldName string) (*objects.Cus
 // It returns nil if the VLAN is not found.
 // This function is thread-safe.
 func (nbi *NetboxInventory) GetVlan(groupID, vlanID int) (*objects.Vlan, bool) {
-	nbi.vlansLock.Lock()
-	defer nbi.vlansLock.Unlock()
-
-	vlanGroup, groupExists := nbi.vlansIndexByVlanGroupIDAndVID[groupID]
-	if !groupExists {
-		return nil, false
-	}
-
-	vlan, vlanExists := vlanGroup[vlanID]
+	nbi.vlans.Lock()
+	defer nbi.vlans.Unlock()
+	vlan, vlanExists := nbi.vlans.Lookup(vlanIndexKey{GroupID: groupID, Vid: vlanID})
 	if !vlanExists {
 		return nil, false
 	}
@@ -68,9 +61,9 @@ func (nbi *NetboxInventory) GetVlan(groupID, vlanID int) (*objects.Vlan, bool) {
 // It returns nil if the Tenant is not found.
 // This function is thread-safe.
 func (nbi *NetboxInventory) GetTenant(tenantName string) (*objects.Tenant, bool) {
-	nbi.tenantsLock.Lock()
-	defer nbi.tenantsLock.Unlock()
-	tenant, tenantExists := nbi.tenantsIndexByName[tenantName]
+	nbi.tenants.Lock()
+	defer nbi.tenants.Unlock()
+	tenant, tenantExists := nbi.tenants.Lookup(tenantName)
 	if !tenantExists {
 		return nil, false
 	}
@@ -81,9 +74,9 @@ func (nbi *NetboxInventory) GetTenant(tenantName string) (*objects.Tenant, bool)
 // It returns nil if the Site is not found.
 // This function is thread-safe.
 func (nbi *NetboxInventory) GetSite(siteName string) (*objects.Site, bool) {
-	nbi.sitesLock.Lock()
-	defer nbi.sitesLock.Unlock()
-	site, siteExists := nbi.sitesIndexByName[siteName]
+	nbi.sites.Lock()
+	defer nbi.sites.Unlock()
+	site, siteExists := nbi.sites.Lookup(siteName)
 	if !siteExists {
 		return nil, false
 	}
@@ -94,23 +87,19 @@ func (nbi *NetboxInventory) GetSite(siteName string) (*objects.Site, bool) {
 // It returns nil if the Site is not found.
 // This function is thread-safe.
 func (nbi *NetboxInventory) GetSiteByID(siteID int) *objects.Site {
-	nbi.sitesLock.Lock()
-	defer nbi.sitesLock.Unlock()
-	for _, site := range nbi.sitesIndexByName {
-		if site.ID == siteID {
-			return site
-		}
-	}
-	return nil
+	nbi.sites.Lock()
+	defer nbi.sites.Unlock()
+	site, _ := nbi.sites.LookupByID(siteID)
+	return site
 }
 
 // GetVlanGroup returns the VlanGroup for the given vlanGroupName.
 // It returns nil if the VlanGroup is not found.
 // This function is thread-safe.
 func (nbi *NetboxInventory) GetVlanGroup(vlanGroupName string) (*objects.VlanGroup, bool) {
-	nbi.vlanGroupsLock.Lock()
-	defer nbi.vlanGroupsLock.Unlock()
-	vlanGroup, vlanGroupExists := nbi.vlanGroupsIndexByName[vlanGroupName]
+	nbi.vlanGroups.Lock()
+	defer nbi.vlanGroups.Unlock()
+	vlanGroup, vlanGroupExists := nbi.vlanGroups.Lookup(vlanGroupName)
 	if !vlanGroupExists {
 		return nil, false
 	}
@@ -121,9 +110,9 @@ func (nbi *NetboxInventory) GetVlanGroup(vlanGroupName string) (*objects.VlanGro
 // It returns nil if the ClusterGroup is not found.
 // This function is thread-safe.
 func (nbi *NetboxInventory) GetClusterGroup(clusterGroupName string) (*objects.ClusterGroup, bool) {
-	nbi.clusterGroupsLock.Lock()
-	defer nbi.clusterGroupsLock.Unlock()
-	clusterGroup, clusterGroupExists := nbi.clusterGroupsIndexByName[clusterGroupName]
+	nbi.clusterGroups.Lock()
+	defer nbi.clusterGroups.Unlock()
+	clusterGroup, clusterGroupExists := nbi.clusterGroups.Lookup(clusterGroupName)
 	if !clusterGroupExists {
 		return nil, false
 	}
@@ -134,9 +123,9 @@ func (nbi *NetboxInventory) GetClusterGroup(clusterGroupName string) (*objects.C
 // It returns nil if the Cluster is not found.
 // This function is thread-safe.
 func (nbi *NetboxInventory) GetCluster(clusterName string) (*objects.Cluster, bool) {
-	nbi.clustersLock.Lock()
-	defer nbi.clustersLock.Unlock()
-	cluster, clusterExists := nbi.clustersIndexByName[clusterName]
+	nbi.clusters.Lock()
+	defer nbi.clusters.Unlock()
+	cluster, clusterExists := nbi.clusters.Lookup(clusterName)
 	if !clusterExists {
 		return nil, false
 	}
@@ -145,9 +134,9 @@ func (nbi *NetboxInventory) GetCluster(clusterName string) (*objects.Cluster, bo
 }
 
 func (nbi *NetboxInventory) GetDevice(deviceName string, siteID int) (*objects.Device, bool) {
-	nbi.devicesLock.Lock()
-	defer nbi.devicesLock.Unlock()
-	device, deviceExists := nbi.devicesIndexByNameAndSiteID[deviceName][siteID]
+	nbi.devices.Lock()
+	defer nbi.devices.Unlock()
+	device, deviceExists := nbi.devices.Lookup(deviceIndexKey{Name: deviceName, SiteID: siteID})
 	if !deviceExists {
 		return nil, false
 	}
@@ -155,9 +144,9 @@ func (nbi *NetboxInventory) GetDevice(deviceName string, siteID int) (*objects.D
 }
 
 func (nbi *NetboxInventory) GetDeviceRole(deviceRoleName string) (*objects.DeviceRole, bool) {
-	nbi.deviceRolesLock.Lock()
-	defer nbi.deviceRolesLock.Unlock()
-	deviceRole, deviceRoleExists := nbi.deviceRolesIndexByName[deviceRoleName]
+	nbi.deviceRoles.Lock()
+	defer nbi.deviceRoles.Unlock()
+	deviceRole, deviceRoleExists := nbi.deviceRoles.Lookup(deviceRoleName)
 	if !deviceRoleExists {
 		return nil, false
 	}
@@ -168,10 +157,9 @@ func (nbi *NetboxInventory) GetDeviceRole(deviceRoleName string) (*objects.Devic
 // It returns nil if the ContactRole is not found.
 // This function is thread-safe.
 func (nbi *NetboxInventory) GetContactRole(contactRoleName string) (*objects.ContactRole, bool) {
-	nbi.contactRolesLock.Lock()
-	defer nbi.contactRolesLock.Unlock()
-
-	contactRole, contactRoleExists := nbi.contactRolesIndexByName[contactRoleName]
+	nbi.contactRoles.Lock()
+	defer nbi.contactRoles.Unlock()
+	contactRole, contactRoleExists := nbi.contactRoles.Lookup(contactRoleName)
 	if !contactRoleExists {
 		return nil, false
 	}
@@ -185,9 +173,11 @@ func (nbi *NetboxInventory) GetVirtualDeviceContext(
 	zoneName string,
 	deviceID int,
 ) (*objects.VirtualDeviceContext, bool) {
-	nbi.virtualDeviceContextsLock.Lock()
-	defer nbi.virtualDeviceContextsLock.Unlock()
-	vdc, vdcExists := nbi.virtualDeviceContextsIndex[zoneName][deviceID]
+	nbi.virtualDeviceContexts.Lock()
+	defer nbi.virtualDeviceContexts.Unlock()
+	vdc, vdcExists := nbi.virtualDeviceContexts.Lookup(
+		deviceChildIndexKey{DeviceID: deviceID, Name: zoneName},
+	)
 	if !vdcExists {
 		return nil, false
 	}
@@ -201,10 +191,11 @@ func (nbi *NetboxInventory) GetInterface(
 	interfaceName string,
 	deviceID int,
 ) (*objects.Interface, bool) {
-	nbi.interfacesLock.Lock()
-	defer nbi.interfacesLock.Unlock()
-
-	iface, ifaceExists := nbi.interfacesIndexByDeviceIDAndName[deviceID][interfaceName]
+	nbi.interfaces.Lock()
+	defer nbi.interfaces.Unlock()
+	iface, ifaceExists := nbi.interfaces.Lookup(
+		deviceChildIndexKey{DeviceID: deviceID, Name: interfaceName},
+	)
 	if !ifaceExists {
 		return nil, false
 	}
@@ -220,9 +211,14 @@ func (nbi *NetboxInventory) GetContactAssignment(
 	contactID int,
 	roleID int,
 ) (*objects.ContactAssignment, bool) {
-	nbi.contactAssignmentsLock.Lock()
-	defer nbi.contactAssignmentsLock.Unlock()
-	contactAssignment, contactAssignmentExists := nbi.contactAssignmentsIndex[contentType][objectID][contactID][roleID]
+	nbi.contactAssignments.Lock()
+	defer nbi.contactAssignments.Unlock()
+	contactAssignment, contactAssignmentExists := nbi.contactAssignments.Lookup(contactAssignmentIndexKey{
+		ModelType: contentType,
+		ObjectID:  objectID,
+		ContactID: contactID,
+		RoleID:    roleID,
+	})
 	if !contactAssignmentExists {
 		return nil, false
 	}
@@ -233,34 +229,38 @@ func (nbi *NetboxInventory) GetContactAssignment(
 // It returns nil if the Interface is not found.
 // This function is thread-safe.
 func (nbi *NetboxInventory) GetInterfaceByID(interfaceID int) *objects.Interface {
-	nbi.interfacesLock.Lock()
-	defer nbi.interfacesLock.Unlock()
-	return nbi.interfacesIndexByID[interfaceID]
+	nbi.interfaces.Lock()
+	defer nbi.interfaces.Unlock()
+	iface, _ := nbi.interfaces.LookupByID(interfaceID)
+	return iface
 }
 
 // GetVMInterfaceByID returns the VMInterface for the given vmInterfaceID.
 // It returns nil if the VMInterface is not found.
 // This function is thread-safe.
 func (nbi *NetboxInventory) GetVMInterfaceByID(vmInterfaceID int) *objects.VMInterface {
-	nbi.vmInterfacesLock.Lock()
-	defer nbi.vmInterfacesLock.Unlock()
-	return nbi.vmInterfacesIndexByID[vmInterfaceID]
+	nbi.vmInterfaces.Lock()
+	defer nbi.vmInterfaces.Unlock()
+	vmIface, _ := nbi.vmInterfaces.LookupByID(vmInterfaceID)
+	return vmIface
 }
 
 // GetDeviceByID returns the Device for the given deviceID.
 // It returns nil if the Device is not found.
 // This function is thread-safe.
 func (nbi *NetboxInventory) GetDeviceByID(deviceID int) *objects.Device {
-	nbi.devicesLock.Lock()
-	defer nbi.devicesLock.Unlock()
-	return nbi.devicesIndexByID[deviceID]
+	nbi.devices.Lock()
+	defer nbi.devices.Unlock()
+	device, _ := nbi.devices.LookupByID(deviceID)
+	return device
 }
 
 // GetVMByID returns the VirtualMachine for the given vmID.
 // It returns nil if the VirtualMachine is not found.
 // This function is thread-safe.
 func (nbi *NetboxInventory) GetVMByID(vmID int) *objects.VM {
-	nbi.vmsLock.Lock()
-	defer nbi.vmsLock.Unlock()
-	return nbi.vmsIndexByID[vmID]
+	nbi.vms.Lock()
+	defer nbi.vms.Unlock()
+	vm, _ := nbi.vms.LookupByID(vmID)
+	return vm
 }
@@ -131,55 +131,3 @@ func (nbi *NetboxInventory) getIndexValuesForMACAddress(
 	}
 	return macIfaceType, macIfaceName, macIfaceParentName, nil
 }
-
-func (nbi *NetboxInventory) verifyIPAddressIndexExists(
-	ifaceType constants.ContentType,
-	ifaceName string,
-	ifaceParentName string,
-) {
-	nbi.ipAddressesLock.Lock()
-	defer nbi.ipAddressesLock.Unlock()
-	if nbi.ipAddressesIndex[ifaceType] == nil {
-		nbi.ipAddressesIndex[ifaceType] = make(
-			map[string]map[string]map[string]*objects.IPAddress,
-		)
-	}
-
-	if nbi.ipAddressesIndex[ifaceType][ifaceName] == nil {
-		nbi.ipAddressesIndex[ifaceType][ifaceName] = make(
-			map[string]map[string]*objects.IPAddress,
-		)
-	}
-
-	if nbi.ipAddressesIndex[ifaceType][ifaceName][ifaceParentName] == nil {
-		nbi.ipAddressesIndex[ifaceType][ifaceName][ifaceParentName] = make(
-			map[string]*objects.IPAddress,
-		)
-	}
-}
-
-func (nbi *NetboxInventory) verifyMACAddressIndexExists(
-	ifaceType constants.ContentType,
-	ifaceName string,
-	ifaceParentName string,
-) {
-	nbi.macAddressesLock.Lock()
-	defer nbi.macAddressesLock.Unlock()
-	if nbi.macAddressesIndex[ifaceType] == nil {
-		nbi.macAddressesIndex[ifaceType] = make(
-			map[string]map[string]map[string]*objects.MACAddress,
-		)
-	}
-
-	if nbi.macAddressesIndex[ifaceType][ifaceName] == nil {
-		nbi.macAddressesIndex[ifaceType][ifaceName] = make(
-			map[string]map[string]*objects.MACAddress,
-		)
-	}
-
-	if nbi.macAddressesIndex[ifaceType][ifaceName][ifaceParentName] == nil {
-		nbi.macAddressesIndex[ifaceType][ifaceName][ifaceParentName] = make(
-			map[string]*objects.MACAddress,
-		)
-	}
-}
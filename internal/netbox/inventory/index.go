@@ -0,0 +1,300 @@
+package inventory
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+)
+
+// Index is a thread-safe index of objects of type T, keyed by the key
+// function of the index. Objects are also indexed by their ids, which is
+// used to resolve relationships between objects.
+//
+// Lookup and upsert functions don't lock the index, so Add* functions can
+// hold the lock while the object is created or patched, and the same
+// object can't be added twice concurrently.
+type Index[K comparable, T objects.OrphanItem] struct {
+	mu sync.Mutex
+	// key returns the key of the object in the index. It is nil for
+	// objects, whose keys can't be computed while the index is locked
+	// (see ipAddressKey). These are stored with UpsertKey and RegisterKey.
+	key       func(T) K
+	items     map[K]T
+	itemsByID map[int]T
+	// keys are keys of objects by their ids, so objects that are stored
+	// under a new key (e.g. renamed objects) are removed from their old key.
+	keys map[int]K
+	// itemsBySourceID are objects by their source ids (see sourceIDKey).
+	itemsBySourceID map[sourceIDIndexKey]T
+	// secondaryIndexes index objects by additional keys, in the order
+	// in which they are used by LookupSecondary.
+	secondaryIndexes []*secondaryIndex[T]
+	// pending are objects by their keys, that are being created while the
+	// index is unlocked (see AddPending).
+	pending map[K]pendingObject[T]
+	// orphanManager tracks registered objects, that can be deleted once they
+	// are not seen in the sources anymore. It is nil for objects that are
+	// never deleted by netbox-ssot (e.g. tags).
+	orphanManager *OrphanManager
+}
+
+// NewIndex returns an empty index with the given key function.
+// Registered objects are added to the orphanManager, when it is not nil.
+func NewIndex[K comparable, T objects.OrphanItem](key func(T) K, orphanManager *OrphanManager) *Index[K, T] {
+	return &Index[K, T]{
+		key:             key,
+		items:           make(map[K]T),
+		itemsByID:       make(map[int]T),
+		keys:            make(map[int]K),
+		itemsBySourceID: make(map[sourceIDIndexKey]T),
+		pending:         make(map[K]pendingObject[T]),
+		orphanManager:   orphanManager,
+	}
+}
+
+func (idx *Index[K, T]) Lock() {
+	idx.mu.Lock()
+}
+
+func (idx *Index[K, T]) Unlock() {
+	idx.mu.Unlock()
+}
+
+// Key returns the key of the object in the index.
+func (idx *Index[K, T]) Key(object T) K {
+	return idx.key(object)
+}
+
+// Lookup returns the object with the given key.
+// The index must be locked.
+func (idx *Index[K, T]) Lookup(key K) (T, bool) {
+	object, ok := idx.items[key]
+	return object, ok
+}
+
+// LookupByID returns the object with the given id.
+// The index must be locked.
+func (idx *Index[K, T]) LookupByID(id int) (T, bool) {
+	object, ok := idx.itemsByID[id]
+	return object, ok
+}
+
+// LookupBySourceID returns the object with the same source and source id
+// as the given object. The index must be locked.
+func (idx *Index[K, T]) LookupBySourceID(object T) (T, bool) {
+	var found T
+	key, ok := sourceIDKey(object)
+	if !ok {
+		return found, false
+	}
+	found, ok = idx.itemsBySourceID[key]
+	return found, ok
+}
+
+// AddSecondaryIndex adds an index of objects by the additional key, returned
+// by key. Objects for which key returns false are not indexed. Secondary
+// indexes must be added before objects are stored in the index.
+func (idx *Index[K, T]) AddSecondaryIndex(name string, key func(T) (string, bool)) {
+	idx.secondaryIndexes = append(idx.secondaryIndexes, &secondaryIndex[T]{
+		name:  name,
+		key:   key,
+		items: make(map[string][]T),
+	})
+}
+
+// LookupSecondary returns the object with the same secondary key as the
+// given object, and the name of the secondary index it was found in.
+// Secondary indexes are searched in the order in which they were added.
+// Keys shared by multiple objects (e.g. serial numbers of devices that were
+// created before matching was enabled) are ambiguous, so these objects are
+// not matched, and are returned as ambiguous matches instead.
+// The index must be locked.
+func (idx *Index[K, T]) LookupSecondary(object T) (T, string, bool, []ambiguousMatch[T]) {
+	var ambiguous []ambiguousMatch[T]
+	for _, secondary := range idx.secondaryIndexes {
+		key, ok := secondary.key(object)
+		if !ok {
+			continue
+		}
+		switch found := secondary.items[key]; {
+		case len(found) == 1:
+			return found[0], secondary.name, true, ambiguous
+		case len(found) > 1:
+			ambiguous = append(ambiguous, ambiguousMatch[T]{index: secondary.name, objects: slices.Clone(found)})
+		}
+	}
+	var found T
+	return found, "", false, ambiguous
+}
+
+// Upsert stores the object under its key, and replaces
+// the object with the same key. The index must be locked.
+func (idx *Index[K, T]) Upsert(object T) {
+	idx.UpsertKey(idx.key(object), object)
+}
+
+// UpsertKey stores the object under the given key, and replaces the object
+// with the same key. It is used when the key of the object can't be computed
+// while the index is locked (see ipAddressKey). The index must be locked.
+func (idx *Index[K, T]) UpsertKey(key K, object T) {
+	if oldObject, ok := idx.items[key]; ok && oldObject.GetID() != object.GetID() {
+		// Replaced object is e.g. an object, that was renamed in Netbox
+		idx.remove(oldObject)
+	}
+	// Objects without ids (e.g. in tests) can't be tracked by their ids
+	if oldObject, ok := idx.itemsByID[object.GetID()]; ok && object.GetID() != 0 {
+		// Object is replaced by its patched version, which can have
+		// different keys (e.g. because it was renamed)
+		idx.remove(oldObject)
+	}
+	idx.items[key] = object
+	idx.itemsByID[object.GetID()] = object
+	idx.keys[object.GetID()] = key
+	if sourceIDKey, ok := sourceIDKey(object); ok {
+		idx.itemsBySourceID[sourceIDKey] = object
+	}
+	for _, secondary := range idx.secondaryIndexes {
+		if secondaryKey, ok := secondary.key(object); ok {
+			// Objects without ids can be upserted again under the same key
+			items := slices.DeleteFunc(secondary.items[secondaryKey], func(item T) bool {
+				return any(item) == any(object)
+			})
+			secondary.items[secondaryKey] = append(items, object)
+		}
+	}
+}
+
+// remove removes all entries of the object, that still point to it.
+func (idx *Index[K, T]) remove(object T) {
+	id := object.GetID()
+	if key, ok := idx.keys[id]; ok && any(idx.items[key]) == any(object) {
+		delete(idx.items, key)
+	}
+	if any(idx.itemsByID[id]) == any(object) {
+		delete(idx.itemsByID, id)
+		delete(idx.keys, id)
+	}
+	if sourceIDKey, ok := sourceIDKey(object); ok && any(idx.itemsBySourceID[sourceIDKey]) == any(object) {
+		delete(idx.itemsBySourceID, sourceIDKey)
+	}
+	for _, secondary := range idx.secondaryIndexes {
+		secondaryKey, ok := secondary.key(object)
+		if !ok {
+			continue
+		}
+		remaining := slices.DeleteFunc(secondary.items[secondaryKey], func(item T) bool {
+			return any(item) == any(object)
+		})
+		if len(remaining) == 0 {
+			delete(secondary.items, secondaryKey)
+		} else {
+			secondary.items[secondaryKey] = remaining
+		}
+	}
+}
+
+// Register adds the object collected from Netbox to the index, and to the
+// orphan manager of the index. The index doesn't have to be locked, because
+// objects are registered only while the inventory is initialized.
+func (idx *Index[K, T]) Register(object T) {
+	idx.RegisterKey(idx.key(object), object)
+}
+
+// RegisterKey is Register with the given key, see UpsertKey.
+func (idx *Index[K, T]) RegisterKey(key K, object T) {
+	idx.UpsertKey(key, object)
+	if idx.orphanManager != nil {
+		idx.orphanManager.AddItem(object)
+	}
+}
+
+// AddPending marks the object with the key as being created, while the
+// index is unlocked (e.g. while the object waits for its bulk create
+// request). Returned function must be called with the index locked, once
+// the object is created or failed to be created. The index must be locked.
+func (idx *Index[K, T]) AddPending(key K, object T) func() {
+	done := make(chan struct{})
+	idx.pending[key] = pendingObject[T]{object: object, done: done}
+	return func() {
+		delete(idx.pending, key)
+		close(done)
+	}
+}
+
+// LookupPending returns a channel, that is closed once the pending object
+// (see AddPending) with the key, or with the same source id or secondary
+// key as the given object, is created. The index must be locked.
+func (idx *Index[K, T]) LookupPending(key K, object T) (<-chan struct{}, bool) {
+	if pending, ok := idx.pending[key]; ok {
+		return pending.done, true
+	}
+	objectSourceID, hasSourceID := sourceIDKey(object)
+	for _, pending := range idx.pending {
+		if pendingSourceID, ok := sourceIDKey(pending.object); hasSourceID && ok && pendingSourceID == objectSourceID {
+			return pending.done, true
+		}
+		for _, secondary := range idx.secondaryIndexes {
+			objectKey, ok := secondary.key(object)
+			if pendingKey, pendingOk := secondary.key(pending.object); ok && pendingOk && pendingKey == objectKey {
+				return pending.done, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// pendingObject is an object, that is being created (see AddPending).
+type pendingObject[T any] struct {
+	object T
+	// done is closed once the object is created or failed to be created.
+	done chan struct{}
+}
+
+// secondaryIndex is an index of objects by an additional key
+// (e.g. serial numbers of devices), see AddSecondaryIndex.
+type secondaryIndex[T any] struct {
+	name string
+	key  func(T) (string, bool)
+	// items are all objects with the same key, because secondary keys
+	// aren't unique in Netbox.
+	items map[string][]T
+}
+
+// ambiguousMatch are objects, that have the same key in the secondary
+// index, and therefore can't be matched by it (see LookupSecondary).
+type ambiguousMatch[T any] struct {
+	index   string
+	objects []T
+}
+
+// sourceIDIndexKey is the key of objects with the source_id custom field.
+// Source ids are stable ids of objects on the source API (e.g. VMware
+// MoRefs), and are unique only within a source.
+type sourceIDIndexKey struct {
+	Source   string
+	SourceID string
+}
+
+// sourceIDKey returns the source id key of the object, and false
+// if the object has no source or source id.
+func sourceIDKey(object objects.OrphanItem) (sourceIDIndexKey, bool) {
+	netboxObject := object.GetNetboxObject()
+	if netboxObject == nil {
+		return sourceIDIndexKey{}, false
+	}
+	source, _ := netboxObject.GetCustomField(constants.CustomFieldSourceName).(string)
+	sourceID := netboxObject.GetCustomField(constants.CustomFieldSourceIDName)
+	if source == "" || sourceID == nil || sourceID == "" {
+		return sourceIDIndexKey{}, false
+	}
+	return sourceIDIndexKey{Source: source, SourceID: fmt.Sprint(sourceID)}, true
+}
+
+func (idx *Index[K, T]) String() string {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return fmt.Sprint(idx.items)
+}
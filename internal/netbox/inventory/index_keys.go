@@ -0,0 +1,236 @@
+package inventory
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/parser"
+)
+
+// Key functions of the inventory indexes. Objects with the same key are
+// treated as the same object, so e.g. a device from a source is patched
+// instead of created, when a device with the same name already exists
+// on the same site.
+
+func tagKey(tag *objects.Tag) string                            { return tag.Name }
+func customFieldKey(customField *objects.CustomField) string    { return customField.Name }
+func contactGroupKey(contactGroup *objects.ContactGroup) string { return contactGroup.Name }
+func contactRoleKey(contactRole *objects.ContactRole) string    { return contactRole.Name }
+func contactKey(contact *objects.Contact) string                { return contact.Name }
+func tenantKey(tenant *objects.Tenant) string                   { return tenant.Name }
+func siteKey(site *objects.Site) string                         { return site.Name }
+func siteGroupKey(siteGroup *objects.SiteGroup) string          { return siteGroup.Name }
+func manufacturerKey(manufacturer *objects.Manufacturer) string { return manufacturer.Name }
+func platformKey(platform *objects.Platform) string             { return platform.Name }
+func deviceTypeKey(deviceType *objects.DeviceType) string       { return deviceType.Model }
+func deviceRoleKey(deviceRole *objects.DeviceRole) string       { return deviceRole.Name }
+func prefixKey(prefix *objects.Prefix) string                   { return prefix.Prefix }
+func vlanGroupKey(vlanGroup *objects.VlanGroup) string          { return vlanGroup.Name }
+func clusterGroupKey(clusterGroup *objects.ClusterGroup) string { return clusterGroup.Name }
+func clusterTypeKey(clusterType *objects.ClusterType) string    { return clusterType.Name }
+func clusterKey(cluster *objects.Cluster) string                { return cluster.Name }
+func wirelessLANKey(wirelessLAN *objects.WirelessLAN) string    { return wirelessLAN.SSID }
+
+func wirelessLANGroupKey(wirelessLANGroup *objects.WirelessLANGroup) string {
+	return wirelessLANGroup.Name
+}
+
+// contactAssignmentIndexKey is the key of contact assignments, which are
+// unique by the assigned object, contact and role.
+type contactAssignmentIndexKey struct {
+	ModelType constants.ContentType
+	ObjectID  int
+	ContactID int
+	RoleID    int
+}
+
+func contactAssignmentKey(contactAssignment *objects.ContactAssignment) contactAssignmentIndexKey {
+	return contactAssignmentIndexKey{
+		ModelType: contactAssignment.ModelType,
+		ObjectID:  contactAssignment.ObjectID,
+		ContactID: contactAssignment.Contact.ID,
+		RoleID:    contactAssignment.Role.ID,
+	}
+}
+
+// deviceIndexKey is the key of devices, which are unique by name within a site.
+type deviceIndexKey struct {
+	Name   string
+	SiteID int
+}
+
+func deviceKey(device *objects.Device) deviceIndexKey {
+	return deviceIndexKey{Name: device.Name, SiteID: device.Site.ID}
+}
+
+// deviceChildIndexKey is the key of objects that belong to a device
+// (interfaces and virtual device contexts), which are unique by name
+// within a device.
+type deviceChildIndexKey struct {
+	DeviceID int
+	Name     string
+}
+
+func interfaceKey(iface *objects.Interface) deviceChildIndexKey {
+	return deviceChildIndexKey{DeviceID: iface.Device.ID, Name: iface.Name}
+}
+
+func virtualDeviceContextKey(vdc *objects.VirtualDeviceContext) deviceChildIndexKey {
+	return deviceChildIndexKey{DeviceID: vdc.Device.ID, Name: vdc.Name}
+}
+
+// vlanIndexKey is the key of vlans, which are unique by vid within a vlan group.
+type vlanIndexKey struct {
+	GroupID int
+	Vid     int
+}
+
+func vlanKey(vlan *objects.Vlan) vlanIndexKey {
+	return vlanIndexKey{GroupID: vlan.Group.ID, Vid: vlan.Vid}
+}
+
+// vmIndexKey is the key of vms, which are unique by name within a cluster.
+// VMs without cluster have cluster id -1.
+type vmIndexKey struct {
+	Name      string
+	ClusterID int
+}
+
+func vmKey(vm *objects.VM) vmIndexKey {
+	clusterID := -1
+	if vm.Cluster != nil {
+		clusterID = vm.Cluster.ID
+	}
+	return vmIndexKey{Name: vm.Name, ClusterID: clusterID}
+}
+
+// vmChildIndexKey is the key of objects that belong to a vm
+// (vm interfaces and virtual disks), which are unique by name within a vm.
+type vmChildIndexKey struct {
+	VMID int
+	Name string
+}
+
+func vmInterfaceKey(vmIface *objects.VMInterface) vmChildIndexKey {
+	return vmChildIndexKey{VMID: vmIface.VM.ID, Name: vmIface.Name}
+}
+
+func virtualDiskKey(virtualDisk *objects.VirtualDisk) vmChildIndexKey {
+	return vmChildIndexKey{VMID: virtualDisk.VM.ID, Name: virtualDisk.Name}
+}
+
+// addressIndexKey is the key of ip and mac addresses. Addresses are
+// indexed by names of the assigned interfaces and their devices or vms,
+// so addresses follow interfaces that are recreated (e.g. when a vm is
+// moved to another cluster). See ipAddressKey and macAddressKey.
+type addressIndexKey struct {
+	// IfaceType is the type of the parent of the assigned interface
+	// (device or vm).
+	IfaceType       constants.ContentType
+	IfaceName       string
+	IfaceParentName string
+	Address         string
+}
+
+// ipAddressKey returns the key of the ip address. Assigned interfaces
+// are looked up in interface indexes, so it must not be called while
+// these are locked.
+func (nbi *NetboxInventory) ipAddressKey(ipAddress *objects.IPAddress) (addressIndexKey, error) {
+	ifaceType, ifaceName, ifaceParentName, err := nbi.getIndexValuesForIPAddress(ipAddress)
+	if err != nil {
+		return addressIndexKey{}, err
+	}
+	return addressIndexKey{
+		IfaceType:       ifaceType,
+		IfaceName:       ifaceName,
+		IfaceParentName: ifaceParentName,
+		Address:         ipAddress.Address,
+	}, nil
+}
+
+// macAddressKey returns the key of the mac address. Like ipAddressKey,
+// it must not be called while interface indexes are locked.
+func (nbi *NetboxInventory) macAddressKey(macAddress *objects.MACAddress) (addressIndexKey, error) {
+	ifaceType, ifaceName, ifaceParentName, err := nbi.getIndexValuesForMACAddress(macAddress)
+	if err != nil {
+		return addressIndexKey{}, err
+	}
+	return addressIndexKey{
+		IfaceType:       ifaceType,
+		IfaceName:       ifaceName,
+		IfaceParentName: ifaceParentName,
+		Address:         macAddress.MAC,
+	}, nil
+}
+
+// addDeviceSecondaryIndexes adds secondary indexes of devices, configured in
+// netbox.deviceMatching. Devices, that are reported by multiple sources under
+// different names (e.g. fqdn and short name), are matched by these indexes,
+// when no device with the same name exists on the same site.
+func addDeviceSecondaryIndexes(devices *Index[deviceIndexKey, *objects.Device], config parser.DeviceMatchingConfig) {
+	if len(config.NameNormalization) > 0 {
+		normalize := deviceNameNormalizer(config.NameNormalization)
+		devices.AddSecondaryIndex("normalized name", func(device *objects.Device) (string, bool) {
+			name := normalize(device.Name)
+			if name == "" || device.Site == nil {
+				return "", false
+			}
+			return fmt.Sprintf("%d/%s", device.Site.ID, name), true
+		})
+	}
+	if config.SerialNumber {
+		ignoredSerialNumbers := make(map[string]bool, len(config.IgnoredSerialNumbers))
+		for _, serialNumber := range config.IgnoredSerialNumbers {
+			ignoredSerialNumbers[strings.ToUpper(strings.TrimSpace(serialNumber))] = true
+		}
+		devices.AddSecondaryIndex("serial number", func(device *objects.Device) (string, bool) {
+			serialNumber := strings.ToUpper(strings.TrimSpace(device.SerialNumber))
+			// Placeholders and serial numbers of zeros would match unrelated devices
+			if strings.Trim(serialNumber, "0") == "" ||
+				constants.PlaceholderSerialNumbers[serialNumber] || ignoredSerialNumbers[serialNumber] {
+				return "", false
+			}
+			return serialNumber, true
+		})
+	}
+	if config.UUID {
+		devices.AddSecondaryIndex("uuid", func(device *objects.Device) (string, bool) {
+			uuid, _ := device.GetCustomField(constants.CustomFieldDeviceUUIDName).(string)
+			uuid = strings.ToLower(strings.TrimSpace(uuid))
+			return uuid, uuid != ""
+		})
+	}
+}
+
+// deviceNameNormalizer returns a function, that applies the name
+// normalization rules to device names in order. Patterns of the rules
+// are already validated by the parser.
+func deviceNameNormalizer(rules []parser.NameNormalizationRule) func(string) string {
+	type compiledRule struct {
+		pattern     *regexp.Regexp
+		replacement string
+		lowercase   bool
+	}
+	compiledRules := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		compiled := compiledRule{replacement: rule.Replacement, lowercase: rule.Lowercase}
+		if rule.Pattern != "" {
+			compiled.pattern = regexp.MustCompile(rule.Pattern)
+		}
+		compiledRules = append(compiledRules, compiled)
+	}
+	return func(name string) string {
+		for _, rule := range compiledRules {
+			if rule.pattern != nil {
+				name = rule.pattern.ReplaceAllString(name, rule.replacement)
+			}
+			if rule.lowercase {
+				name = strings.ToLower(name)
+			}
+		}
+		return name
+	}
+}
@@ -0,0 +1,398 @@
+package inventory
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/parser"
+)
+
+func TestIndex_Upsert(t *testing.T) {
+	device := &objects.Device{NetboxObject: objects.NetboxObject{ID: 1}}
+	queuedIface := &objects.Interface{
+		NetboxObject: objects.NetboxObject{ID: -1},
+		Name:         "eth0",
+		Device:       device,
+	}
+	createdIface := &objects.Interface{
+		NetboxObject: objects.NetboxObject{ID: 10},
+		Name:         "eth0",
+		Device:       device,
+	}
+	otherIface := &objects.Interface{
+		NetboxObject: objects.NetboxObject{ID: 11},
+		Name:         "eth1",
+		Device:       device,
+	}
+	tests := []struct {
+		name        string
+		upserts     []*objects.Interface
+		wantByKey   map[deviceChildIndexKey]*objects.Interface
+		wantByID    map[int]*objects.Interface
+		wantMissing []int
+	}{
+		{
+			name:    "Upsert new interfaces",
+			upserts: []*objects.Interface{createdIface, otherIface},
+			wantByKey: map[deviceChildIndexKey]*objects.Interface{
+				{DeviceID: 1, Name: "eth0"}: createdIface,
+				{DeviceID: 1, Name: "eth1"}: otherIface,
+			},
+			wantByID: map[int]*objects.Interface{10: createdIface, 11: otherIface},
+		},
+		{
+			name:    "Created interface replaces queued interface",
+			upserts: []*objects.Interface{queuedIface, createdIface},
+			wantByKey: map[deviceChildIndexKey]*objects.Interface{
+				{DeviceID: 1, Name: "eth0"}: createdIface,
+			},
+			wantByID:    map[int]*objects.Interface{10: createdIface},
+			wantMissing: []int{-1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := NewIndex(interfaceKey, nil)
+			index.Lock()
+			defer index.Unlock()
+			for _, iface := range tt.upserts {
+				index.Upsert(iface)
+			}
+			for key, want := range tt.wantByKey {
+				if got, ok := index.Lookup(key); !ok || got != want {
+					t.Errorf("Lookup(%v) = %v, want %v", key, got, want)
+				}
+			}
+			for id, want := range tt.wantByID {
+				if got, ok := index.LookupByID(id); !ok || got != want {
+					t.Errorf("LookupByID(%d) = %v, want %v", id, got, want)
+				}
+			}
+			for _, id := range tt.wantMissing {
+				if got, ok := index.LookupByID(id); ok {
+					t.Errorf("LookupByID(%d) = %v, want no interface", id, got)
+				}
+			}
+		})
+	}
+}
+
+func TestIndex_LookupPending(t *testing.T) {
+	sourceFields := map[string]interface{}{
+		constants.CustomFieldSourceName:   "vcenter",
+		constants.CustomFieldSourceIDName: "vm-10",
+	}
+	pendingVM := &objects.VM{
+		NetboxObject: objects.NetboxObject{CustomFields: sourceFields},
+		Name:         "vm1",
+	}
+	tests := []struct {
+		name        string
+		vm          *objects.VM
+		wantPending bool
+	}{
+		{
+			name:        "Vm with the same key",
+			vm:          &objects.VM{Name: "vm1"},
+			wantPending: true,
+		},
+		{
+			name: "Renamed vm with the same source id",
+			vm: &objects.VM{
+				NetboxObject: objects.NetboxObject{CustomFields: sourceFields},
+				Name:         "vm1-renamed",
+			},
+			wantPending: true,
+		},
+		{
+			name: "Other vm",
+			vm:   &objects.VM{Name: "vm2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := NewIndex(vmKey, nil)
+			index.Lock()
+			defer index.Unlock()
+			done := index.AddPending(vmKey(pendingVM), pendingVM)
+			pending, ok := index.LookupPending(vmKey(tt.vm), tt.vm)
+			if ok != tt.wantPending {
+				t.Fatalf("LookupPending() found = %t, want %t", ok, tt.wantPending)
+			}
+			done()
+			if !tt.wantPending {
+				return
+			}
+			select {
+			case <-pending:
+			default:
+				t.Errorf("LookupPending() channel is not closed once the vm is created")
+			}
+			if _, ok := index.LookupPending(vmKey(tt.vm), tt.vm); ok {
+				t.Errorf("LookupPending() found created vm")
+			}
+		})
+	}
+}
+
+func TestIndex_LookupBySourceID(t *testing.T) {
+	sourceFields := func(source string, sourceID interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			constants.CustomFieldSourceName:   source,
+			constants.CustomFieldSourceIDName: sourceID,
+		}
+	}
+	existingVM := &objects.VM{
+		NetboxObject: objects.NetboxObject{ID: 1, CustomFields: sourceFields("vcenter", "vm-10")},
+		Name:         "vm1",
+	}
+	tests := []struct {
+		name    string
+		vm      *objects.VM
+		want    *objects.VM
+		wantKey vmIndexKey
+	}{
+		{
+			name: "Renamed vm",
+			vm: &objects.VM{
+				NetboxObject: objects.NetboxObject{CustomFields: sourceFields("vcenter", "vm-10")},
+				Name:         "vm1-renamed",
+			},
+			want:    existingVM,
+			wantKey: vmIndexKey{Name: "vm1-renamed", ClusterID: -1},
+		},
+		{
+			name: "Vm with the same source id from another source",
+			vm: &objects.VM{
+				NetboxObject: objects.NetboxObject{CustomFields: sourceFields("ovirt", "vm-10")},
+				Name:         "vm1",
+			},
+		},
+		{
+			name: "Vm without source id",
+			vm:   &objects.VM{Name: "vm1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := NewIndex(vmKey, nil)
+			index.Lock()
+			defer index.Unlock()
+			index.Upsert(existingVM)
+			got, ok := index.LookupBySourceID(tt.vm)
+			if tt.want == nil {
+				if ok {
+					t.Errorf("LookupBySourceID() = %v, want no vm", got)
+				}
+				return
+			}
+			if !ok || got != tt.want {
+				t.Errorf("LookupBySourceID() = %v, want %v", got, tt.want)
+			}
+			renamedVM := &objects.VM{NetboxObject: got.NetboxObject, Name: tt.vm.Name}
+			index.Upsert(renamedVM)
+			if got, ok := index.Lookup(tt.wantKey); !ok || got != renamedVM {
+				t.Errorf("Lookup(%v) after rename = %v, want %v", tt.wantKey, got, renamedVM)
+			}
+			if got := len(index.items); got != 1 {
+				t.Errorf("index has %d vms after rename, want 1", got)
+			}
+		})
+	}
+}
+
+func TestIndex_Register(t *testing.T) {
+	tests := []struct {
+		name          string
+		orphanManager *OrphanManager
+		wantOrphans   int
+	}{
+		{
+			name:          "Register with orphan manager",
+			orphanManager: NewOrphanManager(nil),
+			wantOrphans:   1,
+		},
+		{
+			name: "Register without orphan manager",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := NewIndex(deviceRoleKey, tt.orphanManager)
+			deviceRole := &objects.DeviceRole{
+				NetboxObject: objects.NetboxObject{
+					ID:   1,
+					Tags: []*objects.Tag{{Name: constants.SsotTagName}},
+				},
+				Name: "Server",
+			}
+			index.Register(deviceRole)
+			if got, ok := index.Lookup("Server"); !ok || got != deviceRole {
+				t.Errorf("Lookup() = %v, want %v", got, deviceRole)
+			}
+			if tt.orphanManager == nil {
+				return
+			}
+			if got := len(tt.orphanManager.Items[constants.DeviceRolesAPIPath]); got != tt.wantOrphans {
+				t.Errorf("orphan manager has %d device roles, want %d", got, tt.wantOrphans)
+			}
+		})
+	}
+}
+
+func TestAddDeviceSecondaryIndexes(t *testing.T) {
+	site := &objects.Site{NetboxObject: objects.NetboxObject{ID: 1}}
+	otherSite := &objects.Site{NetboxObject: objects.NetboxObject{ID: 2}}
+	existingDevice := &objects.Device{
+		NetboxObject: objects.NetboxObject{
+			ID: 1,
+			CustomFields: map[string]interface{}{
+				constants.CustomFieldDeviceUUIDName: "4C4C4544-0042-3510-8051-B4C04F4D4E32",
+			},
+		},
+		Name:         "ESX1.example.com",
+		Site:         site,
+		SerialNumber: "abc123",
+	}
+	config := parser.DeviceMatchingConfig{
+		SerialNumber: true,
+		UUID:         true,
+		NameNormalization: []parser.NameNormalizationRule{
+			{Pattern: `\.example\.com$`},
+			{Lowercase: true},
+		},
+	}
+	placeholderDevice := &objects.Device{
+		NetboxObject: objects.NetboxObject{ID: 2},
+		Name:         "esx2",
+		Site:         otherSite,
+		SerialNumber: "Not Specified",
+	}
+	duplicateDevices := []*objects.Device{
+		{NetboxObject: objects.NetboxObject{ID: 3}, Name: "sw1", Site: site, SerialNumber: "DUP1"},
+		{NetboxObject: objects.NetboxObject{ID: 4}, Name: "sw2", Site: site, SerialNumber: "dup1"},
+	}
+	tests := []struct {
+		name          string
+		config        parser.DeviceMatchingConfig
+		device        *objects.Device
+		wantFound     bool
+		wantIndex     string
+		wantAmbiguous []string
+	}{
+		{
+			name:      "Short name on the same site",
+			config:    config,
+			device:    &objects.Device{Name: "esx1", Site: site},
+			wantFound: true,
+			wantIndex: "normalized name",
+		},
+		{
+			name:      "Serial number with different case",
+			config:    config,
+			device:    &objects.Device{Name: "fw1", Site: otherSite, SerialNumber: " ABC123 "},
+			wantFound: true,
+			wantIndex: "serial number",
+		},
+		{
+			name:   "Uuid",
+			config: config,
+			device: &objects.Device{
+				NetboxObject: objects.NetboxObject{
+					CustomFields: map[string]interface{}{
+						constants.CustomFieldDeviceUUIDName: "4c4c4544-0042-3510-8051-b4c04f4d4e32",
+					},
+				},
+				Name: "fw1",
+				Site: otherSite,
+			},
+			wantFound: true,
+			wantIndex: "uuid",
+		},
+		{
+			name:   "Short name on another site",
+			config: config,
+			device: &objects.Device{Name: "esx1", Site: otherSite},
+		},
+		{
+			name:   "Matching disabled",
+			device: &objects.Device{Name: "esx1", Site: site, SerialNumber: "abc123"},
+		},
+		{
+			name:   "Placeholder serial number",
+			config: config,
+			device: &objects.Device{Name: "fw1", Site: site, SerialNumber: "NOT SPECIFIED"},
+		},
+		{
+			name:   "Serial number of zeros",
+			config: config,
+			device: &objects.Device{Name: "fw1", Site: site, SerialNumber: "0000"},
+		},
+		{
+			name: "Ignored serial number",
+			config: parser.DeviceMatchingConfig{
+				SerialNumber:         true,
+				IgnoredSerialNumbers: []string{"ABC123"},
+			},
+			device: &objects.Device{Name: "fw1", Site: site, SerialNumber: "abc123"},
+		},
+		{
+			name:          "Serial number of multiple devices",
+			config:        config,
+			device:        &objects.Device{Name: "fw1", Site: otherSite, SerialNumber: "Dup1"},
+			wantAmbiguous: []string{"serial number"},
+		},
+		{
+			name:   "Serial number of multiple devices and uuid",
+			config: config,
+			device: &objects.Device{
+				NetboxObject: objects.NetboxObject{
+					CustomFields: map[string]interface{}{
+						constants.CustomFieldDeviceUUIDName: "4C4C4544-0042-3510-8051-B4C04F4D4E32",
+					},
+				},
+				Name:         "fw1",
+				Site:         otherSite,
+				SerialNumber: "dup1",
+			},
+			wantFound:     true,
+			wantIndex:     "uuid",
+			wantAmbiguous: []string{"serial number"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := NewIndex(deviceKey, nil)
+			addDeviceSecondaryIndexes(index, tt.config)
+			index.Lock()
+			defer index.Unlock()
+			index.Upsert(existingDevice)
+			index.Upsert(placeholderDevice)
+			for _, device := range duplicateDevices {
+				index.Upsert(device)
+			}
+			got, indexName, ok, ambiguous := index.LookupSecondary(tt.device)
+			ambiguousIndexes := make([]string, 0, len(ambiguous))
+			for _, match := range ambiguous {
+				ambiguousIndexes = append(ambiguousIndexes, match.index)
+				if len(match.objects) != len(duplicateDevices) {
+					t.Errorf("LookupSecondary() ambiguous objects = %v, want %v", match.objects, duplicateDevices)
+				}
+			}
+			if !slices.Equal(ambiguousIndexes, tt.wantAmbiguous) {
+				t.Errorf("LookupSecondary() ambiguous = %v, want %v", ambiguousIndexes, tt.wantAmbiguous)
+			}
+			if ok != tt.wantFound {
+				t.Fatalf("LookupSecondary() found = %t, want %t", ok, tt.wantFound)
+			}
+			if !tt.wantFound {
+				return
+			}
+			if got != existingDevice || indexName != tt.wantIndex {
+				t.Errorf("LookupSecondary() = %v, %s, want %v, %s", got, indexName, existingDevice, tt.wantIndex)
+			}
+		})
+	}
+}
@@ -3,30 +3,67 @@ package inventory
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"time"
 
 	"github.com/src-doo/netbox-ssot/internal/constants"
 	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
-	"github.com/src-doo/netbox-ssot/internal/netbox/service"
+	"github.com/src-doo/netbox-ssot/internal/netbox/version"
 	"github.com/src-doo/netbox-ssot/internal/utils"
 )
 
+// refreshFilter returns query filter, that limits collected objects to the ones
+// updated since the last Init or Refresh. When inventory is initialized from
+// scratch, the filter is empty.
+func (nbi *NetboxInventory) refreshFilter() string {
+	return updatedSinceFilter(nbi.refreshSince)
+}
+
+// updatedSinceFilter returns query filter, that limits collected objects to
+// the ones updated since the given time. For zero time the filter is empty.
+func updatedSinceFilter(since time.Time) string {
+	if since.IsZero() {
+		return ""
+	}
+	return "&last_updated__gte=" + url.QueryEscape(since.UTC().Format(time.RFC3339))
+}
+
+// initIndex collects all objects of type T from Netbox API,
+// and registers them in the index.
+func initIndex[T any, K comparable, PT interface {
+	*T
+	objects.OrphanItem
+}](ctx context.Context, nbi *NetboxInventory, index *Index[K, PT]) error {
+	var dummy PT
+	extraArgs := fmt.Sprintf(
+		"&fields=%s",
+		nbi.Capabilities.QueryFields(dummy.GetAPIPath(), utils.ExtractJSONTagsFromStructIntoString(*new(T))),
+	)
+	nbObjects, err := getAll[T](ctx, nbi, extraArgs)
+	if err != nil {
+		return err
+	}
+	for i := range nbObjects {
+		index.Register(&nbObjects[i])
+	}
+	nbi.Logger.Debugf(ctx, "Successfully collected %s from Netbox: %s", dummy.GetAPIPath(), index)
+	return nil
+}
+
 // Collect all tags from Netbox API and store them in the NetBoxInventory.
 func (nbi *NetboxInventory) initTags(ctx context.Context) error {
-	extraArgs := fmt.Sprintf("&fields=%s", utils.ExtractJSONTagsFromStructIntoString(objects.Tag{}))
-	nbTags, err := service.GetAll[objects.Tag](
-		ctx,
-		nbi.NetboxAPI,
-		extraArgs,
+	extraArgs := fmt.Sprintf(
+		"&fields=%s",
+		utils.ExtractJSONTagsFromStructIntoString(objects.Tag{}),
 	)
+	nbTags, err := getAll[objects.Tag](ctx, nbi, extraArgs)
 	if err != nil {
 		return err
 	}
-	nbi.tagsIndexByName = make(map[string]*objects.Tag)
 	for i := range nbTags {
-		tag := nbTags[i]
-		nbi.tagsIndexByName[tag.Name] = &tag
+		nbi.tags.Register(&nbTags[i])
 	}
-	nbi.Logger.Debug(ctx, "Successfully collected tags from Netbox: ", nbi.tagsIndexByName)
+	nbi.Logger.Debug(ctx, "Successfully collected tags from Netbox: ", nbi.tags)
 
 	// Create default tag for netbox-ssot microservice
 	ssotTag, err := nbi.AddTag(
@@ -63,109 +100,21 @@ func (nbi *NetboxInventory) initTags(ctx context.Context) error {
 
 // Collects all tenants from Netbox API and store them in the NetBoxInventory.
 func (nbi *NetboxInventory) initTenants(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.Tenant{}),
-	)
-	nbTenants, err := service.GetAll[objects.Tenant](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-	// We also create an index of tenants by name for easier access
-	nbi.tenantsIndexByName = make(map[string]*objects.Tenant)
-	for i := range nbTenants {
-		tenant := &nbTenants[i]
-		nbi.tenantsIndexByName[tenant.Name] = tenant
-	}
-	nbi.Logger.Debug(ctx, "Successfully collected tenants from Netbox: ", nbi.tenantsIndexByName)
-	return nil
+	return initIndex[objects.Tenant](ctx, nbi, nbi.tenants)
 }
 
 // Collects all contacts from Netbox API and store them in the NetBoxInventory.
 func (nbi *NetboxInventory) initContacts(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.Contact{}),
-	)
-	nbContacts, err := service.GetAll[objects.Contact](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-	// We also create an index of contacts by name for easier access
-	nbi.contactsIndexByName = make(map[string]*objects.Contact)
-	for i := range nbContacts {
-		contact := &nbContacts[i]
-		nbi.contactsIndexByName[contact.Name] = contact
-		nbi.OrphanManager.AddItem(contact)
-	}
-	nbi.Logger.Debug(ctx, "Successfully collected contacts from Netbox: ", nbi.contactsIndexByName)
-	return nil
+	return initIndex[objects.Contact](ctx, nbi, nbi.contacts)
 }
 
 // Collects all contact roles from Netbox API and store them in the NetBoxInventory.
 func (nbi *NetboxInventory) initContactRoles(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.ContactRole{}),
-	)
-	nbContactRoles, err := service.GetAll[objects.ContactRole](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-	// We also create an index of contact roles by name for easier access
-	nbi.contactRolesIndexByName = make(map[string]*objects.ContactRole)
-	for i := range nbContactRoles {
-		contactRole := &nbContactRoles[i]
-		nbi.contactRolesIndexByName[contactRole.Name] = contactRole
-	}
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected ContactRoles from Netbox: ",
-		nbi.contactRolesIndexByName,
-	)
-	return nil
+	return initIndex[objects.ContactRole](ctx, nbi, nbi.contactRoles)
 }
 
 func (nbi *NetboxInventory) initContactAssignments(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.ContactAssignment{}),
-	)
-	nbCAs, err := service.GetAll[objects.ContactAssignment](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-	// We also create an index of contacts by name for easier access
-	nbi.contactAssignmentsIndex = make(
-		map[constants.ContentType]map[int]map[int]map[int]*objects.ContactAssignment,
-	)
-	debugIDs := map[int]bool{} // Netbox pagination bug duplicates
-	for i := range nbCAs {
-		cA := &nbCAs[i]
-		if _, ok := debugIDs[cA.ID]; ok {
-			fmt.Printf("Already been here: %d", cA.ID)
-		}
-		debugIDs[cA.ID] = true
-		if nbi.contactAssignmentsIndex[cA.ModelType] == nil {
-			nbi.contactAssignmentsIndex[cA.ModelType] = make(
-				map[int]map[int]map[int]*objects.ContactAssignment,
-			)
-		}
-		if nbi.contactAssignmentsIndex[cA.ModelType][cA.ObjectID] == nil {
-			nbi.contactAssignmentsIndex[cA.ModelType][cA.ObjectID] = make(
-				map[int]map[int]*objects.ContactAssignment,
-			)
-		}
-		if nbi.contactAssignmentsIndex[cA.ModelType][cA.ObjectID][cA.Contact.ID] == nil {
-			nbi.contactAssignmentsIndex[cA.ModelType][cA.ObjectID][cA.Contact.ID] = make(
-				map[int]*objects.ContactAssignment,
-			)
-		}
-		nbi.contactAssignmentsIndex[cA.ModelType][cA.ObjectID][cA.Contact.ID][cA.Role.ID] = cA
-		nbi.OrphanManager.AddItem(cA)
-	}
-	nbi.Logger.Debug(ctx, "Successfully collected contacts from Netbox: ", nbi.contactsIndexByName)
-	return nil
+	return initIndex[objects.ContactAssignment](ctx, nbi, nbi.contactAssignments)
 }
 
 // Initializes default admin contact role used for adding admin contacts of vms.
@@ -185,70 +134,17 @@ func (nbi *NetboxInventory) initAdminContactRole(ctx context.Context) error {
 
 // Collects all contact groups from Netbox API and store them in the NetBoxInventory.
 func (nbi *NetboxInventory) initContactGroups(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.ContactGroup{}),
-	)
-	nbContactGroups, err := service.GetAll[objects.ContactGroup](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-	// We also create an index of contact groups by name for easier access
-	nbi.contactGroupsIndexByName = make(map[string]*objects.ContactGroup)
-	for i := range nbContactGroups {
-		contactGroup := &nbContactGroups[i]
-		nbi.contactGroupsIndexByName[contactGroup.Name] = contactGroup
-	}
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected ContactGroups from Netbox: ",
-		nbi.contactGroupsIndexByName,
-	)
-	return nil
+	return initIndex[objects.ContactGroup](ctx, nbi, nbi.contactGroups)
 }
 
 // Collects all sites from Netbox API and store them in the NetBoxInventory.
 func (nbi *NetboxInventory) initSites(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.Site{}),
-	)
-	nbSites, err := service.GetAll[objects.Site](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-	// We also create an index of sites by name for easier access
-	nbi.sitesIndexByName = make(map[string]*objects.Site)
-	for i := range nbSites {
-		site := &nbSites[i]
-		nbi.sitesIndexByName[site.Name] = site
-	}
-	nbi.Logger.Debug(ctx, "Successfully collected sites from Netbox: ", nbi.sitesIndexByName)
-	return nil
+	return initIndex[objects.Site](ctx, nbi, nbi.sites)
 }
 
 // Collects all sites from Netbox API and store them in the NetBoxInventory.
 func (nbi *NetboxInventory) initSiteGroups(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.SiteGroup{}),
-	)
-	nbSiteGroups, err := service.GetAll[objects.SiteGroup](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-	// We also create an index of sites by name for easier access
-	nbi.siteGroupsIndexByName = make(map[string]*objects.SiteGroup)
-	for i := range nbSiteGroups {
-		siteGroup := &nbSiteGroups[i]
-		nbi.siteGroupsIndexByName[siteGroup.Name] = siteGroup
-	}
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected SiteGroups from Netbox: ",
-		nbi.siteGroupsIndexByName,
-	)
-	return nil
+	return initIndex[objects.SiteGroup](ctx, nbi, nbi.siteGroups)
 }
 
 // initDefaultSite inits default site, which is used for hosts that have no corresponding site.
@@ -273,176 +169,32 @@ func (nbi *NetboxInventory) initDefaultSite(ctx context.Context) error {
 
 // Collects all manufacturers from Netbox API and store them in NetBoxInventory.
 func (nbi *NetboxInventory) initManufacturers(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.Manufacturer{}),
-	)
-	nbManufacturers, err := service.GetAll[objects.Manufacturer](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-	// Initialize internal index of manufacturers by name
-	nbi.manufacturersIndexByName = make(map[string]*objects.Manufacturer)
-	for i := range nbManufacturers {
-		manufacturer := &nbManufacturers[i]
-		nbi.manufacturersIndexByName[manufacturer.Name] = manufacturer
-		nbi.OrphanManager.AddItem(manufacturer)
-	}
-
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected manufacturers from Netbox: ",
-		nbi.manufacturersIndexByName,
-	)
-	return nil
+	return initIndex[objects.Manufacturer](ctx, nbi, nbi.manufacturers)
 }
 
 // Collects all platforms from Netbox API and store them in the NetBoxInventory.
 func (nbi *NetboxInventory) initPlatforms(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.Platform{}),
-	)
-	nbPlatforms, err := service.GetAll[objects.Platform](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-	// Initialize internal index of platforms by name
-	nbi.platformsIndexByName = make(map[string]*objects.Platform)
-
-	for i, platform := range nbPlatforms {
-		nbPlatform := &nbPlatforms[i]
-		nbi.platformsIndexByName[platform.Name] = nbPlatform
-		nbi.OrphanManager.AddItem(nbPlatform)
-	}
-
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected platforms from Netbox: ",
-		nbi.platformsIndexByName,
-	)
-	return nil
+	return initIndex[objects.Platform](ctx, nbi, nbi.platforms)
 }
 
 // Collect all devices from Netbox API and store them in the NetBoxInventory.
 func (nbi *NetboxInventory) initDevices(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.Device{}),
-	)
-	nbDevices, err := service.GetAll[objects.Device](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-	// Initialize main index of devices by Name and SiteId
-	nbi.devicesIndexByNameAndSiteID = make(map[string]map[int]*objects.Device)
-	// Initialize helper index of devices by ID
-	nbi.devicesIndexByID = make(map[int]*objects.Device)
-
-	for i, device := range nbDevices {
-		nbDevice := &nbDevices[i]
-		nbi.devicesIndexByID[device.ID] = nbDevice
-		if nbi.devicesIndexByNameAndSiteID[device.Name] == nil {
-			nbi.devicesIndexByNameAndSiteID[device.Name] = make(map[int]*objects.Device)
-		}
-		nbi.devicesIndexByNameAndSiteID[device.Name][device.Site.ID] = nbDevice
-		nbi.OrphanManager.AddItem(nbDevice)
-	}
-
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected devices from Netbox: ",
-		nbi.devicesIndexByNameAndSiteID,
-	)
-	return nil
+	return initIndex[objects.Device](ctx, nbi, nbi.devices)
 }
 
 // Collect all devices from Netbox API and store them in the NetBoxInventory.
 func (nbi *NetboxInventory) initVirtualDeviceContexts(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.VirtualDeviceContext{}),
-	)
-	nbVirtualDeviceContexts, err := service.GetAll[objects.VirtualDeviceContext](
-		ctx,
-		nbi.NetboxAPI,
-		extraArgs,
-	)
-	if err != nil {
-		return err
-	}
-	// Initialize internal index of devices by Name and SiteId
-	nbi.virtualDeviceContextsIndex = make(
-		map[string]map[int]*objects.VirtualDeviceContext,
-	)
-	for i, virtualDeviceContext := range nbVirtualDeviceContexts {
-		nbVirtualDeviceContext := &nbVirtualDeviceContexts[i]
-		if nbi.virtualDeviceContextsIndex[virtualDeviceContext.Name] == nil {
-			nbi.virtualDeviceContextsIndex[virtualDeviceContext.Name] = make(
-				map[int]*objects.VirtualDeviceContext,
-			)
-		}
-		nbi.virtualDeviceContextsIndex[virtualDeviceContext.Name][virtualDeviceContext.Device.ID] = nbVirtualDeviceContext
-		nbi.OrphanManager.AddItem(nbVirtualDeviceContext)
-	}
-
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected VirtualDeviceContexts from Netbox: ",
-		nbi.virtualDeviceContextsIndex,
-	)
-	return nil
+	return initIndex[objects.VirtualDeviceContext](ctx, nbi, nbi.virtualDeviceContexts)
 }
 
 // Collects all deviceRoles from Netbox API and store them in the
 // NetBoxInventory.
 func (nbi *NetboxInventory) initDeviceRoles(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.DeviceRole{}),
-	)
-	nbDeviceRoles, err := service.GetAll[objects.DeviceRole](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-	// We also create an index of device roles by name for easier access
-	nbi.deviceRolesIndexByName = make(map[string]*objects.DeviceRole)
-
-	for i := range nbDeviceRoles {
-		deviceRole := &nbDeviceRoles[i]
-		nbi.deviceRolesIndexByName[deviceRole.Name] = deviceRole
-		nbi.OrphanManager.AddItem(deviceRole)
-	}
-
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected device roles from Netbox: ",
-		nbi.deviceRolesIndexByName,
-	)
-	return nil
+	return initIndex[objects.DeviceRole](ctx, nbi, nbi.deviceRoles)
 }
 
 func (nbi *NetboxInventory) initCustomFields(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.CustomField{}),
-	)
-	customFields, err := service.GetAll[objects.CustomField](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-	// Initialize internal index of custom fields by name
-	nbi.customFieldsIndexByName = make(map[string]*objects.CustomField, len(customFields))
-	for i := range customFields {
-		customField := &customFields[i]
-		nbi.customFieldsIndexByName[customField.Name] = customField
-	}
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected custom fields from Netbox: ",
-		nbi.customFieldsIndexByName,
-	)
-	return nil
+	return initIndex[objects.CustomField](ctx, nbi, nbi.customFields)
 }
 
 // This function Initializes all custom fields required for servers and other objects
@@ -661,165 +413,31 @@ func (nbi *NetboxInventory) initSsotCustomFields(ctx context.Context) error {
 
 // Collects all nbClusters from Netbox API and stores them in the NetBoxInventory.
 func (nbi *NetboxInventory) initClusterGroups(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.ClusterGroup{}),
-	)
-	nbClusterGroups, err := service.GetAll[objects.ClusterGroup](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-	// Initialize internal index of cluster groups by name
-	nbi.clusterGroupsIndexByName = make(map[string]*objects.ClusterGroup)
-
-	for i := range nbClusterGroups {
-		clusterGroup := &nbClusterGroups[i]
-		nbi.clusterGroupsIndexByName[clusterGroup.Name] = clusterGroup
-		nbi.OrphanManager.AddItem(clusterGroup)
-	}
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected cluster groups from Netbox: ",
-		nbi.clusterGroupsIndexByName,
-	)
-	return nil
+	return initIndex[objects.ClusterGroup](ctx, nbi, nbi.clusterGroups)
 }
 
 // Collects all ClusterTypes from Netbox API and stores them in the NetBoxInventory.
 func (nbi *NetboxInventory) initClusterTypes(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.ClusterType{}),
-	)
-	nbClusterTypes, err := service.GetAll[objects.ClusterType](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-
-	// Initialize internal index of cluster types by name
-	nbi.clusterTypesIndexByName = make(map[string]*objects.ClusterType)
-	for i := range nbClusterTypes {
-		clusterType := &nbClusterTypes[i]
-		nbi.clusterTypesIndexByName[clusterType.Name] = clusterType
-		nbi.OrphanManager.AddItem(clusterType)
-	}
-
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected cluster types from Netbox: ",
-		nbi.clusterTypesIndexByName,
-	)
-	return nil
+	return initIndex[objects.ClusterType](ctx, nbi, nbi.clusterTypes)
 }
 
 // Collects all clusters from Netbox API and stores them to local inventory.
 func (nbi *NetboxInventory) initClusters(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.Cluster{}),
-	)
-	nbClusters, err := service.GetAll[objects.Cluster](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-
-	// Initialize internal index of clusters by name
-	nbi.clustersIndexByName = make(map[string]*objects.Cluster)
-
-	for i := range nbClusters {
-		cluster := &nbClusters[i]
-		nbi.clustersIndexByName[cluster.Name] = cluster
-		nbi.OrphanManager.AddItem(cluster)
-	}
-
-	nbi.Logger.Debug(ctx, "Successfully collected clusters from Netbox: ", nbi.clustersIndexByName)
-	return nil
+	return initIndex[objects.Cluster](ctx, nbi, nbi.clusters)
 }
 
 func (nbi *NetboxInventory) initDeviceTypes(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.DeviceType{}),
-	)
-	nbDeviceTypes, err := service.GetAll[objects.DeviceType](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-
-	// Initialize internal index of device types by model
-	nbi.deviceTypesIndexByModel = make(map[string]*objects.DeviceType)
-	for i := range nbDeviceTypes {
-		deviceType := &nbDeviceTypes[i]
-		nbi.deviceTypesIndexByModel[deviceType.Model] = deviceType
-		nbi.OrphanManager.AddItem(deviceType)
-	}
-
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected device types from Netbox: ",
-		nbi.deviceTypesIndexByModel,
-	)
-	return nil
+	return initIndex[objects.DeviceType](ctx, nbi, nbi.deviceTypes)
 }
 
 // Collects all interfaces from Netbox API and stores them to local inventory.
 func (nbi *NetboxInventory) initInterfaces(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.Interface{}),
-	)
-	nbInterfaces, err := service.GetAll[objects.Interface](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-
-	// Initialize main index of interfaces by device id and name
-	nbi.interfacesIndexByDeviceIDAndName = make(map[int]map[string]*objects.Interface)
-	// Initialize helper index for interfaces by ID
-	nbi.interfacesIndexByID = make(map[int]*objects.Interface)
-
-	for i := range nbInterfaces {
-		intf := &nbInterfaces[i]
-		nbi.interfacesIndexByID[intf.ID] = intf
-		if nbi.interfacesIndexByDeviceIDAndName[intf.Device.ID] == nil {
-			nbi.interfacesIndexByDeviceIDAndName[intf.Device.ID] = make(
-				map[string]*objects.Interface,
-			)
-		}
-		nbi.interfacesIndexByDeviceIDAndName[intf.Device.ID][intf.Name] = intf
-		nbi.OrphanManager.AddItem(intf)
-	}
-
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected interfaces from Netbox: ",
-		nbi.interfacesIndexByDeviceIDAndName,
-	)
-	return nil
+	return initIndex[objects.Interface](ctx, nbi, nbi.interfaces)
 }
 
 // Collects all vlans from Netbox API and stores them to local inventory.
 func (nbi *NetboxInventory) initVlanGroups(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.VlanGroup{}),
-	)
-	nbVlanGroups, err := service.GetAll[objects.VlanGroup](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-
-	// Initialize internal index of vlans by name
-	nbi.vlanGroupsIndexByName = make(map[string]*objects.VlanGroup)
-
-	for i := range nbVlanGroups {
-		vlanGroup := &nbVlanGroups[i]
-		nbi.vlanGroupsIndexByName[vlanGroup.Name] = vlanGroup
-		nbi.OrphanManager.AddItem(vlanGroup)
-	}
-
-	nbi.Logger.Debug(ctx, "Successfully collected vlans from Netbox: ", nbi.vlanGroupsIndexByName)
-	return nil
+	return initIndex[objects.VlanGroup](ctx, nbi, nbi.vlanGroups)
 }
 
 // Collects all vlans from Netbox API and stores them to local inventory.
@@ -828,14 +446,11 @@ func (nbi *NetboxInventory) initVlans(ctx context.Context) error {
 		"&fields=%s",
 		utils.ExtractJSONTagsFromStructIntoString(objects.Vlan{}),
 	)
-	nbVlans, err := service.GetAll[objects.Vlan](ctx, nbi.NetboxAPI, extraArgs)
+	nbVlans, err := getAll[objects.Vlan](ctx, nbi, extraArgs)
 	if err != nil {
 		return err
 	}
 
-	// Initialize internal index of vlans by VlanGroupId and Vid
-	nbi.vlansIndexByVlanGroupIDAndVID = make(map[int]map[int]*objects.Vlan)
-
 	for i := range nbVlans {
 		vlan := &nbVlans[i]
 		if vlan.Group == nil {
@@ -848,88 +463,21 @@ func (nbi *NetboxInventory) initVlans(ctx context.Context) error {
 			}
 			vlan.Group = defaultVlanGroup
 		}
-		if nbi.vlansIndexByVlanGroupIDAndVID[vlan.Group.ID] == nil {
-			nbi.vlansIndexByVlanGroupIDAndVID[vlan.Group.ID] = make(map[int]*objects.Vlan)
-		}
-		nbi.vlansIndexByVlanGroupIDAndVID[vlan.Group.ID][vlan.Vid] = vlan
-		nbi.OrphanManager.AddItem(vlan)
+		nbi.vlans.Register(vlan)
 	}
 
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected vlans from Netbox: ",
-		nbi.vlansIndexByVlanGroupIDAndVID,
-	)
+	nbi.Logger.Debug(ctx, "Successfully collected vlans from Netbox: ", nbi.vlans)
 	return nil
 }
 
 // Collects all vms from Netbox API and stores them to local inventory.
 func (nbi *NetboxInventory) initVMs(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.VM{}),
-	)
-	nbVMs, err := service.GetAll[objects.VM](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-
-	// Initialize internal index of VMs by name and cluster id
-	nbi.vmsIndexByNameAndClusterID = make(map[string]map[int]*objects.VM)
-	nbi.vmsIndexByID = make(map[int]*objects.VM)
-
-	for i := range nbVMs {
-		vm := &nbVMs[i]
-		nbi.vmsIndexByID[vm.ID] = vm
-		if nbi.vmsIndexByNameAndClusterID[vm.Name] == nil {
-			nbi.vmsIndexByNameAndClusterID[vm.Name] = make(map[int]*objects.VM)
-		}
-		if vm.Cluster == nil {
-			nbi.vmsIndexByNameAndClusterID[vm.Name][-1] = vm
-		} else {
-			nbi.vmsIndexByNameAndClusterID[vm.Name][vm.Cluster.ID] = vm
-		}
-		nbi.OrphanManager.AddItem(vm)
-	}
-
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected VMs from Netbox: ",
-		nbi.vmsIndexByNameAndClusterID,
-	)
-	return nil
+	return initIndex[objects.VM](ctx, nbi, nbi.vms)
 }
 
 // Collects all VMInterfaces from Netbox API and stores them to local inventory.
 func (nbi *NetboxInventory) initVMInterfaces(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.VMInterface{}),
-	)
-	nbVMInterfaces, err := service.GetAll[objects.VMInterface](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return fmt.Errorf("Init vm interfaces: %s", err)
-	}
-
-	// Initialize internal index of VM interfaces by VM id and name
-	nbi.vmInterfacesIndexByVMIdAndName = make(map[int]map[string]*objects.VMInterface)
-	nbi.vmInterfacesIndexByID = make(map[int]*objects.VMInterface)
-	for i := range nbVMInterfaces {
-		vmIntf := &nbVMInterfaces[i]
-		nbi.vmInterfacesIndexByID[vmIntf.ID] = vmIntf
-		if nbi.vmInterfacesIndexByVMIdAndName[vmIntf.VM.ID] == nil {
-			nbi.vmInterfacesIndexByVMIdAndName[vmIntf.VM.ID] = make(map[string]*objects.VMInterface)
-		}
-		nbi.vmInterfacesIndexByVMIdAndName[vmIntf.VM.ID][vmIntf.Name] = vmIntf
-		nbi.OrphanManager.AddItem(vmIntf)
-	}
-
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected VM interfaces from Netbox: ",
-		nbi.vmInterfacesIndexByVMIdAndName,
-	)
-	return nil
+	return initIndex[objects.VMInterface](ctx, nbi, nbi.vmInterfaces)
 }
 
 // Collects all IP addresses from Netbox API and stores them to local inventory.
@@ -938,178 +486,67 @@ func (nbi *NetboxInventory) initIPAddresses(ctx context.Context) error {
 		"&fields=%s",
 		utils.ExtractJSONTagsFromStructIntoString(objects.IPAddress{}),
 	)
-	ipAddresses, err := service.GetAll[objects.IPAddress](ctx, nbi.NetboxAPI, extraArgs)
+	ipAddresses, err := getAll[objects.IPAddress](ctx, nbi, extraArgs)
 	if err != nil {
 		return err
 	}
 
-	// Initializes internal index
-	nbi.ipAddressesIndex = make(
-		map[constants.ContentType]map[string]map[string]map[string]*objects.IPAddress,
-	)
 	for i := range ipAddresses {
 		ipAddr := &ipAddresses[i]
-		ifaceType, ifaceName, ifaceParentName, err := nbi.getIndexValuesForIPAddress(ipAddr)
+		key, err := nbi.ipAddressKey(ipAddr)
 		if err != nil {
 			return fmt.Errorf("get index values for ip address: %s", err)
 		}
-		nbi.verifyIPAddressIndexExists(ifaceType, ifaceName, ifaceParentName)
-		nbi.ipAddressesIndex[ifaceType][ifaceName][ifaceParentName][ipAddr.Address] = ipAddr
-		nbi.OrphanManager.AddItem(ipAddr)
+		nbi.ipAddresses.RegisterKey(key, ipAddr)
 	}
 
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected IP addresses from Netbox: ",
-		nbi.ipAddressesIndex,
-	)
+	nbi.Logger.Debug(ctx, "Successfully collected IP addresses from Netbox: ", nbi.ipAddresses)
 	return nil
 }
 
 func (nbi *NetboxInventory) initMACAddresses(ctx context.Context) error {
+	if !nbi.Capabilities.Has(version.MACAddressObjects) {
+		nbi.Logger.Debugf(ctx, "Netbox %s doesn't have MAC address objects", nbi.Capabilities.Version)
+		return nil
+	}
 	extraArgs := fmt.Sprintf(
 		"&fields=%s",
 		utils.ExtractJSONTagsFromStructIntoString(objects.MACAddress{}),
 	)
-	nbMACAddresses, err := service.GetAll[objects.MACAddress](ctx, nbi.NetboxAPI, extraArgs)
+	nbMACAddresses, err := getAll[objects.MACAddress](ctx, nbi, extraArgs)
 	if err != nil {
 		return err
 	}
-	// Initializes internal indexx
-	nbi.macAddressesIndex = make(
-		map[constants.ContentType]map[string]map[string]map[string]*objects.MACAddress,
-	)
 	for i := range nbMACAddresses {
 		macAddress := &nbMACAddresses[i]
-		ifaceType, ifaceName, ifaceParentName, err := nbi.getIndexValuesForMACAddress(
-			macAddress,
-		)
+		key, err := nbi.macAddressKey(macAddress)
 		if err != nil {
 			return fmt.Errorf("get index values for mac address: %s", err)
 		}
-		nbi.verifyMACAddressIndexExists(ifaceType, ifaceName, ifaceParentName)
-		nbi.macAddressesIndex[ifaceType][ifaceName][ifaceParentName][macAddress.MAC] = macAddress
-		nbi.OrphanManager.AddItem(macAddress)
+		nbi.macAddresses.RegisterKey(key, macAddress)
 	}
 
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected MAC addresses from Netbox: ",
-		nbi.macAddressesIndex,
-	)
+	nbi.Logger.Debug(ctx, "Successfully collected MAC addresses from Netbox: ", nbi.macAddresses)
 	return nil
 }
 
 // Collects all Prefixes from Netbox API and stores them to local inventory.
 func (nbi *NetboxInventory) initPrefixes(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.Prefix{}),
-	)
-	prefixes, err := service.GetAll[objects.Prefix](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-
-	// Initializes internal index of prefixes by prefix
-	nbi.prefixesIndexByPrefix = make(map[string]*objects.Prefix)
-
-	for i := range prefixes {
-		prefix := &prefixes[i]
-		nbi.prefixesIndexByPrefix[prefix.Prefix] = prefix
-		nbi.OrphanManager.AddItem(prefix)
-	}
-
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected prefixes from Netbox: ",
-		nbi.prefixesIndexByPrefix,
-	)
-	return nil
+	return initIndex[objects.Prefix](ctx, nbi, nbi.prefixes)
 }
 
 // Collects all WirelessLANs from Netbox API and stores them to local inventory.
 func (nbi *NetboxInventory) initWirelessLANs(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.WirelessLAN{}),
-	)
-	nbWirelessLans, err := service.GetAll[objects.WirelessLAN](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-
-	// Initialize internal index of WirelessLANs by SSID
-	nbi.wirelessLANsIndexBySSID = make(map[string]*objects.WirelessLAN)
-
-	for i := range nbWirelessLans {
-		wirelessLan := &nbWirelessLans[i]
-		nbi.wirelessLANsIndexBySSID[wirelessLan.SSID] = wirelessLan
-		nbi.OrphanManager.AddItem(wirelessLan)
-	}
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected wireless-lans from Netbox: ",
-		nbi.wirelessLANsIndexBySSID,
-	)
-	return nil
+	return initIndex[objects.WirelessLAN](ctx, nbi, nbi.wirelessLANs)
 }
 
 // Collects all WirelessLANGroups from Netbox API and stores them to local inventory.
 func (nbi *NetboxInventory) initWirelessLANGroups(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.WirelessLANGroup{}),
-	)
-	nbWirelessLanGroups, err := service.GetAll[objects.WirelessLANGroup](
-		ctx,
-		nbi.NetboxAPI,
-		extraArgs,
-	)
-	if err != nil {
-		return err
-	}
-
-	// Initialize internal index of WirelessLanGroups by SSID
-	nbi.wirelessLANGroupsIndexByName = make(map[string]*objects.WirelessLANGroup)
-
-	for i := range nbWirelessLanGroups {
-		wirelessLanGroup := &nbWirelessLanGroups[i]
-		nbi.wirelessLANGroupsIndexByName[wirelessLanGroup.Name] = wirelessLanGroup
-		nbi.OrphanManager.AddItem(wirelessLanGroup)
-	}
-	nbi.Logger.Debug(
-		ctx,
-		"Successfully collected wireless-lan-groups from Netbox: ",
-		nbi.wirelessLANGroupsIndexByName,
-	)
-	return nil
+	return initIndex[objects.WirelessLANGroup](ctx, nbi, nbi.wirelessLANGroups)
 }
 
 // initVirtualDisks collects all virtual disks from Netbox API
 // and stores them to local inventory.
 func (nbi *NetboxInventory) initVirtualDisks(ctx context.Context) error {
-	extraArgs := fmt.Sprintf(
-		"&fields=%s",
-		utils.ExtractJSONTagsFromStructIntoString(objects.VirtualDisk{}),
-	)
-	nbVirtualDisks, err := service.GetAll[objects.VirtualDisk](ctx, nbi.NetboxAPI, extraArgs)
-	if err != nil {
-		return err
-	}
-
-	// Initialize internal index of virtual disks by name and VM id
-	nbi.virtualDisksIndexByVMIDAndName = make(map[int]map[string]*objects.VirtualDisk)
-
-	for i := range nbVirtualDisks {
-		virtualDisk := &nbVirtualDisks[i]
-		if nbi.virtualDisksIndexByVMIDAndName[virtualDisk.VM.ID] == nil {
-			nbi.virtualDisksIndexByVMIDAndName[virtualDisk.VM.ID] = make(
-				map[string]*objects.VirtualDisk,
-			)
-		}
-		nbi.virtualDisksIndexByVMIDAndName[virtualDisk.VM.ID][virtualDisk.Name] = virtualDisk
-		nbi.OrphanManager.AddItem(virtualDisk)
-	}
-	return nil
+	return initIndex[objects.VirtualDisk](ctx, nbi, nbi.virtualDisks)
 }
@@ -3,19 +3,23 @@ package inventory
 import (
 	"context"
 	"fmt"
-	"strconv"
-	"strings"
-	"sync"
 	"time"
 
 	"github.com/src-doo/netbox-ssot/internal/constants"
 	"github.com/src-doo/netbox-ssot/internal/logger"
 	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
 	"github.com/src-doo/netbox-ssot/internal/netbox/service"
+	"github.com/src-doo/netbox-ssot/internal/netbox/version"
 	"github.com/src-doo/netbox-ssot/internal/parser"
+	"github.com/src-doo/netbox-ssot/internal/report"
 	"github.com/src-doo/netbox-ssot/internal/utils"
 )
 
+// refreshSafetyMargin is subtracted from the time of the last Init or Refresh
+// when collecting changed objects, so clock skew between netbox-ssot and
+// Netbox doesn't cause missed changes.
+const refreshSafetyMargin = 5 * time.Minute
+
 // NetboxInventory is a singleton class to manage a inventory of NetBoxObject objects.
 type NetboxInventory struct {
 	// Logger is the logger used for logging messages
@@ -24,9 +28,21 @@ type NetboxInventory struct {
 	NetboxConfig *parser.NetboxConfig
 	// NetboxAPI is the Netbox API object, for communicating with the Netbox API
 	NetboxAPI *service.NetboxClient
+	// Capabilities of the connected Netbox release, that are set by Init.
+	// Fields that the release doesn't support are never sent to it.
+	Capabilities *version.Capabilities
+	// Plan is set when running in dry-run mode. All changes are recorded
+	// in the plan instead of being sent to the Netbox API.
+	Plan *service.Plan
+	// Report is the report of the current run. When set, all changes
+	// of the objects are recorded in it.
+	Report *report.Report
 	// SourcePriority: if object is found on multiple sources, which source has
 	// the priority for the object attributes.
 	SourcePriority map[string]int
+	// FieldOwnership maps object types and their fields to sources, that own
+	// the fields (see netbox.fieldOwnership and utils.JSONDiffMapExceptID).
+	FieldOwnership map[constants.ContentType]map[string][]string
 	// ArpDataLifeSpan determines the lifespan of arp entries in seconds.
 	ArpDataLifeSpan int
 	// OrphanManager object that manages orphaned objects.
@@ -37,170 +53,61 @@ type NetboxInventory struct {
 	// to functions for logging.
 	Ctx context.Context //nolint:containedctx
 
-	// tagsIndexByName is a map of all tags in the Netbox's inventory,
-	// indexed by their name
-	tagsIndexByName map[string]*objects.Tag
-	tagsLock        sync.Mutex
-
-	// contactGroupsIndexByName is a map of all contact groups
-	// indexed by their names.
-	contactGroupsIndexByName map[string]*objects.ContactGroup
-	contactGroupsLock        sync.Mutex
-
-	// contactRolesIndexByName is a map of all contact roles
-	// indexed by their names.
-	contactRolesIndexByName map[string]*objects.ContactRole
-	contactRolesLock        sync.Mutex
-
-	// contactsIndexByName is a map of all contacts in the Netbox's inventory,
-	// indexed by their names
-	contactsIndexByName map[string]*objects.Contact
-	contactsLock        sync.Mutex
-
-	// contactAssignmentsIndex is a
-	// map of all contact assignments indexed by their
-	// content type, object id, contact id and role id.
-	contactAssignmentsIndex map[constants.ContentType]map[int]map[int]map[int]*objects.ContactAssignment
-	contactAssignmentsLock  sync.Mutex
-
-	// sitesIndexByName is a map of all sites in the Netbox's inventory,
-	// indexed by their name
-	sitesIndexByName map[string]*objects.Site
-	sitesLock        sync.Mutex
-
-	// siteGroupsIndexByName is a map of all site groups in the Netbox's inventory,
-	// indexed by their name
-	siteGroupsIndexByName map[string]*objects.SiteGroup
-	siteGroupsLock        sync.Mutex
-
-	// manufacturersIndexByName is a map of all manufacturers in the Netbox's inventory,
-	// indexed by their name
-	manufacturersIndexByName map[string]*objects.Manufacturer
-	manufacturersLock        sync.Mutex
-
-	// platformsIndexByName is a map of all platforms in the Netbox's inventory, indexed by their name
-	platformsIndexByName map[string]*objects.Platform
-	platformsLock        sync.Mutex
-
-	// tenantsIndexByName is a map of all tenants in the Netbox's inventory,
-	// indexed by their name
-	tenantsIndexByName map[string]*objects.Tenant
-	tenantsLock        sync.Mutex
-
-	// deviceTypesIndexByModel is a map of all device types in the Netbox's inventory,
-	// indexed by their model
-	deviceTypesIndexByModel map[string]*objects.DeviceType
-	deviceTypesLock         sync.Mutex
-
-	// devicesIndexByNameAndSiteID is a map of all devices in the Netbox's inventory,
-	// indexed by their name and SiteID
-	devicesIndexByNameAndSiteID map[string]map[int]*objects.Device
-	// devicesIndexByID is a helper index, that we use in init functions
-	// to create relationships between objects.
-	devicesIndexByID map[int]*objects.Device
-	devicesLock      sync.Mutex
-
-	// virtualDeviceContextsIndex is a map of all virtual device contexts
-	// in the Netbox's inventory indexed by their name and device ID.
-	virtualDeviceContextsIndex map[string]map[int]*objects.VirtualDeviceContext
-	virtualDeviceContextsLock  sync.Mutex
-
-	// prefixesIndexByPrefix is a map of all prefixes in the Netbox's inventory,
-	// indexed by their prefix.
-	prefixesIndexByPrefix map[string]*objects.Prefix
-	prefixesLock          sync.Mutex
-
-	// vlanGroupsIndexByName is a map of all VlanGroups in the Netbox's inventory,
-	// indexed by their name.
-	vlanGroupsIndexByName map[string]*objects.VlanGroup
-	vlanGroupsLock        sync.Mutex
-
-	// vlansIndexByVlanGroupIDAndVID is a map of all vlans in the Netbox's inventory,
-	// indexed by their VlanGroup and vid.
-	vlansIndexByVlanGroupIDAndVID map[int]map[int]*objects.Vlan
-	vlansLock                     sync.Mutex
-
-	// clusterGroupsIndexByName is a map of all cluster groups in the Netbox's
-	// inventory indexed by their name
-	clusterGroupsIndexByName map[string]*objects.ClusterGroup
-	clusterGroupsLock        sync.Mutex
-
-	// clusterTypesIndexByName is a map of all cluster types in the Netbox's
-	// inventory, indexed by their name
-	clusterTypesIndexByName map[string]*objects.ClusterType
-	clusterTypesLock        sync.Mutex
-
-	// clustersIndexByName is a map of all clusters in the Netbox's inventory,
-	// indexed by their name
-	clustersIndexByName map[string]*objects.Cluster
-	clustersLock        sync.Mutex
-
-	// Netbox's Device Roles is a map of all device roles in the inventory,
-	// indexed by name.
-	deviceRolesIndexByName map[string]*objects.DeviceRole
-	deviceRolesLock        sync.Mutex
-
-	// customFieldsIndexByName is a map of all custom fields in the inventory,
-	// indexed by name.
-	customFieldsIndexByName map[string]*objects.CustomField
-	customFieldsLock        sync.Mutex
-
-	// InterfacesIndexByDeviceAnName is a map of all interfaces in the inventory,
-	// indexed by their's device id and their name.
-	interfacesIndexByDeviceIDAndName map[int]map[string]*objects.Interface
-	// interfacesIndexByID is a helper index, that we use in init functions
-	// to create relationships between objects.
-	interfacesIndexByID map[int]*objects.Interface
-	interfacesLock      sync.Mutex
-
-	// vmsIndexByNameAndClusterID is a map of all virtual machines in the inventory,
-	// indexed by their name and their cluster id
-	vmsIndexByNameAndClusterID map[string]map[int]*objects.VM
-	// vmsIndexByID is a helper index, that we use in init functions
-	// to create relationships between objects.
-	vmsIndexByID map[int]*objects.VM
-	vmsLock      sync.Mutex
-
-	// vmInterfacesIndexByVMAndName is a map of all virtual machine interfaces in the
-	// inventory, indexed by their's virtual machine id and their name
-	vmInterfacesIndexByVMIdAndName map[int]map[string]*objects.VMInterface
-	// vmInterfacesIndexByID is a helper index, that we use in init functions
-	// to create relationships between objects.
-	vmInterfacesIndexByID map[int]*objects.VMInterface
-	vmInterfacesLock      sync.Mutex
-
-	// ipAdressesIndex is a map of all IP addresses in the inventory,
-	// indexed:
-	//   * iface type (vmiface or device iface)
-	//   * iface name (name of the vminterface/deviceinterface)
-	//   * iface parent name (name of the vm/device that the interface belongs to)
-	//   * ip address
-	ipAddressesIndex map[constants.ContentType]map[string]map[string]map[string]*objects.IPAddress
-	ipAddressesLock  sync.Mutex
-
-	// macAddressesIndex is a map of all MAC addresses in the inventory,
-	// indexed with these levels:
-	//  * iface type (vmiface or device iface)
-	//  * iface name (name of the vminterface/deviceinterface)
-	//  * iface parent name (name of the vm/device that the interface belongs to)
-	//  * mac address
-	macAddressesIndex map[constants.ContentType]map[string]map[string]map[string]*objects.MACAddress
-	macAddressesLock  sync.Mutex
-
-	// wirelessLANGroupsIndexByName is a map of all wireless lan groups in the Netbox's
-	// inventory, indexed by their name
-	wirelessLANGroupsIndexByName map[string]*objects.WirelessLANGroup
-	wirelessLANGroupsLock        sync.Mutex
-
-	// wirelessLANsIndexBySSID is a map of all wireless lans in the Netbox's inventory,
-	// indexed by their ssid
-	wirelessLANsIndexBySSID map[string]*objects.WirelessLAN
-	wirelessLANsLock        sync.Mutex
-
-	// virtualDisksIndex is a map of all virtual disks in the inventory,
-	// indexed by their vm's id and vm's name
-	virtualDisksIndexByVMIDAndName map[int]map[string]*objects.VirtualDisk
-	virtualDisksLock               sync.Mutex
+	// lastInit is the time when the last Init or Refresh started.
+	lastInit time.Time
+	// lastFullInit is the time when the last Init started.
+	lastFullInit time.Time
+	// refreshSince is set during Refresh. When set, init functions only
+	// collect objects that were updated in Netbox after this time.
+	refreshSince time.Time
+	// hardDeletedObjects is the number of objects deleted since the last Init.
+	hardDeletedObjects int
+	// state holds all objects collected by init functions, when
+	// netbox.stateFile is set. It is nil otherwise.
+	state *inventoryState
+	// branch is the netbox-branching branch used since the last Init,
+	// when netbox.branch is set. It is nil otherwise.
+	branch *service.Branch
+	// branchName is netbox.branch with replaced placeholders, that was used
+	// to select branch. Names differ, when branches with the name were
+	// already merged (see service.NetboxClient.UseBranch).
+	branchName string
+
+	// writeQueue sends creates and patches in bulk requests, when bulk
+	// writes are enabled with netbox.bulkSize. It is nil otherwise.
+	writeQueue *writeQueue
+
+	// Indexes of all objects in the Netbox's inventory. Objects are
+	// indexed by the key functions from index_keys.go, and by their ids.
+	tags                  *Index[string, *objects.Tag]
+	customFields          *Index[string, *objects.CustomField]
+	contactGroups         *Index[string, *objects.ContactGroup]
+	contactRoles          *Index[string, *objects.ContactRole]
+	contacts              *Index[string, *objects.Contact]
+	contactAssignments    *Index[contactAssignmentIndexKey, *objects.ContactAssignment]
+	tenants               *Index[string, *objects.Tenant]
+	sites                 *Index[string, *objects.Site]
+	siteGroups            *Index[string, *objects.SiteGroup]
+	manufacturers         *Index[string, *objects.Manufacturer]
+	platforms             *Index[string, *objects.Platform]
+	deviceTypes           *Index[string, *objects.DeviceType]
+	deviceRoles           *Index[string, *objects.DeviceRole]
+	devices               *Index[deviceIndexKey, *objects.Device]
+	virtualDeviceContexts *Index[deviceChildIndexKey, *objects.VirtualDeviceContext]
+	interfaces            *Index[deviceChildIndexKey, *objects.Interface]
+	prefixes              *Index[string, *objects.Prefix]
+	vlanGroups            *Index[string, *objects.VlanGroup]
+	vlans                 *Index[vlanIndexKey, *objects.Vlan]
+	clusterGroups         *Index[string, *objects.ClusterGroup]
+	clusterTypes          *Index[string, *objects.ClusterType]
+	clusters              *Index[string, *objects.Cluster]
+	vms                   *Index[vmIndexKey, *objects.VM]
+	vmInterfaces          *Index[vmChildIndexKey, *objects.VMInterface]
+	virtualDisks          *Index[vmChildIndexKey, *objects.VirtualDisk]
+	ipAddresses           *Index[addressIndexKey, *objects.IPAddress]
+	macAddresses          *Index[addressIndexKey, *objects.MACAddress]
+	wirelessLANGroups     *Index[string, *objects.WirelessLANGroup]
+	wirelessLANs          *Index[string, *objects.WirelessLAN]
 }
 
 // Func string representation.
@@ -224,6 +131,10 @@ func NewNetboxInventory(
 	for i, sourceName := range nbConfig.SourcePriority {
 		sourcePriority[sourceName] = i
 	}
+	fieldOwnership := make(map[constants.ContentType]map[string][]string, len(nbConfig.FieldOwnership))
+	for objectType, fieldOwners := range nbConfig.FieldOwnership {
+		fieldOwnership[constants.ContentType(objectType)] = fieldOwners
+	}
 	orphanManager := NewOrphanManager(logger)
 
 	nbi := &NetboxInventory{
@@ -231,6 +142,7 @@ func NewNetboxInventory(
 		Logger:         logger,
 		NetboxConfig:   nbConfig,
 		SourcePriority: sourcePriority,
+		FieldOwnership: fieldOwnership,
 		OrphanManager:  orphanManager,
 	}
 	return nbi
@@ -250,20 +162,93 @@ func (nbi *NetboxInventory) Init() error {
 	nbi.NetboxAPI, err = service.NewNetboxClient(
 		nbi.Logger,
 		baseURL,
-		nbi.NetboxConfig.APIToken,
-		nbi.NetboxConfig.ValidateCert,
+		nbi.NetboxConfig.APIToken.Value(),
 		nbi.NetboxConfig.Timeout,
-		nbi.NetboxConfig.CAFile,
+		nbi.NetboxConfig.HTTPClientConfig(),
 	)
 	if err != nil {
 		return fmt.Errorf("create new netbox client: %s", err)
 	}
+	nbi.NetboxAPI.Plan = nbi.Plan
+	nbi.NetboxAPI.MaxRetries = nbi.NetboxConfig.MaxRetries
+	nbi.NetboxAPI.PageSize = nbi.NetboxConfig.PageSize
+	nbi.NetboxAPI.PageWorkers = nbi.NetboxConfig.PageWorkers
+	nbi.NetboxAPI.JournalEntries = nbi.NetboxConfig.JournalEntries
+	nbi.startRun()
+	// In dry-run mode changes are only recorded in the plan,
+	// so there is nothing to gain from bulk writes
+	nbi.writeQueue = nil
+	if nbi.NetboxConfig.BulkSize > 0 && nbi.Plan == nil {
+		nbi.writeQueue = newWriteQueue(nbi.NetboxConfig.BulkSize)
+	}
 
 	err = nbi.checkVersion()
 	if err != nil {
 		return err
 	}
 
+	startTime := time.Now()
+	if err := nbi.initBranch(startTime); err != nil {
+		return err
+	}
+	nbi.resetIndexes()
+	nbi.OrphanManager.Reset()
+	nbi.hardDeletedObjects = 0
+	nbi.initState()
+	if err := nbi.runInitFunctions(); err != nil {
+		return err
+	}
+	nbi.saveState(startTime)
+	nbi.lastInit = startTime
+	nbi.lastFullInit = startTime
+	return nil
+}
+
+// Refresh updates already initialized inventory with objects that were
+// changed in Netbox since the last Init or Refresh. Objects that were
+// deleted in Netbox (outside of netbox-ssot) are not removed from the
+// inventory, so Init should still be called periodically (see NeedsFullInit).
+func (nbi *NetboxInventory) Refresh() error {
+	// Changes are never made outside of the configured branch
+	if nbi.NetboxAPI == nil || nbi.lastInit.IsZero() || nbi.branchOutdated(time.Now()) {
+		return nbi.Init()
+	}
+	nbi.startRun()
+	startTime := time.Now()
+	nbi.refreshSince = nbi.lastInit.Add(-refreshSafetyMargin)
+	defer func() { nbi.refreshSince = time.Time{} }()
+
+	nbi.OrphanManager.RestoreItems()
+	if err := nbi.runInitFunctions(); err != nil {
+		return err
+	}
+	nbi.lastInit = startTime
+	return nil
+}
+
+// startRun assigns a new run id to all following requests,
+// so changes made in a single run can be correlated.
+func (nbi *NetboxInventory) startRun() {
+	nbi.NetboxAPI.RunID = service.NewRunID()
+	nbi.Logger.Infof(nbi.Ctx, "Starting run %s", nbi.NetboxAPI.RunID)
+}
+
+// NeedsFullInit returns true if the inventory can't be refreshed with
+// Refresh anymore and must be reinitialized with Init instead. This is
+// the case when inventory hasn't been initialized yet, when objects were
+// hard deleted since the last Init (their indexes would be stale) or when
+// the last Init is older than fullInitInterval. When netbox.branch is set,
+// Init is also needed once a new branch should be used (see branchOutdated).
+func (nbi *NetboxInventory) NeedsFullInit(fullInitInterval time.Duration) bool {
+	return nbi.NetboxAPI == nil ||
+		nbi.lastFullInit.IsZero() ||
+		nbi.hardDeletedObjects > 0 ||
+		time.Since(nbi.lastFullInit) >= fullInitInterval ||
+		nbi.branchOutdated(time.Now())
+}
+
+// runInitFunctions runs all init functions, that collect objects from Netbox.
+func (nbi *NetboxInventory) runInitFunctions() error {
 	// WARNING: Order matters
 	initFunctions := []func(context.Context) error{
 		nbi.initCustomFields,
@@ -316,27 +301,70 @@ func (nbi *NetboxInventory) Init() error {
 	return nil
 }
 
+// resetIndexes replaces all indexes with new empty ones.
+func (nbi *NetboxInventory) resetIndexes() {
+	om := nbi.OrphanManager
+	nbi.tags = NewIndex(tagKey, nil)
+	nbi.customFields = NewIndex(customFieldKey, nil)
+	nbi.contactGroups = NewIndex(contactGroupKey, nil)
+	nbi.contactRoles = NewIndex(contactRoleKey, nil)
+	nbi.contacts = NewIndex(contactKey, om)
+	nbi.contactAssignments = NewIndex(contactAssignmentKey, om)
+	nbi.tenants = NewIndex(tenantKey, nil)
+	nbi.sites = NewIndex(siteKey, nil)
+	nbi.siteGroups = NewIndex(siteGroupKey, nil)
+	nbi.manufacturers = NewIndex(manufacturerKey, om)
+	nbi.platforms = NewIndex(platformKey, om)
+	nbi.deviceTypes = NewIndex(deviceTypeKey, om)
+	nbi.deviceRoles = NewIndex(deviceRoleKey, om)
+	nbi.devices = NewIndex(deviceKey, om)
+	if nbi.NetboxConfig != nil {
+		addDeviceSecondaryIndexes(nbi.devices, nbi.NetboxConfig.DeviceMatching)
+	}
+	nbi.virtualDeviceContexts = NewIndex(virtualDeviceContextKey, om)
+	nbi.interfaces = NewIndex(interfaceKey, om)
+	nbi.prefixes = NewIndex(prefixKey, om)
+	nbi.vlanGroups = NewIndex(vlanGroupKey, om)
+	nbi.vlans = NewIndex(vlanKey, om)
+	nbi.clusterGroups = NewIndex(clusterGroupKey, om)
+	nbi.clusterTypes = NewIndex(clusterTypeKey, om)
+	nbi.clusters = NewIndex(clusterKey, om)
+	nbi.vms = NewIndex(vmKey, om)
+	nbi.vmInterfaces = NewIndex(vmInterfaceKey, om)
+	nbi.virtualDisks = NewIndex(virtualDiskKey, om)
+	// Keys of addresses are computed with ipAddressKey and macAddressKey
+	nbi.ipAddresses = NewIndex[addressIndexKey, *objects.IPAddress](nil, om)
+	nbi.macAddresses = NewIndex[addressIndexKey, *objects.MACAddress](nil, om)
+	nbi.wirelessLANGroups = NewIndex(wirelessLANGroupKey, om)
+	nbi.wirelessLANs = NewIndex(wirelessLANKey, om)
+}
+
 func (nbi *NetboxInventory) checkVersion() error {
-	version, err := service.GetVersion(nbi.Ctx, nbi.NetboxAPI)
+	rawVersion, err := service.GetVersion(nbi.Ctx, nbi.NetboxAPI)
 	if err != nil {
 		return fmt.Errorf("get version: %s", err)
 	}
-	supportedVersion := 4
-	supportedMinorVersion := 2
-	versionComponents := strings.Split(version, ".")
-	majorVersion, err := strconv.Atoi(versionComponents[0])
-	if err != nil {
-		return fmt.Errorf("parse major version: %s", err)
-	}
-	minorVersion, err := strconv.Atoi(versionComponents[1])
+	netboxVersion, err := version.Parse(rawVersion)
 	if err != nil {
-		return fmt.Errorf("parse minor version: %s", err)
+		return fmt.Errorf("parse version: %s", err)
 	}
-	if majorVersion != supportedVersion || minorVersion < supportedMinorVersion {
+	if !netboxVersion.AtLeast(version.MinSupported) {
 		return fmt.Errorf(
-			"this version of netbox-ssot works only with netbox version >= 4.2.0, but received version: %s",
-			version,
+			"this version of netbox-ssot works only with netbox version >= %s, but received version: %s",
+			version.MinSupported,
+			rawVersion,
+		)
+	}
+	if netboxVersion.Major > version.LatestTested.Major ||
+		(netboxVersion.Major == version.LatestTested.Major && netboxVersion.Minor > version.LatestTested.Minor) {
+		nbi.Logger.Warningf(
+			nbi.Ctx,
+			"netbox version %s is newer than the latest tested version %s",
+			rawVersion,
+			version.LatestTested,
 		)
 	}
+	nbi.Capabilities = version.Of(netboxVersion)
+	nbi.NetboxAPI.Capabilities = nbi.Capabilities
 	return nil
 }
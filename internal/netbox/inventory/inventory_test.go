@@ -4,9 +4,16 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/src-doo/netbox-ssot/internal/constants"
 	"github.com/src-doo/netbox-ssot/internal/logger"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/netbox/service"
 	"github.com/src-doo/netbox-ssot/internal/parser"
+	"github.com/src-doo/netbox-ssot/internal/report"
+	"github.com/src-doo/netbox-ssot/internal/utils"
 )
 
 func TestNetboxInventory_String(t *testing.T) {
@@ -71,16 +78,163 @@ func TestNetboxInventory_Init(t *testing.T) {
 func TestNetboxInventory_checkVersion(t *testing.T) {
 	tests := []struct {
 		name    string
-		nbi     *NetboxInventory
+		version string
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{name: "Unsupported older version", version: "4.0.11", wantErr: true},
+		{name: "Minimum supported version", version: "4.1.0", wantErr: false},
+		{name: "Version with scopes and MAC addresses", version: "4.2.0", wantErr: false},
+		{name: "Newer minor version", version: "4.4.1", wantErr: false},
+		{name: "Newer major version", version: "5.0.0", wantErr: false},
+		{name: "Invalid version", version: "latest", wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := tt.nbi.checkVersion(); (err != nil) != tt.wantErr {
+			server := fakenetbox.NewServer()
+			defer server.Close()
+			server.Version = tt.version
+			netboxClient, err := service.NewNetboxClient(
+				MockInventory.Logger, server.URL, "token", constants.DefaultAPITimeout, utils.HTTPClientConfig{},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nbi := &NetboxInventory{Logger: MockInventory.Logger, Ctx: context.Background(), NetboxAPI: netboxClient}
+			if err := nbi.checkVersion(); (err != nil) != tt.wantErr {
 				t.Errorf("NetboxInventory.checkVersion() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if !tt.wantErr && (nbi.Capabilities == nil || netboxClient.Capabilities != nbi.Capabilities) {
+				t.Errorf("capabilities of version %s are not set", tt.version)
+			}
 		})
 	}
 }
+
+func TestNetboxInventory_NeedsFullInit(t *testing.T) {
+	tests := []struct {
+		name string
+		nbi  *NetboxInventory
+		want bool
+	}{
+		{
+			name: "Not initialized",
+			nbi:  &NetboxInventory{},
+			want: true,
+		},
+		{
+			name: "Recently initialized",
+			nbi: &NetboxInventory{
+				NetboxAPI:    &service.NetboxClient{},
+				lastFullInit: time.Now().Add(-time.Hour),
+			},
+			want: false,
+		},
+		{
+			name: "Full init interval elapsed",
+			nbi: &NetboxInventory{
+				NetboxAPI:    &service.NetboxClient{},
+				lastFullInit: time.Now().Add(-25 * time.Hour),
+			},
+			want: true,
+		},
+		{
+			name: "Objects were hard deleted",
+			nbi: &NetboxInventory{
+				NetboxAPI:          &service.NetboxClient{},
+				lastFullInit:       time.Now().Add(-time.Hour),
+				hardDeletedObjects: 1,
+			},
+			want: true,
+		},
+		{
+			name: "Branch is current",
+			nbi: &NetboxInventory{
+				NetboxConfig: &parser.NetboxConfig{Branch: "ssot-{{date}}"},
+				NetboxAPI:    &service.NetboxClient{},
+				lastFullInit: time.Now().Add(-time.Hour),
+				branch:       &service.Branch{Name: service.BranchName("ssot-{{date}}", time.Now()) + "-2"},
+				branchName:   service.BranchName("ssot-{{date}}", time.Now()),
+			},
+			want: false,
+		},
+		{
+			name: "Branch was merged",
+			nbi: &NetboxInventory{
+				NetboxConfig: &parser.NetboxConfig{Branch: "ssot-{{date}}"},
+				NetboxAPI:    &service.NetboxClient{},
+				lastFullInit: time.Now().Add(-time.Hour),
+			},
+			want: true,
+		},
+		{
+			name: "Branch is from yesterday",
+			nbi: &NetboxInventory{
+				NetboxConfig: &parser.NetboxConfig{Branch: "ssot-{{date}}"},
+				NetboxAPI:    &service.NetboxClient{},
+				lastFullInit: time.Now().Add(-time.Hour),
+				branch:       &service.Branch{Name: service.BranchName("ssot-{{date}}", time.Now().Add(-24*time.Hour))},
+				branchName:   service.BranchName("ssot-{{date}}", time.Now().Add(-24*time.Hour)),
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.nbi.NeedsFullInit(24 * time.Hour); got != tt.want {
+				t.Errorf("NetboxInventory.NeedsFullInit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetboxInventory_refreshFilter(t *testing.T) {
+	tests := []struct {
+		name         string
+		refreshSince time.Time
+		want         string
+	}{
+		{
+			name: "Full init",
+			want: "",
+		},
+		{
+			name:         "Refresh",
+			refreshSince: time.Date(2024, time.May, 15, 12, 30, 0, 0, time.FixedZone("CEST", 2*60*60)),
+			want:         "&last_updated__gte=2024-05-15T10%3A30%3A00Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nbi := &NetboxInventory{refreshSince: tt.refreshSince}
+			if got := nbi.refreshFilter(); got != tt.want {
+				t.Errorf("NetboxInventory.refreshFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetboxInventory_recordChange(t *testing.T) {
+	ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "testSource")
+	nbi := &NetboxInventory{Report: report.New(time.Now())}
+	vm1 := &objects.VM{NetboxObject: objects.NetboxObject{ID: 1}, Name: "vm1"}
+	vm2 := &objects.VM{NetboxObject: objects.NetboxObject{ID: 2}, Name: "vm2"}
+	nbi.recordChange(ctx, vm1, report.ActionCreated)
+	nbi.recordChange(ctx, vm1, report.ActionUnchanged)
+	nbi.recordChange(ctx, vm2, report.ActionUnchanged)
+	nbi.recordChange(ctx, vm2, report.ActionUnchanged)
+	nbi.recordChange(ctx, &objects.Tag{ID: 1, Name: "tag"}, report.ActionUpdated)
+
+	want := map[constants.APIPath]*report.ObjectCounts{
+		constants.VirtualMachinesAPIPath: {Created: 1, Unchanged: 1},
+		constants.TagsAPIPath:            {Updated: 1},
+	}
+	if len(nbi.Report.Sources) != 1 || nbi.Report.Sources[0].Name != "testSource" {
+		t.Fatalf("Report.Sources = %v, want only testSource", nbi.Report.Sources)
+	}
+	if got := nbi.Report.Sources[0].Objects; !reflect.DeepEqual(got, want) {
+		t.Errorf("source objects = %v, want %v", got, want)
+	}
+
+	// Changes are not recorded when report is disabled
+	(&NetboxInventory{}).recordChange(ctx, &objects.VM{Name: "vm3"}, report.ActionCreated)
+}
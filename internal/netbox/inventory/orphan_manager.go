@@ -1,8 +1,6 @@
 package inventory
 
 import (
-	"context"
-
 	"github.com/src-doo/netbox-ssot/internal/constants"
 	"github.com/src-doo/netbox-ssot/internal/logger"
 	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
@@ -21,6 +19,10 @@ type OrphanManager struct {
 	// It stores which objects have been created by netbox-ssot and can be deleted
 	// because they are not available in the sources anymore
 	Items map[constants.APIPath]map[int]objects.OrphanItem
+	// managedItems stores all objects managed by netbox-ssot that were collected
+	// from Netbox. Items are restored from it before each refreshed run, because
+	// items seen by the sources are removed from Items during the sync.
+	managedItems map[constants.APIPath]map[int]objects.OrphanItem
 	// OrphanObjectPriority is a map that stores priorities for each object. This is necessary
 	// because map order is non deterministic and if we delete dependent object first we will
 	// get the dependency error.
@@ -35,8 +37,6 @@ type OrphanManager struct {
 	Tag *objects.Tag
 	// Logger for orphan manager
 	Logger *logger.Logger
-	// Context for orphan manager
-	Ctx context.Context
 }
 
 func NewOrphanManager(logger *logger.Logger) *OrphanManager {
@@ -65,13 +65,11 @@ func NewOrphanManager(logger *logger.Logger) *OrphanManager {
 		20: constants.WirelessLANGroupsAPIPath,
 		21: constants.MACAddressesAPIPath,
 	}
-	orphanCtx := context.WithValue(context.Background(), constants.CtxSourceKey, "orphanManager")
-
 	return &OrphanManager{
 		Items:                map[constants.APIPath]map[int]objects.OrphanItem{},
+		managedItems:         map[constants.APIPath]map[int]objects.OrphanItem{},
 		OrphanObjectPriority: orphanObjectPriority,
 		Logger:               logger,
-		Ctx:                  orphanCtx,
 	}
 }
 
@@ -83,9 +81,70 @@ func (orphanManager *OrphanManager) AddItem(orphanItem objects.OrphanItem) {
 			orphanManager.Items[orphanItem.GetAPIPath()] = map[int]objects.OrphanItem{}
 		}
 		orphanManager.Items[orphanItem.GetAPIPath()][netboxObject.ID] = orphanItem
+		if orphanManager.managedItems[orphanItem.GetAPIPath()] == nil {
+			orphanManager.managedItems[orphanItem.GetAPIPath()] = map[int]objects.OrphanItem{}
+		}
+		orphanManager.managedItems[orphanItem.GetAPIPath()][netboxObject.ID] = orphanItem
+	} else {
+		// Object could have been managed before, but the ssot tag was removed
+		delete(orphanManager.Items[orphanItem.GetAPIPath()], netboxObject.ID)
+		delete(orphanManager.managedItems[orphanItem.GetAPIPath()], netboxObject.ID)
 	}
 }
 
 func (orphanManager *OrphanManager) RemoveItem(obj objects.OrphanItem) {
 	delete(orphanManager.Items[obj.GetAPIPath()], obj.GetID())
 }
+
+// Reset removes all tracked items from the orphan manager.
+func (orphanManager *OrphanManager) Reset() {
+	orphanManager.Items = map[constants.APIPath]map[int]objects.OrphanItem{}
+	orphanManager.managedItems = map[constants.APIPath]map[int]objects.OrphanItem{}
+}
+
+// RestoreItems marks all managed objects collected from Netbox as possible
+// orphans again, so they can be removed by the sources in the next sync.
+func (orphanManager *OrphanManager) RestoreItems() {
+	orphanManager.Items = make(
+		map[constants.APIPath]map[int]objects.OrphanItem,
+		len(orphanManager.managedItems),
+	)
+	for apiPath, id2item := range orphanManager.managedItems {
+		orphanManager.Items[apiPath] = make(map[int]objects.OrphanItem, len(id2item))
+		for id, item := range id2item {
+			orphanManager.Items[apiPath][id] = item
+		}
+	}
+}
+
+// unprotectedItems returns orphaned objects of type objectAPIPath, that are
+// not protected by protectedSources. See NetboxInventory.DeleteOrphans.
+func (orphanManager *OrphanManager) unprotectedItems(
+	objectAPIPath constants.APIPath,
+	protectedSources map[string]bool,
+) map[int]objects.OrphanItem {
+	if len(protectedSources) == 0 {
+		return orphanManager.Items[objectAPIPath]
+	}
+	unprotected := make(map[int]objects.OrphanItem)
+	for id, orphanItem := range orphanManager.Items[objectAPIPath] {
+		sourceName := orphanItemSource(orphanItem)
+		if sourceName == "" || protectedSources[sourceName] {
+			continue
+		}
+		unprotected[id] = orphanItem
+	}
+	return unprotected
+}
+
+// orphanItemSource returns name of the source, that owns the object.
+// Empty string is returned for objects, that are shared by all sources:
+// objects without source, and objects created by netbox-ssot itself
+// (e.g. default vlan groups).
+func orphanItemSource(orphanItem objects.OrphanItem) string {
+	sourceName, _ := orphanItem.GetNetboxObject().GetCustomField(constants.CustomFieldSourceName).(string)
+	if sourceName == constants.SsotTagName {
+		return ""
+	}
+	return sourceName
+}
@@ -4,7 +4,9 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/src-doo/netbox-ssot/internal/constants"
 	"github.com/src-doo/netbox-ssot/internal/logger"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
 )
 
 func TestNewOrphanManager(t *testing.T) {
@@ -26,3 +28,85 @@ func TestNewOrphanManager(t *testing.T) {
 		})
 	}
 }
+
+func TestOrphanManager_RestoreItems(t *testing.T) {
+	orphanManager := NewOrphanManager(nil)
+	managedDevice := &objects.Device{
+		NetboxObject: objects.NetboxObject{
+			ID:   1,
+			Tags: []*objects.Tag{{Name: constants.SsotTagName}},
+		},
+	}
+	unmanagedDevice := &objects.Device{NetboxObject: objects.NetboxObject{ID: 2}}
+	orphanManager.AddItem(managedDevice)
+	orphanManager.AddItem(unmanagedDevice)
+
+	// Device was seen by a source during the sync
+	orphanManager.RemoveItem(managedDevice)
+	if len(orphanManager.Items[constants.DevicesAPIPath]) != 0 {
+		t.Fatalf("Items = %v, want no devices", orphanManager.Items)
+	}
+
+	orphanManager.RestoreItems()
+	want := map[constants.APIPath]map[int]objects.OrphanItem{
+		constants.DevicesAPIPath: {1: managedDevice},
+	}
+	if !reflect.DeepEqual(orphanManager.Items, want) {
+		t.Errorf("Items after RestoreItems() = %v, want %v", orphanManager.Items, want)
+	}
+
+	// Ssot tag was removed from the device in Netbox
+	orphanManager.AddItem(&objects.Device{NetboxObject: objects.NetboxObject{ID: 1}})
+	orphanManager.RestoreItems()
+	if len(orphanManager.Items[constants.DevicesAPIPath]) != 0 {
+		t.Errorf("Items after RestoreItems() = %v, want no devices", orphanManager.Items)
+	}
+}
+
+func TestOrphanManager_unprotectedItems(t *testing.T) {
+	ssotTags := []*objects.Tag{{Name: constants.SsotTagName}}
+	newVM := func(id int, sourceName string) *objects.VM {
+		vm := &objects.VM{NetboxObject: objects.NetboxObject{ID: id, Tags: ssotTags}}
+		if sourceName != "" {
+			vm.SetCustomField(constants.CustomFieldSourceName, sourceName)
+		}
+		return vm
+	}
+	vmwareVM := newVM(1, "vmware")
+	ovirtVM := newVM(2, "ovirt")
+	sharedVM := newVM(3, "")
+	ssotVM := newVM(4, constants.SsotTagName)
+	orphanManager := NewOrphanManager(nil)
+	for _, vm := range []*objects.VM{vmwareVM, ovirtVM, sharedVM, ssotVM} {
+		orphanManager.AddItem(vm)
+	}
+
+	tests := []struct {
+		name             string
+		protectedSources map[string]bool
+		want             map[int]objects.OrphanItem
+	}{
+		{
+			name: "No protected sources",
+			want: map[int]objects.OrphanItem{1: vmwareVM, 2: ovirtVM, 3: sharedVM, 4: ssotVM},
+		},
+		{
+			name:             "Failed source",
+			protectedSources: map[string]bool{"ovirt": true},
+			want:             map[int]objects.OrphanItem{1: vmwareVM},
+		},
+		{
+			name:             "All sources failed",
+			protectedSources: map[string]bool{"ovirt": true, "vmware": true},
+			want:             map[int]objects.OrphanItem{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := orphanManager.unprotectedItems(constants.VirtualMachinesAPIPath, tt.protectedSources)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OrphanManager.unprotectedItems() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
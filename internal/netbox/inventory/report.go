@@ -0,0 +1,28 @@
+package inventory
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/mapper"
+	"github.com/src-doo/netbox-ssot/internal/report"
+)
+
+// recordChange records what happened with the object received from the
+// source in the run report, if the report is enabled.
+func (nbi *NetboxInventory) recordChange(ctx context.Context, object interface{}, action report.Action) {
+	if nbi.Report == nil {
+		return
+	}
+	objectPath, ok := mapper.Type2Path[reflect.Indirect(reflect.ValueOf(object)).Type()]
+	if !ok {
+		return
+	}
+	sourceName, _ := ctx.Value(constants.CtxSourceKey).(string)
+	idItem, ok := object.(interface{ GetID() int })
+	if !ok {
+		return
+	}
+	nbi.Report.RecordChange(sourceName, objectPath, idItem.GetID(), action)
+}
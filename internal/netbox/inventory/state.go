@@ -0,0 +1,323 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/mapper"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/netbox/service"
+	"github.com/src-doo/netbox-ssot/internal/utils"
+)
+
+// stateVersion is the version of the state file format.
+// State files with a different version are ignored.
+const stateVersion = 1
+
+// inventoryState is a snapshot of all objects collected from Netbox by the
+// init functions. It is saved to netbox.stateFile, so the next Init only
+// collects objects that changed in Netbox since the snapshot was taken.
+type inventoryState struct {
+	Version int `json:"version"`
+	// NetboxURL is the base url of the Netbox the objects were collected from.
+	NetboxURL string `json:"netbox_url"`
+	// Branch is the name of the netbox-branching branch the objects were
+	// collected from. It is empty when branches are not used.
+	Branch string `json:"branch,omitempty"`
+	// Timestamp is the time when the Init, that collected the objects, started.
+	Timestamp time.Time `json:"timestamp"`
+	// Queries are query params used to collect objects of each type. If they
+	// change (e.g. new fields are collected), objects of the type are
+	// collected from scratch.
+	Queries map[constants.APIPath]string `json:"queries"`
+	// Objects are all collected objects, indexed by their api path and id.
+	Objects map[constants.APIPath]map[int]json.RawMessage `json:"objects"`
+}
+
+func newInventoryState(netboxURL string, branch string) *inventoryState {
+	return &inventoryState{
+		Version:   stateVersion,
+		NetboxURL: netboxURL,
+		Branch:    branch,
+		Queries:   make(map[constants.APIPath]string),
+		Objects:   make(map[constants.APIPath]map[int]json.RawMessage),
+	}
+}
+
+// loadState reads the state from the file. Nil state is returned
+// if the file doesn't exist yet. State older than maxAge is rejected,
+// because deletions may have already been removed from Netbox's changelog.
+// State of a different branch is rejected too, because its objects may
+// not exist in the branch.
+func loadState(path string, netboxURL string, branch string, maxAge time.Duration) (*inventoryState, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read state file: %s", err)
+	}
+	state := newInventoryState("", "")
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("parse state file: %s", err)
+	}
+	if state.Version != stateVersion {
+		return nil, fmt.Errorf("state file has version %d, expected %d", state.Version, stateVersion)
+	}
+	if state.NetboxURL != netboxURL {
+		return nil, fmt.Errorf("state file was saved for %s", state.NetboxURL)
+	}
+	if state.Branch != branch {
+		return nil, fmt.Errorf("state file was saved for branch %q", state.Branch)
+	}
+	if state.Timestamp.IsZero() {
+		return nil, errors.New("state file has no timestamp")
+	}
+	if age := time.Since(state.Timestamp); age > maxAge {
+		return nil, fmt.Errorf("state file is %s old, maximum age is %s", age.Round(time.Second), maxAge)
+	}
+	return state, nil
+}
+
+// save writes the state to the file. The state is first written to a
+// temporary file, so an interrupted save doesn't corrupt the existing state.
+func (s *inventoryState) save(path string) error {
+	content, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("marshal state: %s", err)
+	}
+	err = utils.WriteFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(content)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("write state file: %s", err)
+	}
+	return nil
+}
+
+// since returns the time after which objects have to be collected again.
+func (s *inventoryState) since() time.Time {
+	return s.Timestamp.Add(-refreshSafetyMargin)
+}
+
+// store stores objects of the given api path in the state.
+func (s *inventoryState) store(path constants.APIPath, nbObjects interface{}) error {
+	if s.Objects[path] == nil {
+		s.Objects[path] = make(map[int]json.RawMessage)
+	}
+	objectsValue := reflect.ValueOf(nbObjects)
+	for i := 0; i < objectsValue.Len(); i++ {
+		object, ok := objectsValue.Index(i).Addr().Interface().(interface{ GetID() int })
+		if !ok {
+			return fmt.Errorf("object %T has no id", objectsValue.Index(i).Interface())
+		}
+		content, err := json.Marshal(object)
+		if err != nil {
+			return fmt.Errorf("marshal object: %s", err)
+		}
+		s.Objects[path][object.GetID()] = content
+	}
+	return nil
+}
+
+// stateObjects returns all objects of type T stored in the state,
+// ordered by their ids.
+func stateObjects[T any](s *inventoryState, path constants.APIPath) ([]T, error) {
+	ids := make([]int, 0, len(s.Objects[path]))
+	for id := range s.Objects[path] {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	result := make([]T, len(ids))
+	for i, id := range ids {
+		if err := json.Unmarshal(s.Objects[path][id], &result[i]); err != nil {
+			return nil, fmt.Errorf("unmarshal object %d of %s: %s", id, path, err)
+		}
+	}
+	return result, nil
+}
+
+// contentType2Path maps content types of objects to their api paths.
+var contentType2Path = func() map[constants.ContentType]constants.APIPath {
+	result := make(map[constants.ContentType]constants.APIPath, len(mapper.Type2Path))
+	for objectType, path := range mapper.Type2Path {
+		object, ok := reflect.New(objectType).Interface().(interface {
+			GetObjectType() constants.ContentType
+		})
+		if ok {
+			result[object.GetObjectType()] = path
+		}
+	}
+	return result
+}()
+
+// initState prepares the state used by the init functions, when
+// netbox.stateFile is set. If the state file exists, objects deleted in
+// Netbox after the state was saved are removed from the state, and init
+// functions only collect objects updated since then. When the state file
+// is too old, or deletions can't be read from the changelog, all objects
+// are collected from Netbox instead.
+func (nbi *NetboxInventory) initState() {
+	nbi.state = nil
+	if nbi.NetboxConfig.StateFile == "" {
+		return
+	}
+	maxAgeDays := nbi.NetboxConfig.StateMaxAgeDays
+	if maxAgeDays <= 0 {
+		maxAgeDays = constants.DefaultStateMaxAgeDays
+	}
+	maxAge := time.Duration(maxAgeDays) * 24 * time.Hour
+	branch := ""
+	if nbi.branch != nil {
+		branch = nbi.branch.Name
+	}
+	state, err := loadState(nbi.NetboxConfig.StateFile, nbi.NetboxAPI.BaseURL, branch, maxAge)
+	if err != nil {
+		nbi.Logger.Warningf(nbi.Ctx, "Ignoring state file %s: %s", nbi.NetboxConfig.StateFile, err)
+	}
+	if state != nil {
+		if err := nbi.removeDeletedObjects(nbi.Ctx, state); err != nil {
+			nbi.Logger.Warningf(
+				nbi.Ctx,
+				"Ignoring state file %s, deleted objects can't be read from changelog: %s",
+				nbi.NetboxConfig.StateFile,
+				err,
+			)
+			state = nil
+		}
+	}
+	if state == nil {
+		nbi.Logger.Infof(nbi.Ctx, "No usable state, collecting all objects from Netbox")
+		nbi.state = newInventoryState(nbi.NetboxAPI.BaseURL, branch)
+		return
+	}
+	nbi.Logger.Infof(
+		nbi.Ctx,
+		"Collecting objects changed since %s, the rest is loaded from state file %s",
+		state.since().Format(time.RFC3339),
+		nbi.NetboxConfig.StateFile,
+	)
+	nbi.state = state
+}
+
+// saveState saves objects collected by the init functions that started
+// at startTime to the state file. Failing to save the state is not fatal,
+// the next Init then starts from the previous state file, if there is one.
+func (nbi *NetboxInventory) saveState(startTime time.Time) {
+	if nbi.state == nil {
+		return
+	}
+	nbi.state.Timestamp = startTime
+	if err := nbi.state.save(nbi.NetboxConfig.StateFile); err != nil {
+		nbi.Logger.Warningf(nbi.Ctx, "Save state file: %s", err)
+		return
+	}
+	nbi.Logger.Debugf(nbi.Ctx, "Saved state to %s", nbi.NetboxConfig.StateFile)
+}
+
+// removeDeletedObjects removes objects deleted in Netbox after the state
+// was saved from the state. Deletions are collected from Netbox's changelog.
+func (nbi *NetboxInventory) removeDeletedObjects(ctx context.Context, state *inventoryState) error {
+	extraArgs := fmt.Sprintf(
+		"&fields=%s&action=%s&time_after=%s",
+		utils.ExtractJSONTagsFromStructIntoString(objects.ObjectChange{}),
+		objects.ObjectChangeActionDelete.Value,
+		url.QueryEscape(state.since().UTC().Format(time.RFC3339)),
+	)
+	deletions, err := service.GetAll[objects.ObjectChange](ctx, nbi.NetboxAPI, extraArgs)
+	if err != nil {
+		return err
+	}
+	removed := 0
+	for _, deletion := range deletions {
+		path, ok := contentType2Path[deletion.ChangedObjectType]
+		if !ok {
+			continue
+		}
+		if _, ok := state.Objects[path][deletion.ChangedObjectID]; ok {
+			delete(state.Objects[path], deletion.ChangedObjectID)
+			removed++
+		}
+	}
+	nbi.Logger.Debugf(ctx, "Removed %d objects deleted in Netbox from state", removed)
+	return nil
+}
+
+// getAll collects all objects of type T from Netbox. When the state is used,
+// collected objects are also stored in it. If the state already contains
+// objects of type T, only objects updated after the state was saved are
+// collected from Netbox, and all objects of type T in the state are returned.
+//
+// During Refresh only updated objects are collected and returned.
+func getAll[T any](ctx context.Context, nbi *NetboxInventory, extraArgs string) ([]T, error) {
+	if !nbi.refreshSince.IsZero() {
+		return service.GetAll[T](ctx, nbi.NetboxAPI, extraArgs+nbi.refreshFilter())
+	}
+	if nbi.state == nil {
+		return collectAll[T](ctx, nbi, extraArgs)
+	}
+	var dummy T
+	path := mapper.Type2Path[reflect.TypeOf(dummy)]
+	query, ok := nbi.state.Queries[path]
+	if nbi.state.Timestamp.IsZero() || !ok || query != extraArgs {
+		nbObjects, err := collectAll[T](ctx, nbi, extraArgs)
+		if err != nil {
+			return nil, err
+		}
+		delete(nbi.state.Objects, path)
+		if err := nbi.state.store(path, nbObjects); err != nil {
+			return nil, err
+		}
+		nbi.state.Queries[path] = extraArgs
+		return nbObjects, nil
+	}
+
+	updatedObjects, err := service.GetAll[T](
+		ctx,
+		nbi.NetboxAPI,
+		extraArgs+updatedSinceFilter(nbi.state.since()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := nbi.state.store(path, updatedObjects); err != nil {
+		return nil, err
+	}
+	nbi.Logger.Debugf(
+		ctx,
+		"Collected %d updated objects of %s, %d objects in total",
+		len(updatedObjects),
+		path,
+		len(nbi.state.Objects[path]),
+	)
+	return stateObjects[T](nbi.state, path)
+}
+
+// collectAll collects all objects of type T from Netbox. When netbox.graphQL
+// is enabled, objects are collected with GraphQL API. REST API is used as a
+// fallback for object types that GraphQL loader doesn't support, and when
+// collecting objects with GraphQL API fails.
+func collectAll[T any](ctx context.Context, nbi *NetboxInventory, extraArgs string) ([]T, error) {
+	if nbi.NetboxConfig.GraphQL {
+		nbObjects, err := service.GraphQLGetAll[T](ctx, nbi.NetboxAPI)
+		if err == nil {
+			return nbObjects, nil
+		}
+		if !errors.Is(err, service.ErrGraphQLUnsupported) {
+			var dummy T
+			nbi.Logger.Warningf(ctx, "Collecting %T with GraphQL failed, falling back to REST: %s", dummy, err)
+		}
+	}
+	return service.GetAll[T](ctx, nbi.NetboxAPI, extraArgs)
+}
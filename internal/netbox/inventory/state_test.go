@@ -0,0 +1,278 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/logger"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/netbox/service"
+	"github.com/src-doo/netbox-ssot/internal/parser"
+)
+
+func TestLoadState(t *testing.T) {
+	dir := t.TempDir()
+	netboxURL := "https://netbox.example.com"
+	validState := newInventoryState(netboxURL, "ssot")
+	validState.Timestamp = time.Now().UTC().Truncate(time.Second)
+	validState.Queries[constants.SitesAPIPath] = "&fields=id,name"
+	validState.Objects[constants.SitesAPIPath] = map[int]json.RawMessage{1: json.RawMessage(`{"id":1}`)}
+	if err := validState.save(filepath.Join(dir, "valid.json")); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	oldState := newInventoryState(netboxURL, "ssot")
+	oldState.Version = stateVersion - 1
+	oldState.Timestamp = validState.Timestamp
+	if err := oldState.save(filepath.Join(dir, "old.json")); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	expiredState := newInventoryState(netboxURL, "ssot")
+	expiredState.Timestamp = validState.Timestamp.Add(-48 * time.Hour)
+	if err := expiredState.save(filepath.Join(dir, "expired.json")); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "invalid.json"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		filename  string
+		netboxURL string
+		branch    string
+		want      *inventoryState
+		wantErr   bool
+	}{
+		{name: "valid state", filename: "valid.json", netboxURL: netboxURL, branch: "ssot", want: validState},
+		{name: "missing state", filename: "missing.json", netboxURL: netboxURL, branch: "ssot"},
+		{name: "other netbox", filename: "valid.json", netboxURL: "https://other.example.com", branch: "ssot", wantErr: true},
+		{name: "other branch", filename: "valid.json", netboxURL: netboxURL, branch: "main", wantErr: true},
+		{name: "expired state", filename: "expired.json", netboxURL: netboxURL, branch: "ssot", wantErr: true},
+		{name: "old version", filename: "old.json", netboxURL: netboxURL, branch: "ssot", wantErr: true},
+		{name: "invalid json", filename: "invalid.json", netboxURL: netboxURL, branch: "ssot", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadState(filepath.Join(dir, tt.filename), tt.netboxURL, tt.branch, 24*time.Hour)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadState() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadState() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(matches) > 0 {
+		t.Errorf("temporary state files were left behind: %v", matches)
+	}
+}
+
+// stateTestServer serves sites and deletions from the changelog.
+// Sites updated after the state was saved are returned when the
+// last_updated__gte filter is used.
+type stateTestServer struct {
+	sites        []objects.Site
+	updatedSites []objects.Site
+	deletions    []objects.ObjectChange
+	// changelogForbidden rejects reading of the changelog, as for tokens
+	// without permissions for core.objectchange.
+	changelogForbidden bool
+	queries            []string
+}
+
+func (s *stateTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.queries = append(s.queries, r.URL.Path+"?"+r.URL.RawQuery)
+	var results interface{}
+	switch {
+	case r.URL.Path == string(constants.ObjectChangesAPIPath):
+		if s.changelogForbidden {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		results = s.deletions
+	case r.URL.Query().Has("last_updated__gte"):
+		results = s.updatedSites
+	default:
+		results = s.sites
+	}
+	response := map[string]interface{}{"count": reflect.ValueOf(results).Len(), "results": results}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+func TestNetboxInventory_getAllWithState(t *testing.T) {
+	server := &stateTestServer{
+		sites: []objects.Site{
+			{NetboxObject: objects.NetboxObject{ID: 1}, Name: "site1"},
+			{NetboxObject: objects.NetboxObject{ID: 2}, Name: "site2"},
+			{NetboxObject: objects.NetboxObject{ID: 3}, Name: "site3"},
+		},
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	testLogger := &logger.Logger{Logger: log.New(os.Stdout, "", log.LstdFlags)}
+	ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "test")
+	nbi := &NetboxInventory{
+		Logger:       testLogger,
+		NetboxConfig: &parser.NetboxConfig{StateFile: filepath.Join(t.TempDir(), "state.json")},
+		Ctx:          ctx,
+		NetboxAPI: &service.NetboxClient{
+			HTTPClient: &http.Client{},
+			Logger:     testLogger,
+			BaseURL:    httpServer.URL,
+			Timeout:    constants.DefaultAPITimeout,
+		},
+	}
+
+	// Without saved state all sites are collected
+	nbi.initState()
+	sites, err := getAll[objects.Site](ctx, nbi, "&fields=id,name")
+	if err != nil {
+		t.Fatalf("getAll() error = %v", err)
+	}
+	if !reflect.DeepEqual(sites, server.sites) {
+		t.Errorf("getAll() = %v, want %v", sites, server.sites)
+	}
+	nbi.saveState(time.Now())
+
+	// With saved state only updated sites are collected, and deleted ones are removed
+	server.updatedSites = []objects.Site{
+		{NetboxObject: objects.NetboxObject{ID: 4}, Name: "site4"},
+		{NetboxObject: objects.NetboxObject{ID: 2}, Name: "site2-renamed"},
+	}
+	server.deletions = []objects.ObjectChange{
+		{ChangedObjectType: constants.ContentTypeDcimSite, ChangedObjectID: 3},
+		{ChangedObjectType: constants.ContentTypeDcimDevice, ChangedObjectID: 1},
+	}
+	server.queries = nil
+	nbi.initState()
+	sites, err = getAll[objects.Site](ctx, nbi, "&fields=id,name")
+	if err != nil {
+		t.Fatalf("getAll() error = %v", err)
+	}
+	want := []objects.Site{server.sites[0], server.updatedSites[1], server.updatedSites[0]}
+	if !reflect.DeepEqual(sites, want) {
+		t.Errorf("getAll() = %v, want %v", sites, want)
+	}
+	if len(server.queries) != 2 ||
+		!strings.Contains(server.queries[0], "action=delete") ||
+		!strings.Contains(server.queries[1], "last_updated__gte=") {
+		t.Errorf("queries = %v, want changelog deletions and updated sites", server.queries)
+	}
+
+	// Sites are collected from scratch, when collected fields change
+	server.queries = nil
+	sites, err = getAll[objects.Site](ctx, nbi, "&fields=id,name,slug")
+	if err != nil {
+		t.Fatalf("getAll() error = %v", err)
+	}
+	if !reflect.DeepEqual(sites, server.sites) {
+		t.Errorf("getAll() = %v, want %v", sites, server.sites)
+	}
+	if len(server.queries) != 1 || strings.Contains(server.queries[0], "last_updated__gte=") {
+		t.Errorf("queries = %v, want a single query of all sites", server.queries)
+	}
+	nbi.saveState(time.Now())
+
+	// All sites are collected, when deletions can't be read from the changelog
+	server.changelogForbidden = true
+	server.queries = nil
+	nbi.initState()
+	sites, err = getAll[objects.Site](ctx, nbi, "&fields=id,name,slug")
+	if err != nil {
+		t.Fatalf("getAll() error = %v", err)
+	}
+	if !reflect.DeepEqual(sites, server.sites) {
+		t.Errorf("getAll() = %v, want %v", sites, server.sites)
+	}
+	if len(server.queries) != 2 || strings.Contains(server.queries[1], "last_updated__gte=") {
+		t.Errorf("queries = %v, want changelog deletions and a query of all sites", server.queries)
+	}
+}
+
+func TestCollectAll(t *testing.T) {
+	tests := []struct {
+		name        string
+		graphQL     bool
+		graphQLCode int
+		wantName    string
+		wantPaths   []string
+	}{
+		{
+			name:      "rest",
+			wantName:  "rest",
+			wantPaths: []string{string(constants.DevicesAPIPath)},
+		},
+		{
+			name:        "graphql",
+			graphQL:     true,
+			graphQLCode: http.StatusOK,
+			wantName:    "graphql",
+			wantPaths:   []string{"/graphql/"},
+		},
+		{
+			name:        "rest fallback",
+			graphQL:     true,
+			graphQLCode: http.StatusNotFound,
+			wantName:    "rest",
+			wantPaths:   []string{"/graphql/", string(constants.DevicesAPIPath)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.URL.Path)
+				var response interface{}
+				if r.URL.Path == "/graphql/" {
+					w.WriteHeader(tt.graphQLCode)
+					response = map[string]interface{}{
+						"data": map[string]interface{}{
+							"device_list": []interface{}{map[string]interface{}{"id": "1", "name": "graphql"}},
+						},
+					}
+				} else {
+					response = map[string]interface{}{
+						"count":   1,
+						"results": []interface{}{map[string]interface{}{"id": 1, "name": "rest"}},
+					}
+				}
+				_ = json.NewEncoder(w).Encode(response)
+			}))
+			defer httpServer.Close()
+			testLogger := &logger.Logger{Logger: log.New(os.Stdout, "", log.LstdFlags)}
+			nbi := &NetboxInventory{
+				Logger:       testLogger,
+				NetboxConfig: &parser.NetboxConfig{GraphQL: tt.graphQL},
+				NetboxAPI: &service.NetboxClient{
+					HTTPClient: &http.Client{},
+					Logger:     testLogger,
+					BaseURL:    httpServer.URL,
+					Timeout:    constants.DefaultAPITimeout,
+				},
+			}
+
+			devices, err := collectAll[objects.Device](context.Background(), nbi, "")
+			if err != nil {
+				t.Fatalf("collectAll() error = %v", err)
+			}
+			if len(devices) != 1 || devices[0].Name != tt.wantName {
+				t.Errorf("collectAll() = %v, want device %s", devices, tt.wantName)
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("requested paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
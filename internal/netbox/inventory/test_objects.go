@@ -4,7 +4,6 @@ import (
 	"context"
 	"log"
 	"os"
-	"sync"
 
 	"github.com/src-doo/netbox-ssot/internal/constants"
 	"github.com/src-doo/netbox-ssot/internal/logger"
@@ -63,15 +62,22 @@ var MockExistingSites = map[string]*objects.Site{
 	},
 }
 
+// mockIndex returns an index with the given objects.
+func mockIndex[K comparable, T objects.OrphanItem](key func(T) K, items map[string]T) *Index[K, T] {
+	index := NewIndex(key, nil)
+	for _, item := range items {
+		index.Upsert(item)
+	}
+	return index
+}
+
 var MockInventory = &NetboxInventory{
-	Logger:             &logger.Logger{Logger: log.New(os.Stdout, "", log.LstdFlags)},
-	tagsIndexByName:    MockExistingTags,
-	tagsLock:           sync.Mutex{},
-	tenantsIndexByName: MockExistingTenants,
-	tenantsLock:        sync.Mutex{},
-	sitesIndexByName:   MockExistingSites,
-	sitesLock:          sync.Mutex{},
-	NetboxAPI:          service.MockNetboxClient,
+	Logger:    &logger.Logger{Logger: log.New(os.Stdout, "", log.LstdFlags)},
+	tags:      mockIndex(tagKey, MockExistingTags),
+	tenants:   mockIndex(tenantKey, MockExistingTenants),
+	sites:     mockIndex(siteKey, MockExistingSites),
+	devices:   NewIndex(deviceKey, nil),
+	NetboxAPI: service.MockNetboxClient,
 	Ctx: context.WithValue(
 		context.Background(),
 		constants.CtxSourceKey,
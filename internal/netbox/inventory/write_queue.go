@@ -0,0 +1,522 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/mapper"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/netbox/service"
+)
+
+// bulkObjectType is an object type, whose creates and patches are sent
+// to Netbox in bulk requests, when bulk writes are enabled.
+type bulkObjectType struct {
+	path   constants.APIPath
+	create func(context.Context, *service.NetboxClient, []interface{}, int) ([]interface{}, error)
+	patch  func(context.Context, *service.NetboxClient, []map[string]interface{}, int) ([]interface{}, error)
+}
+
+// bulkObjectTypes are object types with bulk writes, in the order
+// in which their queued patches are sent.
+var bulkObjectTypes = []bulkObjectType{
+	newBulkObjectType[objects.Device](constants.DevicesAPIPath),
+	newBulkObjectType[objects.VM](constants.VirtualMachinesAPIPath),
+	newBulkObjectType[objects.Interface](constants.InterfacesAPIPath),
+	newBulkObjectType[objects.VMInterface](constants.VMInterfacesAPIPath),
+	newBulkObjectType[objects.VirtualDisk](constants.VirtualDisksAPIPath),
+	newBulkObjectType[objects.MACAddress](constants.MACAddressesAPIPath),
+	newBulkObjectType[objects.IPAddress](constants.IPAddressesAPIPath),
+}
+
+func newBulkObjectType[T any](path constants.APIPath) bulkObjectType {
+	return bulkObjectType{
+		path: path,
+		create: func(
+			ctx context.Context,
+			api *service.NetboxClient,
+			queued []interface{},
+			batchSize int,
+		) ([]interface{}, error) {
+			newObjects := make([]*T, 0, len(queued))
+			for _, object := range queued {
+				newObjects = append(newObjects, object.(*T)) //nolint:forcetypeassert
+			}
+			created, err := service.BulkCreate(ctx, api, newObjects, batchSize)
+			return toInterfaces(created), err
+		},
+		patch: func(
+			ctx context.Context,
+			api *service.NetboxClient,
+			bodies []map[string]interface{},
+			batchSize int,
+		) ([]interface{}, error) {
+			patched, err := service.BulkPatch[T](ctx, api, bodies, batchSize)
+			return toInterfaces(patched), err
+		},
+	}
+}
+
+func toInterfaces[T any](objects []*T) []interface{} {
+	result := make([]interface{}, 0, len(objects))
+	for _, object := range objects {
+		result = append(result, object)
+	}
+	return result
+}
+
+// writeQueue sends creates and patches of objects of bulkObjectTypes
+// to Netbox in bulk requests.
+//
+// Creates are never deferred: the first create is sent right away, and
+// creates of the same object type and source, that are added while its
+// request is in flight (e.g. by other goroutines of the source), wait for
+// it and are then sent together in the next request. So Add* functions
+// return created objects with their Netbox ids, and objects only ever
+// reference objects, that already exist in Netbox.
+//
+// Patches of existing objects are queued, until the source flushes them
+// with FlushWrites, or until the queue of the source is full.
+type writeQueue struct {
+	mu        sync.Mutex
+	batchSize int
+	creates   map[createBatchKey]*createBatch
+	patches   map[constants.APIPath][]*queuedPatch
+	// patchesIndex is used for merging multiple patches of the same object.
+	patchesIndex map[constants.APIPath]map[int]*queuedPatch
+}
+
+// createBatchKey are creates of objects of the same type from
+// the same source, which are sent in the same requests.
+type createBatchKey struct {
+	path   constants.APIPath
+	source string
+}
+
+// createBatch holds creates, that wait for the create request in flight.
+type createBatch struct {
+	// sending is true while a create request is in flight.
+	sending bool
+	waiting []*queuedCreate
+}
+
+type queuedCreate struct {
+	object  interface{}
+	created interface{}
+	err     error
+	// send is set, when the create is first of the waiting creates
+	// once the request in flight is finished, so it sends the next one.
+	send bool
+	done chan struct{}
+}
+
+type queuedPatch struct {
+	source   string
+	objectID int
+	body     map[string]interface{}
+	// store replaces the old object in the indexes with the patched object.
+	store func(patched interface{})
+}
+
+func newWriteQueue(batchSize int) *writeQueue {
+	return &writeQueue{
+		batchSize:    batchSize,
+		creates:      make(map[createBatchKey]*createBatch),
+		patches:      make(map[constants.APIPath][]*queuedPatch),
+		patchesIndex: make(map[constants.APIPath]map[int]*queuedPatch),
+	}
+}
+
+// create creates the object in Netbox. With bulk writes, objects of
+// bulkObjectTypes are created together with other objects of the index,
+// that are created by the same source in the meantime (see writeQueue).
+// The index, which must be locked, is unlocked while the object waits for
+// its request, and the object is marked as pending, so the objects with the
+// same key wait for it to be created (see Index.LookupPending).
+func create[T any, K comparable, PT interface {
+	*T
+	objects.OrphanItem
+}](ctx context.Context, nbi *NetboxInventory, index *Index[K, PT], key K, object PT) (PT, error) {
+	if _, ok := bulkObjectTypeIndex(object); nbi.writeQueue == nil || !ok {
+		return service.Create(ctx, nbi.NetboxAPI, (*T)(object))
+	}
+	done := index.AddPending(key, object)
+	index.Unlock()
+	created, err := nbi.writeQueue.create(ctx, nbi.NetboxAPI, object)
+	index.Lock()
+	done()
+	if err != nil {
+		return nil, err
+	}
+	return created.(PT), nil //nolint:forcetypeassert
+}
+
+// patch patches the object with objectID in Netbox. When bulk writes are
+// enabled, patches of bulkObjectTypes are only queued, and oldObject with
+// diffMap applied is returned. References set by diffMap are taken from
+// newObject. store is then called with the patched object, so it replaces
+// oldObject in the indexes.
+func patch[T any](
+	ctx context.Context,
+	nbi *NetboxInventory,
+	oldObject *T,
+	newObject *T,
+	objectID int,
+	diffMap map[string]interface{},
+	store func(*T),
+) (*T, error) {
+	if _, ok := bulkObjectTypeIndex(oldObject); nbi.writeQueue == nil || !ok {
+		return service.Patch[T](ctx, nbi.NetboxAPI, objectID, diffMap)
+	}
+	patchedObject, err := service.ApplyPatch(oldObject, diffMap)
+	if err != nil {
+		return nil, fmt.Errorf("apply patch to %v: %s", oldObject, err)
+	}
+	withReferencesOf(patchedObject, newObject)
+	err = nbi.writeQueue.queuePatch(ctx, oldObject, objectID, diffMap, func(patched interface{}) {
+		store(patched.(*T)) //nolint:forcetypeassert
+	})
+	if err != nil {
+		return nil, err
+	}
+	return patchedObject, nil
+}
+
+// withReferencesOf replaces references of the patched object, which only
+// have their ids set, with the references to the same objects of object.
+func withReferencesOf(patched interface{}, object interface{}) {
+	references := make(map[reflect.Type]map[int]reflect.Value)
+	walkReferences(reflect.ValueOf(object).Elem(), func(reference reflect.Value) reflect.Value {
+		if references[reference.Type()] == nil {
+			references[reference.Type()] = make(map[int]reflect.Value)
+		}
+		references[reference.Type()][referencedID(reference)] = reference
+		return reflect.Value{}
+	})
+	walkReferences(reflect.ValueOf(patched).Elem(), func(reference reflect.Value) reflect.Value {
+		return references[reference.Type()][referencedID(reference)]
+	})
+}
+
+// flushFullWrites sends queued patches of the source in ctx, when there
+// are already as many queued patches of objects of the object's type, as
+// are sent in a single request. It must be called before the index of
+// the object is locked, because flushing locks indexes of bulkObjectTypes.
+func (nbi *NetboxInventory) flushFullWrites(ctx context.Context, object interface{}) error {
+	if nbi.writeQueue == nil {
+		return nil
+	}
+	sourceName, _ := ctx.Value(constants.CtxSourceKey).(string)
+	if !nbi.writeQueue.full(sourceName, object) {
+		return nil
+	}
+	return nbi.FlushWrites(ctx)
+}
+
+// FlushWrites sends queued patches of the source in ctx to Netbox, using
+// bulk requests. It is a no-op if bulk writes are disabled.
+func (nbi *NetboxInventory) FlushWrites(ctx context.Context) error {
+	if nbi.writeQueue == nil {
+		return nil
+	}
+	sourceName, _ := ctx.Value(constants.CtxSourceKey).(string)
+	patches := nbi.writeQueue.takePatches(func(source string) bool { return source == sourceName })
+	if len(patches) == 0 {
+		return nil
+	}
+	for _, lock := range nbi.writeQueueLocks() {
+		lock.Lock()
+		defer lock.Unlock()
+	}
+	nbi.Logger.Debugf(ctx, "Flushing queued patches to Netbox")
+	var errs []error
+	for _, objectType := range bulkObjectTypes {
+		if err := nbi.flushPatches(ctx, objectType, patches[objectType.path]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// dropWrites drops patches, that are still queued once all sources are
+// finished. Sources flush their writes once they are synced, so these are
+// writes of sources that failed, and they are not sent to Netbox.
+// Returned are numbers of dropped writes by their sources.
+func (nbi *NetboxInventory) dropWrites() map[string]int {
+	if nbi.writeQueue == nil {
+		return nil
+	}
+	dropped := make(map[string]int)
+	for _, queuedPatches := range nbi.writeQueue.takePatches(func(string) bool { return true }) {
+		for _, queued := range queuedPatches {
+			dropped[queued.source]++
+		}
+	}
+	return dropped
+}
+
+// writeQueueLocks returns locks of all indexes, that are changed when
+// queued patches are flushed.
+func (nbi *NetboxInventory) writeQueueLocks() []sync.Locker {
+	return []sync.Locker{
+		nbi.devices,
+		nbi.vms,
+		nbi.interfaces,
+		nbi.vmInterfaces,
+		nbi.virtualDisks,
+		nbi.macAddresses,
+		nbi.ipAddresses,
+	}
+}
+
+// flushPatches sends queued patches of the objectType.
+func (nbi *NetboxInventory) flushPatches(
+	ctx context.Context,
+	objectType bulkObjectType,
+	patches []*queuedPatch,
+) error {
+	if len(patches) == 0 {
+		return nil
+	}
+	bodies := make([]map[string]interface{}, 0, len(patches))
+	for _, queued := range patches {
+		body := make(map[string]interface{}, len(queued.body)+1)
+		for k, v := range queued.body {
+			body[k] = v
+		}
+		body["id"] = queued.objectID
+		bodies = append(bodies, body)
+	}
+	patched, err := objectType.patch(ctx, nbi.NetboxAPI, bodies, nbi.writeQueue.batchSize)
+	if err != nil {
+		return fmt.Errorf("bulk patch %d objects of %s: %s", len(patches), objectType.path, err)
+	}
+	for i, queued := range patches {
+		queued.store(patched[i])
+	}
+	return nil
+}
+
+// create sends the create of the object in the next create request of the
+// object's type and source, and returns the created object (see writeQueue).
+func (q *writeQueue) create(ctx context.Context, api *service.NetboxClient, object interface{}) (interface{}, error) {
+	objectType, ok := bulkObjectTypeIndex(object)
+	if !ok {
+		return nil, fmt.Errorf("bulk create of %T is not supported", object)
+	}
+	path := bulkObjectTypes[objectType].path
+	sourceName, _ := ctx.Value(constants.CtxSourceKey).(string)
+	queued := &queuedCreate{object: object, done: make(chan struct{})}
+
+	q.mu.Lock()
+	key := createBatchKey{path: path, source: sourceName}
+	batch, ok := q.creates[key]
+	if !ok {
+		batch = &createBatch{}
+		q.creates[key] = batch
+	}
+	batch.waiting = append(batch.waiting, queued)
+	if batch.sending {
+		q.mu.Unlock()
+		<-queued.done
+		if !queued.send {
+			return queued.created, queued.err
+		}
+		q.mu.Lock()
+	}
+	// Queued create is the first of the waiting creates, so it is sent
+	// together with the creates that arrived while it was waiting
+	batch.sending = true
+	sent := slices.Clone(batch.waiting[:min(len(batch.waiting), q.batchSize)])
+	batch.waiting = batch.waiting[len(sent):]
+	q.mu.Unlock()
+
+	queuedObjects := make([]interface{}, 0, len(sent))
+	for _, sentCreate := range sent {
+		queuedObjects = append(queuedObjects, sentCreate.object)
+	}
+	created, err := bulkObjectTypes[objectType].create(ctx, api, queuedObjects, q.batchSize)
+	for i, sentCreate := range sent {
+		if i < len(created) {
+			sentCreate.created = created[i]
+			continue
+		}
+		if err == nil {
+			err = fmt.Errorf("got %d created objects", len(created))
+		}
+		sentCreate.err = fmt.Errorf("bulk create %d objects of %s: %s", len(sent)-len(created), path, err)
+	}
+
+	q.mu.Lock()
+	if len(batch.waiting) > 0 {
+		next := batch.waiting[0]
+		next.send = true
+		close(next.done)
+	} else {
+		batch.sending = false
+	}
+	q.mu.Unlock()
+	for _, sentCreate := range sent {
+		if sentCreate != queued {
+			close(sentCreate.done)
+		}
+	}
+	return queued.created, queued.err
+}
+
+// queuePatch queues patch of the object with the objectID. Multiple
+// patches of the same object are merged into a single patch.
+func (q *writeQueue) queuePatch(
+	ctx context.Context,
+	object interface{},
+	objectID int,
+	body map[string]interface{},
+	store func(interface{}),
+) error {
+	objectType, ok := bulkObjectTypeIndex(object)
+	if !ok {
+		return fmt.Errorf("bulk patch of %T is not supported", object)
+	}
+	path := bulkObjectTypes[objectType].path
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if queued, ok := q.patchesIndex[path][objectID]; ok {
+		for k, v := range body {
+			queued.body[k] = v
+		}
+		queued.store = store
+		return nil
+	}
+	sourceName, _ := ctx.Value(constants.CtxSourceKey).(string)
+	queued := &queuedPatch{
+		source:   sourceName,
+		objectID: objectID,
+		body:     make(map[string]interface{}, len(body)),
+		store:    store,
+	}
+	for k, v := range body {
+		queued.body[k] = v
+	}
+	if q.patchesIndex[path] == nil {
+		q.patchesIndex[path] = make(map[int]*queuedPatch)
+	}
+	q.patchesIndex[path][objectID] = queued
+	q.patches[path] = append(q.patches[path], queued)
+	return nil
+}
+
+// takePatches removes queued patches of sources, for which include
+// returns true, from the queue and returns them.
+func (q *writeQueue) takePatches(include func(source string) bool) map[constants.APIPath][]*queuedPatch {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	patches := make(map[constants.APIPath][]*queuedPatch)
+	for path, queuedPatches := range q.patches {
+		kept := make([]*queuedPatch, 0, len(queuedPatches))
+		for _, queued := range queuedPatches {
+			if !include(queued.source) {
+				kept = append(kept, queued)
+				continue
+			}
+			patches[path] = append(patches[path], queued)
+			delete(q.patchesIndex[path], queued.objectID)
+		}
+		q.patches[path] = kept
+	}
+	return patches
+}
+
+// full returns true, if the source has as many queued patches of objects
+// of the object's type, as are sent in a single request.
+func (q *writeQueue) full(source string, object interface{}) bool {
+	objectType, ok := bulkObjectTypeIndex(object)
+	if !ok {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	count := 0
+	for _, queued := range q.patches[bulkObjectTypes[objectType].path] {
+		if queued.source == source {
+			count++
+		}
+	}
+	return count >= q.batchSize
+}
+
+// walkReferences calls replace for every reference of the struct to other
+// objects, i.e. pointers to objects with id. If replace
+// returns a valid value, the reference is replaced with it. Slices are copied
+// before their elements are replaced.
+func walkReferences(object reflect.Value, replace func(reference reflect.Value) reflect.Value) {
+	for i := 0; i < object.NumField(); i++ {
+		field := object.Field(i)
+		fieldType := object.Type().Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		switch {
+		case fieldType.Anonymous && field.Kind() == reflect.Struct:
+			walkReferences(field, replace)
+		case isObjectReference(field.Type()):
+			if field.IsNil() {
+				continue
+			}
+			if replacement := replace(field); replacement.IsValid() {
+				field.Set(replacement)
+			}
+		case field.Kind() == reflect.Slice && isObjectReference(field.Type().Elem()):
+			copied := false
+			for j := 0; j < field.Len(); j++ {
+				if field.Index(j).IsNil() {
+					continue
+				}
+				replacement := replace(field.Index(j))
+				if !replacement.IsValid() {
+					continue
+				}
+				if !copied {
+					fieldCopy := reflect.MakeSlice(field.Type(), field.Len(), field.Len())
+					reflect.Copy(fieldCopy, field)
+					field.Set(fieldCopy)
+					copied = true
+				}
+				field.Index(j).Set(replacement)
+			}
+		}
+	}
+}
+
+// referencedID returns the id from the reference found by walkReferences.
+func referencedID(reference reflect.Value) int {
+	return int(reference.Elem().FieldByName("ID").Int())
+}
+
+// isObjectReference returns true for pointers to structs with int id field.
+func isObjectReference(t reflect.Type) bool {
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	idField, ok := t.Elem().FieldByName("ID")
+	return ok && idField.Type.Kind() == reflect.Int
+}
+
+// bulkObjectTypeIndex returns position of the object's type in bulkObjectTypes.
+func bulkObjectTypeIndex(object interface{}) (int, bool) {
+	objectType := reflect.TypeOf(object)
+	if objectType.Kind() == reflect.Ptr {
+		objectType = objectType.Elem()
+	}
+	path := mapper.Type2Path[objectType]
+	for i, bulkType := range bulkObjectTypes {
+		if bulkType.path == path {
+			return i, true
+		}
+	}
+	return 0, false
+}
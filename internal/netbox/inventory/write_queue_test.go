@@ -0,0 +1,398 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/logger"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/netbox/service"
+	"github.com/src-doo/netbox-ssot/internal/parser"
+)
+
+// bulkTestServer is a minimal Netbox, that supports bulk create
+// and bulk update of devices and interfaces.
+type bulkTestServer struct {
+	mu sync.Mutex
+	// failValue fails requests with objects with a field of this value.
+	failValue string
+	// blockCreate, if set, receives the first create request and blocks it,
+	// until it is closed.
+	blockCreate chan struct{}
+	lastID      int
+	objects     map[int]map[string]interface{}
+	requests    map[string][][]map[string]interface{}
+}
+
+func (s *bulkTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	if blockCreate := s.blockCreate; blockCreate != nil && r.Method == http.MethodPost {
+		s.blockCreate = nil
+		s.mu.Unlock()
+		blockCreate <- struct{}{}
+		<-blockCreate
+		s.mu.Lock()
+	}
+	defer s.mu.Unlock()
+	var body []map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.requests[r.Method+" "+r.URL.Path] = append(s.requests[r.Method+" "+r.URL.Path], body)
+	for _, fields := range body {
+		for _, value := range fields {
+			if s.failValue != "" && value == s.failValue {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	response := make([]map[string]interface{}, 0, len(body))
+	for _, fields := range body {
+		var object map[string]interface{}
+		if r.Method == http.MethodPost {
+			s.lastID++
+			object = map[string]interface{}{"id": s.lastID}
+			s.objects[s.lastID] = object
+		} else {
+			object = s.objects[int(fields["id"].(float64))]
+		}
+		for _, key := range []string{"name", "serial", "custom_fields"} {
+			if value, ok := fields[key]; ok {
+				object[key] = value
+			}
+		}
+		for _, key := range []string{"site", "device"} {
+			if id, ok := fields[key]; ok {
+				// References are either ids, or objects with id
+				if _, isObject := id.(map[string]interface{}); isObject {
+					object[key] = id
+				} else {
+					object[key] = map[string]interface{}{"id": id}
+				}
+			}
+		}
+		response = append(response, object)
+	}
+	if r.Method == http.MethodPost {
+		w.WriteHeader(http.StatusCreated)
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+// sentNames returns names of objects in the server's requests.
+func (s *bulkTestServer) sentNames(request string) [][]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names [][]interface{}
+	for _, body := range s.requests[request] {
+		requestNames := make([]interface{}, 0, len(body))
+		for _, fields := range body {
+			requestNames = append(requestNames, fields["name"])
+		}
+		names = append(names, requestNames)
+	}
+	return names
+}
+
+func newBulkTestInventory(t *testing.T, bulkSize int) (*NetboxInventory, *bulkTestServer) {
+	t.Helper()
+	server := &bulkTestServer{
+		lastID:   100,
+		objects:  make(map[int]map[string]interface{}),
+		requests: make(map[string][][]map[string]interface{}),
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	testLogger := &logger.Logger{Logger: log.New(os.Stdout, "", log.LstdFlags)}
+	nbi := &NetboxInventory{
+		Logger:        testLogger,
+		NetboxConfig:  &parser.NetboxConfig{BulkSize: bulkSize},
+		OrphanManager: NewOrphanManager(testLogger),
+		SsotTag:       &objects.Tag{ID: 1, Name: constants.SsotTagName},
+		Ctx:           context.WithValue(context.Background(), constants.CtxSourceKey, "test"),
+		NetboxAPI: &service.NetboxClient{
+			HTTPClient: &http.Client{},
+			Logger:     testLogger,
+			BaseURL:    httpServer.URL,
+			APIToken:   "testtoken",
+			Timeout:    constants.DefaultAPITimeout,
+		},
+		writeQueue: newWriteQueue(bulkSize),
+	}
+	nbi.resetIndexes()
+	return nbi, server
+}
+
+func TestNetboxInventory_BulkCreate(t *testing.T) {
+	nbi, server := newBulkTestInventory(t, 10)
+	blockCreate := make(chan struct{})
+	server.blockCreate = blockCreate
+	ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "test")
+	site := &objects.Site{NetboxObject: objects.NetboxObject{ID: 1}, Name: "site"}
+
+	var wg sync.WaitGroup
+	devices := make(map[string]*objects.Device)
+	var devicesMu sync.Mutex
+	addDevice := func(name string) {
+		defer wg.Done()
+		device, err := nbi.AddDevice(ctx, &objects.Device{Name: name, Site: site})
+		if err != nil {
+			t.Errorf("AddDevice(%s) error = %v", name, err)
+			return
+		}
+		devicesMu.Lock()
+		defer devicesMu.Unlock()
+		devices[name] = device
+	}
+	wg.Add(1)
+	go addDevice("host1")
+	// Devices added while the first create request is in flight
+	// wait for it, and are then created together
+	<-blockCreate
+	for _, name := range []string{"host2", "host3"} {
+		wg.Add(1)
+		go addDevice(name)
+	}
+	for waitingCreates(nbi.writeQueue, constants.DevicesAPIPath, "test") != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	close(blockCreate)
+	wg.Wait()
+
+	got := server.sentNames("POST /api/dcim/devices/")
+	if len(got) != 2 || len(got[0]) != 1 || len(got[1]) != 2 {
+		t.Fatalf("devices create requests = %v, want [[host1] [host2 host3]]", got)
+	}
+	// Devices have their Netbox ids before the flush, so sources
+	// can use them as references right away
+	for name, device := range devices {
+		if device.ID <= 100 {
+			t.Errorf("AddDevice(%s) = device with id %d, want id of created device", name, device.ID)
+		}
+		if got := nbi.GetDeviceByID(device.ID); got != device {
+			t.Errorf("GetDeviceByID(%d) = %v, want %v", device.ID, got, device)
+		}
+	}
+	iface, err := nbi.AddInterface(ctx, &objects.Interface{Name: "eth0", Device: devices["host3"]})
+	if err != nil {
+		t.Fatalf("AddInterface() error = %v", err)
+	}
+	if got, ok := nbi.GetInterface("eth0", devices["host3"].ID); !ok || got != iface {
+		t.Errorf("GetInterface() = %v, want %v", got, iface)
+	}
+	interfaceRequests := server.requests["POST /api/dcim/interfaces/"]
+	if len(interfaceRequests) != 1 || interfaceRequests[0][0]["device"] != float64(devices["host3"].ID) {
+		t.Errorf("interfaces create requests = %v, want interface of device %d", interfaceRequests, devices["host3"].ID)
+	}
+}
+
+func TestNetboxInventory_BulkCreateFailure(t *testing.T) {
+	nbi, server := newBulkTestInventory(t, 10)
+	server.failValue = "host1"
+	ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "test")
+	site := &objects.Site{NetboxObject: objects.NetboxObject{ID: 1}, Name: "site"}
+
+	_, err := nbi.AddDevice(ctx, &objects.Device{Name: "host1", Site: site})
+	if err == nil || !strings.Contains(err.Error(), "bulk create 1 objects of /api/dcim/devices/") {
+		t.Fatalf("AddDevice() error = %v, want error of bulk create", err)
+	}
+	if got, ok := nbi.GetDevice("host1", site.ID); ok {
+		t.Errorf("GetDevice() = %v, want no device", got)
+	}
+	server.failValue = ""
+	device, err := nbi.AddDevice(ctx, &objects.Device{Name: "host1", Site: site})
+	if err != nil || device.ID != 101 {
+		t.Errorf("AddDevice() = %v, %v, want created device with id 101", device, err)
+	}
+}
+
+func TestNetboxInventory_FlushWrites(t *testing.T) {
+	nbi, server := newBulkTestInventory(t, 10)
+	ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "test")
+	site := &objects.Site{NetboxObject: objects.NetboxObject{ID: 1}, Name: "site"}
+	for _, name := range []string{"host1", "host2"} {
+		if _, err := nbi.AddDevice(ctx, &objects.Device{Name: name, Site: site}); err != nil {
+			t.Fatalf("AddDevice() error = %v", err)
+		}
+	}
+	for _, device := range []*objects.Device{
+		{Name: "host1", Site: site, SerialNumber: "1234"},
+		{Name: "host2", Site: site, SerialNumber: "5678"},
+	} {
+		if _, err := nbi.AddDevice(ctx, device); err != nil {
+			t.Fatalf("AddDevice() error = %v", err)
+		}
+	}
+	if got := len(server.requests["PATCH /api/dcim/devices/"]); got != 0 {
+		t.Fatalf("patches were sent before flush in %d requests", got)
+	}
+
+	if err := nbi.FlushWrites(ctx); err != nil {
+		t.Fatalf("FlushWrites() error = %v", err)
+	}
+	patchRequests := server.requests["PATCH /api/dcim/devices/"]
+	if len(patchRequests) != 1 || len(patchRequests[0]) != 2 {
+		t.Fatalf("devices patch requests = %v, want a single request with 2 devices", patchRequests)
+	}
+	if got, ok := nbi.GetDevice("host2", site.ID); !ok || got.SerialNumber != "5678" {
+		t.Errorf("GetDevice() after flush = %v, want device with serial 5678", got)
+	}
+
+	// Failed patch is returned by the flush
+	server.failValue = "invalid"
+	if _, err := nbi.AddDevice(ctx, &objects.Device{Name: "host1", Site: site, SerialNumber: "invalid"}); err != nil {
+		t.Fatalf("AddDevice() error = %v", err)
+	}
+	err := nbi.FlushWrites(ctx)
+	if err == nil || !strings.Contains(err.Error(), "bulk patch 1 objects of /api/dcim/devices/") {
+		t.Errorf("FlushWrites() error = %v, want error of bulk patch", err)
+	}
+}
+
+func TestNetboxInventory_FlushWritesOfSource(t *testing.T) {
+	nbi, server := newBulkTestInventory(t, 10)
+	ctxA := context.WithValue(context.Background(), constants.CtxSourceKey, "a")
+	ctxB := context.WithValue(context.Background(), constants.CtxSourceKey, "b")
+	site := &objects.Site{NetboxObject: objects.NetboxObject{ID: 1}, Name: "site"}
+	for _, device := range []struct {
+		ctx  context.Context
+		name string
+	}{{ctxA, "host1"}, {ctxB, "host2"}} {
+		if _, err := nbi.AddDevice(device.ctx, &objects.Device{Name: device.name, Site: site}); err != nil {
+			t.Fatalf("AddDevice() error = %v", err)
+		}
+		patched := &objects.Device{Name: device.name, Site: site, SerialNumber: device.name}
+		if _, err := nbi.AddDevice(device.ctx, patched); err != nil {
+			t.Fatalf("AddDevice() error = %v", err)
+		}
+	}
+
+	if err := nbi.FlushWrites(ctxA); err != nil {
+		t.Fatalf("FlushWrites() of source a error = %v", err)
+	}
+	patchRequests := server.requests["PATCH /api/dcim/devices/"]
+	if len(patchRequests) != 1 || len(patchRequests[0]) != 1 || patchRequests[0][0]["id"] != float64(101) {
+		t.Errorf("devices patch requests = %v, want only patch of device 101", patchRequests)
+	}
+	if err := nbi.FlushWrites(ctxB); err != nil {
+		t.Fatalf("FlushWrites() of source b error = %v", err)
+	}
+	if got := len(server.requests["PATCH /api/dcim/devices/"]); got != 2 {
+		t.Errorf("devices were patched in %d requests, want 2", got)
+	}
+}
+
+func TestNetboxInventory_FlushWritesWhenFull(t *testing.T) {
+	nbi, server := newBulkTestInventory(t, 2)
+	ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "test")
+	site := &objects.Site{NetboxObject: objects.NetboxObject{ID: 1}, Name: "site"}
+	names := []string{"host1", "host2", "host3"}
+	for _, name := range names {
+		if _, err := nbi.AddDevice(ctx, &objects.Device{Name: name, Site: site}); err != nil {
+			t.Fatalf("AddDevice() error = %v", err)
+		}
+	}
+	for _, name := range names {
+		if _, err := nbi.AddDevice(ctx, &objects.Device{Name: name, Site: site, SerialNumber: name}); err != nil {
+			t.Fatalf("AddDevice() error = %v", err)
+		}
+	}
+	requests := server.requests["PATCH /api/dcim/devices/"]
+	if len(requests) != 1 || len(requests[0]) != 2 {
+		t.Errorf("devices patch requests = %v, want a single request with 2 devices", requests)
+	}
+}
+
+func TestNetboxInventory_QueuedPatch(t *testing.T) {
+	nbi, server := newBulkTestInventory(t, 10)
+	ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "test")
+	site1 := &objects.Site{NetboxObject: objects.NetboxObject{ID: 1}, Name: "site1"}
+	site2 := &objects.Site{NetboxObject: objects.NetboxObject{ID: 2}, Name: "site2"}
+	newDevice := func(site *objects.Site, serialNumber string) *objects.Device {
+		device := &objects.Device{Name: "host", Site: site, SerialNumber: serialNumber}
+		device.SetCustomField(constants.CustomFieldSourceName, "test")
+		device.SetCustomField(constants.CustomFieldSourceIDName, "host-1")
+		return device
+	}
+	if _, err := nbi.AddDevice(ctx, newDevice(site1, "")); err != nil {
+		t.Fatalf("AddDevice() error = %v", err)
+	}
+	if err := nbi.FlushWrites(ctx); err != nil {
+		t.Fatalf("FlushWrites() error = %v", err)
+	}
+
+	// Queued patch returns the device as it is after the patch
+	device, err := nbi.AddDevice(ctx, newDevice(site2, "1234"))
+	if err != nil {
+		t.Fatalf("AddDevice() error = %v", err)
+	}
+	if device.ID != 101 || device.SerialNumber != "1234" || device.Site != site2 {
+		t.Errorf("AddDevice() = %+v, want device 101 with serial 1234 moved to site2", device)
+	}
+	if got := nbi.GetDeviceByID(101); got != device {
+		t.Errorf("GetDeviceByID() = %v, want the patched device", got)
+	}
+	if len(server.requests["PATCH /api/dcim/devices/"]) != 0 {
+		t.Fatalf("patch was sent before flush")
+	}
+	if err := nbi.FlushWrites(ctx); err != nil {
+		t.Fatalf("FlushWrites() error = %v", err)
+	}
+	patchRequests := server.requests["PATCH /api/dcim/devices/"]
+	wantSite := map[string]interface{}{"id": float64(2)}
+	if len(patchRequests) != 1 || !reflect.DeepEqual(patchRequests[0][0]["site"], wantSite) {
+		t.Errorf("devices patch requests = %v, want site patch of device 101", patchRequests)
+	}
+}
+
+func TestNetboxInventory_DeleteOrphansDropsQueuedWrites(t *testing.T) {
+	nbi, server := newBulkTestInventory(t, 10)
+	ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "failed")
+	site := &objects.Site{NetboxObject: objects.NetboxObject{ID: 1}, Name: "site"}
+	if _, err := nbi.AddDevice(ctx, &objects.Device{Name: "host", Site: site}); err != nil {
+		t.Fatalf("AddDevice() error = %v", err)
+	}
+	if _, err := nbi.AddDevice(ctx, &objects.Device{Name: "host", Site: site, SerialNumber: "1234"}); err != nil {
+		t.Fatalf("AddDevice() error = %v", err)
+	}
+
+	// Source failed before it flushed its writes
+	if err := nbi.DeleteOrphans(nbi.Ctx, true, []string{"failed"}); err != nil {
+		t.Fatalf("DeleteOrphans() error = %v", err)
+	}
+	if err := nbi.FlushWrites(ctx); err != nil {
+		t.Errorf("FlushWrites() error = %v, want nil for dropped writes", err)
+	}
+	if got := server.requests["PATCH /api/dcim/devices/"]; len(got) != 0 {
+		t.Errorf("queued patches of failed source were sent: %v", got)
+	}
+	if got := server.objects[101]["serial"]; got != nil {
+		t.Errorf("device in Netbox has serial %v, want none", got)
+	}
+}
+
+// waitingCreates returns number of creates of the source, that wait
+// for the create request in flight.
+func waitingCreates(queue *writeQueue, path constants.APIPath, source string) int {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	batch, ok := queue.creates[createBatchKey{path: path, source: source}]
+	if !ok {
+		return 0
+	}
+	return len(batch.waiting)
+}
@@ -0,0 +1,43 @@
+package objects
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+)
+
+type ObjectChangeAction struct {
+	Choice
+}
+
+// Predefined changelog actions, see
+// https://github.com/netbox-community/netbox/blob/v4.2.0/netbox/core/choices.py
+var (
+	ObjectChangeActionCreate = ObjectChangeAction{Choice{Value: "create", Label: "Created"}}
+	ObjectChangeActionUpdate = ObjectChangeAction{Choice{Value: "update", Label: "Updated"}}
+	ObjectChangeActionDelete = ObjectChangeAction{Choice{Value: "delete", Label: "Deleted"}}
+)
+
+// ObjectChange is an entry of Netbox's changelog. It records a single
+// create, update or delete of an object.
+type ObjectChange struct {
+	ID int `json:"id,omitempty"`
+	// Time when the change was made.
+	Time time.Time `json:"time,omitempty"`
+	// Action is the type of the change.
+	Action *ObjectChangeAction `json:"action,omitempty"`
+	// ChangedObjectType is the content type of the changed object (e.g. dcim.device).
+	ChangedObjectType constants.ContentType `json:"changed_object_type,omitempty"`
+	// ChangedObjectID is the id of the changed object.
+	ChangedObjectID int `json:"changed_object_id,omitempty"`
+}
+
+func (oc ObjectChange) String() string {
+	return fmt.Sprintf(
+		"ObjectChange{Action: %s, ChangedObjectType: %s, ChangedObjectID: %d}",
+		oc.Action,
+		oc.ChangedObjectType,
+		oc.ChangedObjectID,
+	)
+}
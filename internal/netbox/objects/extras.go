@@ -30,6 +30,12 @@ func (t *Tag) GetAPIPath() constants.APIPath {
 	return constants.TagsAPIPath
 }
 
+// Tag implements OrphanItem interface. Tags are not netbox objects,
+// so it returns nil.
+func (t *Tag) GetNetboxObject() *NetboxObject {
+	return nil
+}
+
 // CustomFieldTypes are predefined netbox's types for CustomFields.
 type CustomFieldType struct {
 	Choice
@@ -125,3 +131,44 @@ func (cf *CustomField) GetObjectType() constants.ContentType {
 func (cf *CustomField) GetAPIPath() constants.APIPath {
 	return constants.CustomFieldsAPIPath
 }
+
+// CustomField implements OrphanItem interface. Custom fields are not
+// netbox objects, so it returns nil.
+func (cf *CustomField) GetNetboxObject() *NetboxObject {
+	return nil
+}
+
+type JournalEntryKind struct {
+	Choice
+}
+
+// Predefined kinds of journal entries, see
+// https://github.com/netbox-community/netbox/blob/v4.2.0/netbox/extras/choices.py
+var (
+	JournalEntryKindInfo    = JournalEntryKind{Choice{Value: "info", Label: "Info"}}
+	JournalEntryKindSuccess = JournalEntryKind{Choice{Value: "success", Label: "Success"}}
+	JournalEntryKindWarning = JournalEntryKind{Choice{Value: "warning", Label: "Warning"}}
+	JournalEntryKindDanger  = JournalEntryKind{Choice{Value: "danger", Label: "Danger"}}
+)
+
+// JournalEntry is a comment attached to a Netbox object.
+type JournalEntry struct {
+	ID int `json:"id,omitempty"`
+	// AssignedObjectType is the content type of the object (e.g. dcim.device).
+	AssignedObjectType constants.ContentType `json:"assigned_object_type,omitempty"`
+	// AssignedObjectID is the id of the object.
+	AssignedObjectID int `json:"assigned_object_id,omitempty"`
+	// Kind of the entry. Default is info.
+	Kind *JournalEntryKind `json:"kind,omitempty"`
+	// Comments of the entry. Markdown is supported.
+	Comments string `json:"comments,omitempty"`
+}
+
+func (je JournalEntry) String() string {
+	return fmt.Sprintf(
+		"JournalEntry{AssignedObjectType: %s, AssignedObjectID: %d, Kind: %s}",
+		je.AssignedObjectType,
+		je.AssignedObjectID,
+		je.Kind,
+	)
+}
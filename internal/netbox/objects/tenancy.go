@@ -82,6 +82,9 @@ func (cg *ContactGroup) GetID() int {
 func (cg *ContactGroup) GetObjectType() constants.ContentType {
 	return constants.ContentTypeTenancyContactGroup
 }
+func (cg *ContactGroup) GetAPIPath() constants.APIPath {
+	return constants.ContactGroupsAPIPath
+}
 
 // ContactGroup implements OrphanItem interface.
 func (cg *ContactGroup) GetNetboxObject() *NetboxObject {
@@ -114,6 +117,9 @@ func (cr *ContactRole) GetID() int {
 func (cr *ContactRole) GetObjectType() constants.ContentType {
 	return constants.ContentTypeTenancyContactRole
 }
+func (cr *ContactRole) GetAPIPath() constants.APIPath {
+	return constants.ContactRolesAPIPath
+}
 
 // ContactRole implements OrphanItem interface.
 func (cr *ContactRole) GetNetboxObject() *NetboxObject {
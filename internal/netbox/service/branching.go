@@ -0,0 +1,258 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+)
+
+// BranchHeader is the header used by netbox-branching plugin to select the
+// branch that a request is applied to. Its value is the schema id of the branch.
+const BranchHeader = "X-NetBox-Branch"
+
+// branchingAPIPrefix is the prefix of api paths of netbox-branching plugin.
+const branchingAPIPrefix = "/api/plugins/branching/"
+
+// Statuses of branches, see
+// https://github.com/netboxlabs/netbox-branching/blob/main/netbox_branching/choices.py
+const (
+	branchStatusNew          = "new"
+	branchStatusProvisioning = "provisioning"
+	branchStatusReady        = "ready"
+	branchStatusSyncing      = "syncing"
+	branchStatusMigrating    = "migrating"
+	branchStatusMerged       = "merged"
+	branchStatusArchived     = "archived"
+)
+
+// Branches are provisioned asynchronously, so their status is polled until
+// they are ready. These are variables, so tests can shorten them.
+var (
+	branchPollInterval = 2 * time.Second
+	branchReadyTimeout = 5 * time.Minute
+)
+
+// Branch is a branch of netbox-branching plugin.
+type Branch struct {
+	ID       int            `json:"id"`
+	Name     string         `json:"name"`
+	SchemaID string         `json:"schema_id"`
+	Status   objects.Choice `json:"status"`
+}
+
+func (b Branch) String() string {
+	return fmt.Sprintf("Branch{Name: %s, SchemaID: %s, Status: %s}", b.Name, b.SchemaID, b.Status)
+}
+
+// BranchName returns the name of the branch for the run started at runTime,
+// by replacing placeholders in the branch name template.
+func BranchName(template string, runTime time.Time) string {
+	return strings.NewReplacer(
+		constants.BranchDatePlaceholder, runTime.Format(time.DateOnly),
+		constants.BranchTimePlaceholder, runTime.Format("150405"),
+	).Replace(template)
+}
+
+// UseBranch creates the branch with the given name, or reuses the existing
+// one, and waits until it is ready. All further requests are then sent to
+// the branch, so changes can be reviewed in Netbox before they are merged.
+//
+// Merged and archived branches can't be used anymore, so a new branch with
+// a numeric suffix is used instead (e.g. ssot-2025-01-31-2).
+func (api *NetboxClient) UseBranch(ctx context.Context, name string) (*Branch, error) {
+	api.Branch = ""
+	var branch *Branch
+	var err error
+	for suffix := 1; ; suffix++ {
+		candidate := name
+		if suffix > 1 {
+			candidate = fmt.Sprintf("%s-%d", name, suffix)
+		}
+		branch, err = api.getBranchByName(ctx, candidate)
+		if err != nil {
+			return nil, fmt.Errorf("get branch: %s", err)
+		}
+		if branch == nil || !isClosedBranch(branch) {
+			name = candidate
+			break
+		}
+		api.Logger.Debugf(ctx, "Branch %s is %s, trying the next one", branch.Name, branch.Status.Value)
+	}
+	if branch == nil {
+		api.Logger.Infof(ctx, "Creating branch %s", name)
+		branch, err = api.createBranch(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("create branch: %s", err)
+		}
+	} else {
+		api.Logger.Infof(ctx, "Reusing existing branch %s", name)
+	}
+	branch, err = api.waitForBranch(ctx, branch)
+	if err != nil {
+		return nil, err
+	}
+	api.Branch = branch.SchemaID
+	return branch, nil
+}
+
+// isClosedBranch returns true for branches, that can't be used anymore.
+func isClosedBranch(branch *Branch) bool {
+	return branch.Status.Value == branchStatusMerged || branch.Status.Value == branchStatusArchived
+}
+
+// BranchChangeCount returns the number of changes made in the branch.
+func (api *NetboxClient) BranchChangeCount(ctx context.Context, branch *Branch) (int, error) {
+	response, err := api.doRequest(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s?branch_id=%d&limit=1", constants.BranchChangesAPIPath, branch.ID),
+		nil,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code %d: %s", response.StatusCode, response.Body)
+	}
+	var changes Response[json.RawMessage]
+	if err := json.Unmarshal(response.Body, &changes); err != nil {
+		return 0, fmt.Errorf("unmarshal changes: %s", err)
+	}
+	return changes.Count, nil
+}
+
+// MergeBranch merges the branch into main. Merging is done asynchronously
+// by Netbox, so the branch may not be merged yet, when MergeBranch returns.
+// Requests are no longer sent to the branch afterwards.
+func (api *NetboxClient) MergeBranch(ctx context.Context, branch *Branch) error {
+	requestBody, err := json.Marshal(map[string]interface{}{"commit": true})
+	if err != nil {
+		return err
+	}
+	response, err := api.doRequest(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s%d/merge/", constants.BranchesAPIPath, branch.ID),
+		bytes.NewBuffer(requestBody),
+	)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("unexpected status code %d: %s", response.StatusCode, response.Body)
+	}
+	api.Branch = ""
+	return nil
+}
+
+// getBranchByName returns the branch with the given name,
+// or nil if it doesn't exist.
+func (api *NetboxClient) getBranchByName(ctx context.Context, name string) (*Branch, error) {
+	response, err := api.doRequest(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s?name=%s", constants.BranchesAPIPath, url.QueryEscape(name)),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("branching plugin is not installed in Netbox")
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d: %s", response.StatusCode, response.Body)
+	}
+	var branches Response[Branch]
+	if err := json.Unmarshal(response.Body, &branches); err != nil {
+		return nil, fmt.Errorf("unmarshal branches: %s", err)
+	}
+	for i := range branches.Results {
+		if branches.Results[i].Name == name {
+			return &branches.Results[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (api *NetboxClient) getBranch(ctx context.Context, id int) (*Branch, error) {
+	response, err := api.doRequest(ctx, http.MethodGet, fmt.Sprintf("%s%d/", constants.BranchesAPIPath, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d: %s", response.StatusCode, response.Body)
+	}
+	var branch Branch
+	if err := json.Unmarshal(response.Body, &branch); err != nil {
+		return nil, fmt.Errorf("unmarshal branch: %s", err)
+	}
+	return &branch, nil
+}
+
+func (api *NetboxClient) createBranch(ctx context.Context, name string) (*Branch, error) {
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"name":        name,
+		"description": "Branch created by netbox-ssot",
+	})
+	if err != nil {
+		return nil, err
+	}
+	response, err := api.doRequest(
+		ctx,
+		http.MethodPost,
+		string(constants.BranchesAPIPath),
+		bytes.NewBuffer(requestBody),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("unexpected status code %d: %s", response.StatusCode, response.Body)
+	}
+	var branch Branch
+	if err := json.Unmarshal(response.Body, &branch); err != nil {
+		return nil, fmt.Errorf("unmarshal branch: %s", err)
+	}
+	return &branch, nil
+}
+
+// waitForBranch polls the branch until it is ready. Branches that are
+// merged, archived or failed can't be used anymore.
+func (api *NetboxClient) waitForBranch(ctx context.Context, branch *Branch) (*Branch, error) {
+	deadline := time.Now().Add(branchReadyTimeout)
+	for {
+		switch branch.Status.Value {
+		case branchStatusReady:
+			return branch, nil
+		case branchStatusNew, branchStatusProvisioning, branchStatusSyncing, branchStatusMigrating:
+		default:
+			return nil, fmt.Errorf(
+				"branch %s can't be used, because it is %s. Use a different branch name (e.g. with %s)",
+				branch.Name,
+				branch.Status.Value,
+				constants.BranchTimePlaceholder,
+			)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("branch %s is not ready after %s", branch.Name, branchReadyTimeout)
+		}
+		api.Logger.Debugf(ctx, "Waiting for branch %s, that is %s", branch.Name, branch.Status.Value)
+		if err := sleep(ctx, branchPollInterval); err != nil {
+			return nil, err
+		}
+		var err error
+		branch, err = api.getBranch(ctx, branch.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get branch: %s", err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+)
+
+func TestBranchName(t *testing.T) {
+	runTime := time.Date(2025, 1, 31, 14, 30, 5, 0, time.UTC)
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{template: "ssot", want: "ssot"},
+		{template: "ssot-{{date}}", want: "ssot-2025-01-31"},
+		{template: "ssot-{{date}}-{{time}}", want: "ssot-2025-01-31-143005"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			if got := BranchName(tt.template, runTime); got != tt.want {
+				t.Errorf("BranchName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetboxClient_UseBranch(t *testing.T) {
+	defaultPollInterval := branchPollInterval
+	branchPollInterval = time.Millisecond
+	defer func() { branchPollInterval = defaultPollInterval }()
+	server := fakenetbox.NewServer()
+	defer server.Close()
+	api := &NetboxClient{
+		HTTPClient: &http.Client{},
+		Logger:     MockNetboxClient.Logger,
+		BaseURL:    server.URL,
+		Timeout:    constants.DefaultAPITimeout,
+	}
+	ctx := context.Background()
+
+	branch, err := api.UseBranch(ctx, "ssot-2025-01-31")
+	if err != nil {
+		t.Fatalf("UseBranch() error = %v", err)
+	}
+	if branch.Status.Value != branchStatusReady || api.Branch == "" || api.Branch != branch.SchemaID {
+		t.Errorf("UseBranch() = %v, client branch %s, want ready branch", branch, api.Branch)
+	}
+	// Existing branch is reused
+	reused, err := api.UseBranch(ctx, "ssot-2025-01-31")
+	if err != nil {
+		t.Fatalf("UseBranch() error = %v", err)
+	}
+	if reused.ID != branch.ID || len(server.Branches()) != 1 {
+		t.Errorf("branches = %v, want a single branch", server.Branches())
+	}
+
+	// Objects are changed in the branch, and branches are requested in main
+	if _, err := Create(ctx, api, &objects.Tag{Name: "ssot", Slug: "ssot"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	changeCount, err := api.BranchChangeCount(ctx, branch)
+	if err != nil || changeCount != 1 {
+		t.Errorf("BranchChangeCount() = %d, %v, want 1", changeCount, err)
+	}
+	for _, request := range server.Requests() {
+		header := request.Header.Get(BranchHeader)
+		switch {
+		case strings.HasPrefix(request.Path, branchingAPIPrefix) && header != "":
+			t.Errorf("%s was requested with branch header %q", request.Path, header)
+		case request.Path == string(constants.TagsAPIPath) && header != branch.SchemaID:
+			t.Errorf("tags were requested with branch header %q, want %s", header, branch.SchemaID)
+		}
+	}
+
+	if err := api.MergeBranch(ctx, branch); err != nil {
+		t.Fatalf("MergeBranch() error = %v", err)
+	}
+	if status := server.Branches()[0].Status; status != fakenetbox.BranchStatusMerged || api.Branch != "" {
+		t.Errorf("branch is %s, client branch %q, want merged branch", status, api.Branch)
+	}
+
+	// Merged branch is replaced with a new one
+	next, err := api.UseBranch(ctx, "ssot-2025-01-31")
+	if err != nil {
+		t.Fatalf("UseBranch() error = %v", err)
+	}
+	if next.Name != "ssot-2025-01-31-2" || next.Status.Value != branchStatusReady {
+		t.Errorf("UseBranch() = %v, want ready branch ssot-2025-01-31-2", next)
+	}
+
+	// Failed branch can't be used
+	server.AddBranch("ssot-failed", "failed")
+	if _, err := api.UseBranch(ctx, "ssot-failed"); err == nil {
+		t.Errorf("UseBranch() of failed branch succeeded")
+	}
+}
@@ -1,13 +1,18 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/src-doo/netbox-ssot/internal/logger"
+	"github.com/src-doo/netbox-ssot/internal/metrics"
+	"github.com/src-doo/netbox-ssot/internal/netbox/version"
 	"github.com/src-doo/netbox-ssot/internal/utils"
 )
 
@@ -19,7 +24,30 @@ type NetboxClient struct {
 	BaseURL    string
 	APIToken   string
 	Timeout    int // in seconds
-	MaxRetires int
+	// MaxRetries is the number of times idempotent requests are retried
+	// after transient errors.
+	MaxRetries int
+	// PageSize is the number of objects fetched in a single page by GetAll.
+	// Default page size is used if it is not positive.
+	PageSize int
+	// PageWorkers is the number of pages fetched concurrently by GetAll.
+	// Pages are fetched one by one if it is not positive.
+	PageWorkers int
+	// Plan is set when running in dry-run mode. In that case all
+	// write requests are recorded in the plan instead of being sent.
+	Plan *Plan
+	// Branch is the schema id of the netbox-branching branch. When set,
+	// all requests are sent to the branch instead of main. See UseBranch.
+	Branch string
+	// RunID identifies the current run. It is sent as RunIDHeader on every
+	// request, and written in journal entries. See NewRunID.
+	RunID string
+	// JournalEntries enables writing of a journal entry on each created and
+	// patched object, that summarizes the changes.
+	JournalEntries bool
+	// Capabilities of the connected Netbox release. Request bodies and
+	// responses are adapted to them. All capabilities are assumed when nil.
+	Capabilities *version.Capabilities
 }
 
 // APIResponse is a struct that represents a response from the Netbox API.
@@ -33,11 +61,10 @@ func NewNetboxClient(
 	logger *logger.Logger,
 	baseURL string,
 	apiToken string,
-	validateCert bool,
 	timeout int,
-	caCert string,
+	httpConfig utils.HTTPClientConfig,
 ) (*NetboxClient, error) {
-	httpClient, err := utils.NewHTTPClient(validateCert, caCert)
+	httpClient, err := utils.NewHTTPClient(httpConfig)
 	if err != nil {
 		return nil, fmt.Errorf("create new HTTP client: %s", err)
 	}
@@ -50,39 +77,137 @@ func NewNetboxClient(
 	}, nil
 }
 
+// doRequest sends the request to the Netbox API. Request is canceled when
+// ctx is canceled, or when a single attempt takes longer than the configured timeout.
+//
+// Idempotent requests are retried up to MaxRetries times, when they fail
+// without a response or with a transient status code (429, 502, 503, 504).
+// A retried DELETE, that fails with 404, succeeded on a previous attempt.
+// Retries are delayed with exponential backoff, or as requested by the
+// Retry-After header.
 func (api *NetboxClient) doRequest(
+	ctx context.Context,
 	method string,
 	path string,
 	body io.Reader,
 ) (*APIResponse, error) {
+	maxRetries := 0
+	if isIdempotent(method, body != nil) {
+		maxRetries = api.MaxRetries
+	}
+	return api.doRequestWithRetries(ctx, method, path, body, maxRetries)
+}
+
+// doRequestWithRetries sends the request to the Netbox API like doRequest,
+// but retries it up to maxRetries times regardless of its method. It is used
+// for requests that are not idempotent by method, but are safe to repeat
+// (e.g. GraphQL queries).
+func (api *NetboxClient) doRequestWithRetries(
+	ctx context.Context,
+	method string,
+	path string,
+	body io.Reader,
+	maxRetries int,
+) (*APIResponse, error) {
+	// Body is buffered, so it can be sent again on retries
+	var bodyBytes []byte
+	if body != nil && maxRetries > 0 {
+		var err error
+		bodyBytes, err = io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if bodyBytes != nil {
+			body = bytes.NewReader(bodyBytes)
+		}
+		response, header, err := api.doRequestOnce(ctx, method, path, body)
+		if err == nil && isDeletedOnRetry(method, attempt, response) {
+			api.Logger.Debugf(ctx, "%s %s: object was already deleted by a previous attempt", method, path)
+			response.StatusCode = http.StatusNoContent
+			return response, nil
+		}
+		if attempt >= maxRetries {
+			return response, err
+		}
+		var wait time.Duration
+		switch {
+		case err != nil && isRetryableError(ctx, err):
+			wait = retryBackoff.Duration(attempt)
+		case err == nil && isRetryableStatus(response.StatusCode):
+			var ok bool
+			if wait, ok = retryAfter(header, time.Now()); !ok {
+				wait = retryBackoff.Duration(attempt)
+			}
+			err = fmt.Errorf("unexpected status code %d", response.StatusCode)
+		default:
+			return response, err
+		}
+		api.Logger.Warningf(
+			ctx,
+			"%s %s failed: %s. Retrying in %s (retry %d/%d)",
+			method,
+			path,
+			err,
+			wait.Round(time.Millisecond),
+			attempt+1,
+			maxRetries,
+		)
+		metrics.APIRetries.WithLabelValues(method).Inc()
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// doRequestOnce sends a single request to the Netbox API.
+func (api *NetboxClient) doRequestOnce(
+	ctx context.Context,
+	method string,
+	path string,
+	body io.Reader,
+) (*APIResponse, http.Header, error) {
 	ctx, cancelCtx := context.WithTimeout(
-		context.Background(),
+		ctx,
 		time.Second*time.Duration(api.Timeout),
 	)
 	defer cancelCtx()
 
 	req, err := http.NewRequestWithContext(ctx, method, api.BaseURL+path, body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// We add necessary headers to the request
 	req.Header.Add("Authorization", "Token "+api.APIToken)
 	req.Header.Add("Content-Type", "application/json")
+	// Branches themselves are managed in main
+	if api.Branch != "" && !strings.HasPrefix(path, branchingAPIPrefix) {
+		req.Header.Add(BranchHeader, api.Branch)
+	}
+	if api.RunID != "" {
+		req.Header.Add(RunIDHeader, api.RunID)
+	}
 
+	requestStart := time.Now()
 	resp, err := api.HTTPClient.Do(req)
+	metrics.APIRequestDuration.WithLabelValues(method).Observe(time.Since(requestStart).Seconds())
 	if err != nil {
-		return nil, err
+		metrics.APIRequests.WithLabelValues(method, metrics.StatusCodeError).Inc()
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
+	metrics.APIRequests.WithLabelValues(method, strconv.Itoa(resp.StatusCode)).Inc()
 
 	responseBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	return &APIResponse{
 		StatusCode: resp.StatusCode,
 		Body:       responseBody,
-	}, nil
+	}, resp.Header, nil
 }
@@ -1,25 +1,33 @@
 package service
 
 import (
+	"context"
 	"crypto/tls"
 	"io"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/src-doo/netbox-ssot/internal/constants"
 	"github.com/src-doo/netbox-ssot/internal/logger"
+	"github.com/src-doo/netbox-ssot/internal/metrics"
+	"github.com/src-doo/netbox-ssot/internal/utils"
 )
 
 func TestNewNetBoxAPI(t *testing.T) {
 	type args struct {
-		logger       *logger.Logger
-		baseURL      string
-		apiToken     string
-		validateCert bool
-		timeout      int
-		caCert       string
+		logger     *logger.Logger
+		baseURL    string
+		apiToken   string
+		timeout    int
+		httpConfig utils.HTTPClientConfig
 	}
 	tests := []struct {
 		name string
@@ -29,12 +37,11 @@ func TestNewNetBoxAPI(t *testing.T) {
 		{
 			name: "test new API creation without ssl verify",
 			args: args{
-				logger:       &logger.Logger{Logger: log.Default()},
-				baseURL:      "netbox.example.com",
-				apiToken:     "apitoken",
-				validateCert: false,
-				timeout:      constants.DefaultAPITimeout,
-				caCert:       "",
+				logger:     &logger.Logger{Logger: log.Default()},
+				baseURL:    "netbox.example.com",
+				apiToken:   "apitoken",
+				timeout:    constants.DefaultAPITimeout,
+				httpConfig: utils.HTTPClientConfig{ValidateCert: false},
 			},
 			want: &NetboxClient{
 				Logger:   &logger.Logger{Logger: log.Default()},
@@ -51,12 +58,11 @@ func TestNewNetBoxAPI(t *testing.T) {
 		{
 			name: "test new API creation with ssl verify",
 			args: args{
-				logger:       &logger.Logger{Logger: log.Default()},
-				baseURL:      "netbox.example.com",
-				apiToken:     "apitoken",
-				validateCert: true,
-				timeout:      constants.DefaultAPITimeout,
-				caCert:       "",
+				logger:     &logger.Logger{Logger: log.Default()},
+				baseURL:    "netbox.example.com",
+				apiToken:   "apitoken",
+				timeout:    constants.DefaultAPITimeout,
+				httpConfig: utils.HTTPClientConfig{ValidateCert: true},
 			},
 			want: &NetboxClient{
 				Logger:   &logger.Logger{Logger: log.Default()},
@@ -75,9 +81,8 @@ func TestNewNetBoxAPI(t *testing.T) {
 				tt.args.logger,
 				tt.args.baseURL,
 				tt.args.apiToken,
-				tt.args.validateCert,
 				tt.args.timeout,
-				tt.args.caCert,
+				tt.args.httpConfig,
 			)
 			if err != nil {
 				t.Errorf("NewNetboxClient() error = %v", err)
@@ -97,7 +102,10 @@ func TestNewNetBoxAPI(t *testing.T) {
 }
 
 func TestNetboxAPI_doRequest(t *testing.T) {
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
 	type args struct {
+		ctx    context.Context
 		method string
 		path   string
 		body   io.Reader
@@ -113,6 +121,7 @@ func TestNetboxAPI_doRequest(t *testing.T) {
 			name:         "Test GET /api/status/",
 			netboxClient: MockNetboxClient,
 			args: args{
+				ctx:    context.Background(),
 				method: http.MethodGet,
 				path:   "/api/status/",
 				body:   nil,
@@ -124,6 +133,7 @@ func TestNetboxAPI_doRequest(t *testing.T) {
 			name:         "Test Invalid Request",
 			netboxClient: MockNetboxClient,
 			args: args{
+				ctx:    context.Background(),
 				method: "\n", // Invalid method
 				path:   "/api/status",
 				body:   nil,
@@ -135,6 +145,7 @@ func TestNetboxAPI_doRequest(t *testing.T) {
 			name:         "Client failure",
 			netboxClient: FailingMockNetboxClient,
 			args: args{
+				ctx:    context.Background(),
 				method: http.MethodGet,
 				path:   "/api/status",
 				body:   nil,
@@ -146,6 +157,7 @@ func TestNetboxAPI_doRequest(t *testing.T) {
 			name:         "Test ReadALL Error",
 			netboxClient: MockNetboxClientWithReadError,
 			args: args{
+				ctx:    context.Background(),
 				method: http.MethodGet,
 				path:   "/api/read-error",
 				body:   nil,
@@ -153,13 +165,25 @@ func TestNetboxAPI_doRequest(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name:         "Canceled context",
+			netboxClient: MockNetboxClient,
+			args: args{
+				ctx:    canceledCtx,
+				method: http.MethodGet,
+				path:   "/api/status/",
+				body:   nil,
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	mockServer := CreateMockServer()
 	defer mockServer.Close()
 	MockNetboxClient.BaseURL = mockServer.URL
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := tt.netboxClient.doRequest(tt.args.method, tt.args.path, tt.args.body)
+			got, err := tt.netboxClient.doRequest(tt.args.ctx, tt.args.method, tt.args.path, tt.args.body)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NetboxAPI.doRequest() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -170,3 +194,219 @@ func TestNetboxAPI_doRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestNetboxAPI_doRequestMetrics(t *testing.T) {
+	mockServer := CreateMockServer()
+	defer mockServer.Close()
+	MockNetboxClient.BaseURL = mockServer.URL
+
+	succeeded := metrics.APIRequests.WithLabelValues(http.MethodGet, "200")
+	failed := metrics.APIRequests.WithLabelValues(http.MethodGet, metrics.StatusCodeError)
+	latency := metrics.APIRequestDuration.WithLabelValues(http.MethodGet)
+	okBefore := testutil.ToFloat64(succeeded)
+	failedBefore := testutil.ToFloat64(failed)
+	observedBefore := sampleCount(t, latency)
+
+	_, err := MockNetboxClient.doRequest(context.Background(), http.MethodGet, "/api/status/", nil)
+	if err != nil {
+		t.Fatalf("NetboxAPI.doRequest() error = %v", err)
+	}
+	_, err = FailingMockNetboxClient.doRequest(context.Background(), http.MethodGet, "/api/status/", nil)
+	if err == nil {
+		t.Fatalf("NetboxAPI.doRequest() expected error")
+	}
+
+	if got := testutil.ToFloat64(succeeded) - okBefore; got != 1 {
+		t.Errorf("successful requests = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(failed) - failedBefore; got != 1 {
+		t.Errorf("failed requests = %v, want 1", got)
+	}
+	if got := sampleCount(t, latency) - observedBefore; got != 2 { //nolint:mnd
+		t.Errorf("observed latencies = %v, want 2", got)
+	}
+}
+
+// sampleCount returns number of observations of the histogram.
+func sampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	t.Helper()
+	histogram := &dto.Metric{}
+	if err := observer.(prometheus.Metric).Write(histogram); err != nil {
+		t.Fatalf("write histogram: %s", err)
+	}
+	return histogram.GetHistogram().GetSampleCount()
+}
+
+func TestNetboxAPI_doRequestRetries(t *testing.T) {
+	setTestBackoff(t)
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		statusCodes  []int
+		retryAfter   string
+		maxRetries   int
+		wantStatus   int
+		wantAttempts int
+	}{
+		{
+			name:         "Retry GET on 502 until success",
+			method:       http.MethodGet,
+			statusCodes:  []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			maxRetries:   3,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 3,
+		},
+		{
+			name:         "Retry GET on 429 with Retry-After",
+			method:       http.MethodGet,
+			statusCodes:  []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:   "0",
+			maxRetries:   3,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		{
+			name:         "Retry DELETE",
+			method:       http.MethodDelete,
+			statusCodes:  []int{http.StatusServiceUnavailable, http.StatusNoContent},
+			maxRetries:   3,
+			wantStatus:   http.StatusNoContent,
+			wantAttempts: 2,
+		},
+		{
+			name:         "Retried DELETE of already deleted object succeeds",
+			method:       http.MethodDelete,
+			statusCodes:  []int{http.StatusGatewayTimeout, http.StatusNotFound},
+			maxRetries:   3,
+			wantStatus:   http.StatusNoContent,
+			wantAttempts: 2,
+		},
+		{
+			name:         "DELETE of missing object fails",
+			method:       http.MethodDelete,
+			statusCodes:  []int{http.StatusNotFound},
+			maxRetries:   3,
+			wantStatus:   http.StatusNotFound,
+			wantAttempts: 1,
+		},
+		{
+			name:         "Don't retry bulk DELETE",
+			method:       http.MethodDelete,
+			body:         `[{"id": 1}]`,
+			statusCodes:  []int{http.StatusGatewayTimeout, http.StatusNotFound},
+			maxRetries:   3,
+			wantStatus:   http.StatusGatewayTimeout,
+			wantAttempts: 1,
+		},
+		{
+			name:         "Retry budget exhausted",
+			method:       http.MethodGet,
+			statusCodes:  []int{http.StatusGatewayTimeout},
+			maxRetries:   2,
+			wantStatus:   http.StatusGatewayTimeout,
+			wantAttempts: 3,
+		},
+		{
+			name:         "Don't retry POST",
+			method:       http.MethodPost,
+			body:         `{"name": "test"}`,
+			statusCodes:  []int{http.StatusBadGateway, http.StatusCreated},
+			maxRetries:   3,
+			wantStatus:   http.StatusBadGateway,
+			wantAttempts: 1,
+		},
+		{
+			name:         "Don't retry PATCH",
+			method:       http.MethodPatch,
+			statusCodes:  []int{http.StatusServiceUnavailable, http.StatusOK},
+			maxRetries:   3,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantAttempts: 1,
+		},
+		{
+			name:         "Don't retry client errors",
+			method:       http.MethodGet,
+			statusCodes:  []int{http.StatusBadRequest, http.StatusOK},
+			maxRetries:   3,
+			wantStatus:   http.StatusBadRequest,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != tt.body {
+					t.Errorf("attempt %d: body = %q, want %q", attempts, body, tt.body)
+				}
+				statusCode := tt.statusCodes[min(attempts, len(tt.statusCodes)-1)]
+				attempts++
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(statusCode)
+			}))
+			defer server.Close()
+			client := &NetboxClient{
+				HTTPClient: server.Client(),
+				Logger:     &logger.Logger{Logger: log.Default()},
+				BaseURL:    server.URL,
+				Timeout:    constants.DefaultAPITimeout,
+				MaxRetries: tt.maxRetries,
+			}
+
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			got, err := client.doRequest(context.Background(), tt.method, "/api/test/", body)
+			if err != nil {
+				t.Fatalf("NetboxAPI.doRequest() error = %v", err)
+			}
+			if got.StatusCode != tt.wantStatus {
+				t.Errorf("NetboxAPI.doRequest() status = %d, want %d", got.StatusCode, tt.wantStatus)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestNetboxAPI_doRequestRetriesNetworkErrors(t *testing.T) {
+	setTestBackoff(t)
+	client := *FailingMockNetboxClient
+	client.MaxRetries = 2
+	retries := metrics.APIRetries.WithLabelValues(http.MethodGet)
+	retriesBefore := testutil.ToFloat64(retries)
+	_, err := client.doRequest(context.Background(), http.MethodGet, "/api/status/", nil)
+	if err == nil {
+		t.Fatalf("NetboxAPI.doRequest() expected error")
+	}
+	if got := testutil.ToFloat64(retries) - retriesBefore; got != 2 { //nolint:mnd
+		t.Errorf("retries = %v, want 2", got)
+	}
+
+	// Requests are not retried when ctx is canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	retriesBefore = testutil.ToFloat64(retries)
+	_, err = client.doRequest(ctx, http.MethodGet, "/api/status/", nil)
+	if err == nil {
+		t.Fatalf("NetboxAPI.doRequest() expected error")
+	}
+	if got := testutil.ToFloat64(retries) - retriesBefore; got != 0 {
+		t.Errorf("retries = %v, want 0", got)
+	}
+}
+
+// setTestBackoff shortens backoff durations for the duration of the test.
+func setTestBackoff(t *testing.T) {
+	defaultBackoff := retryBackoff
+	retryBackoff = utils.Backoff{Initial: time.Millisecond, Max: time.Millisecond}
+	t.Cleanup(func() {
+		retryBackoff = defaultBackoff
+	})
+}
@@ -0,0 +1,418 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/mapper"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+)
+
+// ErrGraphQLUnsupported is returned by GraphQLGetAll for object types,
+// that are not collected with GraphQL API.
+var ErrGraphQLUnsupported = errors.New("object type is not supported by graphql loader")
+
+// graphQLPath is the path of Netbox's GraphQL API.
+const graphQLPath = "/graphql/"
+
+// graphQLListQueries are names of GraphQL list queries of object types,
+// that can be collected with GraphQL API. These are the object types
+// with the most objects in large inventories.
+var graphQLListQueries = map[constants.APIPath]string{
+	constants.DevicesAPIPath:         "device_list",
+	constants.InterfacesAPIPath:      "interface_list",
+	constants.VirtualMachinesAPIPath: "virtual_machine_list",
+	constants.VMInterfacesAPIPath:    "vm_interface_list",
+	constants.VirtualDisksAPIPath:    "virtual_disk_list",
+	constants.IPAddressesAPIPath:     "ip_address_list",
+	constants.MACAddressesAPIPath:    "mac_address_list",
+}
+
+// graphQLExcludedFields are json fields of objects, that don't exist
+// on Netbox models, and are therefore not exposed by GraphQL API.
+var graphQLExcludedFields = map[constants.APIPath][]string{
+	constants.VirtualMachinesAPIPath: {"tenant_group"},
+}
+
+// graphQLBriefFields are fields collected for nested objects. They match
+// the brief representation of nested objects returned by REST API.
+var graphQLBriefFields = []string{"id", "name", "slug", "model", "address", "mac_address", "vid"}
+
+// graphQLAssignedObjectTypes maps GraphQL types of assigned objects
+// (of IP and MAC addresses) to their content types.
+var graphQLAssignedObjectTypes = map[string]constants.ContentType{
+	"InterfaceType":   constants.ContentTypeDcimInterface,
+	"VMInterfaceType": constants.ContentTypeVirtualizationVMInterface,
+}
+
+// graphQLAssignedObjectSelection replaces assigned_object_type and
+// assigned_object_id fields, which GraphQL API exposes as a union.
+const graphQLAssignedObjectSelection = "assigned_object { __typename " +
+	"... on InterfaceType { id } ... on VMInterfaceType { id } }"
+
+type graphQLRequest struct {
+	Query string `json:"query"`
+}
+
+// graphQLResponse is a page of objects of type T returned by a list query.
+type graphQLResponse[T any] struct {
+	Data   map[string][]graphQLObject[T] `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// graphQLObject is an object of type T received from GraphQL API.
+type graphQLObject[T any] struct {
+	object T
+}
+
+// UnmarshalJSON decodes fields of the object directly into T,
+// converting them to the form returned by REST API.
+func (o *graphQLObject[T]) UnmarshalJSON(data []byte) error {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if err := decodeGraphQLValue(reflect.ValueOf(&o.object).Elem(), fields); err != nil {
+		return fmt.Errorf("decode %T: %s", o.object, err)
+	}
+	return nil
+}
+
+// graphQLField is a json field of an object.
+type graphQLField struct {
+	name      string
+	fieldType reflect.Type
+	// index is the index sequence of the field, for reflect.Value.FieldByIndex.
+	index []int
+}
+
+// GraphQLGetAll queries all objects of type T from Netbox's GraphQL API.
+// Only fields of T are requested, and nested objects only with their
+// brief fields, so responses are much smaller than the ones of GetAll.
+// Objects are converted to the same form as the ones returned by GetAll.
+// List queries don't return the total count of objects, so after the first
+// page, netboxClient.PageWorkers pages are fetched concurrently, until
+// a page isn't full.
+//
+// ErrGraphQLUnsupported is returned for object types, that can't be
+// collected with GraphQL API.
+func GraphQLGetAll[T any](ctx context.Context, netboxClient *NetboxClient) ([]T, error) {
+	var dummy T // Dummy variable for extracting type of generic
+	objectType := reflect.TypeOf(dummy)
+	path := mapper.Type2Path[objectType]
+	queryName, ok := graphQLListQueries[path]
+	// Responses are only adapted in REST API requests
+	if !ok || netboxClient.Capabilities.AdaptsResponses(path) {
+		return nil, ErrGraphQLUnsupported
+	}
+	limit := netboxClient.PageSize
+	if limit <= 0 {
+		limit = constants.DefaultAPIPageSize
+	}
+	excluded := append(slices.Clone(graphQLExcludedFields[path]), netboxClient.Capabilities.UnsupportedFields(path)...)
+	selection := graphQLSelection(objectType, excluded, false)
+	getPage := func(ctx context.Context, offset int) ([]T, error) {
+		query := fmt.Sprintf(
+			"query { %s(pagination: {offset: %d, limit: %d}) %s }",
+			queryName,
+			offset,
+			limit,
+			selection,
+		)
+		return graphQLQuery[T](ctx, netboxClient, queryName, query)
+	}
+
+	netboxClient.Logger.Debugf(ctx, "Getting all %T from Netbox using GraphQL", dummy)
+	allResults, err := getPage(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	workers := max(netboxClient.PageWorkers, 1)
+	for offset := limit; len(allResults) == offset; offset += workers * limit {
+		offsets := make([]int, workers)
+		for i := range offsets {
+			offsets[i] = offset + i*limit
+		}
+		pages, err := fetchPages(ctx, netboxClient, offsets, getPage)
+		if err != nil {
+			return nil, err
+		}
+		for _, page := range pages {
+			allResults = append(allResults, page...)
+			if len(page) < limit {
+				break
+			}
+		}
+	}
+	netboxClient.Logger.Debugf(ctx, "Successfully received all %T: %v", dummy, allResults)
+	return allResults, nil
+}
+
+// graphQLQuery sends the GraphQL query, and returns the results of the
+// list query queryName. Queries don't change anything, so they are
+// retried like idempotent requests.
+func graphQLQuery[T any](
+	ctx context.Context,
+	netboxClient *NetboxClient,
+	queryName string,
+	query string,
+) ([]T, error) {
+	requestBody, err := json.Marshal(graphQLRequest{Query: query})
+	if err != nil {
+		return nil, err
+	}
+	response, err := netboxClient.doRequestWithRetries(
+		ctx,
+		http.MethodPost,
+		graphQLPath,
+		bytes.NewReader(requestBody),
+		netboxClient.MaxRetries,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"unexpected status code %d: %s",
+			response.StatusCode,
+			response.Body,
+		)
+	}
+	var responseObj graphQLResponse[T]
+	if err := json.Unmarshal(response.Body, &responseObj); err != nil {
+		return nil, err
+	}
+	if len(responseObj.Errors) > 0 {
+		messages := make([]string, 0, len(responseObj.Errors))
+		for _, graphQLErr := range responseObj.Errors {
+			messages = append(messages, graphQLErr.Message)
+		}
+		return nil, fmt.Errorf("graphql errors: %s", strings.Join(messages, "; "))
+	}
+	page := responseObj.Data[queryName]
+	results := make([]T, len(page))
+	for i := range page {
+		results[i] = page[i].object
+	}
+	return results, nil
+}
+
+// graphQLSelection returns GraphQL selection set of all json fields of
+// objectType, except the excluded ones. For brief selections (of nested
+// objects) only graphQLBriefFields are selected.
+func graphQLSelection(objectType reflect.Type, excluded []string, brief bool) string {
+	selections := make([]string, 0)
+	for _, field := range graphQLFields(objectType) {
+		fieldType := indirectType(field.fieldType)
+		if fieldType.Kind() == reflect.Slice {
+			fieldType = indirectType(fieldType.Elem())
+		}
+		switch {
+		case slices.Contains(excluded, field.name):
+		case brief:
+			if slices.Contains(graphQLBriefFields, field.name) && fieldType.Kind() != reflect.Struct {
+				selections = append(selections, field.name)
+			}
+		case field.name == "assigned_object_type":
+			selections = append(selections, graphQLAssignedObjectSelection)
+		case field.name == "assigned_object_id":
+		case fieldType.Kind() == reflect.Struct && !isChoice(fieldType):
+			selections = append(
+				selections,
+				field.name+" "+graphQLSelection(fieldType, nil, true),
+			)
+		default:
+			selections = append(selections, field.name)
+		}
+	}
+	return "{ " + strings.Join(selections, " ") + " }"
+}
+
+// decodeGraphQLValue sets target to the value received from GraphQL API,
+// converted into the form returned by REST API. GraphQL API returns ids
+// and decimals as strings, choices only with their values, and assigned
+// objects as a union.
+//
+//nolint:gocyclo
+func decodeGraphQLValue(target reflect.Value, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	switch target.Kind() {
+	case reflect.Pointer:
+		elemType := target.Type().Elem()
+		if choiceValue, ok := value.(string); ok && choiceValue == "" && isChoice(indirectType(elemType)) {
+			return nil
+		}
+		elem := reflect.New(elemType)
+		if err := decodeGraphQLValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		target.Set(elem)
+	case reflect.Struct:
+		if choiceValue, ok := value.(string); ok && isChoice(target.Type()) {
+			target.Field(0).FieldByName("Value").SetString(choiceValue)
+			return nil
+		}
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected value %v of %s", value, target.Type())
+		}
+		if err := normalizeAssignedObject(fields); err != nil {
+			return err
+		}
+		for _, field := range graphQLFields(target.Type()) {
+			fieldValue, ok := fields[field.name]
+			if !ok {
+				continue
+			}
+			if err := decodeGraphQLValue(target.FieldByIndex(field.index), fieldValue); err != nil {
+				return fmt.Errorf("%s: %s", field.name, err)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		values, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected value %v of %s", value, target.Type())
+		}
+		if target.Kind() == reflect.Slice {
+			target.Set(reflect.MakeSlice(target.Type(), len(values), len(values)))
+		} else if len(values) > target.Len() {
+			return fmt.Errorf("too many values for %s", target.Type())
+		}
+		for i := range values {
+			if err := decodeGraphQLValue(target.Index(i), values[i]); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		fields, ok := value.(map[string]interface{})
+		if !ok || target.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unexpected value %v of %s", value, target.Type())
+		}
+		target.Set(reflect.MakeMapWithSize(target.Type(), len(fields)))
+		for key, fieldValue := range fields {
+			elem := reflect.New(target.Type().Elem()).Elem()
+			if err := decodeGraphQLValue(elem, fieldValue); err != nil {
+				return fmt.Errorf("%s: %s", key, err)
+			}
+			target.SetMapIndex(reflect.ValueOf(key).Convert(target.Type().Key()), elem)
+		}
+	case reflect.Interface:
+		if !reflect.TypeOf(value).AssignableTo(target.Type()) {
+			return fmt.Errorf("unexpected value %v of %s", value, target.Type())
+		}
+		target.Set(reflect.ValueOf(value))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch number := value.(type) {
+		case string:
+			parsed, err := strconv.ParseInt(number, 10, 64)
+			if err != nil {
+				return err
+			}
+			target.SetInt(parsed)
+		case float64:
+			target.SetInt(int64(number))
+		default:
+			return fmt.Errorf("unexpected value %v of %s", value, target.Type())
+		}
+	case reflect.Float32, reflect.Float64:
+		switch number := value.(type) {
+		case string:
+			parsed, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return err
+			}
+			target.SetFloat(parsed)
+		case float64:
+			target.SetFloat(number)
+		default:
+			return fmt.Errorf("unexpected value %v of %s", value, target.Type())
+		}
+	case reflect.String:
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected value %v of %s", value, target.Type())
+		}
+		target.SetString(text)
+	case reflect.Bool:
+		boolean, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected value %v of %s", value, target.Type())
+		}
+		target.SetBool(boolean)
+	default:
+		return fmt.Errorf("unsupported type %s", target.Type())
+	}
+	return nil
+}
+
+// normalizeAssignedObject replaces assigned_object union in fields with
+// assigned_object_type and assigned_object_id fields.
+func normalizeAssignedObject(fields map[string]interface{}) error {
+	assignedObject, ok := fields["assigned_object"]
+	if !ok {
+		return nil
+	}
+	delete(fields, "assigned_object")
+	if assignedObject == nil {
+		return nil
+	}
+	assignedObjectFields, ok := assignedObject.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected assigned object %v", assignedObject)
+	}
+	typeName, _ := assignedObjectFields["__typename"].(string)
+	contentType, ok := graphQLAssignedObjectTypes[typeName]
+	if !ok {
+		return fmt.Errorf("unsupported assigned object type %s", typeName)
+	}
+	fields["assigned_object_type"] = string(contentType)
+	fields["assigned_object_id"] = assignedObjectFields["id"]
+	return nil
+}
+
+// graphQLFields returns all json fields of objectType,
+// including the fields of embedded structs.
+func graphQLFields(objectType reflect.Type) []graphQLField {
+	fields := make([]graphQLField, 0, objectType.NumField())
+	for i := 0; i < objectType.NumField(); i++ {
+		field := objectType.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			for _, embeddedField := range graphQLFields(field.Type) {
+				embeddedField.index = append([]int{i}, embeddedField.index...)
+				fields = append(fields, embeddedField)
+			}
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		fields = append(fields, graphQLField{name: name, fieldType: field.Type, index: []int{i}})
+	}
+	return fields
+}
+
+// isChoice returns true for structs that embed objects.Choice.
+func isChoice(structType reflect.Type) bool {
+	return structType.NumField() > 0 && structType.Field(0).Type == reflect.TypeOf(objects.Choice{})
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+)
+
+func TestGraphQLSelection(t *testing.T) {
+	tests := []struct {
+		name       string
+		objectType reflect.Type
+		excluded   []string
+		want       string
+	}{
+		{
+			name:       "ip address",
+			objectType: reflect.TypeOf(objects.IPAddress{}),
+			want: "{ id tags { id name slug } description custom_fields address status role dns_name " +
+				"tenant { id name slug } " + graphQLAssignedObjectSelection + " }",
+		},
+		{
+			name:       "virtual disk",
+			objectType: reflect.TypeOf(objects.VirtualDisk{}),
+			excluded:   []string{"description", "custom_fields"},
+			want:       "{ id tags { id name slug } virtual_machine { id name } name size }",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := graphQLSelection(tt.objectType, tt.excluded, false); got != tt.want {
+				t.Errorf("graphQLSelection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraphQLGetAll(t *testing.T) {
+	pages := [][]map[string]interface{}{
+		{
+			{
+				"id":      "1",
+				"address": "10.0.0.1/24",
+				"status":  "active",
+				"role":    "",
+				"tags":    []interface{}{map[string]interface{}{"id": "3", "name": "netbox-ssot"}},
+				"assigned_object": map[string]interface{}{
+					"__typename": "VMInterfaceType",
+					"id":         "7",
+				},
+			},
+			{"id": "2", "address": "10.0.0.2/24", "assigned_object": nil},
+		},
+		{
+			{"id": "3", "address": "10.0.0.3/24", "status": "dhcp"},
+		},
+	}
+	tests := []struct {
+		name     string
+		response func(w http.ResponseWriter, query string)
+		want     []objects.IPAddress
+		wantErr  bool
+	}{
+		{
+			name: "pages",
+			response: func(w http.ResponseWriter, query string) {
+				page := pages[0]
+				if strings.Contains(query, "offset: 2") {
+					page = pages[1]
+				}
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"data": map[string]interface{}{"ip_address_list": page},
+				})
+			},
+			want: []objects.IPAddress{
+				{
+					NetboxObject: objects.NetboxObject{
+						ID:   1,
+						Tags: []*objects.Tag{{ID: 3, Name: "netbox-ssot"}},
+					},
+					Address:            "10.0.0.1/24",
+					Status:             &objects.IPAddressStatus{Choice: objects.Choice{Value: "active"}},
+					AssignedObjectType: constants.ContentTypeVirtualizationVMInterface,
+					AssignedObjectID:   7,
+				},
+				{NetboxObject: objects.NetboxObject{ID: 2}, Address: "10.0.0.2/24"},
+				{
+					NetboxObject: objects.NetboxObject{ID: 3},
+					Address:      "10.0.0.3/24",
+					Status:       &objects.IPAddressStatus{Choice: objects.Choice{Value: "dhcp"}},
+				},
+			},
+		},
+		{
+			name: "graphql errors",
+			response: func(w http.ResponseWriter, _ string) {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"errors": []interface{}{map[string]interface{}{"message": "Cannot query field"}},
+				})
+			},
+			wantErr: true,
+		},
+		{
+			name: "graphql disabled",
+			response: func(w http.ResponseWriter, _ string) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var request graphQLRequest
+				if r.Method != http.MethodPost || r.URL.Path != graphQLPath ||
+					json.NewDecoder(r.Body).Decode(&request) != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				tt.response(w, request.Query)
+			}))
+			defer server.Close()
+			api := &NetboxClient{
+				HTTPClient: &http.Client{},
+				Logger:     MockNetboxClient.Logger,
+				BaseURL:    server.URL,
+				Timeout:    constants.DefaultAPITimeout,
+				PageSize:   2,
+			}
+
+			got, err := GraphQLGetAll[objects.IPAddress](context.Background(), api)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GraphQLGetAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GraphQLGetAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraphQLGetAllPages(t *testing.T) {
+	tests := []struct {
+		name        string
+		count       int
+		pageSize    int
+		pageWorkers int
+	}{
+		{name: "single page", count: 3, pageSize: 5, pageWorkers: 4},
+		{name: "full pages", count: 20, pageSize: 5, pageWorkers: 1},
+		{name: "concurrent pages", count: 103, pageSize: 10, pageWorkers: 4},
+	}
+	pagination := regexp.MustCompile(`offset: (\d+), limit: (\d+)`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			requests := make(map[int]int)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var request graphQLRequest
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				match := pagination.FindStringSubmatch(request.Query)
+				offset, _ := strconv.Atoi(match[1])
+				limit, _ := strconv.Atoi(match[2])
+				mu.Lock()
+				requests[offset]++
+				mu.Unlock()
+				page := []map[string]interface{}{}
+				for id := offset; id < min(offset+limit, tt.count); id++ {
+					page = append(page, map[string]interface{}{"id": strconv.Itoa(id)})
+				}
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"data": map[string]interface{}{"device_list": page},
+				})
+			}))
+			defer server.Close()
+			api := &NetboxClient{
+				HTTPClient:  &http.Client{},
+				Logger:      MockNetboxClient.Logger,
+				BaseURL:     server.URL,
+				Timeout:     constants.DefaultAPITimeout,
+				PageSize:    tt.pageSize,
+				PageWorkers: tt.pageWorkers,
+			}
+
+			devices, err := GraphQLGetAll[objects.Device](context.Background(), api)
+			if err != nil {
+				t.Fatalf("GraphQLGetAll() error = %v", err)
+			}
+			if len(devices) != tt.count {
+				t.Fatalf("GraphQLGetAll() returned %d objects, want %d", len(devices), tt.count)
+			}
+			for i, device := range devices {
+				if device.ID != i {
+					t.Fatalf("GraphQLGetAll()[%d] has id %d, want objects in page order", i, device.ID)
+				}
+			}
+			for offset, got := range requests {
+				if got != 1 {
+					t.Errorf("page with offset %d was requested %d times, want 1", offset, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGraphQLGetAllUnsupported(t *testing.T) {
+	_, err := GraphQLGetAll[objects.Tag](context.Background(), MockNetboxClient)
+	if !errors.Is(err, ErrGraphQLUnsupported) {
+		t.Errorf("GraphQLGetAll() error = %v, want %v", err, ErrGraphQLUnsupported)
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+)
+
+// RunIDHeader is the header that carries the id of the current run on
+// every request. Netbox assigns its own request ids to changes and ignores
+// the header, so the run id is used to correlate requests of a single run in
+// proxy and access logs, and it is also written in journal entries
+// (see JournalEntries), that link changed objects to the run.
+const RunIDHeader = "X-Netbox-SSOT-Run-ID"
+
+// NewRunID returns a random (version 4) UUID, that identifies a single run.
+func NewRunID() string {
+	var uuid [16]byte
+	_, _ = rand.Read(uuid[:])
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16])
+}
+
+// unjournaledObjectTypes are object types, that don't support journal entries.
+var unjournaledObjectTypes = map[constants.ContentType]bool{
+	constants.ContentTypeExtrasCustomField:        true,
+	constants.ContentTypeExtrasTag:                true,
+	constants.ContentTypeTenancyContactAssignment: true,
+}
+
+// writeJournalEntries writes a journal entry on each created or patched
+// object of the same type as objectType, with the action and a summary of
+// its patch body. Bodies must contain ids of the objects, and bodies of
+// created objects contain only their ids. Journal entries are supplementary,
+// so errors are only logged.
+func writeJournalEntries(
+	ctx context.Context,
+	netboxClient *NetboxClient,
+	objectType interface{},
+	action objects.ObjectChangeAction,
+	bodies []map[string]interface{},
+) {
+	if !netboxClient.JournalEntries || len(bodies) == 0 {
+		return
+	}
+	object, ok := objectType.(interface {
+		GetObjectType() constants.ContentType
+	})
+	if !ok || unjournaledObjectTypes[object.GetObjectType()] {
+		return
+	}
+	entries := make([]map[string]interface{}, 0, len(bodies))
+	for _, body := range bodies {
+		entries = append(entries, map[string]interface{}{
+			"assigned_object_type": object.GetObjectType(),
+			"assigned_object_id":   body["id"],
+			"kind":                 objects.JournalEntryKindInfo.Value,
+			"comments":             journalComments(action, sourceFromCtx(ctx), netboxClient.RunID, body),
+		})
+	}
+	_, err := bulkRequest[objects.JournalEntry](
+		ctx,
+		netboxClient,
+		http.MethodPost,
+		constants.JournalEntriesAPIPath,
+		entries,
+		http.StatusCreated,
+	)
+	if err != nil {
+		netboxClient.Logger.Warningf(ctx, "failed writing journal entries of %d %T: %s", len(bodies), objectType, err)
+	}
+}
+
+// journalComments returns a human-readable summary of the action and
+// the patch body, that lists the changed fields in alphabetical order.
+func journalComments(
+	action objects.ObjectChangeAction,
+	source string,
+	runID string,
+	body map[string]interface{},
+) string {
+	var comments strings.Builder
+	fmt.Fprintf(&comments, "%s by netbox-ssot", action.Label)
+	if source != "" {
+		fmt.Fprintf(&comments, " from source %s", source)
+	}
+	if runID != "" {
+		fmt.Fprintf(&comments, " (run %s)", runID)
+	}
+	lines := diffLines("", body)
+	if len(lines) > 0 {
+		comments.WriteString(":\n")
+	}
+	for _, line := range lines {
+		fmt.Fprintf(&comments, "\n- %s", line)
+	}
+	return comments.String()
+}
+
+// diffLines returns "field: value" lines of the diff map. Nested maps
+// (e.g. custom_fields) are flattened into "field.key: value" lines.
+func diffLines(prefix string, diffMap map[string]interface{}) []string {
+	lines := make([]string, 0, len(diffMap))
+	for key, value := range diffMap {
+		if prefix == "" && key == "id" {
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			lines = append(lines, diffLines(prefix+key+".", nested)...)
+			continue
+		}
+		formatted, err := json.Marshal(value)
+		if err != nil || reflect.ValueOf(value).Kind() == reflect.String {
+			formatted = []byte(fmt.Sprint(value))
+		}
+		lines = append(lines, fmt.Sprintf("%s%s: %s", prefix, key, formatted))
+	}
+	slices.Sort(lines)
+	return lines
+}
+
+// idBodies returns bodies of the created objects for writeJournalEntries,
+// which contain only ids of the objects.
+func idBodies[T any](created ...*T) []map[string]interface{} {
+	bodies := make([]map[string]interface{}, 0, len(created))
+	for _, object := range created {
+		if idItem, ok := any(object).(objects.IDItem); ok {
+			bodies = append(bodies, map[string]interface{}{"id": idItem.GetID()})
+		}
+	}
+	return bodies
+}
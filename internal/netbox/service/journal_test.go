@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/utils"
+)
+
+func TestNewRunID(t *testing.T) {
+	uuidRegex := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	runID := NewRunID()
+	if !uuidRegex.MatchString(runID) {
+		t.Errorf("NewRunID() = %s, want version 4 uuid", runID)
+	}
+	if NewRunID() == runID {
+		t.Errorf("NewRunID() returned the same id twice")
+	}
+}
+
+func TestJournalComments(t *testing.T) {
+	tests := []struct {
+		name   string
+		action objects.ObjectChangeAction
+		source string
+		runID  string
+		body   map[string]interface{}
+		want   string
+	}{
+		{
+			name:   "fields are sorted",
+			action: objects.ObjectChangeActionUpdate,
+			source: "vmware",
+			runID:  "1234",
+			body: map[string]interface{}{
+				"id":     7,
+				"serial": "ABC",
+				"site":   utils.IDObject{ID: 3},
+				"status": "active",
+			},
+			want: "Updated by netbox-ssot from source vmware (run 1234):\n\n" +
+				"- serial: ABC\n- site: {\"id\":3}\n- status: active",
+		},
+		{
+			name:   "custom fields are flattened",
+			action: objects.ObjectChangeActionUpdate,
+			body: map[string]interface{}{
+				"custom_fields": map[string]interface{}{"host_memory": 128, "host_cpu_cores": nil},
+				"tags":          []int{1, 2},
+			},
+			want: "Updated by netbox-ssot:\n\n" +
+				"- custom_fields.host_cpu_cores: null\n- custom_fields.host_memory: 128\n- tags: [1,2]",
+		},
+		{
+			name:   "created object",
+			action: objects.ObjectChangeActionCreate,
+			source: "vmware",
+			runID:  "1234",
+			body:   map[string]interface{}{"id": 7},
+			want:   "Created by netbox-ssot from source vmware (run 1234)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := journalComments(tt.action, tt.source, tt.runID, tt.body); got != tt.want {
+				t.Errorf("journalComments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetboxClient_JournalEntries(t *testing.T) {
+	tests := []struct {
+		name           string
+		journalEntries bool
+		wantEntries    int
+	}{
+		{name: "disabled", journalEntries: false, wantEntries: 0},
+		{name: "enabled", journalEntries: true, wantEntries: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fakenetbox.NewServer()
+			defer server.Close()
+			devices := make([]*objects.Device, 0, 3)
+			for _, name := range []string{"device1", "device2", "device3"} {
+				device, err := fakenetbox.Add(server, &objects.Device{Name: name})
+				if err != nil {
+					t.Fatalf("Add() error = %v", err)
+				}
+				devices = append(devices, device)
+			}
+			api := &NetboxClient{
+				HTTPClient:     &http.Client{},
+				Logger:         MockNetboxClient.Logger,
+				BaseURL:        server.URL,
+				Timeout:        constants.DefaultAPITimeout,
+				RunID:          "1234",
+				JournalEntries: tt.journalEntries,
+			}
+			ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "vmware")
+
+			if _, err := Patch[objects.Device](ctx, api, devices[0].ID, map[string]interface{}{"serial": "ABC"}); err != nil {
+				t.Fatalf("Patch() error = %v", err)
+			}
+			bodies := []map[string]interface{}{
+				{"id": devices[1].ID, "serial": "DEF"},
+				{"id": devices[2].ID, "serial": "GHI"},
+			}
+			if _, err := BulkPatch[objects.Device](ctx, api, bodies, 0); err != nil {
+				t.Fatalf("BulkPatch() error = %v", err)
+			}
+
+			created, err := Create(ctx, api, &objects.Device{Name: "device4"})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			bulkCreated, err := BulkCreate(ctx, api, []*objects.Device{{Name: "device5"}}, 0)
+			if err != nil {
+				t.Fatalf("BulkCreate() error = %v", err)
+			}
+			devices = append(devices, created, bulkCreated[0])
+
+			entries, err := fakenetbox.Objects[objects.JournalEntry](server)
+			if err != nil {
+				t.Fatalf("Objects() error = %v", err)
+			}
+			if len(entries) != tt.wantEntries {
+				t.Fatalf("journal entries = %v, want %d entries", entries, tt.wantEntries)
+			}
+			for i, entry := range entries {
+				if entry.AssignedObjectType != constants.ContentTypeDcimDevice || entry.AssignedObjectID != devices[i].ID {
+					t.Errorf("entry %v is not assigned to device %d", entry, devices[i].ID)
+				}
+			}
+			if tt.journalEntries {
+				want := "Updated by netbox-ssot from source vmware (run 1234):\n\n- serial: ABC"
+				if entries[0].Comments != want {
+					t.Errorf("comments = %q, want %q", entries[0].Comments, want)
+				}
+				want = "Created by netbox-ssot from source vmware (run 1234)"
+				if entries[3].Comments != want {
+					t.Errorf("comments = %q, want %q", entries[3].Comments, want)
+				}
+			}
+			for _, request := range server.Requests() {
+				if runID := request.Header.Get(RunIDHeader); runID != "1234" {
+					t.Errorf("%s %s was sent with run id %q, want 1234", request.Method, request.Path, runID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+)
+
+// PlanAction represents type of the change that would be sent to Netbox.
+type PlanAction string
+
+const (
+	PlanActionCreate     PlanAction = "create"
+	PlanActionUpdate     PlanAction = "update"
+	PlanActionSoftDelete PlanAction = "soft-delete"
+	PlanActionDelete     PlanAction = "delete"
+)
+
+// planActionsOrder is the order in which actions are printed in the plan.
+var planActionsOrder = []PlanAction{
+	PlanActionCreate,
+	PlanActionUpdate,
+	PlanActionSoftDelete,
+	PlanActionDelete,
+}
+
+// PlannedChange represents a single change, that would be sent to
+// Netbox, if netbox-ssot wasn't run in dry-run mode.
+type PlannedChange struct {
+	Action     PlanAction        `json:"action"`
+	ObjectType constants.APIPath `json:"object_type"`
+	// ObjectID is the id of the object in Netbox. Objects that
+	// would be created get negative placeholder ids.
+	ObjectID int    `json:"object_id"`
+	Object   string `json:"object"`
+	Source   string `json:"source,omitempty"`
+	// Diff contains the body that would be sent to Netbox.
+	// For updates this is the diff map returned from utils.JSONDiffMapExceptID.
+	Diff map[string]interface{} `json:"diff,omitempty"`
+}
+
+// Plan records all changes (creates, patches and deletes), that would
+// be sent to Netbox. When Plan is set on the NetboxClient, no write
+// requests are sent to the Netbox API.
+type Plan struct {
+	mu      sync.Mutex
+	changes []*PlannedChange
+	// changesIndex is used for merging multiple changes of the same object.
+	changesIndex map[constants.APIPath]map[int]*PlannedChange
+	// plannedObjects stores objects that would be created (indexed by their
+	// placeholder ids) or patched, so they can be returned when patching them.
+	plannedObjects map[constants.APIPath]map[int]interface{}
+	// lastPlaceholderID is the last placeholder id given to a created object.
+	lastPlaceholderID int
+}
+
+// NewPlan returns new empty plan.
+func NewPlan() *Plan {
+	return &Plan{
+		changesIndex:   make(map[constants.APIPath]map[int]*PlannedChange),
+		plannedObjects: make(map[constants.APIPath]map[int]interface{}),
+	}
+}
+
+// RecordCreate records creation of the object, and returns placeholder
+// id that should be used for the object.
+func (p *Plan) RecordCreate(
+	ctx context.Context,
+	objectPath constants.APIPath,
+	object interface{},
+	body map[string]interface{},
+) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastPlaceholderID--
+	id := p.lastPlaceholderID
+	p.setPlannedObject(objectPath, id, object)
+	p.addChange(&PlannedChange{
+		Action:     PlanActionCreate,
+		ObjectType: objectPath,
+		ObjectID:   id,
+		Object:     fmt.Sprintf("%v", object),
+		Source:     sourceFromCtx(ctx),
+		Diff:       body,
+	})
+	return id
+}
+
+// RecordPatch records patch of the object with the given id. Multiple
+// patches of the same object are merged into a single change.
+func (p *Plan) RecordPatch(
+	ctx context.Context,
+	objectPath constants.APIPath,
+	objectID int,
+	body map[string]interface{},
+) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if existing, ok := p.changesIndex[objectPath][objectID]; ok {
+		if existing.Diff == nil {
+			existing.Diff = make(map[string]interface{}, len(body))
+		}
+		for k, v := range body {
+			existing.Diff[k] = v
+		}
+		return
+	}
+	p.addChange(&PlannedChange{
+		Action:     PlanActionUpdate,
+		ObjectType: objectPath,
+		ObjectID:   objectID,
+		Source:     sourceFromCtx(ctx),
+		Diff:       body,
+	})
+}
+
+// RecordSoftDelete records that object would be marked as orphan.
+func (p *Plan) RecordSoftDelete(
+	ctx context.Context,
+	objectPath constants.APIPath,
+	objectID int,
+	object string,
+	body map[string]interface{},
+) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.addChange(&PlannedChange{
+		Action:     PlanActionSoftDelete,
+		ObjectType: objectPath,
+		ObjectID:   objectID,
+		Object:     object,
+		Source:     sourceFromCtx(ctx),
+		Diff:       body,
+	})
+}
+
+// RecordDelete records that object would be deleted.
+func (p *Plan) RecordDelete(
+	ctx context.Context,
+	objectPath constants.APIPath,
+	objectID int,
+	object string,
+) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.addChange(&PlannedChange{
+		Action:     PlanActionDelete,
+		ObjectType: objectPath,
+		ObjectID:   objectID,
+		Object:     object,
+		Source:     sourceFromCtx(ctx),
+	})
+}
+
+// plannedObject returns the object with the given id, as it would be
+// after the recorded creates and patches.
+func (p *Plan) plannedObject(objectPath constants.APIPath, objectID int) (interface{}, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	object, ok := p.plannedObjects[objectPath][objectID]
+	return object, ok
+}
+
+// storePlannedObject stores the object as it would be after a recorded patch.
+func (p *Plan) storePlannedObject(objectPath constants.APIPath, objectID int, object interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setPlannedObject(objectPath, objectID, object)
+}
+
+// setPlannedObject stores the planned object. Caller must hold the lock.
+func (p *Plan) setPlannedObject(objectPath constants.APIPath, objectID int, object interface{}) {
+	if p.plannedObjects[objectPath] == nil {
+		p.plannedObjects[objectPath] = make(map[int]interface{})
+	}
+	p.plannedObjects[objectPath][objectID] = object
+}
+
+// addChange adds change to the plan. Caller must hold the lock.
+func (p *Plan) addChange(change *PlannedChange) {
+	p.changes = append(p.changes, change)
+	if p.changesIndex[change.ObjectType] == nil {
+		p.changesIndex[change.ObjectType] = make(map[int]*PlannedChange)
+	}
+	p.changesIndex[change.ObjectType][change.ObjectID] = change
+}
+
+// Changes returns copy of all recorded changes in the order they were recorded.
+func (p *Plan) Changes() []PlannedChange {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	changes := make([]PlannedChange, 0, len(p.changes))
+	for _, change := range p.changes {
+		changes = append(changes, *change)
+	}
+	return changes
+}
+
+// Summary returns number of changes for each object type and action.
+func (p *Plan) Summary() map[constants.APIPath]map[PlanAction]int {
+	summary := make(map[constants.APIPath]map[PlanAction]int)
+	for _, change := range p.Changes() {
+		if summary[change.ObjectType] == nil {
+			summary[change.ObjectType] = make(map[PlanAction]int)
+		}
+		summary[change.ObjectType][change.Action]++
+	}
+	return summary
+}
+
+// WriteText writes human readable representation of the plan,
+// grouped by object type.
+func (p *Plan) WriteText(w io.Writer) error {
+	changes := p.Changes()
+	summary := p.Summary()
+	objectTypes := make([]constants.APIPath, 0, len(summary))
+	for objectType := range summary {
+		objectTypes = append(objectTypes, objectType)
+	}
+	sort.Slice(objectTypes, func(i, j int) bool { return objectTypes[i] < objectTypes[j] })
+
+	if _, err := fmt.Fprintf(w, "Plan: %d changes\n", len(changes)); err != nil {
+		return err
+	}
+	for _, objectType := range objectTypes {
+		_, err := fmt.Fprintf(w, "\n%s", objectType)
+		if err != nil {
+			return err
+		}
+		for _, action := range planActionsOrder {
+			_, err = fmt.Fprintf(w, " %s=%d", action, summary[objectType][action])
+			if err != nil {
+				return err
+			}
+		}
+		if _, err = fmt.Fprintln(w); err != nil {
+			return err
+		}
+		for _, action := range planActionsOrder {
+			for _, change := range changes {
+				if change.ObjectType != objectType || change.Action != action {
+					continue
+				}
+				diff, err := json.Marshal(change.Diff)
+				if err != nil {
+					return fmt.Errorf("marshal diff: %s", err)
+				}
+				_, err = fmt.Fprintf(
+					w,
+					"  %-11s id=%d source=%s %s %s\n",
+					change.Action,
+					change.ObjectID,
+					change.Source,
+					change.Object,
+					diff,
+				)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes json representation of the plan.
+func (p *Plan) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Summary map[constants.APIPath]map[PlanAction]int `json:"summary"`
+		Changes []PlannedChange                          `json:"changes"`
+	}{
+		Summary: p.Summary(),
+		Changes: p.Changes(),
+	})
+}
+
+// setPlaceholderID sets ID field of the object to the given id.
+func setPlaceholderID(object interface{}, id int) {
+	v := reflect.ValueOf(object)
+	if v.Kind() != reflect.Ptr {
+		return
+	}
+	idField := v.Elem().FieldByName("ID")
+	if idField.IsValid() && idField.CanSet() && idField.Kind() == reflect.Int {
+		idField.SetInt(int64(id))
+	}
+}
+
+func sourceFromCtx(ctx context.Context) string {
+	if source, ok := ctx.Value(constants.CtxSourceKey).(string); ok {
+		return source
+	}
+	return ""
+}
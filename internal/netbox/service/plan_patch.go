@@ -0,0 +1,124 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+)
+
+// ApplyPatch returns a copy of the object with the patch body applied
+// (see applyPatch). The object itself is not changed.
+func ApplyPatch[T any](object *T, body map[string]interface{}) (*T, error) {
+	patched := *object
+	if err := applyPatch(reflect.ValueOf(&patched).Elem(), body); err != nil {
+		return nil, err
+	}
+	return &patched, nil
+}
+
+// applyPatch sets fields of the object to the values of the patch body
+// (see utils.JSONDiffMapExceptID), so the object looks like it would be
+// returned by Netbox after the patch. Referenced objects only have their
+// ids set, like brief nested objects returned by Netbox. Fields of the body,
+// that the object doesn't have, are ignored.
+func applyPatch(object reflect.Value, body map[string]interface{}) error {
+	fields := make(map[string]reflect.Value)
+	collectJSONFields(object, fields)
+	for name, value := range body {
+		field, ok := fields[name]
+		if !ok {
+			continue
+		}
+		if err := setPatchedField(field, value); err != nil {
+			return fmt.Errorf("field %s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// collectJSONFields collects fields of the struct (including fields of
+// embedded structs like NetboxObject) by their json names.
+func collectJSONFields(object reflect.Value, fields map[string]reflect.Value) {
+	objectType := object.Type()
+	for i := 0; i < object.NumField(); i++ {
+		structField := objectType.Field(i)
+		if structField.Anonymous && structField.Type.Kind() == reflect.Struct {
+			collectJSONFields(object.Field(i), fields)
+			continue
+		}
+		name := strings.Split(structField.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = object.Field(i)
+	}
+}
+
+// setPatchedField sets the field to the value from the patch body.
+func setPatchedField(field reflect.Value, value interface{}) error {
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	patchValue := reflect.ValueOf(value)
+	if patchValue.Type().AssignableTo(field.Type()) {
+		field.Set(patchValue)
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.Ptr:
+		element := reflect.New(field.Type().Elem())
+		if err := setPatchedField(element.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(element)
+	case reflect.Struct:
+		// Choice fields are patched with their values
+		if field.NumField() > 0 && field.Type().Field(0).Type == reflect.TypeOf(objects.Choice{}) {
+			choiceValue, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("choice value %v is not a string", value)
+			}
+			field.Field(0).FieldByName("Value").SetString(choiceValue)
+			return nil
+		}
+		// References are patched with their ids (e.g. utils.IDObject)
+		idField := field.FieldByName("ID")
+		if !idField.IsValid() {
+			return fmt.Errorf("can't set %T to %s", value, field.Type())
+		}
+		return setPatchedField(idField, patchedID(patchValue))
+	case reflect.Slice:
+		if patchValue.Kind() != reflect.Slice {
+			return fmt.Errorf("can't set %T to %s", value, field.Type())
+		}
+		slice := reflect.MakeSlice(field.Type(), patchValue.Len(), patchValue.Len())
+		for i := 0; i < patchValue.Len(); i++ {
+			if err := setPatchedField(slice.Index(i), patchValue.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+	default:
+		// Numbers are not converted to strings (and vice versa)
+		if (patchValue.Kind() == reflect.String) != (field.Kind() == reflect.String) ||
+			!patchValue.Type().ConvertibleTo(field.Type()) {
+			return fmt.Errorf("can't set %T to %s", value, field.Type())
+		}
+		field.Set(patchValue.Convert(field.Type()))
+	}
+	return nil
+}
+
+// patchedID returns the id of the referenced object in the patch body,
+// which is either an object with id field, or the id itself.
+func patchedID(value reflect.Value) interface{} {
+	if value.Kind() == reflect.Struct {
+		if idField := value.FieldByName("ID"); idField.IsValid() {
+			return idField.Interface()
+		}
+	}
+	return value.Interface()
+}
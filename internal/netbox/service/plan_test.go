@@ -0,0 +1,195 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/logger"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/utils"
+)
+
+func TestPlan_DryRunRequests(t *testing.T) {
+	ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "test")
+	plan := NewPlan()
+	// Every request sent to this client fails, so dry-run must not send any.
+	dryRunClient := &NetboxClient{
+		HTTPClient: &http.Client{Transport: &FailingHTTPClient{}},
+		Logger:     &logger.Logger{Logger: log.Default()},
+		Timeout:    constants.DefaultAPITimeout,
+		Plan:       plan,
+	}
+
+	created, err := Create(ctx, dryRunClient, &objects.Tag{Name: "new", Slug: "new"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if created.ID != -1 {
+		t.Errorf("Create() placeholder id = %d, want -1", created.ID)
+	}
+
+	patched, err := Patch[objects.Tag](
+		ctx,
+		dryRunClient,
+		created.ID,
+		map[string]interface{}{"description": "patched"},
+	)
+	if err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+	if patched.ID != created.ID || patched.Description != "patched" {
+		t.Errorf("Patch() = %v, want patched planned object %v", patched, created)
+	}
+	if created.Description != "" {
+		t.Errorf("Patch() modified planned object %v", created)
+	}
+
+	err = dryRunClient.DeleteObject(ctx, &objects.Device{NetboxObject: objects.NetboxObject{ID: 5}})
+	if err != nil {
+		t.Fatalf("DeleteObject() error = %v", err)
+	}
+	err = dryRunClient.BulkDeleteObjects(ctx, constants.VlansAPIPath, map[int]bool{7: true})
+	if err != nil {
+		t.Fatalf("BulkDeleteObjects() error = %v", err)
+	}
+
+	wantSummary := map[constants.APIPath]map[PlanAction]int{
+		constants.TagsAPIPath:    {PlanActionCreate: 1},
+		constants.DevicesAPIPath: {PlanActionDelete: 1},
+		constants.VlansAPIPath:   {PlanActionDelete: 1},
+	}
+	if got := plan.Summary(); !reflect.DeepEqual(got, wantSummary) {
+		t.Errorf("Summary() = %v, want %v", got, wantSummary)
+	}
+
+	changes := plan.Changes()
+	wantDiff := map[string]interface{}{"name": "new", "slug": "new", "description": "patched"}
+	if !reflect.DeepEqual(changes[0].Diff, wantDiff) {
+		t.Errorf("merged create diff = %v, want %v", changes[0].Diff, wantDiff)
+	}
+	if changes[0].Source != "test" {
+		t.Errorf("change source = %s, want test", changes[0].Source)
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		device  objects.Device
+		body    map[string]interface{}
+		want    objects.Device
+		wantErr bool
+	}{
+		{
+			name: "Patch fields, choices and references",
+			device: objects.Device{
+				NetboxObject: objects.NetboxObject{ID: 1, Description: "old"},
+				Name:         "device",
+				Status:       &objects.DeviceStatusOffline,
+				Site:         &objects.Site{NetboxObject: objects.NetboxObject{ID: 1}, Name: "site"},
+			},
+			body: map[string]interface{}{
+				"description":   "new",
+				"status":        "active",
+				"site":          utils.IDObject{ID: 2},
+				"tags":          []int{3},
+				"custom_fields": map[string]interface{}{"uuid": "1234"},
+				"unknown":       "ignored",
+			},
+			want: objects.Device{
+				NetboxObject: objects.NetboxObject{
+					ID:           1,
+					Description:  "new",
+					Tags:         []*objects.Tag{{ID: 3}},
+					CustomFields: map[string]interface{}{"uuid": "1234"},
+				},
+				Name:   "device",
+				Status: &objects.DeviceStatus{Choice: objects.Choice{Value: "active"}},
+				Site:   &objects.Site{NetboxObject: objects.NetboxObject{ID: 2}},
+			},
+		},
+		{
+			name: "Reset fields",
+			device: objects.Device{
+				Name:     "device",
+				Comments: "comment",
+				Platform: &objects.Platform{NetboxObject: objects.NetboxObject{ID: 1}},
+			},
+			body: map[string]interface{}{"comments": "", "platform": nil},
+			want: objects.Device{Name: "device"},
+		},
+		{
+			name:    "Number for string field",
+			device:  objects.Device{Name: "device"},
+			body:    map[string]interface{}{"name": 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device := tt.device
+			err := applyPatch(reflect.ValueOf(&device).Elem(), tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyPatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(device, tt.want) {
+				t.Errorf("applyPatch() = %+v, want %+v", device, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlan_RecordPatch(t *testing.T) {
+	ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "test")
+	plan := NewPlan()
+	plan.RecordPatch(ctx, constants.DevicesAPIPath, 1, map[string]interface{}{"name": "a"})
+	plan.RecordPatch(ctx, constants.DevicesAPIPath, 1, map[string]interface{}{"serial": "b"})
+	plan.RecordPatch(ctx, constants.DevicesAPIPath, 2, map[string]interface{}{"name": "c"})
+
+	changes := plan.Changes()
+	if len(changes) != 2 { //nolint:mnd
+		t.Fatalf("len(Changes()) = %d, want 2", len(changes))
+	}
+	wantDiff := map[string]interface{}{"name": "a", "serial": "b"}
+	if !reflect.DeepEqual(changes[0].Diff, wantDiff) {
+		t.Errorf("Changes()[0].Diff = %v, want %v", changes[0].Diff, wantDiff)
+	}
+}
+
+func TestPlan_WriteJSON(t *testing.T) {
+	ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "test")
+	plan := NewPlan()
+	plan.RecordSoftDelete(ctx, constants.VlansAPIPath, 3, "vlan", nil)
+
+	var buf bytes.Buffer
+	if err := plan.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var got struct {
+		Summary map[string]map[string]int `json:"summary"`
+		Changes []PlannedChange           `json:"changes"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal plan: %s", err)
+	}
+	if got.Summary[string(constants.VlansAPIPath)][string(PlanActionSoftDelete)] != 1 {
+		t.Errorf("WriteJSON() summary = %v", got.Summary)
+	}
+	if len(got.Changes) != 1 || got.Changes[0].ObjectID != 3 {
+		t.Errorf("WriteJSON() changes = %v", got.Changes)
+	}
+
+	buf.Reset()
+	if err := plan.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(string(constants.VlansAPIPath))) {
+		t.Errorf("WriteText() = %s, missing object type", buf.String())
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"sync"
 
 	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/metrics"
 	"github.com/src-doo/netbox-ssot/internal/netbox/mapper"
 	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
 	"github.com/src-doo/netbox-ssot/internal/utils"
@@ -30,7 +32,7 @@ type Response[T any] struct {
 func GetVersion(ctx context.Context, netboxClient *NetboxClient) (string, error) {
 	var versionResponse VersionResponse
 	netboxClient.Logger.Debugf(ctx, "Getting netbox's version")
-	response, err := netboxClient.doRequest(http.MethodGet, "/api/status", nil)
+	response, err := netboxClient.doRequest(ctx, http.MethodGet, "/api/status", nil)
 	if err != nil {
 		return "", err
 	}
@@ -46,7 +48,12 @@ func GetVersion(ctx context.Context, netboxClient *NetboxClient) (string, error)
 }
 
 // GetAll queries all objects of type T from Netbox's API.
-// It is querying objects via pagination of limit=200.
+// The first page is used to get the total count of objects, the remaining
+// pages are then fetched concurrently by netboxClient.PageWorkers workers.
+// Each page is retried on its own, and pages are merged in their order,
+// so the result is the same as when fetching pages one by one.
+// If Netbox returns fewer objects per page than requested, because of its
+// MAX_PAGE_SIZE setting, the returned page size is used instead.
 //
 // extraParams in a string format of: &extraParam1=...&extraParam2=...
 func GetAll[T any](
@@ -54,51 +61,78 @@ func GetAll[T any](
 	netboxClient *NetboxClient,
 	extraParams string,
 ) ([]T, error) {
-	var allResults []T
 	var dummy T // Dummy variable for extracting type of generic
 	path := mapper.Type2Path[reflect.TypeOf(dummy)]
 	if path == "" {
 		return nil, fmt.Errorf("path not found for type %T", dummy)
 	}
-	limit := 250
-	offset := 0
+	limit := netboxClient.PageSize
+	if limit <= 0 {
+		limit = constants.DefaultAPIPageSize
+	}
 
 	netboxClient.Logger.Debugf(ctx, "Getting all %T from Netbox", dummy)
 
-	for {
-		netboxClient.Logger.Debugf(
+	firstPage, err := getPage[T](ctx, netboxClient, path, limit, 0, extraParams)
+	if err != nil {
+		return nil, err
+	}
+	allResults := firstPage.Results
+	next := firstPage.Next
+
+	// Netbox clamps limit to its MAX_PAGE_SIZE, so offsets of the remaining
+	// pages are stepped by the number of objects it actually returned
+	if next != nil && len(firstPage.Results) > 0 && len(firstPage.Results) < limit {
+		netboxClient.Logger.Warningf(
 			ctx,
-			"Getting %T with limit=%d and offset=%d",
-			dummy,
-			limit,
-			offset,
+			"Netbox returned %d objects per page instead of %d, using page size %d",
+			len(firstPage.Results), limit, len(firstPage.Results),
 		)
-		queryPath := fmt.Sprintf("%s?limit=%d&offset=%d%s", path, limit, offset, extraParams)
-		response, err := netboxClient.doRequest(http.MethodGet, queryPath, nil)
-		if err != nil {
-			return nil, err
-		}
+		limit = len(firstPage.Results)
+	}
 
-		if response.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf(
-				"unexpected status code %d: %s",
-				response.StatusCode,
-				response.Body,
-			)
+	if next != nil {
+		remainingPages := make([]int, 0)
+		for offset := limit; offset < firstPage.Count; offset += limit {
+			remainingPages = append(remainingPages, offset)
 		}
-
-		var responseObj Response[T]
-		err = json.Unmarshal(response.Body, &responseObj)
+		pages, err := fetchPages(
+			ctx,
+			netboxClient,
+			remainingPages,
+			func(ctx context.Context, offset int) (*Response[T], error) {
+				return getPage[T](ctx, netboxClient, path, limit, offset, extraParams)
+			},
+		)
 		if err != nil {
 			return nil, err
 		}
-
-		allResults = append(allResults, responseObj.Results...)
-
-		if responseObj.Next == nil {
-			break
+		for _, page := range pages {
+			allResults = append(allResults, page.Results...)
+			next = page.Next
+		}
+		// Objects created while the pages were fetched are fetched one by one
+		offset := limit * (len(remainingPages) + 1)
+		for next != nil {
+			page, err := getPage[T](ctx, netboxClient, path, limit, offset, extraParams)
+			if err != nil {
+				return nil, err
+			}
+			allResults = append(allResults, page.Results...)
+			next = page.Next
+			offset += limit
 		}
-		offset += limit
+	}
+
+	// Pages overlap if objects were moved to earlier pages while they were
+	// fetched, and objects are skipped, if objects were deleted from them
+	allResults = uniqueByID(allResults)
+	if len(allResults) < firstPage.Count {
+		netboxClient.Logger.Warningf(
+			ctx,
+			"Received %d %T instead of %d, objects were probably deleted while they were fetched",
+			len(allResults), dummy, firstPage.Count,
+		)
 	}
 
 	netboxClient.Logger.Debugf(ctx, "Successfully received all %T: %v", dummy, allResults)
@@ -106,6 +140,118 @@ func GetAll[T any](
 	return allResults, nil
 }
 
+// uniqueByID returns objects without duplicates with the same id, keeping
+// the first one. Objects, that don't implement GetID, are all kept.
+func uniqueByID[T any](objs []T) []T {
+	seen := make(map[int]bool, len(objs))
+	unique := objs[:0]
+	for i := range objs {
+		if idItem, ok := any(&objs[i]).(interface{ GetID() int }); ok {
+			if seen[idItem.GetID()] {
+				continue
+			}
+			seen[idItem.GetID()] = true
+		}
+		unique = append(unique, objs[i])
+	}
+	return unique
+}
+
+// fetchPages fetches pages at the given offsets concurrently with
+// netboxClient.PageWorkers workers, and returns them in the same order
+// as offsets. Fetching stops on the first error.
+func fetchPages[P any](
+	ctx context.Context,
+	netboxClient *NetboxClient,
+	offsets []int,
+	fetch func(ctx context.Context, offset int) (P, error),
+) ([]P, error) {
+	workers := min(max(netboxClient.PageWorkers, 1), len(offsets))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pages := make([]P, len(offsets))
+	pageIndexes := make(chan int)
+	var firstErr error
+	var errOnce sync.Once
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pageIndexes {
+				page, err := fetch(ctx, offsets[i])
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				pages[i] = page
+			}
+		}()
+	}
+sendPages:
+	for i := range offsets {
+		select {
+		case pageIndexes <- i:
+		case <-ctx.Done():
+			break sendPages
+		}
+	}
+	close(pageIndexes)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// getPage fetches a single page of objects of type T.
+func getPage[T any](
+	ctx context.Context,
+	netboxClient *NetboxClient,
+	path constants.APIPath,
+	limit int,
+	offset int,
+	extraParams string,
+) (*Response[T], error) {
+	var dummy T
+	netboxClient.Logger.Debugf(
+		ctx,
+		"Getting %T with limit=%d and offset=%d",
+		dummy,
+		limit,
+		offset,
+	)
+	// Pages are ordered by id, so objects created while the pages are
+	// fetched are appended to the last page and don't shift other pages
+	queryPath := fmt.Sprintf("%s?limit=%d&offset=%d&ordering=id%s", path, limit, offset, extraParams)
+	response, err := netboxClient.doRequest(ctx, http.MethodGet, queryPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"unexpected status code %d: %s",
+			response.StatusCode,
+			response.Body,
+		)
+	}
+
+	var responseObj Response[T]
+	err = decodeResponse(netboxClient, path, response.Body, &responseObj)
+	if err != nil {
+		return nil, err
+	}
+	return &responseObj, nil
+}
+
 // Patch func patches the object of type T, with the given api path and body.
 // Path of the object (must contain the id), for example /api/dcim/devices/1/.
 func Patch[T any](
@@ -119,38 +265,87 @@ func Patch[T any](
 	if objectPath == "" {
 		return nil, fmt.Errorf("path not found for type %T", dummy)
 	}
+
+	if netboxClient.Plan != nil {
+		netboxClient.Plan.RecordPatch(ctx, objectPath, objectID, body)
+		return getPlannedObject[T](ctx, netboxClient, objectPath, objectID, body)
+	}
+
+	var objectResponse T
+	err := patchObject(ctx, netboxClient, objectPath, objectID, body, &objectResponse)
+	if err != nil {
+		return nil, err
+	}
+	return &objectResponse, nil
+}
+
+// PatchObject patches the object in Netbox with the given body. Unlike Patch,
+// it can be used when the type of the object is only known at runtime
+// (e.g. for orphans), so the patched object is not returned.
+func (api *NetboxClient) PatchObject(ctx context.Context, idItem objects.IDItem, body map[string]interface{}) error {
+	objectPath := idItem.GetAPIPath()
+	if api.Plan != nil {
+		api.Plan.RecordPatch(ctx, objectPath, idItem.GetID(), body)
+		return nil
+	}
+	patchedObject := reflect.New(reflect.TypeOf(idItem).Elem()).Interface()
+	return patchObject(ctx, api, objectPath, idItem.GetID(), body, patchedObject)
+}
+
+// patchObject sends the patch of the object with objectID on objectPath,
+// and decodes the patched object into patchedObject.
+func patchObject(
+	ctx context.Context,
+	netboxClient *NetboxClient,
+	objectPath constants.APIPath,
+	objectID int,
+	body map[string]interface{},
+	patchedObject interface{},
+) error {
 	path := fmt.Sprintf("%s%d/", objectPath, objectID)
 	netboxClient.Logger.Debugf(
 		ctx,
 		"Patching %T with path %s with data: %v",
-		dummy,
+		patchedObject,
 		path,
 		body,
 	)
 
-	requestBody, err := json.Marshal(body)
+	requestBody, err := json.Marshal(netboxClient.Capabilities.AdaptRequest(objectPath, body))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	requestBodyBuffer := bytes.NewBuffer(requestBody)
-	response, err := netboxClient.doRequest(http.MethodPatch, path, requestBodyBuffer)
+	response, err := netboxClient.doRequest(ctx, http.MethodPatch, path, requestBodyBuffer)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d: %s", response.StatusCode, response.Body)
+		return fmt.Errorf("unexpected status code: %d: %s", response.StatusCode, response.Body)
 	}
 
-	var objectResponse T
-	err = json.Unmarshal(response.Body, &objectResponse)
+	err = decodeResponse(netboxClient, objectPath, response.Body, patchedObject)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	netboxClient.Logger.Debugf(ctx, "Successfully patched %T: %v", dummy, objectResponse)
-	return &objectResponse, nil
+	metrics.ObjectChanges.WithLabelValues(sourceFromCtx(ctx), string(objectPath), metrics.ActionUpdate).Inc()
+	netboxClient.Logger.Debugf(ctx, "Successfully patched %T: %v", patchedObject, patchedObject)
+	journalBody := make(map[string]interface{}, len(body)+1)
+	for k, v := range body {
+		journalBody[k] = v
+	}
+	journalBody["id"] = objectID
+	writeJournalEntries(
+		ctx,
+		netboxClient,
+		patchedObject,
+		objects.ObjectChangeActionUpdate,
+		[]map[string]interface{}{journalBody},
+	)
+	return nil
 }
 
 // Create func creates the new NetboxObject of type T, with the given api path and body.
@@ -168,13 +363,33 @@ func Create[T any](ctx context.Context, netboxClient *NetboxClient, object *T) (
 		objectPath,
 		object,
 	)
-	requestBody, err := utils.NetboxJSONMarshal(object)
+
+	if netboxClient.Plan != nil {
+		plannedObject := *object
+		id := netboxClient.Plan.RecordCreate(
+			ctx,
+			objectPath,
+			&plannedObject,
+			utils.StructToNetboxJSONMap(object),
+		)
+		setPlaceholderID(&plannedObject, id)
+		return &plannedObject, nil
+	}
+
+	var requestBody []byte
+	var err error
+	if netboxClient.Capabilities.AdaptsRequests(objectPath) {
+		body := netboxClient.Capabilities.AdaptRequest(objectPath, utils.StructToNetboxJSONMap(object))
+		requestBody, err = json.Marshal(body)
+	} else {
+		requestBody, err = utils.NetboxJSONMarshal(object)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	requestBodyBuffer := bytes.NewBuffer(requestBody)
-	response, err := netboxClient.doRequest(http.MethodPost, string(objectPath), requestBodyBuffer)
+	response, err := netboxClient.doRequest(ctx, http.MethodPost, string(objectPath), requestBodyBuffer)
 	if err != nil {
 		return nil, err
 	}
@@ -184,15 +399,231 @@ func Create[T any](ctx context.Context, netboxClient *NetboxClient, object *T) (
 	}
 
 	var objectResponse T
-	err = json.Unmarshal(response.Body, &objectResponse)
+	err = decodeResponse(netboxClient, objectPath, response.Body, &objectResponse)
 	if err != nil {
 		return nil, err
 	}
 
+	metrics.ObjectChanges.WithLabelValues(sourceFromCtx(ctx), string(objectPath), metrics.ActionCreate).Inc()
 	netboxClient.Logger.Debugf(ctx, "Successfully created %T: %v", dummy, objectResponse)
+	writeJournalEntries(ctx, netboxClient, &objectResponse, objects.ObjectChangeActionCreate, idBodies(&objectResponse))
 	return &objectResponse, nil
 }
 
+// BulkCreate creates all objects of type T, using Netbox's bulk create,
+// which accepts a list of objects in the request body. Objects are created
+// in batches of batchSize, and created objects are returned in the same
+// order as the given objects. When a batch fails, objects of the batches
+// that were already created are returned together with the error.
+func BulkCreate[T any](
+	ctx context.Context,
+	netboxClient *NetboxClient,
+	newObjects []*T,
+	batchSize int,
+) ([]*T, error) {
+	var dummy T // dummy variable for printf
+	objectPath := mapper.Type2Path[reflect.TypeOf(dummy)]
+	if objectPath == "" {
+		return nil, fmt.Errorf("path not found for type %T", dummy)
+	}
+
+	// Plan records each create separately, so placeholder ids are given
+	// the same way as without bulk create
+	if netboxClient.Plan != nil {
+		created := make([]*T, 0, len(newObjects))
+		for _, object := range newObjects {
+			createdObject, err := Create(ctx, netboxClient, object)
+			if err != nil {
+				return created, err
+			}
+			created = append(created, createdObject)
+		}
+		return created, nil
+	}
+
+	created := make([]*T, 0, len(newObjects))
+	for _, batch := range batches(newObjects, batchSize) {
+		netboxClient.Logger.Debugf(
+			ctx,
+			"Bulk creating %d %T with path %s",
+			len(batch),
+			dummy,
+			objectPath,
+		)
+		body := make([]map[string]interface{}, 0, len(batch))
+		for _, object := range batch {
+			body = append(body, utils.StructToNetboxJSONMap(object))
+		}
+		createdBatch, err := bulkRequest[T](ctx, netboxClient, http.MethodPost, objectPath, body, http.StatusCreated)
+		if err != nil {
+			return created, err
+		}
+		created = append(created, createdBatch...)
+		metrics.ObjectChanges.WithLabelValues(sourceFromCtx(ctx), string(objectPath), metrics.ActionCreate).
+			Add(float64(len(createdBatch)))
+		writeJournalEntries(ctx, netboxClient, &dummy, objects.ObjectChangeActionCreate, idBodies(createdBatch...))
+	}
+	netboxClient.Logger.Debugf(ctx, "Successfully bulk created %d %T", len(created), dummy)
+	return created, nil
+}
+
+// BulkPatch patches objects of type T, using Netbox's bulk update, which
+// accepts a list of patches in the request body. Each body must contain
+// the id of the object it patches. Objects are patched in batches of
+// batchSize, and patched objects are returned in the same order as bodies.
+func BulkPatch[T any](
+	ctx context.Context,
+	netboxClient *NetboxClient,
+	bodies []map[string]interface{},
+	batchSize int,
+) ([]*T, error) {
+	var dummy T // dummy variable for printf
+	objectPath := mapper.Type2Path[reflect.TypeOf(dummy)]
+	if objectPath == "" {
+		return nil, fmt.Errorf("path not found for type %T", dummy)
+	}
+	for _, body := range bodies {
+		if _, ok := body["id"].(int); !ok {
+			return nil, fmt.Errorf("bulk patch of %T: body %v has no id", dummy, body)
+		}
+	}
+
+	if netboxClient.Plan != nil {
+		patched := make([]*T, 0, len(bodies))
+		for _, body := range bodies {
+			patchBody := make(map[string]interface{}, len(body))
+			for k, v := range body {
+				if k != "id" {
+					patchBody[k] = v
+				}
+			}
+			patchedObject, err := Patch[T](ctx, netboxClient, body["id"].(int), patchBody)
+			if err != nil {
+				return nil, err
+			}
+			patched = append(patched, patchedObject)
+		}
+		return patched, nil
+	}
+
+	patched := make([]*T, 0, len(bodies))
+	for _, batch := range batches(bodies, batchSize) {
+		netboxClient.Logger.Debugf(
+			ctx,
+			"Bulk patching %d %T with path %s with data: %v",
+			len(batch),
+			dummy,
+			objectPath,
+			batch,
+		)
+		patchedBatch, err := bulkRequest[T](ctx, netboxClient, http.MethodPatch, objectPath, batch, http.StatusOK)
+		if err != nil {
+			return nil, err
+		}
+		patched = append(patched, patchedBatch...)
+		metrics.ObjectChanges.WithLabelValues(sourceFromCtx(ctx), string(objectPath), metrics.ActionUpdate).
+			Add(float64(len(patchedBatch)))
+		writeJournalEntries(ctx, netboxClient, &dummy, objects.ObjectChangeActionUpdate, batch)
+	}
+	netboxClient.Logger.Debugf(ctx, "Successfully bulk patched %d %T", len(patched), dummy)
+	return patched, nil
+}
+
+// bulkRequest sends the list body to the objectPath, and returns the
+// list of objects from the response.
+func bulkRequest[T any](
+	ctx context.Context,
+	netboxClient *NetboxClient,
+	method string,
+	objectPath constants.APIPath,
+	body []map[string]interface{},
+	expectedStatusCode int,
+) ([]*T, error) {
+	adaptedBody := body
+	if netboxClient.Capabilities.AdaptsRequests(objectPath) {
+		adaptedBody = make([]map[string]interface{}, 0, len(body))
+		for _, object := range body {
+			adaptedBody = append(adaptedBody, netboxClient.Capabilities.AdaptRequest(objectPath, object))
+		}
+	}
+	requestBody, err := json.Marshal(adaptedBody)
+	if err != nil {
+		return nil, err
+	}
+
+	requestBodyBuffer := bytes.NewBuffer(requestBody)
+	response, err := netboxClient.doRequest(ctx, method, string(objectPath), requestBodyBuffer)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != expectedStatusCode {
+		return nil, fmt.Errorf("unexpected status code: %d: %s", response.StatusCode, response.Body)
+	}
+
+	var objectsResponse []*T
+	err = decodeResponse(netboxClient, objectPath, response.Body, &objectsResponse)
+	if err != nil {
+		return nil, err
+	}
+	if len(objectsResponse) != len(body) {
+		return nil, fmt.Errorf(
+			"bulk request returned %d objects instead of %d",
+			len(objectsResponse),
+			len(body),
+		)
+	}
+	return objectsResponse, nil
+}
+
+// decodeResponse unmarshals the response body of objects on path into v.
+// Objects are adapted to the connected Netbox release first (see Capabilities.AdaptResponse).
+// Body can be a single object, a list of objects or a page of objects.
+func decodeResponse(netboxClient *NetboxClient, path constants.APIPath, body []byte, v interface{}) error {
+	if !netboxClient.Capabilities.AdaptsResponses(path) {
+		return json.Unmarshal(body, v)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return err
+	}
+	var objectList []interface{}
+	switch decoded := decoded.(type) {
+	case []interface{}:
+		objectList = decoded
+	case map[string]interface{}:
+		if results, ok := decoded["results"].([]interface{}); ok {
+			objectList = results
+		} else {
+			objectList = []interface{}{decoded}
+		}
+	}
+	for _, object := range objectList {
+		if object, ok := object.(map[string]interface{}); ok {
+			netboxClient.Capabilities.AdaptResponse(path, object)
+		}
+	}
+	adapted, err := json.Marshal(decoded)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(adapted, v)
+}
+
+// batches splits items into consecutive batches of at most batchSize items.
+// Non-positive batchSize returns all items in a single batch.
+func batches[T any](items []T, batchSize int) [][]T {
+	if batchSize <= 0 {
+		batchSize = len(items)
+	}
+	var result [][]T
+	for start := 0; start < len(items); start += batchSize {
+		end := min(start+batchSize, len(items))
+		result = append(result, items[start:end])
+	}
+	return result
+}
+
 // Function that deletes object on path objectPath.
 // It deletes objects in pages of 50 so we don't stress
 // the API too much.
@@ -209,6 +640,13 @@ func (api *NetboxClient) BulkDeleteObjects(
 		ids = append(ids, id)
 	}
 
+	if api.Plan != nil {
+		for _, id := range ids {
+			api.Plan.RecordDelete(ctx, objectPath, id, "")
+		}
+		return nil
+	}
+
 	for i := 0; i < len(ids); i += pageSize {
 		api.Logger.Debugf(
 			ctx,
@@ -235,7 +673,7 @@ func (api *NetboxClient) BulkDeleteObjects(
 		}
 
 		requestBodyBuffer := bytes.NewBuffer(requestBody)
-		response, err := api.doRequest(http.MethodDelete, string(objectPath), requestBodyBuffer)
+		response, err := api.doRequest(ctx, http.MethodDelete, string(objectPath), requestBodyBuffer)
 		if err != nil {
 			return err
 		}
@@ -243,6 +681,8 @@ func (api *NetboxClient) BulkDeleteObjects(
 		if response.StatusCode != http.StatusNoContent {
 			return fmt.Errorf("unexpected status code: %d: %s", response.StatusCode, response.Body)
 		}
+		metrics.ObjectChanges.WithLabelValues(sourceFromCtx(ctx), string(objectPath), metrics.ActionDelete).
+			Add(float64(end - i))
 	}
 	api.Logger.Debugf(ctx, "Successfully deleted all objects of path %s", objectPath)
 
@@ -257,7 +697,12 @@ func (api *NetboxClient) DeleteObject(ctx context.Context, idItem objects.IDItem
 	objectPath := idItem.GetAPIPath()
 	api.Logger.Debugf(ctx, "Deleting object with id %d on route %s", id, objectPath)
 
-	response, err := api.doRequest(http.MethodDelete, fmt.Sprintf("%s%d/", objectPath, id), nil)
+	if api.Plan != nil {
+		api.Plan.RecordDelete(ctx, objectPath, id, fmt.Sprintf("%v", idItem))
+		return nil
+	}
+
+	response, err := api.doRequest(ctx, http.MethodDelete, fmt.Sprintf("%s%d/", objectPath, id), nil)
 	if err != nil {
 		return err
 	}
@@ -265,5 +710,50 @@ func (api *NetboxClient) DeleteObject(ctx context.Context, idItem objects.IDItem
 	if response.StatusCode != http.StatusNoContent {
 		return fmt.Errorf("unexpected status code: %d: %s", response.StatusCode, response.Body)
 	}
+	metrics.ObjectChanges.WithLabelValues(sourceFromCtx(ctx), string(objectPath), metrics.ActionDelete).Inc()
 	return nil
 }
+
+// getPlannedObject returns the object with the given id, as it would be
+// after the patch with the given body. It is used in dry-run mode, where
+// patches are only recorded. Objects that were created or patched in the
+// same dry-run are taken from the plan, others are fetched from the Netbox API.
+func getPlannedObject[T any](
+	ctx context.Context,
+	netboxClient *NetboxClient,
+	objectPath constants.APIPath,
+	objectID int,
+	body map[string]interface{},
+) (*T, error) {
+	var object T
+	if plannedObject, ok := netboxClient.Plan.plannedObject(objectPath, objectID); ok {
+		if plannedObject, ok := plannedObject.(*T); ok {
+			// Planned object is copied, because it can be referenced by the inventory
+			object = *plannedObject
+		}
+	} else {
+		response, err := netboxClient.doRequest(
+			ctx,
+			http.MethodGet,
+			fmt.Sprintf("%s%d/", objectPath, objectID),
+			nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if response.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code: %d: %s", response.StatusCode, response.Body)
+		}
+		err = decodeResponse(netboxClient, objectPath, response.Body, &object)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyPatch(reflect.ValueOf(&object).Elem(), body); err != nil {
+		return nil, fmt.Errorf("apply patch to %T with id %d: %s", object, objectID, err)
+	}
+	netboxClient.Plan.storePlannedObject(objectPath, objectID, &object)
+	netboxClient.Logger.Debugf(ctx, "Dry-run: recorded patch of %T with id %d", object, objectID)
+	return &object, nil
+}
@@ -2,11 +2,17 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/src-doo/netbox-ssot/internal/constants"
 	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/netbox/version"
 )
 
 func TestGetAll(t *testing.T) {
@@ -70,6 +76,106 @@ func TestGetAll(t *testing.T) {
 	}
 }
 
+func TestGetAllPages(t *testing.T) {
+	setTestBackoff(t)
+	tests := []struct {
+		name        string
+		count       int
+		pageSize    int
+		pageWorkers int
+		maxPageSize int
+		failOffsets map[int]int
+		// overlap moves start of pages after the first one back by overlap objects
+		overlap int
+		wantErr bool
+	}{
+		{name: "single page", count: 3, pageSize: 5, pageWorkers: 4},
+		{name: "sequential pages", count: 23, pageSize: 5, pageWorkers: 1},
+		{name: "concurrent pages", count: 1003, pageSize: 10, pageWorkers: 8},
+		{name: "page size clamped by netbox", count: 47, pageSize: 20, pageWorkers: 3, maxPageSize: 6},
+		{name: "overlapping pages are deduplicated", count: 30, pageSize: 10, pageWorkers: 2, overlap: 2},
+		{
+			name:        "failed page is retried",
+			count:       50,
+			pageSize:    10,
+			pageWorkers: 3,
+			failOffsets: map[int]int{20: 1, 40: 2},
+		},
+		{
+			name:        "failed page",
+			count:       50,
+			pageSize:    10,
+			pageWorkers: 3,
+			failOffsets: map[int]int{30: 10},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			requests := make(map[int]int)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if ordering := r.URL.Query().Get("ordering"); ordering != "id" {
+					t.Errorf("ordering = %q, want id", ordering)
+				}
+				limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+				offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+				if tt.maxPageSize > 0 {
+					limit = min(limit, tt.maxPageSize)
+				}
+				mu.Lock()
+				requests[offset]++
+				failed := requests[offset] <= tt.failOffsets[offset]
+				mu.Unlock()
+				if failed {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				response := Response[objects.Tag]{Count: tt.count, Results: []objects.Tag{}}
+				for id := max(offset-tt.overlap, 0); id < min(offset+limit, tt.count); id++ {
+					response.Results = append(response.Results, objects.Tag{ID: id})
+				}
+				if offset+limit < tt.count {
+					next := "next"
+					response.Next = &next
+				}
+				_ = json.NewEncoder(w).Encode(response)
+			}))
+			defer server.Close()
+			api := &NetboxClient{
+				HTTPClient:  &http.Client{},
+				Logger:      MockNetboxClient.Logger,
+				BaseURL:     server.URL,
+				Timeout:     constants.DefaultAPITimeout,
+				MaxRetries:  constants.DefaultAPIMaxRetries,
+				PageSize:    tt.pageSize,
+				PageWorkers: tt.pageWorkers,
+			}
+
+			tags, err := GetAll[objects.Tag](context.Background(), api, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(tags) != tt.count {
+				t.Fatalf("GetAll() returned %d objects, want %d", len(tags), tt.count)
+			}
+			for i, tag := range tags {
+				if tag.ID != i {
+					t.Fatalf("GetAll()[%d] has id %d, want objects in page order", i, tag.ID)
+				}
+			}
+			for offset, got := range requests {
+				if want := tt.failOffsets[offset] + 1; got != want {
+					t.Errorf("page with offset %d was requested %d times, want %d", offset, got, want)
+				}
+			}
+		})
+	}
+}
+
 func TestPatch(t *testing.T) {
 	type args struct {
 		ctx      context.Context
@@ -123,6 +229,35 @@ func TestPatch(t *testing.T) {
 	}
 }
 
+func TestPatchObject(t *testing.T) {
+	tests := []struct {
+		name    string
+		object  objects.IDItem
+		body    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:   "Test patch tag",
+			object: &objects.Tag{ID: 1},
+			body: map[string]interface{}{
+				"description": "new description",
+			},
+		},
+	}
+	for _, tt := range tests {
+		mockServer := CreateMockServer()
+		defer mockServer.Close()
+		MockNetboxClient.BaseURL = mockServer.URL
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), constants.CtxSourceKey, "test")
+			err := MockNetboxClient.PatchObject(ctx, tt.object, tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PatchObject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestCreate(t *testing.T) {
 	type args struct {
 		ctx    context.Context
@@ -201,3 +336,203 @@ func TestBulkDeleteObjects(t *testing.T) {
 		})
 	}
 }
+
+func TestBulkCreate(t *testing.T) {
+	tests := []struct {
+		name         string
+		tags         []*objects.Tag
+		batchSize    int
+		wantRequests int
+		wantCreated  int
+		wantErr      bool
+	}{
+		{
+			name:         "Test bulk create tags in a single batch",
+			tags:         []*objects.Tag{{Name: "tag1"}, {Name: "tag2"}},
+			batchSize:    10,
+			wantRequests: 1,
+			wantCreated:  2,
+		},
+		{
+			name:         "Test bulk create tags in multiple batches",
+			tags:         []*objects.Tag{{Name: "tag1"}, {Name: "tag2"}, {Name: "tag3"}},
+			batchSize:    2,
+			wantRequests: 2,
+			wantCreated:  3,
+		},
+		{
+			name:         "Test bulk create with wrong response",
+			tags:         []*objects.Tag{{Name: "wrong"}},
+			batchSize:    2,
+			wantRequests: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "Test bulk create returns batches created before the error",
+			tags:         []*objects.Tag{{Name: "tag1"}, {Name: "tag2"}, {Name: "wrong"}},
+			batchSize:    2,
+			wantRequests: 2,
+			wantCreated:  2,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			lastID := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				var body []map[string]interface{}
+				if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&body) != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				response := make([]objects.Tag, 0, len(body))
+				for _, tag := range body {
+					if tag["name"] == "wrong" {
+						continue
+					}
+					lastID++
+					response = append(response, objects.Tag{ID: lastID, Name: tag["name"].(string)})
+				}
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(response)
+			}))
+			defer server.Close()
+			api := &NetboxClient{
+				HTTPClient: &http.Client{},
+				Logger:     MockNetboxClient.Logger,
+				BaseURL:    server.URL,
+				Timeout:    constants.DefaultAPITimeout,
+			}
+
+			created, err := BulkCreate(context.Background(), api, tt.tags, tt.batchSize)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BulkCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("BulkCreate() sent %d requests, want %d", requests, tt.wantRequests)
+			}
+			if len(created) != tt.wantCreated {
+				t.Fatalf("BulkCreate() created %d tags, want %d", len(created), tt.wantCreated)
+			}
+			for i, tag := range created {
+				if tag.ID != i+1 || tag.Name != tt.tags[i].Name {
+					t.Errorf("BulkCreate()[%d] = %+v, want tag %s with id %d", i, tag, tt.tags[i].Name, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestBulkPatch(t *testing.T) {
+	var gotBody []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || json.NewDecoder(r.Body).Decode(&gotBody) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		response := make([]objects.Tag, 0, len(gotBody))
+		for _, patch := range gotBody {
+			response = append(response, objects.Tag{
+				ID:          int(patch["id"].(float64)),
+				Description: patch["description"].(string),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+	api := &NetboxClient{
+		HTTPClient: &http.Client{},
+		Logger:     MockNetboxClient.Logger,
+		BaseURL:    server.URL,
+		Timeout:    constants.DefaultAPITimeout,
+	}
+
+	bodies := []map[string]interface{}{
+		{"id": 1, "description": "first"},
+		{"id": 2, "description": "second"},
+	}
+	patched, err := BulkPatch[objects.Tag](context.Background(), api, bodies, 10)
+	if err != nil {
+		t.Fatalf("BulkPatch() error = %v", err)
+	}
+	want := []*objects.Tag{{ID: 1, Description: "first"}, {ID: 2, Description: "second"}}
+	if !reflect.DeepEqual(patched, want) {
+		t.Errorf("BulkPatch() = %v, want %v", patched, want)
+	}
+	wantBody := []map[string]interface{}{
+		{"id": float64(1), "description": "first"},
+		{"id": float64(2), "description": "second"},
+	}
+	if !reflect.DeepEqual(gotBody, wantBody) {
+		t.Errorf("BulkPatch() sent %v, want %v", gotBody, wantBody)
+	}
+
+	_, err = BulkPatch[objects.Tag](context.Background(), api, []map[string]interface{}{{"description": "no id"}}, 10)
+	if err == nil {
+		t.Errorf("BulkPatch() without id returned no error")
+	}
+}
+
+func TestCreate_SiteScopedPrefix(t *testing.T) {
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":     1,
+			"prefix": "10.0.0.0/24",
+			"site":   map[string]interface{}{"id": 1, "name": "site"},
+		})
+	}))
+	defer server.Close()
+	api := &NetboxClient{
+		HTTPClient:   &http.Client{},
+		Logger:       MockNetboxClient.Logger,
+		BaseURL:      server.URL,
+		Timeout:      constants.DefaultAPITimeout,
+		Capabilities: version.Of(version.Version{Major: 4, Minor: 1}),
+	}
+
+	prefix, err := Create(context.Background(), api, &objects.Prefix{
+		Prefix:    "10.0.0.0/24",
+		ScopeType: constants.ContentTypeDcimSite,
+		ScopeID:   1,
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if gotBody["prefix"] != "10.0.0.0/24" || gotBody["site"] != 1.0 {
+		t.Errorf("Create() sent %v, want prefix with site 1", gotBody)
+	}
+	for _, field := range []string{"scope_type", "scope_id"} {
+		if _, ok := gotBody[field]; ok {
+			t.Errorf("Create() sent unsupported field %s", field)
+		}
+	}
+	if prefix.ScopeType != constants.ContentTypeDcimSite || prefix.ScopeID != 1 {
+		t.Errorf("Create() returned scope %s %d, want site 1", prefix.ScopeType, prefix.ScopeID)
+	}
+}
+
+func TestBatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		items     []int
+		batchSize int
+		want      [][]int
+	}{
+		{name: "Empty", items: nil, batchSize: 2, want: nil},
+		{name: "Exact batches", items: []int{1, 2, 3, 4}, batchSize: 2, want: [][]int{{1, 2}, {3, 4}}},
+		{name: "Last batch smaller", items: []int{1, 2, 3}, batchSize: 2, want: [][]int{{1, 2}, {3}}},
+		{name: "No batch size", items: []int{1, 2, 3}, batchSize: 0, want: [][]int{{1, 2, 3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := batches(tt.items, tt.batchSize); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("batches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
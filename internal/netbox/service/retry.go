@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/src-doo/netbox-ssot/internal/utils"
+)
+
+// maxRetryAfter limits the wait requested by the Retry-After header.
+const maxRetryAfter = 5 * time.Minute
+
+// retryBackoff is a variable, so tests can shorten it.
+var retryBackoff = utils.Backoff{Initial: 500 * time.Millisecond, Max: 30 * time.Second, Jitter: true}
+
+// isIdempotent returns true for requests that can be safely retried,
+// because repeating them has the same effect as sending them once.
+//
+// Bulk deletes (DELETE with a list of objects in the body) are not retried:
+// if the response of the first attempt is lost, the retry fails, because
+// some of the objects no longer exist, and it can't be told which.
+func isIdempotent(method string, hasBody bool) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut:
+		return true
+	case http.MethodDelete:
+		return !hasBody
+	}
+	return false
+}
+
+// isDeletedOnRetry returns true if the DELETE request failed on a retry,
+// because the object doesn't exist (404). That happens when the object was
+// deleted by a previous attempt, whose response was lost.
+func isDeletedOnRetry(method string, attempt int, response *APIResponse) bool {
+	return method == http.MethodDelete && attempt > 0 && response != nil &&
+		response.StatusCode == http.StatusNotFound
+}
+
+// isRetryableStatus returns true for status codes of transient errors,
+// such as rate limiting (429) or an unavailable upstream (502, 503, 504).
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isRetryableError returns true for errors of requests that didn't receive
+// any response, unless they were caused by ctx being canceled.
+func isRetryableError(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && !errors.Is(err, context.Canceled)
+}
+
+// retryAfter parses the Retry-After header, which is either a number of
+// seconds or an HTTP date. False is returned if the header is not set or invalid.
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		wait = date.Sub(now)
+	} else {
+		return 0, false
+	}
+	return min(max(wait, 0), maxRetryAfter), true
+}
+
+// sleep waits for the duration d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{name: "Not set", value: "", want: 0, wantOk: false},
+		{name: "Seconds", value: "120", want: 2 * time.Minute, wantOk: true},
+		{name: "HTTP date", value: "Mon, 01 Jan 2024 12:00:30 GMT", want: 30 * time.Second, wantOk: true},
+		{name: "HTTP date in the past", value: "Mon, 01 Jan 2024 11:00:00 GMT", want: 0, wantOk: true},
+		{name: "Too long", value: "86400", want: maxRetryAfter, wantOk: true},
+		{name: "Invalid", value: "soon", want: 0, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.value != "" {
+				header.Set("Retry-After", tt.value)
+			}
+			got, ok := retryAfter(header, now)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("retryAfter() = %s, %t, want %s, %t", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
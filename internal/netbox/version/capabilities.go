@@ -0,0 +1,288 @@
+package version
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+)
+
+// Capability is a feature of Netbox, that is only available in some releases.
+type Capability string
+
+const (
+	// MACAddressObjects are MAC addresses stored as standalone objects,
+	// and assigned to interfaces with primary_mac_address (Netbox 4.2).
+	MACAddressObjects Capability = "mac-address-objects"
+	// ScopedPrefixes are prefixes assigned to a scope (site, location,
+	// region...), which replaced their site field (Netbox 4.2).
+	ScopedPrefixes Capability = "scoped-prefixes"
+	// ScopedClusters are clusters assigned to a scope, which replaced
+	// their site field (Netbox 4.2).
+	ScopedClusters Capability = "scoped-clusters"
+	// ScopedVlanGroups are vlan groups assigned to a scope (Netbox 2.11).
+	ScopedVlanGroups Capability = "scoped-vlan-groups"
+)
+
+// Fields of scoped objects.
+const (
+	scopeTypeField = "scope_type"
+	scopeIDField   = "scope_id"
+)
+
+type capabilitySpec struct {
+	// since is the first release with the capability.
+	since Version
+	// until is the first release without the capability.
+	// It is zero when the capability wasn't removed.
+	until Version
+	// fields are json fields of objects, that can only be sent to releases with the capability.
+	fields map[constants.APIPath][]string
+	// renamedFields are json fields of objects by their names in releases
+	// with the capability, that have other names in releases without it.
+	renamedFields map[constants.APIPath]map[string]string
+	// objectTypes are object types, that only exist in releases with the capability.
+	objectTypes []constants.ContentType
+	// scopeTypes are types of scopes, that objects on path can be assigned
+	// to in releases with the capability.
+	scopeTypes map[constants.APIPath][]constants.ContentType
+}
+
+// registry holds all capabilities that netbox-ssot depends on.
+// When a Netbox release adds, removes or renames fields used by
+// netbox-ssot, a capability with these fields is added here.
+var registry = map[Capability]capabilitySpec{
+	MACAddressObjects: {
+		since: Version{Major: 4, Minor: 2},
+		fields: map[constants.APIPath][]string{
+			constants.InterfacesAPIPath:   {"primary_mac_address"},
+			constants.VMInterfacesAPIPath: {"primary_mac_address"},
+		},
+		objectTypes: []constants.ContentType{constants.ContentTypeDcimMACAddress},
+	},
+	ScopedPrefixes: {
+		since:         Version{Major: 4, Minor: 2},
+		renamedFields: map[constants.APIPath]map[string]string{constants.PrefixesAPIPath: {scopeIDField: "site"}},
+		scopeTypes:    map[constants.APIPath][]constants.ContentType{constants.PrefixesAPIPath: siteScopeTypes},
+	},
+	ScopedClusters: {
+		since:         Version{Major: 4, Minor: 2},
+		renamedFields: map[constants.APIPath]map[string]string{constants.ClustersAPIPath: {scopeIDField: "site"}},
+		scopeTypes:    map[constants.APIPath][]constants.ContentType{constants.ClustersAPIPath: siteScopeTypes},
+	},
+	ScopedVlanGroups: {
+		since: Version{Major: 2, Minor: 11},
+		fields: map[constants.APIPath][]string{
+			constants.VlanGroupsAPIPath: {scopeTypeField, scopeIDField},
+		},
+		scopeTypes: map[constants.APIPath][]constants.ContentType{
+			constants.VlanGroupsAPIPath: append(
+				slices.Clone(siteScopeTypes),
+				constants.ContentTypeDcimRack,
+				constants.ContentTypeVirtualizationClusterGroup,
+				constants.ContentTypeVirtualizationCluster,
+			),
+		},
+	},
+}
+
+// siteScopeTypes are scope types of objects, that are scoped to sites
+// and their parents or children.
+var siteScopeTypes = []constants.ContentType{
+	constants.ContentTypeDcimRegion,
+	constants.ContentTypeDcimSiteGroup,
+	constants.ContentTypeDcimSite,
+	constants.ContentTypeDcimLocation,
+}
+
+// Capabilities are capabilities of a single Netbox release.
+// Nil Capabilities are treated as a release with all capabilities.
+type Capabilities struct {
+	Version      Version
+	capabilities map[Capability]bool
+	// unsupportedFields are fields of capabilities, that the release doesn't have.
+	unsupportedFields map[constants.APIPath]map[string]bool
+	// renamedFields are names of fields in the release by their names in
+	// releases with all capabilities.
+	renamedFields map[constants.APIPath]map[string]string
+	// unsupportedObjectTypes are object types of capabilities, that the release doesn't have.
+	unsupportedObjectTypes map[constants.ContentType]bool
+	// scopeTypes are types of scopes, that the release accepts for objects on path.
+	scopeTypes map[constants.APIPath]map[constants.ContentType]bool
+}
+
+// Of returns capabilities of the given Netbox release.
+func Of(v Version) *Capabilities {
+	c := &Capabilities{
+		Version:                v,
+		capabilities:           make(map[Capability]bool),
+		unsupportedFields:      make(map[constants.APIPath]map[string]bool),
+		renamedFields:          make(map[constants.APIPath]map[string]string),
+		unsupportedObjectTypes: make(map[constants.ContentType]bool),
+		scopeTypes:             make(map[constants.APIPath]map[constants.ContentType]bool),
+	}
+	for capability, spec := range registry {
+		supported := v.AtLeast(spec.since) && (spec.until.IsZero() || !v.AtLeast(spec.until))
+		c.capabilities[capability] = supported
+		for path, scopeTypes := range spec.scopeTypes {
+			if c.scopeTypes[path] == nil {
+				c.scopeTypes[path] = make(map[constants.ContentType]bool)
+			}
+			switch {
+			case supported:
+				for _, scopeType := range scopeTypes {
+					c.scopeTypes[path][scopeType] = true
+				}
+			case spec.renamedFields[path][scopeIDField] != "":
+				// Scope of the release is a site
+				c.scopeTypes[path][constants.ContentTypeDcimSite] = true
+			}
+		}
+		if supported {
+			continue
+		}
+		for path, fields := range spec.fields {
+			if c.unsupportedFields[path] == nil {
+				c.unsupportedFields[path] = make(map[string]bool)
+			}
+			for _, field := range fields {
+				c.unsupportedFields[path][field] = true
+			}
+		}
+		for path, fields := range spec.renamedFields {
+			if c.renamedFields[path] == nil {
+				c.renamedFields[path] = make(map[string]string)
+			}
+			for field, releaseField := range fields {
+				c.renamedFields[path][field] = releaseField
+			}
+		}
+		for _, objectType := range spec.objectTypes {
+			c.unsupportedObjectTypes[objectType] = true
+		}
+	}
+	return c
+}
+
+// Has returns true if the release has the capability.
+func (c *Capabilities) Has(capability Capability) bool {
+	return c == nil || c.capabilities[capability]
+}
+
+// UnsupportedFields returns sorted fields of objects on path,
+// that can't be sent to the release.
+func (c *Capabilities) UnsupportedFields(path constants.APIPath) []string {
+	if c == nil {
+		return nil
+	}
+	fields := make([]string, 0, len(c.unsupportedFields[path]))
+	for field := range c.unsupportedFields[path] {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+// SupportedObjectTypes returns objectTypes without object types,
+// that the release doesn't have. ObjectTypes are not modified.
+func (c *Capabilities) SupportedObjectTypes(objectTypes []constants.ContentType) []constants.ContentType {
+	if c == nil || len(c.unsupportedObjectTypes) == 0 {
+		return objectTypes
+	}
+	return slices.DeleteFunc(slices.Clone(objectTypes), func(objectType constants.ContentType) bool {
+		return c.unsupportedObjectTypes[objectType]
+	})
+}
+
+// QueryFields returns fields, that must be requested from the release,
+// for objects on path with the given comma separated json fields.
+func (c *Capabilities) QueryFields(path constants.APIPath, fields string) string {
+	if !c.AdaptsResponses(path) {
+		return fields
+	}
+	releaseFields := make([]string, 0, len(c.renamedFields[path]))
+	for _, releaseField := range c.renamedFields[path] {
+		releaseFields = append(releaseFields, releaseField)
+	}
+	slices.Sort(releaseFields)
+	return fields + "," + strings.Join(releaseFields, ",")
+}
+
+// AdaptsRequests returns true if request bodies of objects on path
+// must be adapted with AdaptRequest.
+func (c *Capabilities) AdaptsRequests(path constants.APIPath) bool {
+	return c != nil && (len(c.unsupportedFields[path]) > 0 || c.AdaptsResponses(path) || c.scopeTypes[path] != nil)
+}
+
+// AdaptsResponses returns true if objects on path, returned by the release,
+// must be adapted with AdaptResponse.
+func (c *Capabilities) AdaptsResponses(path constants.APIPath) bool {
+	return c != nil && len(c.renamedFields[path]) > 0
+}
+
+// AdaptRequest returns the request body of objects on path in the form
+// accepted by the release: scopes of types, that the release doesn't accept
+// for objects on path, and unsupported fields are removed, and renamed fields
+// get their names in the release. When the scope id is renamed (e.g. to the
+// site of prefixes in releases without scoped prefixes), the scope type is
+// removed as well, because the renamed field only references sites.
+// The body itself is not modified.
+func (c *Capabilities) AdaptRequest(path constants.APIPath, body map[string]interface{}) map[string]interface{} {
+	if !c.AdaptsRequests(path) {
+		return body
+	}
+	adapted := make(map[string]interface{}, len(body))
+	for field, value := range body {
+		if c.unsupportedFields[path][field] {
+			continue
+		}
+		adapted[field] = value
+	}
+	scopeType, ok := adapted[scopeTypeField]
+	if ok && scopeType != nil && c.scopeTypes[path] != nil &&
+		!c.scopeTypes[path][constants.ContentType(fmt.Sprint(scopeType))] {
+		delete(adapted, scopeTypeField)
+		delete(adapted, scopeIDField)
+	}
+	for field, releaseField := range c.renamedFields[path] {
+		if field == scopeIDField {
+			delete(adapted, scopeTypeField)
+		}
+		value, ok := adapted[field]
+		if !ok {
+			continue
+		}
+		delete(adapted, field)
+		adapted[releaseField] = value
+	}
+	return adapted
+}
+
+// AdaptResponse converts the object on path, returned by the release,
+// to the form used by objects of netbox-ssot: renamed fields get their
+// names in releases with all capabilities, and references of renamed
+// fields are replaced with their ids. Objects with renamed scope id
+// (e.g. prefixes with site) are assigned to a scope of the site.
+func (c *Capabilities) AdaptResponse(path constants.APIPath, object map[string]interface{}) {
+	if !c.AdaptsResponses(path) {
+		return
+	}
+	for field, releaseField := range c.renamedFields[path] {
+		value, ok := object[releaseField]
+		if !ok {
+			continue
+		}
+		delete(object, releaseField)
+		if reference, ok := value.(map[string]interface{}); ok {
+			value = reference["id"]
+		}
+		if value == nil {
+			continue
+		}
+		object[field] = value
+		if field == scopeIDField {
+			object[scopeTypeField] = string(constants.ContentTypeDcimSite)
+		}
+	}
+}
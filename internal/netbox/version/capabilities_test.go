@@ -0,0 +1,290 @@
+package version
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+)
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		name                  string
+		version               Version
+		wantMACAddresses      bool
+		wantScopedPrefixes    bool
+		wantUnsupportedIfaces []string
+	}{
+		{
+			name:                  "before scopes and mac addresses",
+			version:               Version{Major: 4, Minor: 1, Patch: 9},
+			wantMACAddresses:      false,
+			wantScopedPrefixes:    false,
+			wantUnsupportedIfaces: []string{"primary_mac_address"},
+		},
+		{
+			name:                  "scopes and mac addresses",
+			version:               Version{Major: 4, Minor: 2},
+			wantMACAddresses:      true,
+			wantScopedPrefixes:    true,
+			wantUnsupportedIfaces: []string{},
+		},
+		{
+			name:                  "next major",
+			version:               Version{Major: 5},
+			wantMACAddresses:      true,
+			wantScopedPrefixes:    true,
+			wantUnsupportedIfaces: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Of(tt.version)
+			if got := c.Has(MACAddressObjects); got != tt.wantMACAddresses {
+				t.Errorf("Has(MACAddressObjects) = %t, want %t", got, tt.wantMACAddresses)
+			}
+			if got := c.Has(ScopedPrefixes); got != tt.wantScopedPrefixes {
+				t.Errorf("Has(ScopedPrefixes) = %t, want %t", got, tt.wantScopedPrefixes)
+			}
+			got := c.UnsupportedFields(constants.InterfacesAPIPath)
+			if !reflect.DeepEqual(got, tt.wantUnsupportedIfaces) {
+				t.Errorf("UnsupportedFields() = %v, want %v", got, tt.wantUnsupportedIfaces)
+			}
+		})
+	}
+}
+
+func TestCapabilities_SupportedObjectTypes(t *testing.T) {
+	objectTypes := []constants.ContentType{constants.ContentTypeDcimInterface, constants.ContentTypeDcimMACAddress}
+	if got := Of(Version{Major: 4, Minor: 2}).SupportedObjectTypes(objectTypes); !reflect.DeepEqual(got, objectTypes) {
+		t.Errorf("SupportedObjectTypes() = %v, want %v", got, objectTypes)
+	}
+	want := []constants.ContentType{constants.ContentTypeDcimInterface}
+	if got := Of(Version{Major: 4, Minor: 1}).SupportedObjectTypes(objectTypes); !reflect.DeepEqual(got, want) {
+		t.Errorf("SupportedObjectTypes() = %v, want %v", got, want)
+	}
+	if len(objectTypes) != 2 {
+		t.Errorf("SupportedObjectTypes() modified object types to %v", objectTypes)
+	}
+}
+
+func TestCapabilities_AdaptRequest(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities *Capabilities
+		path         constants.APIPath
+		body         map[string]interface{}
+		wantRequest  map[string]interface{}
+	}{
+		{
+			name:         "nil capabilities",
+			capabilities: nil,
+			path:         constants.PrefixesAPIPath,
+			body:         map[string]interface{}{"scope_type": constants.ContentTypeDcimSite, "scope_id": 1},
+			wantRequest:  map[string]interface{}{"scope_type": constants.ContentTypeDcimSite, "scope_id": 1},
+		},
+		{
+			name:         "scoped prefix",
+			capabilities: Of(Version{Major: 4, Minor: 2}),
+			path:         constants.PrefixesAPIPath,
+			body:         map[string]interface{}{"scope_type": constants.ContentTypeDcimSite, "scope_id": 1},
+			wantRequest:  map[string]interface{}{"scope_type": constants.ContentTypeDcimSite, "scope_id": 1},
+		},
+		{
+			name:         "site scoped prefix",
+			capabilities: Of(Version{Major: 4, Minor: 1}),
+			path:         constants.PrefixesAPIPath,
+			body: map[string]interface{}{
+				"prefix":     "10.0.0.0/24",
+				"scope_type": constants.ContentTypeDcimSite,
+				"scope_id":   1,
+			},
+			wantRequest: map[string]interface{}{"prefix": "10.0.0.0/24", "site": 1},
+		},
+		{
+			name:         "site scoped cluster patched with scope id only",
+			capabilities: Of(Version{Major: 4, Minor: 1}),
+			path:         constants.ClustersAPIPath,
+			body:         map[string]interface{}{"scope_id": 2},
+			wantRequest:  map[string]interface{}{"site": 2},
+		},
+		{
+			name:         "reset scope of site scoped cluster",
+			capabilities: Of(Version{Major: 4, Minor: 1}),
+			path:         constants.ClustersAPIPath,
+			body:         map[string]interface{}{"scope_type": nil, "scope_id": nil},
+			wantRequest:  map[string]interface{}{"site": nil},
+		},
+		{
+			name:         "location scope of site scoped cluster",
+			capabilities: Of(Version{Major: 4, Minor: 1}),
+			path:         constants.ClustersAPIPath,
+			body:         map[string]interface{}{"name": "cluster", "scope_type": "dcim.location", "scope_id": 3},
+			wantRequest:  map[string]interface{}{"name": "cluster"},
+		},
+		{
+			name:         "location scope of prefix",
+			capabilities: Of(Version{Major: 4, Minor: 2}),
+			path:         constants.PrefixesAPIPath,
+			body:         map[string]interface{}{"scope_type": "dcim.location", "scope_id": 3},
+			wantRequest:  map[string]interface{}{"scope_type": "dcim.location", "scope_id": 3},
+		},
+		{
+			name:         "rack scope of prefix",
+			capabilities: Of(Version{Major: 4, Minor: 2}),
+			path:         constants.PrefixesAPIPath,
+			body:         map[string]interface{}{"prefix": "10.0.0.0/24", "scope_type": "dcim.rack", "scope_id": 3},
+			wantRequest:  map[string]interface{}{"prefix": "10.0.0.0/24"},
+		},
+		{
+			name:         "rack scope of vlan group",
+			capabilities: Of(Version{Major: 4, Minor: 2}),
+			path:         constants.VlanGroupsAPIPath,
+			body:         map[string]interface{}{"scope_type": "dcim.rack", "scope_id": 3},
+			wantRequest:  map[string]interface{}{"scope_type": "dcim.rack", "scope_id": 3},
+		},
+		{
+			name:         "location scope of vlan group",
+			capabilities: Of(Version{Major: 4, Minor: 1}),
+			path:         constants.VlanGroupsAPIPath,
+			body:         map[string]interface{}{"scope_type": "dcim.location", "scope_id": 3},
+			wantRequest:  map[string]interface{}{"scope_type": "dcim.location", "scope_id": 3},
+		},
+		{
+			name:         "device scope of vlan group",
+			capabilities: Of(Version{Major: 4, Minor: 2}),
+			path:         constants.VlanGroupsAPIPath,
+			body:         map[string]interface{}{"name": "vlans", "scope_type": "dcim.device", "scope_id": 3},
+			wantRequest:  map[string]interface{}{"name": "vlans"},
+		},
+		{
+			name:         "rack scope of vlan group in the next major",
+			capabilities: Of(Version{Major: 5}),
+			path:         constants.VlanGroupsAPIPath,
+			body:         map[string]interface{}{"scope_type": "dcim.rack", "scope_id": 3},
+			wantRequest:  map[string]interface{}{"scope_type": "dcim.rack", "scope_id": 3},
+		},
+		{
+			name:         "unsupported field",
+			capabilities: Of(Version{Major: 4, Minor: 1}),
+			path:         constants.InterfacesAPIPath,
+			body:         map[string]interface{}{"name": "eth0", "primary_mac_address": 1},
+			wantRequest:  map[string]interface{}{"name": "eth0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := make(map[string]interface{}, len(tt.body))
+			for k, v := range tt.body {
+				body[k] = v
+			}
+			request := tt.capabilities.AdaptRequest(tt.path, body)
+			if !reflect.DeepEqual(request, tt.wantRequest) {
+				t.Errorf("AdaptRequest() = %v, want %v", request, tt.wantRequest)
+			}
+			if !reflect.DeepEqual(body, tt.body) {
+				t.Errorf("AdaptRequest() modified the body to %v", body)
+			}
+		})
+	}
+}
+
+func TestCapabilities_AdaptResponse(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities *Capabilities
+		object       map[string]interface{}
+		want         map[string]interface{}
+	}{
+		{
+			name:         "scoped prefix",
+			capabilities: Of(Version{Major: 4, Minor: 2}),
+			object:       map[string]interface{}{"scope_type": "dcim.site", "scope_id": 1.0},
+			want:         map[string]interface{}{"scope_type": "dcim.site", "scope_id": 1.0},
+		},
+		{
+			name:         "site scoped prefix",
+			capabilities: Of(Version{Major: 4, Minor: 1}),
+			object: map[string]interface{}{
+				"prefix": "10.0.0.0/24",
+				"site":   map[string]interface{}{"id": 1.0, "name": "site"},
+			},
+			want: map[string]interface{}{"prefix": "10.0.0.0/24", "scope_type": "dcim.site", "scope_id": 1.0},
+		},
+		{
+			name:         "prefix in the next major",
+			capabilities: Of(Version{Major: 5}),
+			object:       map[string]interface{}{"scope_type": "dcim.location", "scope_id": 2.0, "site": nil},
+			want:         map[string]interface{}{"scope_type": "dcim.location", "scope_id": 2.0, "site": nil},
+		},
+		{
+			name:         "site scoped prefix without site",
+			capabilities: Of(Version{Major: 4, Minor: 1}),
+			object:       map[string]interface{}{"prefix": "10.0.0.0/24", "site": nil},
+			want:         map[string]interface{}{"prefix": "10.0.0.0/24"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.capabilities.AdaptResponse(constants.PrefixesAPIPath, tt.object)
+			if !reflect.DeepEqual(tt.object, tt.want) {
+				t.Errorf("AdaptResponse() = %v, want %v", tt.object, tt.want)
+			}
+		})
+	}
+}
+
+func TestCapabilities_renamedFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		version Version
+		path    constants.APIPath
+		want    map[string]string
+	}{
+		{
+			name:    "prefix before scopes",
+			version: Version{Major: 4, Minor: 1},
+			path:    constants.PrefixesAPIPath,
+			want:    map[string]string{"scope_id": "site"},
+		},
+		{
+			name:    "cluster before scopes",
+			version: Version{Major: 4, Minor: 1},
+			path:    constants.ClustersAPIPath,
+			want:    map[string]string{"scope_id": "site"},
+		},
+		{
+			name:    "prefix with scopes",
+			version: Version{Major: 4, Minor: 2},
+			path:    constants.PrefixesAPIPath,
+		},
+		{
+			name:    "vlan group",
+			version: Version{Major: 4, Minor: 1},
+			path:    constants.VlanGroupsAPIPath,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Of(tt.version)
+			got := c.renamedFields[tt.path]
+			if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("renamedFields[%s] = %v, want %v", tt.path, got, tt.want)
+			}
+			if got := c.AdaptsResponses(tt.path); got != (len(tt.want) > 0) {
+				t.Errorf("AdaptsResponses() = %t, want %t", got, len(tt.want) > 0)
+			}
+		})
+	}
+}
+
+func TestCapabilities_QueryFields(t *testing.T) {
+	got := Of(Version{Major: 4, Minor: 1}).QueryFields(constants.ClustersAPIPath, "id,scope_id")
+	if got != "id,scope_id,site" {
+		t.Errorf("QueryFields() = %s, want id,scope_id,site", got)
+	}
+	got = Of(Version{Major: 4, Minor: 2}).QueryFields(constants.ClustersAPIPath, "id,scope_id")
+	if got != "id,scope_id" {
+		t.Errorf("QueryFields() = %s, want id,scope_id", got)
+	}
+}
@@ -0,0 +1,80 @@
+// Package version holds Netbox versions, and capabilities of Netbox
+// releases that netbox-ssot depends on. Capabilities determine which
+// fields and objects can be sent to the connected Netbox, and how they are encoded.
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Version is a version of a Netbox release.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+var (
+	// MinSupported is the oldest Netbox release that works with netbox-ssot.
+	// It is 4.1, because all differences between 4.1 and 4.2, that affect
+	// netbox-ssot (MAC address objects, scopes of prefixes and clusters),
+	// are covered by the capability registry. Older releases differ in
+	// fields, which are not in the registry.
+	MinSupported = Version{Major: 4, Minor: 1}
+	// LatestTested is the newest Netbox release that netbox-ssot was tested with.
+	// Newer releases are supported according to the capability registry.
+	LatestTested = Version{Major: 4, Minor: 2, Patch: 3}
+)
+
+// versionRegex matches versions like 4.2, 4.2.3, v4.2.3 or 4.3.0-beta1.
+// Suffixes (e.g. of pre-releases or docker images) are ignored.
+var versionRegex = regexp.MustCompile(`^v?(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// Parse parses the version returned by Netbox's status endpoint.
+func Parse(version string) (Version, error) {
+	match := versionRegex.FindStringSubmatch(version)
+	if match == nil {
+		return Version{}, fmt.Errorf("invalid version %q", version)
+	}
+	var components [3]int
+	for i, component := range match[1:] {
+		if component == "" {
+			continue
+		}
+		value, err := strconv.Atoi(component)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid version %q: %s", version, err)
+		}
+		components[i] = value
+	}
+	return Version{Major: components[0], Minor: components[1], Patch: components[2]}, nil
+}
+
+// Compare returns -1 if v is older than other, 1 if it is newer and 0 if they are equal.
+func (v Version) Compare(other Version) int {
+	for _, diff := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if diff < 0 {
+			return -1
+		}
+		if diff > 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+// AtLeast returns true if v is the same or newer than other.
+func (v Version) AtLeast(other Version) bool {
+	return v.Compare(other) >= 0
+}
+
+// IsZero returns true for the zero version.
+func (v Version) IsZero() bool {
+	return v == Version{}
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
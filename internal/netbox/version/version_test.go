@@ -0,0 +1,57 @@
+package version
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		version string
+		want    Version
+		wantErr bool
+	}{
+		{version: "4.2.3", want: Version{Major: 4, Minor: 2, Patch: 3}},
+		{version: "v4.2.3", want: Version{Major: 4, Minor: 2, Patch: 3}},
+		{version: "4.3", want: Version{Major: 4, Minor: 3}},
+		{version: "4.3.0-beta1", want: Version{Major: 4, Minor: 3}},
+		{version: "5.0.1-Docker-3.2.0", want: Version{Major: 5, Patch: 1}},
+		{version: "4", wantErr: true},
+		{version: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := Parse(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	tests := []struct {
+		name  string
+		v     Version
+		other Version
+		want  int
+	}{
+		{name: "equal", v: Version{4, 2, 3}, other: Version{4, 2, 3}, want: 0},
+		{name: "older patch", v: Version{4, 2, 1}, other: Version{4, 2, 3}, want: -1},
+		{name: "newer minor", v: Version{4, 3, 0}, other: Version{4, 2, 3}, want: 1},
+		{name: "newer major", v: Version{5, 0, 0}, other: Version{4, 9, 9}, want: 1},
+		{name: "older major", v: Version{3, 7, 8}, other: Version{4, 0, 0}, want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.v.Compare(tt.other); got != tt.want {
+				t.Errorf("Compare() = %d, want %d", got, tt.want)
+			}
+			if got := tt.v.AtLeast(tt.other); got != (tt.want >= 0) {
+				t.Errorf("AtLeast() = %t, want %t", got, tt.want >= 0)
+			}
+		})
+	}
+}
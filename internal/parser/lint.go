@@ -0,0 +1,254 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+)
+
+// relationSourceTypes contains source types that use each of the relations.
+// Relations configured for other source types are ignored.
+var relationSourceTypes = map[string][]constants.SourceType{
+	"datacenterClusterGroupRelations": {constants.Ovirt, constants.Vmware},
+	"hostSiteRelations": {
+		constants.FMC, constants.Fortigate, constants.IOSXE, constants.Ovirt,
+		constants.PaloAlto, constants.Proxmox, constants.Vmware,
+	},
+	"hostRoleRelations": {
+		constants.Dnac, constants.FMC, constants.Fortigate, constants.IOSXE,
+		constants.Ovirt, constants.PaloAlto, constants.Proxmox, constants.Vmware,
+	},
+	"clusterSiteRelations":   {constants.Ovirt, constants.Proxmox, constants.Vmware},
+	"clusterTenantRelations": {constants.Ovirt, constants.Proxmox, constants.Vmware},
+	"hostTenantRelations": {
+		constants.Dnac, constants.FMC, constants.Fortigate, constants.IOSXE,
+		constants.Ovirt, constants.PaloAlto, constants.Proxmox, constants.Vmware,
+	},
+	"vmTenantRelations": {constants.Proxmox, constants.Vmware},
+	"vmRoleRelations":   {constants.Ovirt, constants.Proxmox, constants.Vmware},
+	"vlanGroupRelations": {
+		constants.Dnac, constants.FMC, constants.Fortigate, constants.Ovirt, constants.PaloAlto, constants.Vmware,
+	},
+	"vlanGroupSiteRelations": {
+		constants.Dnac, constants.FMC, constants.Fortigate, constants.Ovirt, constants.PaloAlto, constants.Vmware,
+	},
+	"vlanTenantRelations": {
+		constants.Dnac, constants.FMC, constants.Fortigate, constants.Ovirt, constants.PaloAlto, constants.Vmware,
+	},
+	"vlanSiteRelations": {
+		constants.Dnac, constants.FMC, constants.Fortigate, constants.Ovirt, constants.PaloAlto, constants.Vmware,
+	},
+	"wlanTenantRelations": {},
+	"customFieldMappings": {constants.Vmware},
+}
+
+// Lint returns warnings about likely mistakes in the valid config,
+// that don't prevent netbox-ssot from running.
+func Lint(config *Config) []string {
+	var warnings []string
+	warnings = append(warnings, lintSourcePriority(config)...)
+	for i := range config.Sources {
+		warnings = append(warnings, lintRelations(&config.Sources[i])...)
+	}
+	return warnings
+}
+
+// lintSourcePriority warns about sourcePriority entries, that have no effect.
+// Validation requires an entry for every source, so sources can only be
+// missing from sourcePriority when other entries are duplicated.
+func lintSourcePriority(config *Config) []string {
+	var warnings []string
+	if len(config.Netbox.SourcePriority) == 1 {
+		warnings = append(
+			warnings,
+			"netbox.sourcePriority: has no effect with a single source",
+		)
+	}
+	seen := make(map[string]bool, len(config.Netbox.SourcePriority))
+	for _, sourceName := range config.Netbox.SourcePriority {
+		if seen[sourceName] {
+			warnings = append(warnings, fmt.Sprintf(
+				"netbox.sourcePriority: %s is listed more than once, only its last position is used",
+				sourceName,
+			))
+		}
+		seen[sourceName] = true
+	}
+	return warnings
+}
+
+// lintRelations warns about relations that are never used by the source,
+// relations that can never match and relations that match all names.
+func lintRelations(sourceConfig *SourceConfig) []string {
+	var warnings []string
+	relations := sourceConfig.relations()
+	relationNames := make([]string, 0, len(relations))
+	for relationName := range relations {
+		relationNames = append(relationNames, relationName)
+	}
+	sort.Strings(relationNames)
+	for _, relationName := range relationNames {
+		relation := relations[relationName]
+		if len(relation) == 0 {
+			continue
+		}
+		if !usesRelation(sourceConfig.Type, relationName) {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s.%s: is not used by sources of type %s",
+				sourceConfig.Name,
+				relationName,
+				sourceConfig.Type,
+			))
+			continue
+		}
+		regexes := make([]string, 0, len(relation))
+		for regex := range relation {
+			regexes = append(regexes, regex)
+		}
+		sort.Strings(regexes)
+		for _, regex := range regexes {
+			if neverMatches(regex) {
+				warnings = append(warnings, fmt.Sprintf(
+					"%s.%s: regex %s can never match",
+					sourceConfig.Name,
+					relationName,
+					regex,
+				))
+			} else if len(relation) > 1 && matchesEverything(regex) {
+				// Relations are stored in a map, so they are matched in random order
+				warnings = append(warnings, fmt.Sprintf(
+					"%s.%s: regex %s matches all names, so other relations are matched in random order",
+					sourceConfig.Name,
+					relationName,
+					regex,
+				))
+			}
+		}
+	}
+	return warnings
+}
+
+// relations returns all relations of the source config by their yaml name.
+func (sc *SourceConfig) relations() map[string]map[string]string {
+	return map[string]map[string]string{
+		"datacenterClusterGroupRelations": sc.DatacenterClusterGroupRelations,
+		"hostSiteRelations":               sc.HostSiteRelations,
+		"hostRoleRelations":               sc.HostRoleRelations,
+		"clusterSiteRelations":            sc.ClusterSiteRelations,
+		"clusterTenantRelations":          sc.ClusterTenantRelations,
+		"hostTenantRelations":             sc.HostTenantRelations,
+		"vmTenantRelations":               sc.VMTenantRelations,
+		"vmRoleRelations":                 sc.VMRoleRelations,
+		"vlanGroupRelations":              sc.VlanGroupRelations,
+		"vlanGroupSiteRelations":          sc.VlanGroupSiteRelations,
+		"vlanTenantRelations":             sc.VlanTenantRelations,
+		"vlanSiteRelations":               sc.VlanSiteRelations,
+		"wlanTenantRelations":             sc.WlanTenantRelations,
+		"customFieldMappings":             sc.CustomFieldMappings,
+	}
+}
+
+func usesRelation(sourceType constants.SourceType, relationName string) bool {
+	for _, relationSourceType := range relationSourceTypes[relationName] {
+		if relationSourceType == sourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// neverMatches returns true if the regex can't match any string, e.g.
+// because it expects characters after the end or before the beginning of the text.
+func neverMatches(regex string) bool {
+	re, err := syntax.Parse(regex, syntax.Perl)
+	if err != nil {
+		return false
+	}
+	return !canMatch(re.Simplify())
+}
+
+func canMatch(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpCharClass:
+		return len(re.Rune) > 0
+	case syntax.OpCapture, syntax.OpPlus:
+		return canMatch(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min == 0 || canMatch(re.Sub[0])
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if canMatch(sub) {
+				return true
+			}
+		}
+		return false
+	case syntax.OpConcat:
+		afterEnd := false
+		consumed := false
+		for _, sub := range re.Sub {
+			if !canMatch(sub) {
+				return false
+			}
+			switch {
+			case sub.Op == syntax.OpBeginText && consumed:
+				return false
+			case sub.Op == syntax.OpEndText:
+				afterEnd = true
+			case minLength(sub) > 0:
+				if afterEnd {
+					return false
+				}
+				consumed = true
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// minLength returns the minimum number of characters matched by the regex.
+func minLength(re *syntax.Regexp) int {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune)
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return 1
+	case syntax.OpCapture, syntax.OpPlus:
+		return minLength(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min * minLength(re.Sub[0])
+	case syntax.OpConcat:
+		length := 0
+		for _, sub := range re.Sub {
+			length += minLength(sub)
+		}
+		return length
+	case syntax.OpAlternate:
+		length := -1
+		for _, sub := range re.Sub {
+			if subLength := minLength(sub); length == -1 || subLength < length {
+				length = subLength
+			}
+		}
+		return max(length, 0)
+	default:
+		return 0
+	}
+}
+
+// matchesEverything returns true if the regex matches all names. Relations
+// are matched with unanchored search, so this is true for every regex that
+// matches both the empty string and an arbitrary name.
+func matchesEverything(regex string) bool {
+	re, err := regexp.Compile(regex)
+	if err != nil {
+		return false
+	}
+	return re.MatchString("") && re.MatchString("\x00arbitrary name\x00")
+}
@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+)
+
+func TestNeverMatches(t *testing.T) {
+	tests := []struct {
+		regex string
+		want  bool
+	}{
+		{regex: ".*", want: false},
+		{regex: "^Cluster_.*$", want: false},
+		{regex: "prod$", want: false},
+		{regex: "(a|b$)c", want: false},
+		{regex: "prod$-cluster", want: true},
+		{regex: "cluster^prod", want: true},
+		{regex: "(prod$x|y^z)", want: true},
+		{regex: "[^\\x00-\\x{10FFFF}]", want: true},
+		{regex: "(?m)prod$\\ncluster", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.regex, func(t *testing.T) {
+			if got := neverMatches(tt.regex); got != tt.want {
+				t.Errorf("neverMatches(%q) = %v, want %v", tt.regex, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesEverything(t *testing.T) {
+	tests := []struct {
+		regex string
+		want  bool
+	}{
+		{regex: ".*", want: true},
+		{regex: "^.*$", want: true},
+		{regex: "x*", want: true},
+		{regex: "", want: true},
+		{regex: "^$", want: false},
+		{regex: ".*Health", want: false},
+		{regex: "^a*$", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.regex, func(t *testing.T) {
+			if got := matchesEverything(tt.regex); got != tt.want {
+				t.Errorf("matchesEverything(%q) = %v, want %v", tt.regex, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+		want   []string
+	}{
+		{
+			name: "No warnings",
+			config: &Config{
+				Netbox: &NetboxConfig{SourcePriority: []string{"vmware", "dnac"}},
+				Sources: []SourceConfig{
+					{
+						Name:              "vmware",
+						Type:              constants.Vmware,
+						HostSiteRelations: map[string]string{".*": "Berlin"},
+						VMTenantRelations: map[string]string{".*Health": "Health", "^Stark": "Stark"},
+					},
+					{Name: "dnac", Type: constants.Dnac},
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "Unused source priority",
+			config: &Config{
+				Netbox:  &NetboxConfig{SourcePriority: []string{"vmware"}},
+				Sources: []SourceConfig{{Name: "vmware", Type: constants.Vmware}},
+			},
+			want: []string{"netbox.sourcePriority: has no effect with a single source"},
+		},
+		{
+			name: "Duplicated source priority",
+			config: &Config{
+				Netbox: &NetboxConfig{SourcePriority: []string{"vmware", "vmware"}},
+				Sources: []SourceConfig{
+					{Name: "vmware", Type: constants.Vmware},
+					{Name: "dnac", Type: constants.Dnac},
+				},
+			},
+			want: []string{
+				"netbox.sourcePriority: vmware is listed more than once, only its last position is used",
+			},
+		},
+		{
+			name: "Relation warnings",
+			config: &Config{
+				Netbox: &NetboxConfig{},
+				Sources: []SourceConfig{
+					{
+						Name:                   "dnac",
+						Type:                   constants.Dnac,
+						ClusterTenantRelations: map[string]string{".*": "Default"},
+						HostTenantRelations:    map[string]string{".*": "Default", ".*Health": "Health"},
+						HostRoleRelations:      map[string]string{"switch$-core": "Core switch"},
+					},
+				},
+			},
+			want: []string{
+				"dnac.clusterTenantRelations: is not used by sources of type dnac",
+				"dnac.hostRoleRelations: regex switch$-core can never match",
+				"dnac.hostTenantRelations: regex .* matches all names, so other relations are matched in random order",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lint(tt.config); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
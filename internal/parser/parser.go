@@ -1,12 +1,20 @@
 package parser
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
 	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/schedule"
 	"github.com/src-doo/netbox-ssot/internal/utils"
 	"gopkg.in/yaml.v3"
 )
@@ -15,6 +23,7 @@ type Config struct {
 	Logger  *LoggerConfig  `yaml:"logger"`
 	Netbox  *NetboxConfig  `yaml:"netbox"`
 	Sources []SourceConfig `yaml:"source"`
+	Daemon  *DaemonConfig  `yaml:"daemon"`
 }
 
 type LoggerConfig struct {
@@ -68,6 +77,25 @@ func (l LoggerConfig) String() string {
 	return fmt.Sprintf("LoggerConfig{Level: %d, Dest: %s}", l.Level, l.Dest)
 }
 
+// Configuration used when netbox-ssot is run in daemon mode.
+// In daemon block.
+type DaemonConfig struct {
+	// Default schedule for sources, that don't have their own schedule.
+	// Can be either an interval (e.g. 30m) or a cron expression (e.g. */30 * * * *).
+	Schedule string `yaml:"schedule"`
+	// Between full refreshes, netbox inventory is only updated with
+	// objects that were changed since the last run.
+	FullRefreshInterval time.Duration `yaml:"fullRefreshInterval"`
+}
+
+func (d DaemonConfig) String() string {
+	return fmt.Sprintf(
+		"DaemonConfig{Schedule: %s, FullRefreshInterval: %s}",
+		d.Schedule,
+		d.FullRefreshInterval,
+	)
+}
+
 type HTTPScheme string
 
 const (
@@ -78,39 +106,153 @@ const (
 // Configuration that can be used for Netbox.
 // In netbox block.
 type NetboxConfig struct {
-	APIToken string `yaml:"apiToken"`
+	APIToken Secret `yaml:"apiToken"`
 	Hostname string `yaml:"hostname"`
 	Port     int    `yaml:"port"`
 	// Can be http or https (default)
 	HTTPScheme             HTTPScheme `yaml:"httpScheme"`
 	ValidateCert           bool       `yaml:"validateCert"`
 	Timeout                int        `yaml:"timeout"`
+	MaxRetries             int        `yaml:"maxRetries"`
 	Tag                    string     `yaml:"tag"`
 	TagColor               string     `yaml:"tagColor"`
 	RemoveOrphans          bool       `yaml:"removeOrphans"`
 	RemoveOrphansAfterDays int        `yaml:"removeOrphansAfterDays"`
 	SourcePriority         []string   `yaml:"sourcePriority"`
 	CAFile                 string     `yaml:"caFile"`
+	// ClientCert and ClientKey are paths of the client certificate and
+	// its key, that are used for mutual TLS authentication.
+	ClientCert string `yaml:"clientCert"`
+	ClientKey  string `yaml:"clientKey"`
+	// ProxyURL is the URL of the HTTP proxy, that requests are sent through.
+	ProxyURL string `yaml:"proxyURL"`
+	// MaxOrphans is the maximum number of objects of each type, or of each
+	// source, that can be orphaned in a single run. 0 means no limit.
+	MaxOrphans int `yaml:"maxOrphans"`
+	// MaxOrphansPercent is the maximum percentage of managed objects of each
+	// type, or of each source, that can be orphaned in a single run. 0 means no limit.
+	MaxOrphansPercent float64 `yaml:"maxOrphansPercent"`
+	// BulkSize is the maximum number of objects sent to Netbox in a single
+	// bulk create or bulk update request. 0 disables bulk writes.
+	BulkSize int `yaml:"bulkSize"`
+	// PageSize is the number of objects fetched from Netbox in a single page.
+	PageSize int `yaml:"pageSize"`
+	// PageWorkers is the number of pages of the same object type that are
+	// fetched from Netbox concurrently.
+	PageWorkers int `yaml:"pageWorkers"`
+	// StateFile is the path of the file where objects collected from Netbox
+	// are saved, so the next run only collects objects that changed since.
+	StateFile string `yaml:"stateFile"`
+	// StateMaxAgeDays is the maximum age of the state file in days. Older
+	// state files are ignored, because deletions may have already been
+	// removed from Netbox's changelog.
+	StateMaxAgeDays int `yaml:"stateMaxAgeDays"`
+	// GraphQL enables collecting of objects with the most instances (devices,
+	// interfaces, VMs, IP addresses...) with Netbox's GraphQL API.
+	GraphQL bool `yaml:"graphQL"`
+	// Branch is the name of the netbox-branching branch, that all changes are
+	// made in, so they can be reviewed before they are merged. It can contain
+	// {{date}} and {{time}} placeholders. Branches are not used when empty.
+	Branch string `yaml:"branch"`
+	// BranchMergeThreshold enables automatic merging of the branch after
+	// successful runs, that made fewer changes than the threshold. 0 disables it.
+	BranchMergeThreshold int `yaml:"branchMergeThreshold"`
+	// JournalEntries enables writing of a journal entry on each object,
+	// that is created or updated by netbox-ssot, with a summary of the changes.
+	JournalEntries bool `yaml:"journalEntries"`
+	// DeviceMatching configures matching of devices, that are reported by
+	// multiple sources under different names.
+	DeviceMatching DeviceMatchingConfig `yaml:"deviceMatching"`
+	// FieldOwnership maps object types (e.g. dcim.device) and their json fields
+	// (e.g. serial) to sources in order of priority, that can overwrite the field,
+	// or to manual, when the field is maintained manually in Netbox.
+	// Other sources can only fill the field when it is empty.
+	FieldOwnership map[string]map[string][]string `yaml:"fieldOwnership"`
+}
+
+// DeviceMatchingConfig configures how devices are matched to existing devices,
+// when no device with the same name exists on the same site.
+// In netbox.deviceMatching block.
+type DeviceMatchingConfig struct {
+	// SerialNumber matches devices with the same serial number.
+	SerialNumber bool `yaml:"serialNumber"`
+	// IgnoredSerialNumbers are serial numbers, that are never used for matching,
+	// in addition to constants.PlaceholderSerialNumbers. They are case-insensitive.
+	IgnoredSerialNumbers []string `yaml:"ignoredSerialNumbers"`
+	// UUID matches devices with the same uuid custom field.
+	UUID bool `yaml:"uuid"`
+	// NameNormalization are rules that are applied in order to device names,
+	// and devices with the same normalized names on the same site are matched.
+	NameNormalization []NameNormalizationRule `yaml:"nameNormalization"`
+}
+
+func (d DeviceMatchingConfig) String() string {
+	return fmt.Sprintf(
+		"DeviceMatchingConfig{SerialNumber: %t, IgnoredSerialNumbers: %v, UUID: %t, NameNormalization: %v}",
+		d.SerialNumber,
+		d.IgnoredSerialNumbers,
+		d.UUID,
+		d.NameNormalization,
+	)
+}
+
+// NameNormalizationRule replaces all matches of the Pattern regex in names with
+// Replacement (e.g. pattern "\\..*$" with empty replacement strips domains from fqdns).
+// When Lowercase is set, names are also converted to lowercase.
+type NameNormalizationRule struct {
+	Pattern     string `yaml:"pattern"`
+	Replacement string `yaml:"replacement"`
+	Lowercase   bool   `yaml:"lowercase"`
 }
 
 func (n NetboxConfig) String() string {
 	return fmt.Sprintf(
 		"NetboxConfig{ApiToken: %s, Hostname: %s, Port: %d, "+
-			"HTTPScheme: %s, ValidateCert: %t, Timeout: %d, "+
-			"Tag: %s, TagColor: %s, RemoveOrphans: %t, RemoveOrphansAfterDays: %d}",
+			"HTTPScheme: %s, ValidateCert: %t, Timeout: %d, MaxRetries: %d, "+
+			"Tag: %s, TagColor: %s, RemoveOrphans: %t, RemoveOrphansAfterDays: %d, "+
+			"MaxOrphans: %d, MaxOrphansPercent: %g, BulkSize: %d, PageSize: %d, PageWorkers: %d, "+
+			"StateFile: %s, StateMaxAgeDays: %d, GraphQL: %t, Branch: %s, BranchMergeThreshold: %d, "+
+			"JournalEntries: %t, ClientCert: %s, ProxyURL: %s, DeviceMatching: %s, FieldOwnership: %v}",
 		n.APIToken,
 		n.Hostname,
 		n.Port,
 		n.HTTPScheme,
 		n.ValidateCert,
 		n.Timeout,
+		n.MaxRetries,
 		n.Tag,
 		n.TagColor,
 		n.RemoveOrphans,
 		n.RemoveOrphansAfterDays,
+		n.MaxOrphans,
+		n.MaxOrphansPercent,
+		n.BulkSize,
+		n.PageSize,
+		n.PageWorkers,
+		n.StateFile,
+		n.StateMaxAgeDays,
+		n.GraphQL,
+		n.Branch,
+		n.BranchMergeThreshold,
+		n.JournalEntries,
+		n.ClientCert,
+		redactURL(n.ProxyURL),
+		n.DeviceMatching,
+		n.FieldOwnership,
 	)
 }
 
+// HTTPClientConfig returns tls and proxy options of the Netbox client.
+func (n NetboxConfig) HTTPClientConfig() utils.HTTPClientConfig {
+	return utils.HTTPClientConfig{
+		ValidateCert: n.ValidateCert,
+		CAFile:       n.CAFile,
+		ClientCert:   n.ClientCert,
+		ClientKey:    n.ClientKey,
+		ProxyURL:     n.ProxyURL,
+	}
+}
+
 // Configuration that can be used for each of the sources.
 type SourceConfig struct {
 	Name                string               `yaml:"name"`
@@ -119,8 +261,8 @@ type SourceConfig struct {
 	Hostname            string               `yaml:"hostname"`
 	Port                int                  `yaml:"port"`
 	Username            string               `yaml:"username"`
-	Password            string               `yaml:"password"`
-	APIToken            string               `yaml:"apiToken"`
+	Password            Secret               `yaml:"password"`
+	APIToken            Secret               `yaml:"apiToken"`
 	ValidateCert        bool                 `yaml:"validateCert"`
 	Tag                 string               `yaml:"tag"`
 	TagColor            string               `yaml:"tagColor"`
@@ -129,9 +271,16 @@ type SourceConfig struct {
 	InterfaceFilter     string               `yaml:"interfaceFilter"`
 	CollectArpData      bool                 `yaml:"collectArpData"`
 	CAFile              string               `yaml:"caFile"`
+	ClientCert          string               `yaml:"clientCert"`
+	ClientKey           string               `yaml:"clientKey"`
+	ProxyURL            string               `yaml:"proxyURL"`
 	IgnoreAssetTags     bool                 `yaml:"ignoreAssetTags"`
 	IgnoreSerialNumbers bool                 `yaml:"ignoreSerialNumbers"`
 	IgnoreVMTemplates   bool                 `yaml:"ignoreVMTemplates"`
+	// Schedule used in daemon mode. If empty, daemon.schedule is used.
+	Schedule string `yaml:"schedule"`
+	// Timeout for initializing and syncing the source. 0 means no timeout.
+	Timeout time.Duration `yaml:"timeout"`
 
 	// Relations
 	DatacenterClusterGroupRelations map[string]string `yaml:"datacenterClusterGroupRelations"`
@@ -148,46 +297,58 @@ type SourceConfig struct {
 	VlanSiteRelations               map[string]string `yaml:"vlanSiteRelations"`
 	WlanTenantRelations             map[string]string `yaml:"wlanTenantRelations"`
 	CustomFieldMappings             map[string]string `yaml:"customFieldMappings"`
+
+	// relationErrs are errors of invalid relations found while unmarshaling,
+	// so they are reported together with other validation errors.
+	relationErrs []error
+}
+
+// realSourceConfig is SourceConfig as it is written in the config file,
+// with relations in format "regex = value".
+type realSourceConfig struct {
+	Name                            string               `yaml:"name"`
+	Type                            constants.SourceType `yaml:"type"`
+	HTTPScheme                      HTTPScheme           `yaml:"httpScheme"`
+	Hostname                        string               `yaml:"hostname"`
+	Port                            int                  `yaml:"port"`
+	Username                        string               `yaml:"username"`
+	Password                        Secret               `yaml:"password"`
+	APIToken                        Secret               `yaml:"apiToken"`
+	ValidateCert                    bool                 `yaml:"validateCert"`
+	Tag                             string               `yaml:"tag"`
+	TagColor                        string               `yaml:"tagColor"`
+	IgnoredSubnets                  []string             `yaml:"ignoredSubnets"`
+	PermittedSubnets                []string             `yaml:"permittedSubnets"`
+	InterfaceFilter                 string               `yaml:"interfaceFilter"`
+	CollectArpData                  bool                 `yaml:"collectArpData"`
+	CAFile                          string               `yaml:"caFile"`
+	ClientCert                      string               `yaml:"clientCert"`
+	ClientKey                       string               `yaml:"clientKey"`
+	ProxyURL                        string               `yaml:"proxyURL"`
+	IgnoreSerialNumbers             bool                 `yaml:"ignoreSerialNumbers"`
+	IgnoreAssetTags                 bool                 `yaml:"ignoreAssetTags"`
+	IgnoreVMTemplates               bool                 `yaml:"ignoreVMTemplates"`
+	Schedule                        string               `yaml:"schedule"`
+	Timeout                         time.Duration        `yaml:"timeout"`
+	DatacenterClusterGroupRelations []string             `yaml:"datacenterClusterGroupRelations,omitempty"`
+	HostSiteRelations               []string             `yaml:"hostSiteRelations,omitempty"`
+	HostRoleRelations               []string             `yaml:"hostRoleRelations,omitempty"`
+	ClusterSiteRelations            []string             `yaml:"clusterSiteRelations,omitempty"`
+	ClusterTenantRelations          []string             `yaml:"clusterTenantRelations,omitempty"`
+	HostTenantRelations             []string             `yaml:"hostTenantRelations,omitempty"`
+	VMTenantRelations               []string             `yaml:"vmTenantRelations,omitempty"`
+	VMRoleRelations                 []string             `yaml:"vmRoleRelations,omitempty"`
+	VlanGroupRelations              []string             `yaml:"vlanGroupRelations,omitempty"`
+	VlanGroupSiteRelations          []string             `yaml:"vlanGroupSiteRelations,omitempty"`
+	VlanTenantRelations             []string             `yaml:"vlanTenantRelations,omitempty"`
+	VlanSiteRelations               []string             `yaml:"vlanSiteRelations,omitempty"`
+	WlanTenantRelations             []string             `yaml:"wlanTenantRelations,omitempty"`
+	CustomFieldMappings             []string             `yaml:"customFieldMappings,omitempty"`
 }
 
 // UnmarshalYAML is a custom unmarshal function for SourceConfig.
 // This is needed because we map relations to the map[string]string.
 func (sc *SourceConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	type realSourceConfig struct {
-		Name                            string               `yaml:"name"`
-		Type                            constants.SourceType `yaml:"type"`
-		HTTPScheme                      HTTPScheme           `yaml:"httpScheme"`
-		Hostname                        string               `yaml:"hostname"`
-		Port                            int                  `yaml:"port"`
-		Username                        string               `yaml:"username"`
-		Password                        string               `yaml:"password"`
-		APIToken                        string               `yaml:"apiToken"`
-		ValidateCert                    bool                 `yaml:"validateCert"`
-		Tag                             string               `yaml:"tag"`
-		TagColor                        string               `yaml:"tagColor"`
-		IgnoredSubnets                  []string             `yaml:"ignoredSubnets"`
-		PermittedSubnets                []string             `yaml:"permittedSubnets"`
-		InterfaceFilter                 string               `yaml:"interfaceFilter"`
-		CollectArpData                  bool                 `yaml:"collectArpData"`
-		CAFile                          string               `yaml:"caFile"`
-		IgnoreSerialNumbers             bool                 `yaml:"ignoreSerialNumbers"`
-		IgnoreAssetTags                 bool                 `yaml:"ignoreAssetTags"`
-		IgnoreVMTemplates               bool                 `yaml:"ignoreVMTemplates"`
-		DatacenterClusterGroupRelations []string             `yaml:"datacenterClusterGroupRelations"`
-		HostSiteRelations               []string             `yaml:"hostSiteRelations"`
-		HostRoleRelations               []string             `yaml:"hostRoleRelations"`
-		ClusterSiteRelations            []string             `yaml:"clusterSiteRelations"`
-		ClusterTenantRelations          []string             `yaml:"clusterTenantRelations"`
-		HostTenantRelations             []string             `yaml:"hostTenantRelations"`
-		VMTenantRelations               []string             `yaml:"vmTenantRelations"`
-		VMRoleRelations                 []string             `yaml:"vmRoleRelations"`
-		VlanGroupRelations              []string             `yaml:"vlanGroupRelations"`
-		VlanGroupSiteRelations          []string             `yaml:"vlanGroupSiteRelations"`
-		VlanTenantRelations             []string             `yaml:"vlanTenantRelations"`
-		VlanSiteRelations               []string             `yaml:"vlanSiteRelations"`
-		WlanTenantRelations             []string             `yaml:"wlanTenantRelations"`
-		CustomFieldMappings             []string             `yaml:"customFieldMappings"`
-	}
 	rawMarshal := realSourceConfig{}
 	if err := unmarshal(&rawMarshal); err != nil {
 		return err
@@ -208,117 +369,103 @@ func (sc *SourceConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	sc.InterfaceFilter = rawMarshal.InterfaceFilter
 	sc.CollectArpData = rawMarshal.CollectArpData
 	sc.CAFile = rawMarshal.CAFile
+	sc.ClientCert = rawMarshal.ClientCert
+	sc.ClientKey = rawMarshal.ClientKey
+	sc.ProxyURL = rawMarshal.ProxyURL
 	sc.IgnoreSerialNumbers = rawMarshal.IgnoreSerialNumbers
 	sc.IgnoreAssetTags = rawMarshal.IgnoreAssetTags
 	sc.IgnoreVMTemplates = rawMarshal.IgnoreVMTemplates
+	sc.Schedule = rawMarshal.Schedule
+	sc.Timeout = rawMarshal.Timeout
 
-	if len(rawMarshal.DatacenterClusterGroupRelations) > 0 {
-		err := utils.ValidateRegexRelations(rawMarshal.DatacenterClusterGroupRelations)
-		if err != nil {
-			return fmt.Errorf("%s.datacenterClusterGroupRelations: %s", rawMarshal.Name, err)
-		}
-		sc.DatacenterClusterGroupRelations = utils.ConvertStringsToRegexPairs(
-			rawMarshal.DatacenterClusterGroupRelations,
-		)
-	}
-	if len(rawMarshal.HostSiteRelations) > 0 {
-		err := utils.ValidateRegexRelations(rawMarshal.HostSiteRelations)
-		if err != nil {
-			return fmt.Errorf("%s.hostSiteRelations: %s", rawMarshal.Name, err)
-		}
-		sc.HostSiteRelations = utils.ConvertStringsToRegexPairs(rawMarshal.HostSiteRelations)
-	}
-	if len(rawMarshal.HostRoleRelations) > 0 {
-		err := utils.ValidateRegexRelations(rawMarshal.HostRoleRelations)
-		if err != nil {
-			return fmt.Errorf("%s.hostRoleRelations: %s", rawMarshal.Name, err)
-		}
-		sc.HostRoleRelations = utils.ConvertStringsToRegexPairs(rawMarshal.HostRoleRelations)
-	}
-	if len(rawMarshal.ClusterSiteRelations) > 0 {
-		err := utils.ValidateRegexRelations(rawMarshal.ClusterSiteRelations)
-		if err != nil {
-			return fmt.Errorf("%s.clusterSiteRelations: %s", rawMarshal.Name, err)
-		}
-		sc.ClusterSiteRelations = utils.ConvertStringsToRegexPairs(rawMarshal.ClusterSiteRelations)
-	}
-	if len(rawMarshal.ClusterTenantRelations) > 0 {
-		err := utils.ValidateRegexRelations(rawMarshal.ClusterTenantRelations)
-		if err != nil {
-			return fmt.Errorf("%s.clusterTenantRelations: %s", rawMarshal.Name, err)
-		}
-		sc.ClusterTenantRelations = utils.ConvertStringsToRegexPairs(
-			rawMarshal.ClusterTenantRelations,
-		)
-	}
-	if len(rawMarshal.HostTenantRelations) > 0 {
-		err := utils.ValidateRegexRelations(rawMarshal.HostTenantRelations)
-		if err != nil {
-			return fmt.Errorf("%s.hostTenantRelations: %s", rawMarshal.Name, err)
-		}
-		sc.HostTenantRelations = utils.ConvertStringsToRegexPairs(rawMarshal.HostTenantRelations)
-	}
-	if len(rawMarshal.VMTenantRelations) > 0 {
-		err := utils.ValidateRegexRelations(rawMarshal.VMTenantRelations)
-		if err != nil {
-			return fmt.Errorf("%s.vmTenantRelations: %s", rawMarshal.Name, err)
-		}
-		sc.VMTenantRelations = utils.ConvertStringsToRegexPairs(rawMarshal.VMTenantRelations)
-	}
-	if len(rawMarshal.VMRoleRelations) > 0 {
-		err := utils.ValidateRegexRelations(rawMarshal.VMRoleRelations)
-		if err != nil {
-			return fmt.Errorf("%s.vmRoleRelations: %s", rawMarshal.Name, err)
-		}
-		sc.VMRoleRelations = utils.ConvertStringsToRegexPairs(rawMarshal.VMRoleRelations)
-	}
-	if len(rawMarshal.VlanGroupRelations) > 0 {
-		err := utils.ValidateRegexRelations(rawMarshal.VlanGroupRelations)
-		if err != nil {
-			return fmt.Errorf("%s.vlanGroupRelations: %v", rawMarshal.Name, err)
-		}
-		sc.VlanGroupRelations = utils.ConvertStringsToRegexPairs(rawMarshal.VlanGroupRelations)
-	}
-	if len(rawMarshal.VlanTenantRelations) > 0 {
-		err := utils.ValidateRegexRelations((rawMarshal.VlanTenantRelations))
-		if err != nil {
-			return fmt.Errorf("%s.vlanTenantRelations: %v", rawMarshal.Name, err)
-		}
-		sc.VlanTenantRelations = utils.ConvertStringsToRegexPairs(rawMarshal.VlanTenantRelations)
-	}
-	if len(rawMarshal.VlanSiteRelations) > 0 {
-		err := utils.ValidateRegexRelations((rawMarshal.VlanSiteRelations))
-		if err != nil {
-			return fmt.Errorf("%s.vlanSiteRelations: %v", rawMarshal.Name, err)
-		}
-		sc.VlanSiteRelations = utils.ConvertStringsToRegexPairs(rawMarshal.VlanSiteRelations)
-	}
-	if len(rawMarshal.VlanGroupSiteRelations) > 0 {
-		err := utils.ValidateRegexRelations((rawMarshal.VlanGroupSiteRelations))
-		if err != nil {
-			return fmt.Errorf("%s.vlanGroupSiteRelations: %v", rawMarshal.Name, err)
+	// Invalid relations are collected, so all of them are reported at once
+	parseRelations := func(fieldName string, relations []string) map[string]string {
+		if len(relations) == 0 {
+			return nil
 		}
-		sc.VlanGroupSiteRelations = utils.ConvertStringsToRegexPairs(
-			rawMarshal.VlanGroupSiteRelations,
-		)
-	}
-	if len(rawMarshal.WlanTenantRelations) > 0 {
-		err := utils.ValidateRegexRelations((rawMarshal.WlanTenantRelations))
-		if err != nil {
-			return fmt.Errorf("%s.wlanTenantRelations: %v", rawMarshal.Name, err)
-		}
-		sc.WlanTenantRelations = utils.ConvertStringsToRegexPairs(rawMarshal.WlanTenantRelations)
-	}
-	if len(rawMarshal.CustomFieldMappings) > 0 {
-		err := utils.ValidateRegexRelations((rawMarshal.CustomFieldMappings))
-		if err != nil {
-			return fmt.Errorf("%s.customFieldMappings: %v", rawMarshal.Name, err)
+		if err := utils.ValidateRegexRelations(relations); err != nil {
+			sc.relationErrs = append(sc.relationErrs, fmt.Errorf("%s.%s: %s", rawMarshal.Name, fieldName, err))
+			return nil
 		}
-		sc.CustomFieldMappings = utils.ConvertStringsToRegexPairs(rawMarshal.CustomFieldMappings)
+		return utils.ConvertStringsToRegexPairs(relations)
 	}
+	sc.DatacenterClusterGroupRelations = parseRelations(
+		"datacenterClusterGroupRelations",
+		rawMarshal.DatacenterClusterGroupRelations,
+	)
+	sc.HostSiteRelations = parseRelations("hostSiteRelations", rawMarshal.HostSiteRelations)
+	sc.HostRoleRelations = parseRelations("hostRoleRelations", rawMarshal.HostRoleRelations)
+	sc.ClusterSiteRelations = parseRelations("clusterSiteRelations", rawMarshal.ClusterSiteRelations)
+	sc.ClusterTenantRelations = parseRelations("clusterTenantRelations", rawMarshal.ClusterTenantRelations)
+	sc.HostTenantRelations = parseRelations("hostTenantRelations", rawMarshal.HostTenantRelations)
+	sc.VMTenantRelations = parseRelations("vmTenantRelations", rawMarshal.VMTenantRelations)
+	sc.VMRoleRelations = parseRelations("vmRoleRelations", rawMarshal.VMRoleRelations)
+	sc.VlanGroupRelations = parseRelations("vlanGroupRelations", rawMarshal.VlanGroupRelations)
+	sc.VlanTenantRelations = parseRelations("vlanTenantRelations", rawMarshal.VlanTenantRelations)
+	sc.VlanSiteRelations = parseRelations("vlanSiteRelations", rawMarshal.VlanSiteRelations)
+	sc.VlanGroupSiteRelations = parseRelations("vlanGroupSiteRelations", rawMarshal.VlanGroupSiteRelations)
+	sc.WlanTenantRelations = parseRelations("wlanTenantRelations", rawMarshal.WlanTenantRelations)
+	sc.CustomFieldMappings = parseRelations("customFieldMappings", rawMarshal.CustomFieldMappings)
 	return nil
 }
 
+// MarshalYAML is a custom marshal function for SourceConfig.
+// It writes relations back in format "regex = value", so the
+// marshaled config can be parsed again.
+func (sc SourceConfig) MarshalYAML() (interface{}, error) {
+	return realSourceConfig{
+		Name:                            sc.Name,
+		Type:                            sc.Type,
+		HTTPScheme:                      sc.HTTPScheme,
+		Hostname:                        sc.Hostname,
+		Port:                            sc.Port,
+		Username:                        sc.Username,
+		Password:                        sc.Password,
+		APIToken:                        sc.APIToken,
+		ValidateCert:                    sc.ValidateCert,
+		Tag:                             sc.Tag,
+		TagColor:                        sc.TagColor,
+		IgnoredSubnets:                  sc.IgnoredSubnets,
+		PermittedSubnets:                sc.PermittedSubnets,
+		InterfaceFilter:                 sc.InterfaceFilter,
+		CollectArpData:                  sc.CollectArpData,
+		CAFile:                          sc.CAFile,
+		ClientCert:                      sc.ClientCert,
+		ClientKey:                       sc.ClientKey,
+		ProxyURL:                        sc.ProxyURL,
+		IgnoreSerialNumbers:             sc.IgnoreSerialNumbers,
+		IgnoreAssetTags:                 sc.IgnoreAssetTags,
+		IgnoreVMTemplates:               sc.IgnoreVMTemplates,
+		Schedule:                        sc.Schedule,
+		Timeout:                         sc.Timeout,
+		DatacenterClusterGroupRelations: utils.ConvertRegexPairsToStrings(sc.DatacenterClusterGroupRelations),
+		HostSiteRelations:               utils.ConvertRegexPairsToStrings(sc.HostSiteRelations),
+		HostRoleRelations:               utils.ConvertRegexPairsToStrings(sc.HostRoleRelations),
+		ClusterSiteRelations:            utils.ConvertRegexPairsToStrings(sc.ClusterSiteRelations),
+		ClusterTenantRelations:          utils.ConvertRegexPairsToStrings(sc.ClusterTenantRelations),
+		HostTenantRelations:             utils.ConvertRegexPairsToStrings(sc.HostTenantRelations),
+		VMTenantRelations:               utils.ConvertRegexPairsToStrings(sc.VMTenantRelations),
+		VMRoleRelations:                 utils.ConvertRegexPairsToStrings(sc.VMRoleRelations),
+		VlanGroupRelations:              utils.ConvertRegexPairsToStrings(sc.VlanGroupRelations),
+		VlanGroupSiteRelations:          utils.ConvertRegexPairsToStrings(sc.VlanGroupSiteRelations),
+		VlanTenantRelations:             utils.ConvertRegexPairsToStrings(sc.VlanTenantRelations),
+		VlanSiteRelations:               utils.ConvertRegexPairsToStrings(sc.VlanSiteRelations),
+		WlanTenantRelations:             utils.ConvertRegexPairsToStrings(sc.WlanTenantRelations),
+		CustomFieldMappings:             utils.ConvertRegexPairsToStrings(sc.CustomFieldMappings),
+	}, nil
+}
+
+// HTTPClientConfig returns tls and proxy options of the source's client.
+func (sc SourceConfig) HTTPClientConfig() utils.HTTPClientConfig {
+	return utils.HTTPClientConfig{
+		ValidateCert: sc.ValidateCert,
+		CAFile:       sc.CAFile,
+		ClientCert:   sc.ClientCert,
+		ClientKey:    sc.ClientKey,
+		ProxyURL:     sc.ProxyURL,
+	}
+}
+
 func (sc SourceConfig) String() string {
 	return fmt.Sprintf(
 		"SourceConfig{Name: %s, Type: %s, HTTPScheme: %s, Hostname: %s, Port: %d, "+
@@ -350,88 +497,216 @@ func (sc SourceConfig) String() string {
 	)
 }
 
-// Validates the user's config for limits and required fields.
-func validateConfig(config *Config) error {
-	err := validateLoggerConfig(config)
-	if err != nil {
-		return err
-	}
+// ValidationErrors contains all errors found while validating the config.
+type ValidationErrors []error
 
-	err = validateNetboxConfig(config)
-	if err != nil {
-		return err
+func (v ValidationErrors) Error() string {
+	errStrings := make([]string, 0, len(v))
+	for _, err := range v {
+		errStrings = append(errStrings, err.Error())
 	}
+	return strings.Join(errStrings, "\n")
+}
 
-	err = validateSourceConfig(config)
-	if err != nil {
-		return err
+// Validates the user's config for limits and required fields.
+// All errors are returned at once as ValidationErrors.
+func validateConfig(config *Config) error {
+	var errs ValidationErrors
+	errs = append(errs, validateLoggerConfig(config)...)
+	errs = append(errs, validateNetboxConfig(config)...)
+	errs = append(errs, validateSourceConfig(config)...)
+	errs = append(errs, validateDaemonConfig(config)...)
+	if len(errs) > 0 {
+		return errs
 	}
-
 	return nil
 }
 
-func validateLoggerConfig(config *Config) error {
+func validateLoggerConfig(config *Config) []error {
 	if config.Logger.Level < 0 || config.Logger.Level > 3 {
-		return errors.New("logger.level: must be between 0 and 3")
+		return []error{errors.New("logger.level: must be between 0 and 3")}
 	}
 	return nil
 }
 
+// fieldOwnershipObjects are objects of object types, that can be used in
+// netbox.fieldOwnership. Fields are json tags of the objects.
+var fieldOwnershipObjects = map[constants.ContentType]interface{}{
+	constants.ContentTypeDcimDevice:                   objects.Device{},
+	constants.ContentTypeDcimDeviceRole:               objects.DeviceRole{},
+	constants.ContentTypeDcimDeviceType:               objects.DeviceType{},
+	constants.ContentTypeDcimInterface:                objects.Interface{},
+	constants.ContentTypeDcimManufacturer:             objects.Manufacturer{},
+	constants.ContentTypeDcimPlatform:                 objects.Platform{},
+	constants.ContentTypeDcimSite:                     objects.Site{},
+	constants.ContentTypeDcimVirtualDeviceContext:     objects.VirtualDeviceContext{},
+	constants.ContentTypeDcimMACAddress:               objects.MACAddress{},
+	constants.ContentTypeIpamIPAddress:                objects.IPAddress{},
+	constants.ContentTypeIpamVlanGroup:                objects.VlanGroup{},
+	constants.ContentTypeIpamVlan:                     objects.Vlan{},
+	constants.ContentTypeIpamPrefix:                   objects.Prefix{},
+	constants.ContentTypeTenancyContact:               objects.Contact{},
+	constants.ContentTypeVirtualizationCluster:        objects.Cluster{},
+	constants.ContentTypeVirtualizationClusterGroup:   objects.ClusterGroup{},
+	constants.ContentTypeVirtualizationClusterType:    objects.ClusterType{},
+	constants.ContentTypeVirtualizationVirtualMachine: objects.VM{},
+	constants.ContentTypeVirtualizationVMInterface:    objects.VMInterface{},
+	constants.ContentTypeVirtualizationVirtualDisk:    objects.VirtualDisk{},
+	constants.ContentTypeWirelessLAN:                  objects.WirelessLAN{},
+	constants.ContentTypeWirelessLANGroup:             objects.WirelessLANGroup{},
+}
+
+// validateFieldOwnership validates object types and owners of fields in netbox.fieldOwnership.
+func validateFieldOwnership(config *Config) []error {
+	var errs []error
+	sourceNames := make(map[string]bool, len(config.Sources))
+	for _, source := range config.Sources {
+		sourceNames[source.Name] = true
+	}
+	for _, objectType := range slices.Sorted(maps.Keys(config.Netbox.FieldOwnership)) {
+		object, ok := fieldOwnershipObjects[constants.ContentType(objectType)]
+		if !ok {
+			errs = append(errs, fmt.Errorf(
+				"netbox.fieldOwnership.%s: unsupported object type (e.g. %s)",
+				objectType,
+				constants.ContentTypeDcimDevice,
+			))
+			continue
+		}
+		objectFields := utils.ExtractJSONTagsFromStruct(object)
+		fields := config.Netbox.FieldOwnership[objectType]
+		for _, field := range slices.Sorted(maps.Keys(fields)) {
+			owners := fields[field]
+			switch {
+			case !slices.Contains(objectFields, field):
+				errs = append(errs, fmt.Errorf(
+					"netbox.fieldOwnership.%s.%s: %s has no such field",
+					objectType,
+					field,
+					objectType,
+				))
+			case len(owners) == 0:
+				errs = append(errs, fmt.Errorf(
+					"netbox.fieldOwnership.%s.%s: must have at least one source or %s",
+					objectType,
+					field,
+					constants.FieldOwnerManual,
+				))
+			case slices.Contains(owners, constants.FieldOwnerManual) && len(owners) > 1:
+				errs = append(errs, fmt.Errorf(
+					"netbox.fieldOwnership.%s.%s: %s can't be combined with sources",
+					objectType,
+					field,
+					constants.FieldOwnerManual,
+				))
+			}
+			for _, owner := range owners {
+				if owner != constants.FieldOwnerManual && !sourceNames[owner] {
+					errs = append(errs, fmt.Errorf(
+						"netbox.fieldOwnership.%s.%s: %s doesn't exist in the sources array",
+						objectType,
+						field,
+						owner,
+					))
+				}
+			}
+		}
+	}
+	return errs
+}
+
 // Function that validates NetboxConfig.
-func validateNetboxConfig(config *Config) error {
+//
+//nolint:gocyclo
+func validateNetboxConfig(config *Config) []error {
+	var errs []error
 	// Validate Netbox config
 	if config.Netbox.APIToken == "" {
-		return errors.New("netbox.apiToken: cannot be empty")
+		errs = append(errs, errors.New("netbox.apiToken: cannot be empty"))
 	}
 	if config.Netbox.HTTPScheme != HTTP && config.Netbox.HTTPScheme != HTTPS {
-		return errors.New(
-			"netbox.httpScheme: must be either http or https. Is " + string(
+		errs = append(errs, errors.New(
+			"netbox.httpScheme: must be either http or https. Is "+string(
 				config.Netbox.HTTPScheme,
 			),
-		)
+		))
 	}
 	if config.Netbox.Hostname == "" {
-		return errors.New("netbox.hostname: cannot be empty")
+		errs = append(errs, errors.New("netbox.hostname: cannot be empty"))
 	}
 	if config.Netbox.Port < 0 || config.Netbox.Port > 65535 {
-		return errors.New(
-			"netbox.port: must be between 0 and 65535. Is " + fmt.Sprintf("%d", config.Netbox.Port),
-		)
+		errs = append(errs, errors.New(
+			"netbox.port: must be between 0 and 65535. Is "+fmt.Sprintf("%d", config.Netbox.Port),
+		))
 	}
 	if config.Netbox.Timeout < 0 {
-		return errors.New("netbox.timeout: cannot be negative")
+		errs = append(errs, errors.New("netbox.timeout: cannot be negative"))
+	}
+	if config.Netbox.MaxRetries < 0 {
+		errs = append(errs, errors.New("netbox.maxRetries: cannot be negative"))
+	}
+	if config.Netbox.BulkSize < 0 {
+		errs = append(errs, errors.New("netbox.bulkSize: cannot be negative"))
+	}
+	if config.Netbox.PageSize < 1 {
+		errs = append(errs, errors.New("netbox.pageSize: must be positive"))
+	}
+	if config.Netbox.PageWorkers < 1 {
+		errs = append(errs, errors.New("netbox.pageWorkers: must be positive"))
+	}
+	if config.Netbox.StateMaxAgeDays < 1 {
+		errs = append(errs, errors.New("netbox.stateMaxAgeDays: must be positive"))
+	}
+	if err := validateBranchName(config.Netbox.Branch); err != nil {
+		errs = append(errs, fmt.Errorf("netbox.branch: %s", err))
+	}
+	if config.Netbox.BranchMergeThreshold < 0 {
+		errs = append(errs, errors.New("netbox.branchMergeThreshold: cannot be negative"))
+	} else if config.Netbox.BranchMergeThreshold > 0 && config.Netbox.Branch == "" {
+		errs = append(errs, errors.New("netbox.branchMergeThreshold: has no effect when netbox.branch is not set"))
+	}
+	for i, rule := range config.Netbox.DeviceMatching.NameNormalization {
+		if rule.Pattern == "" && !rule.Lowercase {
+			errs = append(errs, fmt.Errorf("netbox.deviceMatching.nameNormalization[%d]: pattern cannot be empty", i))
+		} else if _, err := regexp.Compile(rule.Pattern); err != nil {
+			errs = append(errs, fmt.Errorf("netbox.deviceMatching.nameNormalization[%d].pattern: %s", i, err))
+		}
 	}
 	if config.Netbox.Tag == "" {
 		config.Netbox.Tag = constants.SsotTagName
 	}
 	if !config.Netbox.RemoveOrphans {
 		if config.Netbox.RemoveOrphansAfterDays < 0 {
-			return fmt.Errorf("netbox.RemoveOrphansAfterDays: must be positive integer")
+			errs = append(errs, fmt.Errorf("netbox.RemoveOrphansAfterDays: must be positive integer"))
 		}
 		if config.Netbox.RemoveOrphansAfterDays == 0 {
 			config.Netbox.RemoveOrphansAfterDays = constants.CustomFieldOrphanLastSeenDefaultValue
 		}
 	} else if config.Netbox.RemoveOrphansAfterDays != 0 {
-		return fmt.Errorf("netbox.removeOrphansAfterDays has no effect when netbox.removeOrphans is set to true")
+		errs = append(
+			errs,
+			fmt.Errorf("netbox.removeOrphansAfterDays has no effect when netbox.removeOrphans is set to true"),
+		)
+	}
+	if config.Netbox.MaxOrphans < 0 {
+		errs = append(errs, errors.New("netbox.maxOrphans: cannot be negative"))
+	}
+	if config.Netbox.MaxOrphansPercent < 0 || config.Netbox.MaxOrphansPercent > 100 {
+		errs = append(
+			errs,
+			fmt.Errorf("netbox.maxOrphansPercent: must be between 0 and 100. Is %g", config.Netbox.MaxOrphansPercent),
+		)
 	}
 	if config.Netbox.TagColor == "" {
 		config.Netbox.TagColor = constants.SsotTagColor
-	} else {
-		// Ensure that TagColor is a string of 6 hexadecimal characters
-		if len(config.Netbox.TagColor) != len("ffffff") {
-			return errors.New("netbox.tagColor: must be a string of 6 hexadecimal characters")
-		}
-		for _, c := range config.Netbox.TagColor {
-			if c < '0' || c > '9' && c < 'a' || c > 'f' {
-				return errors.New("netbox.tagColor: must be a string of 6 lowercase hexadecimal characters")
-			}
-		}
+	} else if err := validateTagColor(config.Netbox.TagColor); err != nil {
+		errs = append(errs, fmt.Errorf("netbox.tagColor: %s", err))
 	}
 	if len(config.Netbox.SourcePriority) > 0 {
 		if len(config.Netbox.SourcePriority) != len(config.Sources) {
-			return fmt.Errorf(
+			errs = append(errs, fmt.Errorf(
 				"netbox.sourcePriority: len(config.Netbox.SourcePriority) != len(config.Sources)",
-			)
+			))
 		}
 		for _, sourceName := range config.Netbox.SourcePriority {
 			contains := false
@@ -442,30 +717,116 @@ func validateNetboxConfig(config *Config) error {
 				}
 			}
 			if !contains {
-				return fmt.Errorf(
+				errs = append(errs, fmt.Errorf(
 					"netbox.sourcePriority: %s doesn't exist in the sources array",
 					sourceName,
-				)
+				))
 			}
 		}
 	}
+	errs = append(errs, validateFieldOwnership(config)...)
 	if config.Netbox.CAFile != "" {
 		_, err := os.ReadFile(config.Netbox.CAFile)
 		if err != nil {
-			return fmt.Errorf("netbox.caFile: %s", err)
+			errs = append(errs, fmt.Errorf("netbox.caFile: %s", err))
+		}
+	}
+	errs = append(errs, validateHTTPClientConfig("netbox", config.Netbox.HTTPClientConfig())...)
+	return errs
+}
+
+// httpClientSourceTypes are types of sources, that support clientCert,
+// clientKey and proxyURL options. oVirt SDK builds its own http clients,
+// that don't use proxies at all (not even from the environment), and
+// ios-xe sources connect with NETCONF over SSH.
+var httpClientSourceTypes = []constants.SourceType{
+	constants.Vmware,
+	constants.Dnac,
+	constants.Proxmox,
+	constants.PaloAlto,
+	constants.Fortigate,
+	constants.FMC,
+}
+
+// validateHTTPClientConfig ensures that client certificate can be loaded,
+// and that proxy url is valid.
+func validateHTTPClientConfig(prefix string, httpConfig utils.HTTPClientConfig) []error {
+	var errs []error
+	switch {
+	case httpConfig.ClientCert != "" && httpConfig.ClientKey == "":
+		errs = append(errs, fmt.Errorf("%s.clientKey: is required with clientCert", prefix))
+	case httpConfig.ClientCert == "" && httpConfig.ClientKey != "":
+		errs = append(errs, fmt.Errorf("%s.clientCert: is required with clientKey", prefix))
+	case httpConfig.ClientCert != "":
+		if _, err := tls.LoadX509KeyPair(httpConfig.ClientCert, httpConfig.ClientKey); err != nil {
+			errs = append(errs, fmt.Errorf("%s.clientCert: %s", prefix, err))
+		}
+	}
+	if httpConfig.ProxyURL != "" {
+		proxyURL, err := url.Parse(httpConfig.ProxyURL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s.proxyURL: %s", prefix, err))
+		} else if !slices.Contains([]string{"http", "https", "socks5"}, proxyURL.Scheme) || proxyURL.Host == "" {
+			errs = append(errs, fmt.Errorf(
+				"%s.proxyURL: must be an url with http, https or socks5 scheme (e.g. http://proxy:3128)",
+				prefix,
+			))
+		}
+	}
+	return errs
+}
+
+// redactURL returns the url with its password replaced with "xxxxx".
+func redactURL(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return parsedURL.Redacted()
+}
+
+// validateTagColor ensures that tagColor is a string of 6 lowercase
+// hexadecimal characters.
+func validateTagColor(tagColor string) error {
+	if len(tagColor) != len("ffffff") {
+		return errors.New("must be a string of 6 hexadecimal characters")
+	}
+	for _, c := range tagColor {
+		if c < '0' || c > '9' && c < 'a' || c > 'f' {
+			return errors.New("must be a string of 6 lowercase hexadecimal characters")
 		}
 	}
 	return nil
 }
 
+// validateBranchName ensures that branch name template contains
+// only known placeholders.
+func validateBranchName(branch string) error {
+	withoutPlaceholders := strings.NewReplacer(
+		constants.BranchDatePlaceholder, "",
+		constants.BranchTimePlaceholder, "",
+	).Replace(branch)
+	if strings.Contains(withoutPlaceholders, "{{") || strings.Contains(withoutPlaceholders, "}}") {
+		return fmt.Errorf(
+			"unknown placeholder, only %s and %s are supported",
+			constants.BranchDatePlaceholder,
+			constants.BranchTimePlaceholder,
+		)
+	}
+	return nil
+}
+
 //nolint:gocyclo
-func validateSourceConfig(config *Config) error {
+func validateSourceConfig(config *Config) []error {
+	var errs []error
 	// Validate Sources
 	for i := range config.Sources {
 		externalSource := &config.Sources[i]
 		externalSourceStr := externalSource.Name
+		errs = append(errs, externalSource.relationErrs...)
 		if externalSource.Name == "" {
-			return fmt.Errorf("source name: cannot be empty")
+			errs = append(errs, fmt.Errorf("source name: cannot be empty"))
+			continue
 		}
 		switch externalSource.Type {
 		case constants.Ovirt:
@@ -477,37 +838,40 @@ func validateSourceConfig(config *Config) error {
 		case constants.FMC:
 		case constants.IOSXE:
 		default:
-			return fmt.Errorf("%s.type is not valid", externalSourceStr)
+			errs = append(errs, fmt.Errorf("%s.type is not valid", externalSourceStr))
 		}
 		if externalSource.HTTPScheme == "" {
 			externalSource.HTTPScheme = "https"
 		} else if externalSource.HTTPScheme != HTTP && externalSource.HTTPScheme != HTTPS {
-			return fmt.Errorf(
+			errs = append(errs, fmt.Errorf(
 				"%s.httpScheme: must be either http or https. Is %s",
 				externalSourceStr,
 				string(externalSource.HTTPScheme),
-			)
+			))
 		}
 		if externalSource.Hostname == "" {
-			return fmt.Errorf("%s.hostname: cannot be empty", externalSourceStr)
+			errs = append(errs, fmt.Errorf("%s.hostname: cannot be empty", externalSourceStr))
 		}
 		if externalSource.Port == 0 {
 			externalSource.Port = 443
 		} else if externalSource.Port < 0 || externalSource.Port > 65535 {
-			return fmt.Errorf("%s.port: must be between 0 and 65535. Is %d", externalSourceStr, externalSource.Port)
+			errs = append(
+				errs,
+				fmt.Errorf("%s.port: must be between 0 and 65535. Is %d", externalSourceStr, externalSource.Port),
+			)
 		}
 		if externalSource.APIToken == "" && externalSource.Type == constants.Fortigate {
-			return fmt.Errorf(
+			errs = append(errs, fmt.Errorf(
 				"%s.apiToken is required for %s",
 				externalSourceStr,
 				constants.Fortigate,
-			)
+			))
 		}
 		if externalSource.Username == "" && externalSource.Type != constants.Fortigate {
-			return fmt.Errorf("%s.username: cannot be empty", externalSourceStr)
+			errs = append(errs, fmt.Errorf("%s.username: cannot be empty", externalSourceStr))
 		}
 		if externalSource.Password == "" && externalSource.Type != constants.Fortigate {
-			return fmt.Errorf("%s.password: cannot be empty", externalSourceStr)
+			errs = append(errs, fmt.Errorf("%s.password: cannot be empty", externalSourceStr))
 		}
 		if externalSource.Tag == "" {
 			externalSource.Tag = fmt.Sprintf("Source: %s", externalSource.Name)
@@ -517,39 +881,69 @@ func validateSourceConfig(config *Config) error {
 		}
 		if externalSource.CAFile != "" {
 			if _, err := os.ReadFile(externalSource.CAFile); err != nil {
-				return fmt.Errorf("%s.caFile: %s", externalSourceStr, err)
+				errs = append(errs, fmt.Errorf("%s.caFile: %s", externalSourceStr, err))
 			}
 		}
-		if len(externalSource.IgnoredSubnets) > 0 {
-			for _, ignoredSubnet := range externalSource.IgnoredSubnets {
-				if !utils.VerifySubnet(ignoredSubnet) {
-					return fmt.Errorf(
-						"%s.ignoredSubnets: wrong format: %s",
-						externalSourceStr,
-						ignoredSubnet,
-					)
-				}
+		errs = append(errs, validateHTTPClientConfig(externalSourceStr, externalSource.HTTPClientConfig())...)
+		if !slices.Contains(httpClientSourceTypes, externalSource.Type) {
+			if externalSource.ClientCert != "" {
+				errs = append(errs, fmt.Errorf(
+					"%s.clientCert: is not supported for %s sources", externalSourceStr, externalSource.Type,
+				))
+			}
+			if externalSource.ProxyURL != "" {
+				errs = append(errs, fmt.Errorf(
+					"%s.proxyURL: is not supported for %s sources", externalSourceStr, externalSource.Type,
+				))
 			}
 		}
-		if len(externalSource.PermittedSubnets) > 0 {
-			for _, permittedSubnet := range externalSource.PermittedSubnets {
-				if !utils.VerifySubnet(permittedSubnet) {
-					return fmt.Errorf(
-						"%s.permittedSubnets: wrong format: %s",
-						externalSourceStr,
-						permittedSubnet,
-					)
-				}
+		for _, ignoredSubnet := range externalSource.IgnoredSubnets {
+			if !utils.VerifySubnet(ignoredSubnet) {
+				errs = append(errs, fmt.Errorf(
+					"%s.ignoredSubnets: wrong format: %s",
+					externalSourceStr,
+					ignoredSubnet,
+				))
+			}
+		}
+		for _, permittedSubnet := range externalSource.PermittedSubnets {
+			if !utils.VerifySubnet(permittedSubnet) {
+				errs = append(errs, fmt.Errorf(
+					"%s.permittedSubnets: wrong format: %s",
+					externalSourceStr,
+					permittedSubnet,
+				))
 			}
 		}
 
 		// Try to compile interfaceFilter
 		_, err := regexp.Compile(externalSource.InterfaceFilter)
 		if err != nil {
-			return fmt.Errorf("%s.interfaceFilter: wrong format: %s", externalSourceStr, err)
+			errs = append(errs, fmt.Errorf("%s.interfaceFilter: wrong format: %s", externalSourceStr, err))
+		}
+
+		if externalSource.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("%s.timeout: cannot be negative", externalSourceStr))
+		}
+		if externalSource.Schedule != "" {
+			if _, err := schedule.Parse(externalSource.Schedule); err != nil {
+				errs = append(errs, fmt.Errorf("%s.schedule: %s", externalSourceStr, err))
+			}
 		}
 	}
-	return nil
+	return errs
+}
+
+// Function that validates DaemonConfig.
+func validateDaemonConfig(config *Config) []error {
+	var errs []error
+	if _, err := schedule.Parse(config.Daemon.Schedule); err != nil {
+		errs = append(errs, fmt.Errorf("daemon.schedule: %s", err))
+	}
+	if config.Daemon.FullRefreshInterval <= 0 {
+		errs = append(errs, errors.New("daemon.fullRefreshInterval: must be positive"))
+	}
+	return errs
 }
 
 func ParseConfig(configFilename string) (*Config, error) {
@@ -567,12 +961,20 @@ func ParseConfig(configFilename string) (*Config, error) {
 			Dest:  "",
 		},
 		Netbox: &NetboxConfig{
-			HTTPScheme:    "https",
-			Port:          constants.HTTPSDefaultPort,
-			Timeout:       constants.DefaultAPITimeout,
-			RemoveOrphans: true,
+			HTTPScheme:      "https",
+			Port:            constants.HTTPSDefaultPort,
+			Timeout:         constants.DefaultAPITimeout,
+			MaxRetries:      constants.DefaultAPIMaxRetries,
+			PageSize:        constants.DefaultAPIPageSize,
+			PageWorkers:     constants.DefaultAPIPageWorkers,
+			StateMaxAgeDays: constants.DefaultStateMaxAgeDays,
+			RemoveOrphans:   true,
 		},
 		Sources: []SourceConfig{},
+		Daemon: &DaemonConfig{
+			Schedule:            constants.DefaultDaemonSchedule,
+			FullRefreshInterval: constants.DefaultDaemonFullRefreshInterval,
+		},
 	}
 
 	// Parse the config file into a Config struct
@@ -581,10 +983,15 @@ func ParseConfig(configFilename string) (*Config, error) {
 		return nil, err
 	}
 
-	// Validate the config for limits and required fields
-	err = validateConfig(config)
-	if err != nil {
-		return nil, err
+	// Resolve ${ENV_VAR} and file: references in credentials, and validate
+	// the config for limits and required fields. All errors are reported at once.
+	errs := ValidationErrors(resolveReferences(config))
+	var validationErrs ValidationErrors
+	if errors.As(validateConfig(config), &validationErrs) {
+		errs = append(errs, validationErrs...)
+	}
+	if len(errs) > 0 {
+		return nil, errs
 	}
 
 	return config, nil
@@ -24,6 +24,10 @@ func TestValidonfig(t *testing.T) {
 			Port:                   666,
 			ValidateCert:           false, // Default
 			Timeout:                constants.DefaultAPITimeout,
+			MaxRetries:             constants.DefaultAPIMaxRetries,
+			PageSize:               constants.DefaultAPIPageSize,
+			PageWorkers:            constants.DefaultAPIPageWorkers,
+			StateMaxAgeDays:        constants.DefaultStateMaxAgeDays,
 			Tag:                    constants.SsotTagName,  // Default
 			TagColor:               constants.SsotTagColor, // Default
 			RemoveOrphans:          false,                  // Default
@@ -114,6 +118,10 @@ func TestValidonfig(t *testing.T) {
 				},
 			},
 		},
+		Daemon: &DaemonConfig{
+			Schedule:            constants.DefaultDaemonSchedule,            // Default
+			FullRefreshInterval: constants.DefaultDaemonFullRefreshInterval, // Default
+		},
 	}
 	got, err := ParseConfig(filename)
 	if err != nil {
@@ -153,6 +161,9 @@ func TestParseValidConfigs(t *testing.T) {
 		{
 			filename: "valid_config7.yaml",
 		},
+		{
+			filename: "valid_config8.yaml",
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.filename, func(t *testing.T) {
@@ -271,8 +282,9 @@ func TestParseConfigInvalidConfigs(t *testing.T) {
 			expectedErr: "fortigate.apiToken is required for fortigate",
 		},
 		{
-			filename:    "invalid_config31.yaml",
-			expectedErr: "netbox.removeOrphansAfterDays has no effect when netbox.removeOrphans is set to true",
+			filename: "invalid_config31.yaml",
+			expectedErr: "netbox.removeOrphansAfterDays has no effect when netbox.removeOrphans is set to true\n" +
+				"netbox.caFile: open wrong path: no such file or directory",
 		},
 		{
 			filename:    "invalid_config32.yaml",
@@ -340,6 +352,108 @@ func TestParseConfigInvalidConfigs(t *testing.T) {
 			filename:    "invalid_config48.yaml",
 			expectedErr: "wrong.vlanGroupSiteRelations: invalid regex: (wrong(), in relation: (wrong() = wwrong",
 		},
+		{
+			filename: "invalid_config49.yaml",
+			expectedErr: "wrong.schedule: schedule \"every day\" is neither a valid duration " +
+				"nor a cron expression: expected 5 fields, got 2",
+		},
+		{
+			filename: "invalid_config50.yaml",
+			expectedErr: "daemon.schedule: schedule \"61 * * * *\" is neither a valid duration " +
+				"nor a cron expression: minute: \"61\" out of range 0-59",
+		},
+		{
+			filename:    "invalid_config51.yaml",
+			expectedErr: "daemon.fullRefreshInterval: must be positive",
+		},
+		{
+			filename:    "invalid_config52.yaml",
+			expectedErr: "netbox.maxOrphans: cannot be negative",
+		},
+		{
+			filename:    "invalid_config53.yaml",
+			expectedErr: "netbox.maxOrphansPercent: must be between 0 and 100. Is 120",
+		},
+		{
+			filename:    "invalid_config54.yaml",
+			expectedErr: "testolvm.timeout: cannot be negative",
+		},
+		{
+			filename: "invalid_config55.yaml",
+			expectedErr: "netbox.apiToken: environment variable NETBOX_SSOT_UNSET_TEST_TOKEN is not set\n" +
+				"testolvm.password: read secret file: open /nonexistent/netbox-ssot/password: no such file or directory",
+		},
+		{
+			filename:    "invalid_config56.yaml",
+			expectedErr: "netbox.maxRetries: cannot be negative",
+		},
+		{
+			filename:    "invalid_config57.yaml",
+			expectedErr: "netbox.bulkSize: cannot be negative",
+		},
+		{
+			filename:    "invalid_config58.yaml",
+			expectedErr: "netbox.pageSize: must be positive",
+		},
+		{
+			filename:    "invalid_config59.yaml",
+			expectedErr: "netbox.pageWorkers: must be positive",
+		},
+		{
+			filename:    "invalid_config60.yaml",
+			expectedErr: "netbox.branch: unknown placeholder, only {{date}} and {{time}} are supported",
+		},
+		{
+			filename:    "invalid_config61.yaml",
+			expectedErr: "netbox.branchMergeThreshold: cannot be negative",
+		},
+		{
+			filename:    "invalid_config62.yaml",
+			expectedErr: "netbox.branchMergeThreshold: has no effect when netbox.branch is not set",
+		},
+		{
+			filename:    "invalid_config63.yaml",
+			expectedErr: "netbox.clientKey: is required with clientCert",
+		},
+		{
+			filename:    "invalid_config64.yaml",
+			expectedErr: "netbox.proxyURL: must be an url with http, https or socks5 scheme (e.g. http://proxy:3128)",
+		},
+		{
+			filename:    "invalid_config65.yaml",
+			expectedErr: "testolvm.proxyURL: is not supported for ovirt sources",
+		},
+		{
+			filename:    "invalid_config66.yaml",
+			expectedErr: "netbox.deviceMatching.nameNormalization[1].pattern: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			filename:    "invalid_config67.yaml",
+			expectedErr: "netbox.fieldOwnership.dcim.device.platform: testdnac doesn't exist in the sources array",
+		},
+		{
+			filename:    "invalid_config68.yaml",
+			expectedErr: "netbox.fieldOwnership.dcim.device.comments: manual can't be combined with sources",
+		},
+		{
+			filename:    "invalid_config69.yaml",
+			expectedErr: "netbox.stateMaxAgeDays: must be positive",
+		},
+		{
+			filename:    "invalid_config70.yaml",
+			expectedErr: "netbox.fieldOwnership.dcim.device.seriall: dcim.device has no such field",
+		},
+		{
+			filename: "invalid_config71.yaml",
+			expectedErr: "netbox.apiToken: environment variable NETBOX_SSOT_UNSET_TEST_TOKEN is not set\n" +
+				"missing.password: read secret file: " +
+				"open /nonexistent/netbox-ssot/password: no such file or directory\n" +
+				"netbox.port: must be between 0 and 65535. Is 666666\n" +
+				"wrong.hostSiteRelations: invalid regex: (wrong(), in relation: (wrong() = wwrong\n" +
+				"wrong.vmTenantRelations: invalid regex relation: This should not work. " +
+				"Should be of format: regex = value\n" +
+				"missing.hostname: cannot be empty",
+		},
 		{
 			filename:    "invalid_config1111.yaml",
 			expectedErr: "open ../../testdata/parser/invalid_config1111.yaml: no such file or directory",
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// fileReferencePrefix is the prefix of values that are read from a file,
+// e.g. file:/run/secrets/netbox-token.
+const fileReferencePrefix = "file:"
+
+// literalPrefix is the prefix of values that are used as they are, without
+// the prefix, e.g. literal:file:password is resolved to file:password.
+const literalPrefix = "literal:"
+
+// envReferenceRegex matches ${ENV_VAR} references, and escaped $${ENV_VAR}
+// references, which are replaced with literal ${ENV_VAR}.
+var envReferenceRegex = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// resolveReferences replaces references in the credentials of netbox and
+// sources with their values. It returns errors of all references that
+// couldn't be resolved.
+func resolveReferences(config *Config) []error {
+	var errs []error
+	appendErr := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	appendErr(resolveField("netbox.apiToken", &config.Netbox.APIToken))
+	for i := range config.Sources {
+		sourceConfig := &config.Sources[i]
+		appendErr(resolveField(sourceConfig.Name+".username", &sourceConfig.Username))
+		appendErr(resolveField(sourceConfig.Name+".password", &sourceConfig.Password))
+		appendErr(resolveField(sourceConfig.Name+".apiToken", &sourceConfig.APIToken))
+	}
+	return errs
+}
+
+// resolveField replaces the reference in the value of the field fieldName
+// with its value.
+func resolveField[T ~string](fieldName string, value *T) error {
+	resolved, err := resolveReference(string(*value))
+	if err != nil {
+		return fmt.Errorf("%s: %s", fieldName, err)
+	}
+	*value = T(resolved)
+	return nil
+}
+
+// resolveReference resolves a single config value. Values of format
+// file:/path/to/secret are replaced with the content of the file, without
+// the trailing newline. Otherwise all ${ENV_VAR} references in the value are
+// replaced with values of the environment variables. Values with literalPrefix
+// are not resolved, and $${ENV_VAR} is an escaped literal ${ENV_VAR}.
+func resolveReference(value string) (string, error) {
+	if literal, ok := strings.CutPrefix(value, literalPrefix); ok {
+		return literal, nil
+	}
+	if path, ok := strings.CutPrefix(value, fileReferencePrefix); ok {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read secret file: %s", err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+
+	var missingVars []string
+	resolved := envReferenceRegex.ReplaceAllStringFunc(value, func(reference string) string {
+		if escaped, ok := strings.CutPrefix(reference, "$$"); ok {
+			return "$" + escaped
+		}
+		varName := envReferenceRegex.FindStringSubmatch(reference)[1]
+		varValue, ok := os.LookupEnv(varName)
+		if !ok {
+			missingVars = append(missingVars, varName)
+		}
+		return varValue
+	})
+	if len(missingVars) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missingVars, ", "))
+	}
+	return resolved, nil
+}
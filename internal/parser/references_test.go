@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveReference(t *testing.T) {
+	t.Setenv("NETBOX_SSOT_TEST_USER", "admin")
+	t.Setenv("NETBOX_SSOT_TEST_EMPTY", "")
+	secretFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secretFile, []byte("s3cr3t$\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "Plain value",
+			value: "pa$$word",
+			want:  "pa$$word",
+		},
+		{
+			name:  "Environment variable",
+			value: "${NETBOX_SSOT_TEST_USER}",
+			want:  "admin",
+		},
+		{
+			name:  "Environment variable inside value",
+			value: "${NETBOX_SSOT_TEST_USER}@internal",
+			want:  "admin@internal",
+		},
+		{
+			name:  "Empty environment variable",
+			value: "${NETBOX_SSOT_TEST_EMPTY}",
+			want:  "",
+		},
+		{
+			name:    "Unset environment variable",
+			value:   "${NETBOX_SSOT_TEST_UNSET}",
+			wantErr: true,
+		},
+		{
+			name:  "Secret file",
+			value: "file:" + secretFile,
+			want:  "s3cr3t$",
+		},
+		{
+			name:  "Escaped environment variable",
+			value: "pa$${NETBOX_SSOT_TEST_UNSET}${NETBOX_SSOT_TEST_USER}",
+			want:  "pa${NETBOX_SSOT_TEST_UNSET}admin",
+		},
+		{
+			name:  "Literal value",
+			value: "literal:file:pa${NETBOX_SSOT_TEST_USER}",
+			want:  "file:pa${NETBOX_SSOT_TEST_USER}",
+		},
+		{
+			name:    "Missing secret file",
+			value:   "file:" + filepath.Join(t.TempDir(), "missing"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveReference(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("resolveReference() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("resolveReference() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
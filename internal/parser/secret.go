@@ -0,0 +1,38 @@
+package parser
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// maskedSecret is printed instead of the value of a non-empty Secret.
+const maskedSecret = "********"
+
+// Secret is a string, that is masked whenever it is printed, logged or
+// marshaled, so credentials from the config can't leak into logs.
+// Use Value to get the actual secret.
+type Secret string
+
+// Value returns the unmasked secret.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return maskedSecret
+}
+
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
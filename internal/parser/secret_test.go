@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"gopkg.in/yaml.v3"
+)
+
+const testSecret = "s3cr3t-value"
+
+func TestSecret(t *testing.T) {
+	secret := Secret(testSecret)
+	if secret.Value() != testSecret {
+		t.Errorf("Secret.Value() = %s, want %s", secret.Value(), testSecret)
+	}
+	if Secret("").String() != "" {
+		t.Errorf("empty Secret.String() = %s, want empty string", Secret("").String())
+	}
+	for _, format := range []string{"%s", "%v", "%+v", "%#v", "%q", "%x"} {
+		if got := fmt.Sprintf(format, secret); strings.Contains(got, testSecret) ||
+			strings.Contains(got, fmt.Sprintf("%x", testSecret)) {
+			t.Errorf("fmt.Sprintf(%q) leaked the secret: %s", format, got)
+		}
+	}
+}
+
+// TestConfigDoesNotLeakSecrets ensures that secrets are masked however
+// the config is printed or marshaled.
+func TestConfigDoesNotLeakSecrets(t *testing.T) {
+	netboxConfig := &NetboxConfig{APIToken: testSecret, Hostname: "netbox.example.com"}
+	sourceConfig := SourceConfig{
+		Name:     "vmware",
+		Type:     constants.Vmware,
+		Username: "admin",
+		Password: testSecret,
+		APIToken: testSecret,
+	}
+	config := &Config{
+		Logger:  &LoggerConfig{},
+		Netbox:  netboxConfig,
+		Sources: []SourceConfig{sourceConfig},
+		Daemon:  &DaemonConfig{},
+	}
+
+	outputs := map[string]string{
+		"NetboxConfig.String()": netboxConfig.String(),
+		"SourceConfig.String()": sourceConfig.String(),
+		"%v":                    fmt.Sprintf("%v", config),
+		"%+v NetboxConfig":      fmt.Sprintf("%+v", *netboxConfig),
+		"%+v SourceConfig":      fmt.Sprintf("%+v", sourceConfig),
+		"%#v SourceConfig":      fmt.Sprintf("%#v", sourceConfig),
+	}
+	jsonOutput, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	outputs["json"] = string(jsonOutput)
+	yamlOutput, err := yaml.Marshal(config)
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+	outputs["yaml"] = string(yamlOutput)
+
+	for name, output := range outputs {
+		if strings.Contains(output, testSecret) {
+			t.Errorf("%s leaked the secret: %s", name, output)
+		}
+		if !strings.Contains(output, maskedSecret) {
+			t.Errorf("%s doesn't contain masked secret: %s", name, output)
+		}
+	}
+}
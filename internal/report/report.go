@@ -0,0 +1,242 @@
+// Package report implements machine-readable report of a single
+// netbox-ssot run, that can be written in JSON or YAML format.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Action represents what happened with the object received from a source.
+type Action string
+
+const (
+	ActionCreated   Action = "created"
+	ActionUpdated   Action = "updated"
+	ActionUnchanged Action = "unchanged"
+)
+
+// Status of the run or of a single source.
+type Status string
+
+const (
+	StatusSuccess Status = "success"
+	StatusFailed  Status = "failed"
+)
+
+// ObjectCounts counts objects of a single object type.
+type ObjectCounts struct {
+	Created   int `json:"created"            yaml:"created"`
+	Updated   int `json:"updated"            yaml:"updated"`
+	Unchanged int `json:"unchanged"          yaml:"unchanged"`
+	Orphaned  int `json:"orphaned,omitempty" yaml:"orphaned,omitempty"`
+}
+
+// SourceReport is a report of a single source.
+type SourceReport struct {
+	Name                string                              `json:"name"                yaml:"name"`
+	Type                constants.SourceType                `json:"type"                yaml:"type"`
+	Status              Status                              `json:"status"              yaml:"status"`
+	Error               string                              `json:"error,omitempty"     yaml:"error,omitempty"`
+	StartedAt           time.Time                           `json:"startedAt"           yaml:"startedAt"`
+	FinishedAt          time.Time                           `json:"finishedAt"          yaml:"finishedAt"`
+	InitDurationSeconds float64                             `json:"initDurationSeconds" yaml:"initDurationSeconds"`
+	SyncDurationSeconds float64                             `json:"syncDurationSeconds" yaml:"syncDurationSeconds"`
+	Objects             map[constants.APIPath]*ObjectCounts `json:"objects"             yaml:"objects"`
+
+	// seen are ids of objects, that were already counted in Objects.
+	seen map[constants.APIPath]map[int]bool
+}
+
+// Report is a report of a single netbox-ssot run. All methods are safe
+// for concurrent use.
+type Report struct {
+	mu sync.Mutex
+
+	StartedAt       time.Time `json:"startedAt"       yaml:"startedAt"`
+	FinishedAt      time.Time `json:"finishedAt"      yaml:"finishedAt"`
+	DurationSeconds float64   `json:"durationSeconds" yaml:"durationSeconds"`
+	Status          Status    `json:"status"          yaml:"status"`
+	// Sources contains reports of all sources that were run, ordered by name.
+	Sources []*SourceReport `json:"sources" yaml:"sources"`
+	// Objects contains counts of objects of all sources together, including
+	// orphaned objects, which don't belong to any source.
+	Objects map[constants.APIPath]*ObjectCounts `json:"objects" yaml:"objects"`
+	// HardDeleted contains ids of objects that were deleted from Netbox.
+	HardDeleted map[constants.APIPath][]int `json:"hardDeleted" yaml:"hardDeleted"`
+
+	sourcesByName map[string]*SourceReport
+	// seen are ids of objects, that were already counted in Objects.
+	seen map[constants.APIPath]map[int]bool
+}
+
+// New returns new empty report of a run started at startedAt.
+func New(startedAt time.Time) *Report {
+	return &Report{
+		StartedAt:     startedAt,
+		Sources:       []*SourceReport{},
+		Objects:       make(map[constants.APIPath]*ObjectCounts),
+		HardDeleted:   make(map[constants.APIPath][]int),
+		sourcesByName: make(map[string]*SourceReport),
+		seen:          make(map[constants.APIPath]map[int]bool),
+	}
+}
+
+// source returns report of the source with the given name, creating it
+// if it doesn't exist yet. Caller must hold the lock.
+func (r *Report) source(name string) *SourceReport {
+	if sourceReport, ok := r.sourcesByName[name]; ok {
+		return sourceReport
+	}
+	sourceReport := &SourceReport{
+		Name:    name,
+		Objects: make(map[constants.APIPath]*ObjectCounts),
+		seen:    make(map[constants.APIPath]map[int]bool),
+	}
+	r.sourcesByName[name] = sourceReport
+	r.Sources = append(r.Sources, sourceReport)
+	sort.Slice(r.Sources, func(i, j int) bool { return r.Sources[i].Name < r.Sources[j].Name })
+	return sourceReport
+}
+
+// RecordChange records that object of type objectPath with objectID received
+// from the source was created, updated or unchanged. Sources add the same
+// objects repeatedly (e.g. sites and tags), so unchanged objects are counted
+// only once, and only if they were not created or updated in the same run.
+func (r *Report) RecordChange(sourceName string, objectPath constants.APIPath, objectID int, action Action) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sourceReport := r.source(sourceName)
+	recordChange(sourceReport.Objects, sourceReport.seen, objectPath, objectID, action)
+	recordChange(r.Objects, r.seen, objectPath, objectID, action)
+}
+
+func recordChange(
+	objectCounts map[constants.APIPath]*ObjectCounts,
+	seen map[constants.APIPath]map[int]bool,
+	objectPath constants.APIPath,
+	objectID int,
+	action Action,
+) {
+	if seen[objectPath] == nil {
+		seen[objectPath] = make(map[int]bool)
+	}
+	alreadySeen := seen[objectPath][objectID]
+	seen[objectPath][objectID] = true
+	c := counts(objectCounts, objectPath)
+	switch action {
+	case ActionCreated:
+		c.Created++
+	case ActionUpdated:
+		c.Updated++
+	case ActionUnchanged:
+		if !alreadySeen {
+			c.Unchanged++
+		}
+	}
+}
+
+// RecordOrphan records that object of type objectPath was not found
+// on any of the sources.
+func (r *Report) RecordOrphan(objectPath constants.APIPath) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts(r.Objects, objectPath).Orphaned++
+}
+
+// RecordHardDelete records that object was deleted from Netbox.
+func (r *Report) RecordHardDelete(objectPath constants.APIPath, objectID int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.HardDeleted[objectPath] = append(r.HardDeleted[objectPath], objectID)
+	sort.Ints(r.HardDeleted[objectPath])
+}
+
+// RecordSource records the result of the source run.
+func (r *Report) RecordSource(
+	sourceName string,
+	sourceType constants.SourceType,
+	startedAt time.Time,
+	initDuration time.Duration,
+	syncDuration time.Duration,
+	err error,
+) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sourceReport := r.source(sourceName)
+	sourceReport.Type = sourceType
+	sourceReport.StartedAt = startedAt
+	sourceReport.FinishedAt = startedAt.Add(initDuration + syncDuration)
+	sourceReport.InitDurationSeconds = initDuration.Seconds()
+	sourceReport.SyncDurationSeconds = syncDuration.Seconds()
+	sourceReport.Status = StatusSuccess
+	if err != nil {
+		sourceReport.Status = StatusFailed
+		sourceReport.Error = err.Error()
+	}
+}
+
+// Finish sets the end time and the status of the run.
+func (r *Report) Finish(finishedAt time.Time, success bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.FinishedAt = finishedAt
+	r.DurationSeconds = finishedAt.Sub(r.StartedAt).Seconds()
+	r.Status = StatusSuccess
+	if !success {
+		r.Status = StatusFailed
+	}
+}
+
+// WriteJSON writes the report in JSON format.
+func (r *Report) WriteJSON(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteYAML writes the report in YAML format.
+func (r *Report) WriteYAML(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	encoder := yaml.NewEncoder(w)
+	defer encoder.Close()
+	return encoder.Encode(r)
+}
+
+// WriteFile atomically writes the report to the file at path. Report is
+// written in YAML format if file has .yaml or .yml extension, and in JSON
+// format otherwise.
+func (r *Report) WriteFile(path string) error {
+	write := r.WriteJSON
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		write = r.WriteYAML
+	}
+	if err := utils.WriteFileAtomic(path, write); err != nil {
+		return fmt.Errorf("write report file: %s", err)
+	}
+	return nil
+}
+
+func counts(
+	objectCounts map[constants.APIPath]*ObjectCounts,
+	objectPath constants.APIPath,
+) *ObjectCounts {
+	if objectCounts[objectPath] == nil {
+		objectCounts[objectPath] = &ObjectCounts{}
+	}
+	return objectCounts[objectPath]
+}
@@ -0,0 +1,116 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"gopkg.in/yaml.v3"
+)
+
+func testReport() *Report {
+	startedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	r := New(startedAt)
+	r.RecordChange("vmware", constants.VirtualMachinesAPIPath, 1, ActionCreated)
+	r.RecordChange("vmware", constants.VirtualMachinesAPIPath, 1, ActionUnchanged)
+	r.RecordChange("vmware", constants.VirtualMachinesAPIPath, 2, ActionUnchanged)
+	r.RecordChange("vmware", constants.VirtualMachinesAPIPath, 2, ActionUnchanged)
+	r.RecordChange("ovirt", constants.VirtualMachinesAPIPath, 3, ActionUpdated)
+	r.RecordChange("ovirt", constants.VirtualMachinesAPIPath, 2, ActionUnchanged)
+	r.RecordOrphan(constants.VirtualMachinesAPIPath)
+	r.RecordHardDelete(constants.VirtualMachinesAPIPath, 7) //nolint:mnd
+	r.RecordHardDelete(constants.VirtualMachinesAPIPath, 3) //nolint:mnd
+	r.RecordSource("vmware", constants.Vmware, startedAt, time.Second, 2*time.Second, nil)
+	r.RecordSource("ovirt", constants.Ovirt, startedAt, time.Second, 0, errors.New("connection refused"))
+	r.Finish(startedAt.Add(time.Minute), false)
+	return r
+}
+
+func TestReport_Record(t *testing.T) {
+	r := testReport()
+
+	wantObjects := map[constants.APIPath]*ObjectCounts{
+		constants.VirtualMachinesAPIPath: {Created: 1, Updated: 1, Unchanged: 1, Orphaned: 1},
+	}
+	if !reflect.DeepEqual(r.Objects, wantObjects) {
+		t.Errorf("Objects = %v, want %v", r.Objects, wantObjects)
+	}
+	wantHardDeleted := map[constants.APIPath][]int{constants.VirtualMachinesAPIPath: {3, 7}}
+	if !reflect.DeepEqual(r.HardDeleted, wantHardDeleted) {
+		t.Errorf("HardDeleted = %v, want %v", r.HardDeleted, wantHardDeleted)
+	}
+	if len(r.Sources) != 2 || r.Sources[0].Name != "ovirt" || r.Sources[1].Name != "vmware" {
+		t.Fatalf("Sources = %v, want ovirt and vmware", r.Sources)
+	}
+	ovirt := r.Sources[0]
+	if ovirt.Status != StatusFailed || ovirt.Error != "connection refused" {
+		t.Errorf("ovirt status = %s, error = %q, want failed with error", ovirt.Status, ovirt.Error)
+	}
+	vmware := r.Sources[1]
+	if vmware.Status != StatusSuccess || vmware.SyncDurationSeconds != 2 {
+		t.Errorf("vmware status = %s, sync duration = %v, want success and 2", vmware.Status, vmware.SyncDurationSeconds)
+	}
+	wantVmwareObjects := map[constants.APIPath]*ObjectCounts{
+		constants.VirtualMachinesAPIPath: {Created: 1, Unchanged: 1},
+	}
+	if !reflect.DeepEqual(vmware.Objects, wantVmwareObjects) {
+		t.Errorf("vmware objects = %v, want %v", vmware.Objects, wantVmwareObjects)
+	}
+	if r.Status != StatusFailed || r.DurationSeconds != 60 {
+		t.Errorf("status = %s, duration = %v, want failed and 60", r.Status, r.DurationSeconds)
+	}
+}
+
+func TestReport_WriteFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		fileName  string
+		unmarshal func([]byte, interface{}) error
+	}{
+		{
+			name:      "JSON report",
+			fileName:  "report.json",
+			unmarshal: json.Unmarshal,
+		},
+		{
+			name:      "YAML report",
+			fileName:  "report.yaml",
+			unmarshal: yaml.Unmarshal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.fileName)
+			if err := testReport().WriteFile(path); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read report file: %s", err)
+			}
+			var got struct {
+				Status      Status                              `json:"status"      yaml:"status"`
+				Sources     []SourceReport                      `json:"sources"     yaml:"sources"`
+				Objects     map[constants.APIPath]*ObjectCounts `json:"objects"     yaml:"objects"`
+				HardDeleted map[constants.APIPath][]int         `json:"hardDeleted" yaml:"hardDeleted"`
+			}
+			if err := tt.unmarshal(data, &got); err != nil {
+				t.Fatalf("unmarshal report: %s\n%s", err, data)
+			}
+			if got.Status != StatusFailed || len(got.Sources) != 2 {
+				t.Errorf("report = %+v, want failed status and 2 sources", got)
+			}
+			if got.Objects[constants.VirtualMachinesAPIPath].Orphaned != 1 {
+				t.Errorf("objects = %v, want 1 orphaned vm", got.Objects)
+			}
+			if !reflect.DeepEqual(got.HardDeleted[constants.VirtualMachinesAPIPath], []int{3, 7}) {
+				t.Errorf("hardDeleted = %v, want [3 7]", got.HardDeleted)
+			}
+		})
+	}
+}
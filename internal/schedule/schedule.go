@@ -0,0 +1,190 @@
+// Package schedule implements schedules used for running netbox-ssot in
+// daemon mode. Schedule can be either an interval (e.g. 30m, 1h) or a
+// standard 5 field cron expression (e.g. */15 * * * *).
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule returns the next activation time, later than the given time.
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// Interval is a schedule that activates every Every duration.
+type Interval struct {
+	Every time.Duration
+}
+
+func (i Interval) Next(t time.Time) time.Time {
+	return t.Add(i.Every)
+}
+
+// Cron is a schedule parsed from a 5 field cron expression:
+// minute hour day-of-month month day-of-week.
+type Cron struct {
+	minute     map[int]bool
+	hour       map[int]bool
+	dayOfMonth map[int]bool
+	month      map[int]bool
+	dayOfWeek  map[int]bool
+	// domStar and dowStar are true when the corresponding field is *.
+	// As in POSIX cron, if both day fields are restricted, time matches
+	// if either of them matches. Steps like */2 are restricted too (unlike
+	// in Vixie cron, which treats all fields starting with * as unrestricted).
+	domStar bool
+	dowStar bool
+}
+
+// maxCronSearch is the maximum time we search for the next cron activation.
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+// Next returns the first minute after t, that matches the cron expression.
+// If there is no such minute in the next 5 years, zero time is returned.
+func (c *Cron) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxCronSearch)
+	for next.Before(limit) {
+		if !c.month[int(next.Month())] {
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if !c.dayMatches(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if !c.hour[next.Hour()] {
+			// Truncate would round absolute time, which isn't a full hour
+			// in zones with offsets like +05:30
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+			continue
+		}
+		if !c.minute[next.Minute()] {
+			next = next.Add(time.Minute)
+			continue
+		}
+		return next
+	}
+	return time.Time{}
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	domMatch := c.dayOfMonth[t.Day()]
+	dowMatch := c.dayOfWeek[int(t.Weekday())]
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Parse parses schedule expression. Expression is first parsed as
+// a duration (interval schedule), and if that fails, as a cron expression.
+func Parse(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("empty schedule")
+	}
+	if every, err := time.ParseDuration(expr); err == nil {
+		if every <= 0 {
+			return nil, fmt.Errorf("interval must be positive: %s", expr)
+		}
+		return Interval{Every: every}, nil
+	}
+	cron, err := parseCron(expr)
+	if err != nil {
+		return nil, fmt.Errorf("schedule %q is neither a valid duration nor a cron expression: %s", expr, err)
+	}
+	return cron, nil
+}
+
+// cronField describes allowed values of a cron field.
+type cronField struct {
+	name string
+	min  int
+	max  int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+func parseCron(expr string) (*Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(cronFields), len(fields))
+	}
+	values := make([]map[int]bool, len(fields))
+	for i, field := range fields {
+		parsed, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", cronFields[i].name, err)
+		}
+		values[i] = parsed
+	}
+	// Sunday can be written as 0 or 7
+	if values[4][7] {
+		values[4][0] = true
+	}
+	return &Cron{
+		minute:     values[0],
+		hour:       values[1],
+		dayOfMonth: values[2],
+		month:      values[3],
+		dayOfWeek:  values[4],
+		domStar:    fields[2] == "*",
+		dowStar:    fields[4] == "*",
+	}, nil
+}
+
+// parseCronField parses comma separated list of values, ranges (a-b),
+// wildcards (*) and steps (*/n, a-b/n).
+func parseCronField(field string, bounds cronField) (map[int]bool, error) {
+	values := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q", stepPart)
+			}
+			part = rangePart
+		}
+		start, end := bounds.min, bounds.max
+		if part != "*" {
+			startStr, endStr, isRange := strings.Cut(part, "-")
+			var err error
+			start, err = strconv.Atoi(startStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", startStr)
+			}
+			end = start
+			if hasStep && !isRange {
+				// a/n means every n-th value starting with a
+				end = bounds.max
+			}
+			if isRange {
+				end, err = strconv.Atoi(endStr)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value %q", endStr)
+				}
+			}
+			if start < bounds.min || end > bounds.max || start > end {
+				return nil, fmt.Errorf("%q out of range %d-%d", part, bounds.min, bounds.max)
+			}
+		}
+		for v := start; v <= end; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
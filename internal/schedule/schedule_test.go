@@ -0,0 +1,136 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{name: "Interval", expr: "30m"},
+		{name: "Cron every 15 minutes", expr: "*/15 * * * *"},
+		{name: "Cron with lists and ranges", expr: "0,30 8-18 * * 1-5"},
+		{name: "Cron sunday as 7", expr: "0 3 * * 7"},
+		{name: "Empty", expr: "", wantErr: true},
+		{name: "Negative interval", expr: "-5m", wantErr: true},
+		{name: "Wrong number of cron fields", expr: "* * * *", wantErr: true},
+		{name: "Cron value out of range", expr: "60 * * * *", wantErr: true},
+		{name: "Cron invalid step", expr: "*/0 * * * *", wantErr: true},
+		{name: "Garbage", expr: "every hour", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	// Wednesday
+	from := time.Date(2024, time.May, 15, 10, 7, 30, 0, time.UTC)
+	kolkata := time.FixedZone("Asia/Kolkata", 5*60*60+30*60)
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{
+			name: "Interval",
+			expr: "1h",
+			want: time.Date(2024, time.May, 15, 11, 7, 30, 0, time.UTC),
+		},
+		{
+			name: "Every 15 minutes",
+			expr: "*/15 * * * *",
+			want: time.Date(2024, time.May, 15, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			name: "Daily at 3:00",
+			expr: "0 3 * * *",
+			want: time.Date(2024, time.May, 16, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Sundays",
+			expr: "30 2 * * 0",
+			want: time.Date(2024, time.May, 19, 2, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "First of the month",
+			expr: "0 0 1 * *",
+			want: time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Day of month or day of week",
+			expr: "0 0 20 * 5",
+			want: time.Date(2024, time.May, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Day of month range or day of week",
+			expr: "0 0 1-7 * 1",
+			want: time.Date(2024, time.May, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Step in day of month",
+			expr: "0 0 */10 * *",
+			want: time.Date(2024, time.May, 21, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Step in day of week",
+			expr: "0 0 * * */2",
+			want: time.Date(2024, time.May, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// Odd day of month
+			name: "Step in day of month is restricted",
+			expr: "0 0 */2 * 1",
+			want: time.Date(2024, time.May, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// Even day of month, that is a Monday
+			name: "Step in day of month or day of week",
+			expr: "0 0 */2 * 1",
+			from: time.Date(2024, time.May, 19, 10, 0, 0, 0, time.UTC),
+			want: time.Date(2024, time.May, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// Thursday
+			name: "Step in day of week is restricted",
+			expr: "0 0 1 * */2",
+			want: time.Date(2024, time.May, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Step starting with value",
+			expr: "5/20 * * * *",
+			want: time.Date(2024, time.May, 15, 10, 25, 0, 0, time.UTC),
+		},
+		{
+			name: "Hour in zone with half hour offset",
+			expr: "0 12 * * *",
+			from: time.Date(2024, time.May, 15, 10, 7, 30, 0, kolkata),
+			want: time.Date(2024, time.May, 15, 12, 0, 0, 0, kolkata),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			start := from
+			if !tt.from.IsZero() {
+				start = tt.from
+			}
+			if got := s.Next(start); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,10 +12,10 @@ import (
 
 // Source is an interface for all sources (e.g. oVirt, VMware, etc.).
 type Source interface {
-	// Init initializes the source
-	Init() error
-	// Sync syncs the source to Netbox inventory
-	Sync(*inventory.NetboxInventory) error
+	// Init initializes the source. It should stop when ctx is canceled.
+	Init(ctx context.Context) error
+	// Sync syncs the source to Netbox inventory. It should stop when ctx is canceled.
+	Sync(ctx context.Context, nbi *inventory.NetboxInventory) error
 }
 
 // Config is a common configuration that all sources share.
@@ -25,8 +25,7 @@ type Config struct {
 	CustomCertPool *x509.CertPool
 	SourceNameTag  *objects.Tag
 	SourceTypeTag  *objects.Tag
-	Ctx            context.Context //nolint:containedctx
-	CAFile         string          // path to the ca file
+	CAFile         string // path to the ca file
 }
 
 func (c Config) GetSourceTags() []*objects.Tag {
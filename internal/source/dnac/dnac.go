@@ -1,6 +1,7 @@
 package dnac
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"sync"
@@ -45,17 +46,17 @@ type DnacSource struct {
 	InterfaceID2nbInterface sync.Map // InterfaceID -> nbInterface
 }
 
-func (ds *DnacSource) Init() error {
+func (ds *DnacSource) Init(ctx context.Context) error {
 	dnacURL := fmt.Sprintf(
 		"%s://%s:%d",
 		ds.Config.SourceConfig.HTTPScheme,
 		ds.Config.SourceConfig.Hostname,
 		ds.Config.SourceConfig.Port,
 	)
-	Client, err := dnac.NewClientWithOptions(
+	Client, err := dnac.NewClientWithOptionsNoAuth(
 		dnacURL,
 		ds.SourceConfig.Username,
-		ds.SourceConfig.Password,
+		ds.SourceConfig.Password.Value(),
 		"false",
 		strconv.FormatBool(ds.SourceConfig.ValidateCert),
 		nil,
@@ -63,8 +64,17 @@ func (ds *DnacSource) Init() error {
 	if err != nil {
 		return fmt.Errorf("creating dnac client: %s", err)
 	}
+	// Transport must be set before the client authenticates
+	transport, err := utils.NewHTTPTransport(ds.SourceConfig.HTTPClientConfig())
+	if err != nil {
+		return fmt.Errorf("create http transport: %s", err)
+	}
+	Client.RestyClient().SetTransport(transport)
+	if err := Client.AuthClient(); err != nil {
+		return fmt.Errorf("authenticating dnac client: %s", err)
+	}
 	// Initialize items from vsphere API to local storage
-	initFunctions := []func(*dnac.Client) error{
+	initFunctions := []func(context.Context, *dnac.Client) error{
 		ds.initSites,
 		ds.initMemberships,
 		ds.initDevices,
@@ -74,12 +84,12 @@ func (ds *DnacSource) Init() error {
 
 	for _, initFunc := range initFunctions {
 		startTime := time.Now()
-		if err := initFunc(Client); err != nil {
+		if err := initFunc(ctx, Client); err != nil {
 			return fmt.Errorf("dnac initialization failure: %v", err)
 		}
 		duration := time.Since(startTime)
 		ds.Logger.Infof(
-			ds.Ctx,
+			ctx,
 			"Successfully initialized %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(initFunc, "init"),
 			duration.Seconds(),
@@ -88,8 +98,8 @@ func (ds *DnacSource) Init() error {
 	return nil
 }
 
-func (ds *DnacSource) Sync(nbi *inventory.NetboxInventory) error {
-	syncFunctions := []func(*inventory.NetboxInventory) error{
+func (ds *DnacSource) Sync(ctx context.Context, nbi *inventory.NetboxInventory) error {
+	syncFunctions := []func(context.Context, *inventory.NetboxInventory) error{
 		ds.syncSites,
 		ds.syncVlans,
 		ds.syncDevices,
@@ -100,13 +110,13 @@ func (ds *DnacSource) Sync(nbi *inventory.NetboxInventory) error {
 
 	for _, syncFunc := range syncFunctions {
 		startTime := time.Now()
-		err := syncFunc(nbi)
+		err := syncFunc(ctx, nbi)
 		if err != nil {
 			return err
 		}
 		duration := time.Since(startTime)
 		ds.Logger.Infof(
-			ds.Ctx,
+			ctx,
 			"Successfully synced %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(syncFunc, "sync"),
 			duration.Seconds(),
@@ -1,6 +1,7 @@
 package dnac
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -9,7 +10,7 @@ import (
 
 // Collects all sites from DNAC API and stores them in the
 // local source inventory.
-func (ds *DnacSource) initSites(c *dnac.Client) error {
+func (ds *DnacSource) initSites(ctx context.Context, c *dnac.Client) error {
 	offset := 0
 	limit := 100
 	allSites := make([]dnac.ResponseSitesGetSiteResponse, 0)
@@ -40,7 +41,7 @@ func (ds *DnacSource) initSites(c *dnac.Client) error {
 
 // Collects all devices from DNAC API and stores them in the
 // local source inventory.
-func (ds *DnacSource) initDevices(c *dnac.Client) error {
+func (ds *DnacSource) initDevices(ctx context.Context, c *dnac.Client) error {
 	offset := 0
 	limit := 100
 	allDevices := make([]dnac.ResponseDevicesGetDeviceListResponse, 0)
@@ -86,7 +87,7 @@ func (ds *DnacSource) initVlansForDevice(c *dnac.Client, deviceID string) {
 
 // Collects all interfaces from DNAC API and stores them in the
 // local source inventory.
-func (ds *DnacSource) initInterfaces(c *dnac.Client) error {
+func (ds *DnacSource) initInterfaces(ctx context.Context, c *dnac.Client) error {
 	offset := 0
 	limit := 100
 	allInterfaces := make([]dnac.ResponseDevicesGetAllInterfacesResponse, 0)
@@ -129,7 +130,7 @@ func (ds *DnacSource) initInterfaces(c *dnac.Client) error {
 // This is necessary to find relations between devices and sites.
 //
 // This function has to run after InitSites.
-func (ds *DnacSource) initMemberships(c *dnac.Client) error {
+func (ds *DnacSource) initMemberships(ctx context.Context, c *dnac.Client) error {
 	offset := 0
 	limit := 100
 	ds.Site2Devices = make(map[string]map[string]bool)
@@ -170,7 +171,7 @@ func (ds *DnacSource) initMemberships(c *dnac.Client) error {
 // initWirelessLANs collects all wireless profiles, dynamic interfaces
 // and enterprise SSIDs from DNAC API and stores them in the local source inventory.
 // All this data is necessary to create WirelessLANs and WirelessLANGroups in netbox.
-func (ds *DnacSource) initWirelessLANs(c *dnac.Client) error {
+func (ds *DnacSource) initWirelessLANs(ctx context.Context, c *dnac.Client) error {
 	// Get all WirelessProfiles
 	wirelessProfiles, response, err := c.Wireless.GetWirelessProfile(nil)
 	if err != nil {
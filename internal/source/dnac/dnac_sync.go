@@ -1,6 +1,7 @@
 package dnac
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -15,7 +16,7 @@ import (
 )
 
 // Syncs dnac sites to netbox inventory.
-func (ds *DnacSource) syncSites(nbi *inventory.NetboxInventory) error {
+func (ds *DnacSource) syncSites(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	for _, site := range ds.Sites {
 		dnacSite := &objects.Site{
 			NetboxObject: objects.NetboxObject{
@@ -40,7 +41,7 @@ func (ds *DnacSource) syncSites(nbi *inventory.NetboxInventory) error {
 				}
 			}
 		}
-		nbSite, err := nbi.AddSite(ds.Ctx, dnacSite)
+		nbSite, err := nbi.AddSite(ctx, dnacSite)
 		if err != nil {
 			return fmt.Errorf("adding site: %s", err)
 		}
@@ -50,10 +51,10 @@ func (ds *DnacSource) syncSites(nbi *inventory.NetboxInventory) error {
 }
 
 // Syncs dnac vlans to netbox inventory.
-func (ds *DnacSource) syncVlans(nbi *inventory.NetboxInventory) error {
+func (ds *DnacSource) syncVlans(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	for vid, vlan := range ds.Vlans {
 		vlanSite, err := common.MatchVlanToSite(
-			ds.Ctx,
+			ctx,
 			nbi,
 			vlan.InterfaceName,
 			ds.SourceConfig.VlanSiteRelations,
@@ -62,7 +63,7 @@ func (ds *DnacSource) syncVlans(nbi *inventory.NetboxInventory) error {
 			return fmt.Errorf("match vlan to site: %s", err)
 		}
 		vlanGroup, err := common.MatchVlanToGroup(
-			ds.Ctx,
+			ctx,
 			nbi,
 			vlan.InterfaceName,
 			vlanSite,
@@ -73,7 +74,7 @@ func (ds *DnacSource) syncVlans(nbi *inventory.NetboxInventory) error {
 			return fmt.Errorf("vlanGroup: %s", err)
 		}
 		vlanTenant, err := common.MatchVlanToTenant(
-			ds.Ctx,
+			ctx,
 			nbi,
 			vlan.InterfaceName,
 			ds.SourceConfig.VlanTenantRelations,
@@ -81,7 +82,7 @@ func (ds *DnacSource) syncVlans(nbi *inventory.NetboxInventory) error {
 		if err != nil {
 			return fmt.Errorf("vlanTenant: %s", err)
 		}
-		newVlan, err := nbi.AddVlan(ds.Ctx, &objects.Vlan{
+		newVlan, err := nbi.AddVlan(ctx, &objects.Vlan{
 			NetboxObject: objects.NetboxObject{
 				Tags:        ds.GetSourceTags(),
 				Description: vlan.VLANType,
@@ -102,7 +103,7 @@ func (ds *DnacSource) syncVlans(nbi *inventory.NetboxInventory) error {
 		if vlan.Prefix != "" && vlan.NetworkAddress != "" {
 			// Create prefix for this vlan
 			prefix := fmt.Sprintf("%s/%s", vlan.NetworkAddress, vlan.Prefix)
-			_, err = nbi.AddPrefix(ds.Ctx, &objects.Prefix{
+			_, err = nbi.AddPrefix(ctx, &objects.Prefix{
 				NetboxObject: objects.NetboxObject{
 					Tags: ds.GetSourceTags(),
 					CustomFields: map[string]interface{}{
@@ -122,7 +123,7 @@ func (ds *DnacSource) syncVlans(nbi *inventory.NetboxInventory) error {
 	}
 	return nil
 }
-func (ds *DnacSource) syncDevices(nbi *inventory.NetboxInventory) error {
+func (ds *DnacSource) syncDevices(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	const maxGoroutines = 50
 	guard := make(chan struct{}, maxGoroutines)
 	errChan := make(chan error, len(ds.Devices))
@@ -136,7 +137,7 @@ func (ds *DnacSource) syncDevices(nbi *inventory.NetboxInventory) error {
 			defer wg.Done()
 			defer func() { <-guard }() // Release one spot in the semaphore
 
-			err := ds.syncDevice(nbi, deviceID, device)
+			err := ds.syncDevice(ctx, nbi, deviceID, device)
 			if err != nil {
 				errChan <- err
 			}
@@ -157,6 +158,7 @@ func (ds *DnacSource) syncDevices(nbi *inventory.NetboxInventory) error {
 }
 
 func (ds *DnacSource) syncDevice(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	deviceID string,
 	device dnac.ResponseDevicesGetDeviceListResponse,
@@ -170,7 +172,7 @@ func (ds *DnacSource) syncDevice(
 		description = "See comments"
 	}
 
-	ciscoManufacturer, err := nbi.AddManufacturer(ds.Ctx, &objects.Manufacturer{
+	ciscoManufacturer, err := nbi.AddManufacturer(ctx, &objects.Manufacturer{
 		Name: "Cisco",
 		Slug: utils.Slugify("Cisco"),
 	})
@@ -182,7 +184,7 @@ func (ds *DnacSource) syncDevice(
 	var deviceRole *objects.DeviceRole
 	if len(ds.SourceConfig.HostRoleRelations) > 0 {
 		deviceRole, err = common.MatchHostToRole(
-			ds.Ctx,
+			ctx,
 			nbi,
 			device.Hostname,
 			ds.SourceConfig.HostRoleRelations,
@@ -192,7 +194,7 @@ func (ds *DnacSource) syncDevice(
 		}
 	}
 	if deviceRole == nil {
-		deviceRole, err = nbi.AddDeviceRole(ds.Ctx, &objects.DeviceRole{
+		deviceRole, err = nbi.AddDeviceRole(ctx, &objects.DeviceRole{
 			Name:   device.Family,
 			Slug:   utils.Slugify(device.Family),
 			Color:  constants.ColorAqua,
@@ -210,7 +212,7 @@ func (ds *DnacSource) syncDevice(
 		platformName = strings.Trim(fmt.Sprintf("%s %s", device.SoftwareType, device.SoftwareVersion), " ")
 	}
 
-	platform, err := nbi.AddPlatform(ds.Ctx, &objects.Platform{
+	platform, err := nbi.AddPlatform(ctx, &objects.Platform{
 		Name:         platformName,
 		Slug:         utils.Slugify(platformName),
 		Manufacturer: ciscoManufacturer,
@@ -223,7 +225,7 @@ func (ds *DnacSource) syncDevice(
 	if site, ok := ds.SiteID2nbSite.Load(ds.Device2Site[device.ID]); ok {
 		if deviceSite, ok = site.(*objects.Site); !ok {
 			ds.Logger.Errorf(
-				ds.Ctx,
+				ctx,
 				"Type assertion to *objects.Site failed for device %s, this should not happen. This device will be skipped",
 				device.ID,
 			)
@@ -231,7 +233,7 @@ func (ds *DnacSource) syncDevice(
 		}
 	} else {
 		ds.Logger.Errorf(
-			ds.Ctx,
+			ctx,
 			"DeviceSite is not existing for device %s, this should not happen. This device will be skipped",
 			device.ID,
 		)
@@ -240,14 +242,14 @@ func (ds *DnacSource) syncDevice(
 
 	if device.Type == "" {
 		ds.Logger.Errorf(
-			ds.Ctx,
+			ctx,
 			"Device type for device %s is empty, this should not happen. This device will be skipped",
 			device.ID,
 		)
 		return nil
 	}
 
-	deviceType, err := nbi.AddDeviceType(ds.Ctx, &objects.DeviceType{
+	deviceType, err := nbi.AddDeviceType(ctx, &objects.DeviceType{
 		Manufacturer: ciscoManufacturer,
 		Model:        device.Type,
 		Slug:         utils.Slugify(device.Type),
@@ -257,7 +259,7 @@ func (ds *DnacSource) syncDevice(
 	}
 
 	deviceTenant, err := common.MatchHostToTenant(
-		ds.Ctx,
+		ctx,
 		nbi,
 		device.Hostname,
 		ds.SourceConfig.HostTenantRelations,
@@ -276,7 +278,7 @@ func (ds *DnacSource) syncDevice(
 		deviceSerialNumber = device.SerialNumber
 	}
 
-	nbDevice, err := nbi.AddDevice(ds.Ctx, &objects.Device{
+	nbDevice, err := nbi.AddDevice(ctx, &objects.Device{
 		NetboxObject: objects.NetboxObject{
 			Tags:        ds.GetSourceTags(),
 			Description: description,
@@ -305,7 +307,7 @@ func (ds *DnacSource) syncDevice(
 	return nil
 }
 
-func (ds *DnacSource) syncDeviceInterfaces(nbi *inventory.NetboxInventory) error {
+func (ds *DnacSource) syncDeviceInterfaces(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	const maxGoroutines = 50
 	guard := make(chan struct{}, maxGoroutines)
 	errChan := make(chan error, len(ds.Interfaces))
@@ -319,7 +321,7 @@ func (ds *DnacSource) syncDeviceInterfaces(nbi *inventory.NetboxInventory) error
 			defer wg.Done()
 			defer func() { <-guard }()
 
-			err := ds.syncDeviceInterface(nbi, ifaceID, iface)
+			err := ds.syncDeviceInterface(ctx, nbi, ifaceID, iface)
 			if err != nil {
 				errChan <- err
 			}
@@ -340,6 +342,7 @@ func (ds *DnacSource) syncDeviceInterfaces(nbi *inventory.NetboxInventory) error
 }
 
 func (ds *DnacSource) syncDeviceInterface(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	ifaceID string,
 	iface dnac.ResponseDevicesGetAllInterfacesResponse,
@@ -348,14 +351,14 @@ func (ds *DnacSource) syncDeviceInterface(
 
 	ifaceDevice, err := ds.getDevice(iface.DeviceID)
 	if err != nil {
-		ds.Logger.Errorf(ds.Ctx, "%s This interface will be skipped", err)
+		ds.Logger.Errorf(ctx, "%s This interface will be skipped", err)
 		return nil
 	}
 
-	ifaceDuplex := ds.getInterfaceDuplex(iface.Duplex)
+	ifaceDuplex := ds.getInterfaceDuplex(ctx, iface.Duplex)
 	ifaceStatus, err := ds.getInterfaceStatus(iface.Status)
 	if err != nil {
-		ds.Logger.Errorf(ds.Ctx, "%s", err)
+		ds.Logger.Errorf(ctx, "%s", err)
 		return nil
 	}
 
@@ -363,12 +366,12 @@ func (ds *DnacSource) syncDeviceInterface(
 	var ifaceType *objects.InterfaceType
 	speed, err := strconv.Atoi(iface.Speed)
 	if err != nil {
-		ds.Logger.Errorf(ds.Ctx, "wrong speed for iface %s", iface.Speed)
+		ds.Logger.Errorf(ctx, "wrong speed for iface %s", iface.Speed)
 	} else {
 		ifaceSpeed = objects.InterfaceSpeed(speed)
 		typeI, err := ds.getInterfaceType(iface.InterfaceType, speed)
 		if err != nil {
-			ds.Logger.Errorf(ds.Ctx, "%s. Skipping this device...", err)
+			ds.Logger.Errorf(ctx, "%s. Skipping this device...", err)
 			return nil
 		}
 		ifaceType = typeI
@@ -376,17 +379,17 @@ func (ds *DnacSource) syncDeviceInterface(
 
 	ifaceName := iface.PortName
 	if err := ds.validateInterfaceName(ifaceName, ifaceID); err != nil {
-		ds.Logger.Errorf(ds.Ctx, "%s", err)
+		ds.Logger.Errorf(ctx, "%s", err)
 		return nil
 	}
 
-	ifaceMode, ifaceAccessVlan, err := ds.getVlanModeAndAccessVlan(iface.PortMode, iface.VLANID)
+	ifaceMode, ifaceAccessVlan, err := ds.getVlanModeAndAccessVlan(ctx, iface.PortMode, iface.VLANID)
 	if err != nil {
-		ds.Logger.Errorf(ds.Ctx, "%s", err)
+		ds.Logger.Errorf(ctx, "%s", err)
 		return nil
 	}
 
-	nbIface, err := nbi.AddInterface(ds.Ctx, &objects.Interface{
+	nbIface, err := nbi.AddInterface(ctx, &objects.Interface{
 		NetboxObject: objects.NetboxObject{
 			Description: strings.TrimSpace(ifaceDescription),
 			Tags:        ds.GetSourceTags(),
@@ -410,7 +413,7 @@ func (ds *DnacSource) syncDeviceInterface(
 
 	if iface.MacAddress != "" {
 		nbMACAddress, err := common.CreateMACAddressForObjectType(
-			ds.Ctx,
+			ctx,
 			nbi,
 			iface.MacAddress,
 			nbIface,
@@ -418,14 +421,14 @@ func (ds *DnacSource) syncDeviceInterface(
 		if err != nil {
 			return fmt.Errorf("creating MAC address: %s", err)
 		}
-		if err = common.SetPrimaryMACForInterface(ds.Ctx, nbi, nbIface, nbMACAddress); err != nil {
+		if err = common.SetPrimaryMACForInterface(ctx, nbi, nbIface, nbMACAddress); err != nil {
 			return fmt.Errorf("setting primary MAC for interface: %s", err)
 		}
 	}
 
-	err = ds.addIPAddressToInterface(nbi, nbIface, iface, ifaceDevice)
+	err = ds.addIPAddressToInterface(ctx, nbi, nbIface, iface, ifaceDevice)
 	if err != nil {
-		ds.Logger.Errorf(ds.Ctx, "adding IP address: %s", err)
+		ds.Logger.Errorf(ctx, "adding IP address: %s", err)
 	}
 
 	ds.InterfaceID2nbInterface.Store(ifaceID, nbIface)
@@ -442,7 +445,7 @@ func (ds *DnacSource) getDevice(deviceID string) (*objects.Device, error) {
 	return nil, fmt.Errorf("device %s not found", deviceID)
 }
 
-func (ds *DnacSource) getInterfaceDuplex(duplex string) *objects.InterfaceDuplex {
+func (ds *DnacSource) getInterfaceDuplex(ctx context.Context, duplex string) *objects.InterfaceDuplex {
 	switch duplex {
 	case "":
 		return nil
@@ -453,7 +456,7 @@ func (ds *DnacSource) getInterfaceDuplex(duplex string) *objects.InterfaceDuplex
 	case "HalfDuplex":
 		return &objects.DuplexHalf
 	default:
-		ds.Logger.Warningf(ds.Ctx, "Not implemented Duplex value: %s", duplex)
+		ds.Logger.Warningf(ctx, "Not implemented Duplex value: %s", duplex)
 		return nil
 	}
 }
@@ -502,6 +505,7 @@ func (ds *DnacSource) validateInterfaceName(ifaceName, ifaceID string) error {
 }
 
 func (ds *DnacSource) getVlanModeAndAccessVlan(
+	ctx context.Context,
 	portMode, vlanID string,
 ) (*objects.InterfaceMode, *objects.Vlan, error) {
 	vid, err := strconv.Atoi(vlanID)
@@ -522,7 +526,7 @@ func (ds *DnacSource) getVlanModeAndAccessVlan(
 	case "trunk":
 		return &objects.InterfaceModeTagged, nil, nil
 	case "dynamic_auto", "routed":
-		ds.Logger.Debugf(ds.Ctx, "vlan mode '%s' is not implemented yet", portMode)
+		ds.Logger.Debugf(ctx, "vlan mode '%s' is not implemented yet", portMode)
 		return nil, nil, nil
 	default:
 		return nil, nil, fmt.Errorf("unknown interface mode: '%s'", portMode)
@@ -530,6 +534,7 @@ func (ds *DnacSource) getVlanModeAndAccessVlan(
 }
 
 func (ds *DnacSource) addIPAddressToInterface(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	iface *objects.Interface,
 	ifaceDetails dnac.ResponseDevicesGetAllInterfacesResponse,
@@ -553,7 +558,7 @@ func (ds *DnacSource) addIPAddressToInterface(
 		defaultMask = maskBits
 	}
 
-	nbIPAddress, err := nbi.AddIPAddress(ds.Ctx, &objects.IPAddress{
+	nbIPAddress, err := nbi.AddIPAddress(ctx, &objects.IPAddress{
 		NetboxObject: objects.NetboxObject{
 			Tags: ds.GetSourceTags(),
 			CustomFields: map[string]interface{}{
@@ -575,9 +580,9 @@ func (ds *DnacSource) addIPAddressToInterface(
 	// Optionally, add the prefix to NetBox
 	prefix, mask, err := utils.GetPrefixAndMaskFromIPAddress(nbIPAddress.Address)
 	if err != nil {
-		ds.Logger.Warningf(ds.Ctx, "failed extracting prefix from IPAddress: %s", err)
+		ds.Logger.Warningf(ctx, "failed extracting prefix from IPAddress: %s", err)
 	} else if mask != constants.MaxIPv4MaskBits {
-		_, err = nbi.AddPrefix(ds.Ctx, &objects.Prefix{
+		_, err = nbi.AddPrefix(ctx, &objects.Prefix{
 			NetboxObject: objects.NetboxObject{
 				Tags: ds.GetSourceTags(),
 			},
@@ -585,7 +590,7 @@ func (ds *DnacSource) addIPAddressToInterface(
 			Tenant: iface.Device.Tenant,
 		})
 		if err != nil {
-			ds.Logger.Errorf(ds.Ctx, "adding prefix: %s", err)
+			ds.Logger.Errorf(ctx, "adding prefix: %s", err)
 		}
 	}
 
@@ -593,7 +598,7 @@ func (ds *DnacSource) addIPAddressToInterface(
 	dnacDevice := ds.Devices[ifaceDetails.DeviceID]
 	deviceManagementIP := dnacDevice.ManagementIPAddress
 	if deviceManagementIP == ifaceDetails.IPv4Address {
-		if err := common.SetPrimaryIPAddressForObject(ds.Ctx, nbi, ifaceDevice, nbIPAddress, nil); err != nil {
+		if err := common.SetPrimaryIPAddressForObject(ctx, nbi, ifaceDevice, nbIPAddress, nil); err != nil {
 			return fmt.Errorf("setting primary IPv4 for device: %s", err)
 		}
 	}
@@ -601,12 +606,12 @@ func (ds *DnacSource) addIPAddressToInterface(
 	return nil
 }
 
-func (ds *DnacSource) syncWirelessLANs(nbi *inventory.NetboxInventory) error {
+func (ds *DnacSource) syncWirelessLANs(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	// First we sync wirelessLANGroups
 	for wlanName, wlanSecDetails := range ds.SSID2SecurityDetails {
 		wlanWirelessProfile := ds.SSID2WirelessProfileDetails[wlanName]
 		wlanGroupName := ds.SSID2WlanGroupName[wlanName]
-		wlanGroup, err := nbi.AddWirelessLANGroup(ds.Ctx, &objects.WirelessLANGroup{
+		wlanGroup, err := nbi.AddWirelessLANGroup(ctx, &objects.WirelessLANGroup{
 			NetboxObject: objects.NetboxObject{
 				Tags: ds.GetSourceTags(),
 				CustomFields: map[string]interface{}{
@@ -620,7 +625,7 @@ func (ds *DnacSource) syncWirelessLANs(nbi *inventory.NetboxInventory) error {
 			return fmt.Errorf("add wirelessLANGroup %s: %s", wlanGroup, err)
 		}
 		vlanSite, err := common.MatchVlanToSite(
-			ds.Ctx,
+			ctx,
 			nbi,
 			wlanWirelessProfile.InterfaceName,
 			ds.SourceConfig.VlanSiteRelations,
@@ -629,7 +634,7 @@ func (ds *DnacSource) syncWirelessLANs(nbi *inventory.NetboxInventory) error {
 			return fmt.Errorf("match vlan to site: %s", err)
 		}
 		vlanGroup, err := common.MatchVlanToGroup(
-			ds.Ctx,
+			ctx,
 			nbi,
 			wlanWirelessProfile.InterfaceName,
 			vlanSite,
@@ -651,7 +656,7 @@ func (ds *DnacSource) syncWirelessLANs(nbi *inventory.NetboxInventory) error {
 			wlanAuthType = &objects.WirelessLanAuthTypeWep
 		default:
 			ds.Logger.Debugf(
-				ds.Ctx,
+				ctx,
 				"wlan auth type %s is not implemented yet",
 				wlanSecDetails.SecurityLevel,
 			)
@@ -681,7 +686,7 @@ func (ds *DnacSource) syncWirelessLANs(nbi *inventory.NetboxInventory) error {
 			Status:   wlanStatus,
 		}
 
-		_, err = nbi.AddWirelessLAN(ds.Ctx, wlanStruct)
+		_, err = nbi.AddWirelessLAN(ctx, wlanStruct)
 		if err != nil {
 			return fmt.Errorf("add wirelessLAN %+v: %s", wlanStruct, err)
 		}
@@ -693,24 +698,24 @@ func (ds *DnacSource) syncWirelessLANs(nbi *inventory.NetboxInventory) error {
 // IPs are not assigned to any interface found in /interface endpoint.
 // These devices are usually APs, whose interfaces are not returned
 // by the /interface endpoint.
-func (ds *DnacSource) syncMissingDevicePrimaryIPs(nbi *inventory.NetboxInventory) error {
+func (ds *DnacSource) syncMissingDevicePrimaryIPs(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	var syncErr error
 	ds.DeviceID2isMissingPrimaryIP.Range(func(key, value interface{}) bool {
 		dnacDeviceID, ok := key.(string)
 		if !ok {
-			ds.Logger.Errorf(ds.Ctx, "Invalid type for key in DeviceID2isMissingPrimaryIP map")
+			ds.Logger.Errorf(ctx, "Invalid type for key in DeviceID2isMissingPrimaryIP map")
 			return false
 		}
 		isMissingPrimaryIP, ok := value.(bool)
 		if !ok {
-			ds.Logger.Errorf(ds.Ctx, "Invalid type for value in DeviceID2isMissingPrimaryIP map")
+			ds.Logger.Errorf(ctx, "Invalid type for value in DeviceID2isMissingPrimaryIP map")
 			return false
 		}
 
 		if isMissingPrimaryIP {
 			device := ds.Devices[dnacDeviceID]
 			if device.ManagementIPAddress == "" {
-				ds.Logger.Debugf(ds.Ctx, "Device %s has no management IP assigned", dnacDeviceID)
+				ds.Logger.Debugf(ctx, "Device %s has no management IP assigned", dnacDeviceID)
 				return true
 			}
 
@@ -728,14 +733,14 @@ func (ds *DnacSource) syncMissingDevicePrimaryIPs(nbi *inventory.NetboxInventory
 				Type:   &objects.OtherInterfaceType,
 				Status: true,
 			}
-			nbIface, err := nbi.AddInterface(ds.Ctx, managementInterfaceStruct)
+			nbIface, err := nbi.AddInterface(ctx, managementInterfaceStruct)
 			if err != nil {
 				syncErr = fmt.Errorf("add interface %+v: %s", managementInterfaceStruct, err)
 				return false
 			}
 
 			nbMACAddress, err := common.CreateMACAddressForObjectType(
-				ds.Ctx,
+				ctx,
 				nbi,
 				device.MacAddress,
 				nbIface,
@@ -744,7 +749,7 @@ func (ds *DnacSource) syncMissingDevicePrimaryIPs(nbi *inventory.NetboxInventory
 				syncErr = fmt.Errorf("creating MAC address: %s", err)
 				return false
 			}
-			if err = common.SetPrimaryMACForInterface(ds.Ctx, nbi, nbIface, nbMACAddress); err != nil {
+			if err = common.SetPrimaryMACForInterface(ctx, nbi, nbIface, nbMACAddress); err != nil {
 				syncErr = fmt.Errorf("setting primary MAC for interface: %s", err)
 				return false
 			}
@@ -764,14 +769,14 @@ func (ds *DnacSource) syncMissingDevicePrimaryIPs(nbi *inventory.NetboxInventory
 				AssignedObjectType: constants.ContentTypeDcimInterface,
 				AssignedObjectID:   nbIface.ID,
 			}
-			nbIPAddress, err := nbi.AddIPAddress(ds.Ctx, nbIPAddressStruct)
+			nbIPAddress, err := nbi.AddIPAddress(ctx, nbIPAddressStruct)
 			if err != nil {
 				syncErr = fmt.Errorf("add IP address %+v: %s", nbIPAddressStruct, err)
 				return false
 			}
 			updatedDevice := *nbDevice
 			updatedDevice.PrimaryIPv4 = nbIPAddress
-			_, err = nbi.AddDevice(ds.Ctx, &updatedDevice)
+			_, err = nbi.AddDevice(ctx, &updatedDevice)
 			if err != nil {
 				syncErr = fmt.Errorf("add primary IPv4 address %+v: %s", updatedDevice, err)
 				return false
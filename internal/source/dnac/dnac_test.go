@@ -1 +1,109 @@
 package dnac
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	dnac "github.com/cisco-en-programmability/dnacenter-go-sdk/v7/sdk"
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox/sourcetest"
+	"github.com/src-doo/netbox-ssot/internal/netbox/inventory"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/parser"
+	"github.com/src-doo/netbox-ssot/internal/source/common"
+)
+
+// runDnac runs netbox-ssot with a dnac source, that has sites HQ and
+// Branch, and the given devices on sites in device2Site.
+func runDnac(
+	t *testing.T,
+	netboxServer *fakenetbox.Server,
+	devices []dnac.ResponseDevicesGetDeviceListResponse,
+	device2Site map[string]string,
+) {
+	t.Helper()
+	sourceConfig := &parser.SourceConfig{Name: "dnac", Type: constants.Dnac}
+	sourcetest.Run(t, netboxServer, sourceConfig,
+		func(ctx context.Context, nbi *inventory.NetboxInventory, config common.Config) error {
+			ds := &DnacSource{
+				Config: config,
+				Sites: map[string]dnac.ResponseSitesGetSiteResponse{
+					"HQ":     {ID: "HQ", Name: "HQ"},
+					"Branch": {ID: "Branch", Name: "Branch"},
+				},
+				Devices:     make(map[string]dnac.ResponseDevicesGetDeviceListResponse),
+				Device2Site: device2Site,
+			}
+			for _, device := range devices {
+				ds.Devices[device.ID] = device
+			}
+			return ds.Sync(ctx, nbi)
+		})
+}
+
+// newDevice returns a dnac switch with the id and hostname.
+func newDevice(id string, hostname string) dnac.ResponseDevicesGetDeviceListResponse {
+	return dnac.ResponseDevicesGetDeviceListResponse{
+		ID:       id,
+		Hostname: hostname,
+		Family:   "Switches and Hubs",
+		Type:     "Cisco Catalyst 9300 Switch",
+	}
+}
+
+// deviceSites returns sites of all devices in Netbox by device names.
+func deviceSites(t *testing.T, netboxServer *fakenetbox.Server) map[string]string {
+	t.Helper()
+	devices, err := fakenetbox.Objects[objects.Device](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sites := make(map[string]string, len(devices))
+	for _, device := range devices {
+		sites[device.Name] = device.Site.Name
+	}
+	return sites
+}
+
+func TestDnacSource_EndToEnd(t *testing.T) {
+	netboxServer := fakenetbox.NewServer()
+	defer netboxServer.Close()
+	devices := []dnac.ResponseDevicesGetDeviceListResponse{
+		newDevice("2c9f3d1e-5b7a-4e8c-9d0f-1a2b3c4d5e6f", "switch1"),
+		newDevice("7a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d", "switch2"),
+	}
+	device2Site := map[string]string{devices[0].ID: "HQ", devices[1].ID: "Branch"}
+
+	runDnac(t, netboxServer, devices, device2Site)
+	want := map[string]string{"switch1": "HQ", "switch2": "Branch"}
+	if got := deviceSites(t, netboxServer); !maps.Equal(got, want) {
+		t.Errorf("devices = %v, want %v", got, want)
+	}
+
+	// Device removed from dnac is removed from Netbox
+	runDnac(t, netboxServer, devices[:1], device2Site)
+	want = map[string]string{"switch1": "HQ"}
+	if got := deviceSites(t, netboxServer); !maps.Equal(got, want) {
+		t.Errorf("devices = %v, want %v", got, want)
+	}
+}
+
+func TestDnacSource_EndToEnd_SourceID(t *testing.T) {
+	netboxServer := fakenetbox.NewServer()
+	defer netboxServer.Close()
+	devices := []dnac.ResponseDevicesGetDeviceListResponse{newDevice("2c9f3d1e-5b7a-4e8c-9d0f-1a2b3c4d5e6f", "switch1")}
+	runDnac(t, netboxServer, devices, map[string]string{devices[0].ID: "HQ"})
+
+	// Device id identifies the device, so the device moved to
+	// another site is patched instead of created again
+	runDnac(t, netboxServer, devices, map[string]string{devices[0].ID: "Branch"})
+	netboxDevices, err := fakenetbox.Objects[objects.Device](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(netboxDevices) != 1 || netboxDevices[0].ID != 1 || netboxDevices[0].Site.Name != "Branch" {
+		t.Errorf("devices = %v, want device 1 moved to Branch", netboxDevices)
+	}
+}
@@ -6,27 +6,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
 	"strings"
 	"time"
-)
 
-const (
-	maxRetries     = 5
-	initialBackoff = 500 * time.Millisecond
-	backoffFactor  = 2.0
-	maxBackoff     = 16 * time.Second
+	"github.com/src-doo/netbox-ssot/internal/utils"
 )
 
-// exponentialBackoff calculates the backoff duration based on the number of attempts.
-func exponentialBackoff(attempt int) time.Duration {
-	backoff := time.Duration(float64(initialBackoff) * math.Pow(backoffFactor, float64(attempt)))
-	if backoff > maxBackoff {
-		backoff = maxBackoff
-	}
-	return backoff
-}
+const maxRetries = 5
+
+// retryBackoff calculates waits between retries of failed requests.
+var retryBackoff = utils.Backoff{Initial: 500 * time.Millisecond, Max: 16 * time.Second}
 
 // Authenticate performs authentication on FMC API. If successful it returns access and refresh tokens.
 func (fmcc FMCClient) Authenticate() (string, string, error) {
@@ -43,7 +33,7 @@ func (fmcc FMCClient) Authenticate() (string, string, error) {
 		}
 
 		fmcc.Logger.Debugf(fmcc.Ctx, "authentication attempt %d failed: %s", attempt, err)
-		time.Sleep(exponentialBackoff(attempt))
+		time.Sleep(retryBackoff.Duration(attempt))
 	}
 
 	return "", "", fmt.Errorf("authentication failed after %d attempts: %w", maxRetries, err)
@@ -167,11 +157,11 @@ func (fmcc *FMCClient) MakeRequest(
 					attempt,
 					err,
 				)
-				time.Sleep(exponentialBackoff(attempt))
+				time.Sleep(retryBackoff.Duration(attempt))
 				continue
 			}
 			fmcc.Logger.Debugf(fmcc.Ctx, "request attempt %d failed: %s", attempt, err)
-			time.Sleep(exponentialBackoff(attempt))
+			time.Sleep(retryBackoff.Duration(attempt))
 			continue
 		}
 
@@ -1,6 +1,7 @@
 package fmc
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -38,16 +39,16 @@ type FMCSource struct {
 	Name2NBInterface map[string]*objects.Interface
 }
 
-func (fmcs *FMCSource) Init() error {
-	httpClient, err := utils.NewHTTPClient(fmcs.SourceConfig.ValidateCert, fmcs.CAFile)
+func (fmcs *FMCSource) Init(ctx context.Context) error {
+	httpClient, err := utils.NewHTTPClient(fmcs.SourceConfig.HTTPClientConfig())
 	if err != nil {
 		return fmt.Errorf("create new http client: %s", err)
 	}
 
 	c, err := client.NewFMCClient(
-		fmcs.Ctx,
+		ctx,
 		fmcs.SourceConfig.Username,
-		fmcs.SourceConfig.Password,
+		fmcs.SourceConfig.Password.Value(),
 		string(fmcs.SourceConfig.HTTPScheme),
 		fmcs.SourceConfig.Hostname,
 		fmcs.SourceConfig.Port,
@@ -60,17 +61,17 @@ func (fmcs *FMCSource) Init() error {
 
 	fmcs.Name2NBInterface = make(map[string]*objects.Interface)
 
-	initFunctions := []func(*client.FMCClient) error{
+	initFunctions := []func(context.Context, *client.FMCClient) error{
 		fmcs.initObjects,
 	}
 	for _, initFunc := range initFunctions {
 		startTime := time.Now()
-		if err := initFunc(c); err != nil {
+		if err := initFunc(ctx, c); err != nil {
 			return fmt.Errorf("fmc initialization failure: %v", err)
 		}
 		duration := time.Since(startTime)
 		fmcs.Logger.Infof(
-			fmcs.Ctx,
+			ctx,
 			"Successfully initialized %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(initFunc, "init"),
 			duration.Seconds(),
@@ -79,20 +80,20 @@ func (fmcs *FMCSource) Init() error {
 	return nil
 }
 
-func (fmcs *FMCSource) Sync(nbi *inventory.NetboxInventory) error {
-	syncFunctions := []func(*inventory.NetboxInventory) error{
+func (fmcs *FMCSource) Sync(ctx context.Context, nbi *inventory.NetboxInventory) error {
+	syncFunctions := []func(context.Context, *inventory.NetboxInventory) error{
 		fmcs.syncDevices,
 	}
 
 	for _, syncFunc := range syncFunctions {
 		startTime := time.Now()
-		err := syncFunc(nbi)
+		err := syncFunc(ctx, nbi)
 		if err != nil {
 			return err
 		}
 		duration := time.Since(startTime)
 		fmcs.Logger.Infof(
-			fmcs.Ctx,
+			ctx,
 			"Successfully synced %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(syncFunc, "sync"),
 			duration.Seconds(),
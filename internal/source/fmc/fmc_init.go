@@ -1,28 +1,29 @@
 package fmc
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/src-doo/netbox-ssot/internal/source/fmc/client"
 )
 
 // Init initializes the FMC source.
-func (fmcs *FMCSource) initObjects(c *client.FMCClient) error {
-	domains, err := fmcs.initDomains(c)
+func (fmcs *FMCSource) initObjects(ctx context.Context, c *client.FMCClient) error {
+	domains, err := fmcs.initDomains(ctx, c)
 	if err != nil {
 		return fmt.Errorf("init domains: %s", err)
 	}
 
 	for _, domain := range domains {
-		if err := fmcs.initDevices(c, domain); err != nil {
+		if err := fmcs.initDevices(ctx, c, domain); err != nil {
 			return fmt.Errorf("init devices: %s", err)
 		}
 	}
 	return nil
 }
 
-func (fmcs *FMCSource) initDomains(c *client.FMCClient) ([]client.Domain, error) {
-	fmcs.Logger.Debug(fmcs.Ctx, "Getting domains from fmc...")
+func (fmcs *FMCSource) initDomains(ctx context.Context, c *client.FMCClient) ([]client.Domain, error) {
+	fmcs.Logger.Debug(ctx, "Getting domains from fmc...")
 	domains, err := c.GetDomains()
 	if err != nil {
 		return nil, fmt.Errorf("get domains: %s", err)
@@ -31,17 +32,17 @@ func (fmcs *FMCSource) initDomains(c *client.FMCClient) ([]client.Domain, error)
 	for _, domain := range domains {
 		fmcs.Domains[domain.UUID] = domain
 	}
-	fmcs.Logger.Debugf(fmcs.Ctx, "Received domains %v", domains)
+	fmcs.Logger.Debugf(ctx, "Received domains %v", domains)
 	return domains, nil
 }
 
-func (fmcs *FMCSource) initDevices(c *client.FMCClient, domain client.Domain) error {
-	fmcs.Logger.Debugf(fmcs.Ctx, "Getting devices for %s domain...", domain.Name)
+func (fmcs *FMCSource) initDevices(ctx context.Context, c *client.FMCClient, domain client.Domain) error {
+	fmcs.Logger.Debugf(ctx, "Getting devices for %s domain...", domain.Name)
 	devices, err := c.GetDevices(domain.UUID)
 	if err != nil {
 		return fmt.Errorf("get devices: %s", err)
 	}
-	fmcs.Logger.Debugf(fmcs.Ctx, "Received devices %v", devices)
+	fmcs.Logger.Debugf(ctx, "Received devices %v", devices)
 
 	fmcs.Devices = make(map[string]*client.DeviceInfo, len(devices))
 	for _, device := range devices {
@@ -52,14 +53,14 @@ func (fmcs *FMCSource) initDevices(c *client.FMCClient, domain client.Domain) er
 		fmcs.Devices[device.ID] = deviceInfo
 
 		// Initialize Device physical interfaces
-		fmcs.Logger.Debugf(fmcs.Ctx, "Getting physical interfaces for device %s", deviceInfo.Name)
+		fmcs.Logger.Debugf(ctx, "Getting physical interfaces for device %s", deviceInfo.Name)
 		err = fmcs.initDevicePhysicalInterfaces(c, domain, device)
 		if err != nil {
 			return fmt.Errorf("error initializing physical interfaces: %s", err)
 		}
 
 		// Initialize device VLAN interfaces
-		fmcs.Logger.Debugf(fmcs.Ctx, "Getting vlan interfaces for device %s", deviceInfo.Name)
+		fmcs.Logger.Debugf(ctx, "Getting vlan interfaces for device %s", deviceInfo.Name)
 		err = fmcs.initDeviceVLANInterfaces(c, domain, device)
 		if err != nil {
 			return fmt.Errorf("error initializing vlan interfaces: %s", err)
@@ -67,7 +68,7 @@ func (fmcs *FMCSource) initDevices(c *client.FMCClient, domain client.Domain) er
 
 		// Initialize EtherChannel interfaces
 		fmcs.Logger.Debugf(
-			fmcs.Ctx,
+			ctx,
 			"Getting etherchannel interfaces for device %s",
 			deviceInfo.Name,
 		)
@@ -77,7 +78,7 @@ func (fmcs *FMCSource) initDevices(c *client.FMCClient, domain client.Domain) er
 		}
 
 		// Initialize SubInterfaces
-		fmcs.Logger.Debugf(fmcs.Ctx, "Getting subinterfaces for device %s", deviceInfo.Name)
+		fmcs.Logger.Debugf(ctx, "Getting subinterfaces for device %s", deviceInfo.Name)
 		err = fmcs.initDeviceSubInterfaces(c, domain, device)
 		if err != nil {
 			return fmt.Errorf("error initializing subinterfaces: %s", err)
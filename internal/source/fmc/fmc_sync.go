@@ -1,6 +1,7 @@
 package fmc
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/src-doo/netbox-ssot/internal/constants"
@@ -11,11 +12,11 @@ import (
 	"github.com/src-doo/netbox-ssot/internal/utils"
 )
 
-func (fmcs *FMCSource) syncDevices(nbi *inventory.NetboxInventory) error {
+func (fmcs *FMCSource) syncDevices(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	for deviceUUID, device := range fmcs.Devices {
 		deviceName := device.Name
 		if deviceName == "" {
-			fmcs.Logger.Warningf(fmcs.Ctx, "device with empty name. Skipping...")
+			fmcs.Logger.Warningf(ctx, "device with empty name. Skipping...")
 			continue
 		}
 		var deviceSerialNumber string
@@ -24,17 +25,17 @@ func (fmcs *FMCSource) syncDevices(nbi *inventory.NetboxInventory) error {
 		}
 		deviceModel := device.Model
 		if deviceModel == "" {
-			fmcs.Logger.Warning(fmcs.Ctx, "model field for device is emptpy. Using fallback model.")
+			fmcs.Logger.Warning(ctx, "model field for device is emptpy. Using fallback model.")
 			deviceModel = constants.DefaultModel
 		}
-		deviceManufacturer, err := nbi.AddManufacturer(fmcs.Ctx, &objects.Manufacturer{
+		deviceManufacturer, err := nbi.AddManufacturer(ctx, &objects.Manufacturer{
 			Name: "Cisco",
 			Slug: utils.Slugify("Cisco"),
 		})
 		if err != nil {
 			return fmt.Errorf("add manufacturer: %s", err)
 		}
-		deviceType, err := nbi.AddDeviceType(fmcs.Ctx, &objects.DeviceType{
+		deviceType, err := nbi.AddDeviceType(ctx, &objects.DeviceType{
 			Manufacturer: deviceManufacturer,
 			Model:        deviceModel,
 			Slug:         utils.Slugify(deviceManufacturer.Name + deviceModel),
@@ -43,7 +44,7 @@ func (fmcs *FMCSource) syncDevices(nbi *inventory.NetboxInventory) error {
 			return fmt.Errorf("add device type: %s", err)
 		}
 		deviceTenant, err := common.MatchHostToTenant(
-			fmcs.Ctx,
+			ctx,
 			nbi,
 			deviceName,
 			fmcs.SourceConfig.HostTenantRelations,
@@ -57,7 +58,7 @@ func (fmcs *FMCSource) syncDevices(nbi *inventory.NetboxInventory) error {
 		var deviceRole *objects.DeviceRole
 		if len(fmcs.SourceConfig.HostRoleRelations) > 0 {
 			deviceRole, err = common.MatchHostToRole(
-				fmcs.Ctx,
+				ctx,
 				nbi,
 				deviceName,
 				fmcs.SourceConfig.HostRoleRelations,
@@ -67,14 +68,14 @@ func (fmcs *FMCSource) syncDevices(nbi *inventory.NetboxInventory) error {
 			}
 		}
 		if deviceRole == nil {
-			deviceRole, err = nbi.AddFirewallDeviceRole(fmcs.Ctx)
+			deviceRole, err = nbi.AddFirewallDeviceRole(ctx)
 			if err != nil {
 				return fmt.Errorf("add DeviceRole firewall: %s", err)
 			}
 		}
 
 		deviceSite, err := common.MatchHostToSite(
-			fmcs.Ctx,
+			ctx,
 			nbi,
 			deviceName,
 			fmcs.SourceConfig.HostSiteRelations,
@@ -83,7 +84,7 @@ func (fmcs *FMCSource) syncDevices(nbi *inventory.NetboxInventory) error {
 			return fmt.Errorf("match host to site: %s", err)
 		}
 		devicePlatformName := fmt.Sprintf("FXOS %s", device.SWVersion)
-		devicePlatform, err := nbi.AddPlatform(fmcs.Ctx, &objects.Platform{
+		devicePlatform, err := nbi.AddPlatform(ctx, &objects.Platform{
 			Name:         devicePlatformName,
 			Slug:         utils.Slugify(devicePlatformName),
 			Manufacturer: deviceManufacturer,
@@ -91,7 +92,7 @@ func (fmcs *FMCSource) syncDevices(nbi *inventory.NetboxInventory) error {
 		if err != nil {
 			return fmt.Errorf("add platform: %s", err)
 		}
-		NBDevice, err := nbi.AddDevice(fmcs.Ctx, &objects.Device{
+		NBDevice, err := nbi.AddDevice(ctx, &objects.Device{
 			NetboxObject: objects.NetboxObject{
 				Description: device.Description,
 				Tags:        fmcs.GetSourceTags(),
@@ -117,21 +118,21 @@ func (fmcs *FMCSource) syncDevices(nbi *inventory.NetboxInventory) error {
 		if err != nil {
 			return fmt.Errorf("add device: %s", err)
 		}
-		err = fmcs.syncPhysicalInterfaces(nbi, NBDevice, deviceUUID)
+		err = fmcs.syncPhysicalInterfaces(ctx, nbi, NBDevice, deviceUUID)
 		if err != nil {
 			return fmt.Errorf("sync physical interfaces: %s", err)
 		}
-		err = fmcs.syncVlanInterfaces(nbi, NBDevice, deviceUUID)
+		err = fmcs.syncVlanInterfaces(ctx, nbi, NBDevice, deviceUUID)
 		if err != nil {
 			return fmt.Errorf("sync vlan interfaces: %s", err)
 		}
-		err = fmcs.syncEtherChannelInterfaces(nbi, NBDevice, deviceUUID)
+		err = fmcs.syncEtherChannelInterfaces(ctx, nbi, NBDevice, deviceUUID)
 		if err != nil {
 			return fmt.Errorf("sync etherchannel interfaces: %s", err)
 		}
 		// syncSubInterfaces should be called lastly, since it is dependant
 		// on other sync functions.
-		err = fmcs.syncSubInterfaces(nbi, NBDevice, deviceUUID)
+		err = fmcs.syncSubInterfaces(ctx, nbi, NBDevice, deviceUUID)
 		if err != nil {
 			return fmt.Errorf("sync subinterfaces: %s", err)
 		}
@@ -169,6 +170,7 @@ func getIPAddressForIface(ipv4 *client.InterfaceIPv4) string {
 // syncVlanInterfaces syncs vlan interfaces for given device,
 // into netbox inventory.
 func (fmcs *FMCSource) syncVlanInterfaces(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	nbDevice *objects.Device,
 	deviceUUID string,
@@ -180,7 +182,7 @@ func (fmcs *FMCSource) syncVlanInterfaces(
 			if vlanIface.VID != 0 {
 				// Match vlan to site
 				vlanSite, err := common.MatchVlanToSite(
-					fmcs.Ctx,
+					ctx,
 					nbi,
 					vlanIface.Name,
 					fmcs.SourceConfig.VlanSiteRelations,
@@ -190,7 +192,7 @@ func (fmcs *FMCSource) syncVlanInterfaces(
 				}
 				// Match vlan to group
 				vlanGroup, err := common.MatchVlanToGroup(
-					fmcs.Ctx,
+					ctx,
 					nbi,
 					vlanIface.Name,
 					vlanSite,
@@ -201,7 +203,7 @@ func (fmcs *FMCSource) syncVlanInterfaces(
 					return fmt.Errorf("match vlan to group: %s", err)
 				}
 				vlanTenant, err := common.MatchVlanToTenant(
-					fmcs.Ctx,
+					ctx,
 					nbi,
 					vlanIface.Name,
 					fmcs.SourceConfig.VlanTenantRelations,
@@ -209,7 +211,7 @@ func (fmcs *FMCSource) syncVlanInterfaces(
 				if err != nil {
 					return fmt.Errorf("match vlan to tenant: %s", err)
 				}
-				vlan, err := nbi.AddVlan(fmcs.Ctx, &objects.Vlan{
+				vlan, err := nbi.AddVlan(ctx, &objects.Vlan{
 					NetboxObject: objects.NetboxObject{
 						Tags:        fmcs.GetSourceTags(),
 						Description: vlanIface.Description,
@@ -227,7 +229,7 @@ func (fmcs *FMCSource) syncVlanInterfaces(
 				ifaceTaggedVlans = append(ifaceTaggedVlans, vlan)
 			}
 
-			NBIface, err := nbi.AddInterface(fmcs.Ctx, &objects.Interface{
+			NBIface, err := nbi.AddInterface(ctx, &objects.Interface{
 				NetboxObject: objects.NetboxObject{
 					Description: vlanIface.Description,
 					Tags:        fmcs.GetSourceTags(),
@@ -253,7 +255,7 @@ func (fmcs *FMCSource) syncVlanInterfaces(
 					fmcs.SourceConfig.IgnoredSubnets,
 				) {
 					dnsName := utils.ReverseLookup(vlanIface.IPv4.Static.Address)
-					_, err := nbi.AddIPAddress(fmcs.Ctx, &objects.IPAddress{
+					_, err := nbi.AddIPAddress(ctx, &objects.IPAddress{
 						NetboxObject: objects.NetboxObject{
 							Tags: fmcs.GetSourceTags(),
 							CustomFields: map[string]interface{}{
@@ -271,7 +273,7 @@ func (fmcs *FMCSource) syncVlanInterfaces(
 					// Also add prefix
 					prefix, mask, err := utils.GetPrefixAndMaskFromIPAddress(ipAddress)
 					if err != nil {
-						fmcs.Logger.Debugf(fmcs.Ctx, "extract prefix from address: %s", err)
+						fmcs.Logger.Debugf(ctx, "extract prefix from address: %s", err)
 					} else if mask != constants.MaxIPv4MaskBits {
 						var prefixTenant *objects.Tenant
 						var prefixVlan *objects.Vlan
@@ -279,7 +281,7 @@ func (fmcs *FMCSource) syncVlanInterfaces(
 							prefixVlan = ifaceTaggedVlans[0]
 							prefixTenant = prefixVlan.Tenant
 						}
-						_, err = nbi.AddPrefix(fmcs.Ctx, &objects.Prefix{
+						_, err = nbi.AddPrefix(ctx, &objects.Prefix{
 							Prefix: prefix,
 							Tenant: prefixTenant,
 							Vlan:   prefixVlan,
@@ -301,6 +303,7 @@ func (fmcs *FMCSource) syncVlanInterfaces(
 // syncPhysicalInterfaces syncs physical interfaces for given device,
 // into netbox inventory.
 func (fmcs *FMCSource) syncPhysicalInterfaces(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	nbDevice *objects.Device,
 	deviceUUID string,
@@ -321,7 +324,7 @@ func (fmcs *FMCSource) syncPhysicalInterfaces(
 				MTU:    pIface.MTU,
 				Type:   &objects.OtherInterfaceType,
 			}
-			NBIface, err := nbi.AddInterface(fmcs.Ctx, iface)
+			NBIface, err := nbi.AddInterface(ctx, iface)
 			if err != nil {
 				return fmt.Errorf("add physical interface %+v: %s", iface, err)
 			}
@@ -332,7 +335,7 @@ func (fmcs *FMCSource) syncPhysicalInterfaces(
 					fmcs.SourceConfig.IgnoredSubnets,
 				) {
 					dnsName := utils.ReverseLookup(pIface.IPv4.Static.Address)
-					_, err := nbi.AddIPAddress(fmcs.Ctx, &objects.IPAddress{
+					_, err := nbi.AddIPAddress(ctx, &objects.IPAddress{
 						NetboxObject: objects.NetboxObject{
 							Tags: fmcs.GetSourceTags(),
 							CustomFields: map[string]interface{}{
@@ -358,13 +361,14 @@ func (fmcs *FMCSource) syncPhysicalInterfaces(
 }
 
 func (fmcs *FMCSource) syncEtherChannelInterfaces(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	nbDevice *objects.Device,
 	deviceUUID string,
 ) error {
 	if etherChannelIfaces, ok := fmcs.DeviceEtherChannelIfaces[deviceUUID]; ok {
 		for _, eIface := range etherChannelIfaces {
-			NBIface, err := nbi.AddInterface(fmcs.Ctx, &objects.Interface{
+			NBIface, err := nbi.AddInterface(ctx, &objects.Interface{
 				NetboxObject: objects.NetboxObject{
 					Description: eIface.Description,
 					Tags:        fmcs.GetSourceTags(),
@@ -389,7 +393,7 @@ func (fmcs *FMCSource) syncEtherChannelInterfaces(
 					fmcs.SourceConfig.IgnoredSubnets,
 				) {
 					dnsName := utils.ReverseLookup(eIface.IPv4.Static.Address)
-					_, err := nbi.AddIPAddress(fmcs.Ctx, &objects.IPAddress{
+					_, err := nbi.AddIPAddress(ctx, &objects.IPAddress{
 						NetboxObject: objects.NetboxObject{
 							Tags: fmcs.GetSourceTags(),
 							CustomFields: map[string]interface{}{
@@ -416,6 +420,7 @@ func (fmcs *FMCSource) syncEtherChannelInterfaces(
 
 // syncSubInterfaces syncs sub interfaces for a given nbDevice and its deviceUUID.
 func (fmcs *FMCSource) syncSubInterfaces(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	nbDevice *objects.Device,
 	deviceUUID string,
@@ -427,7 +432,7 @@ func (fmcs *FMCSource) syncSubInterfaces(
 			if subIface.VlanID > 1 {
 				// Match vlan to site
 				vlanSite, err := common.MatchVlanToSite(
-					fmcs.Ctx,
+					ctx,
 					nbi,
 					subIface.Name,
 					fmcs.SourceConfig.VlanSiteRelations,
@@ -437,7 +442,7 @@ func (fmcs *FMCSource) syncSubInterfaces(
 				}
 				// Match vlan to group
 				vlanGroup, err := common.MatchVlanToGroup(
-					fmcs.Ctx,
+					ctx,
 					nbi,
 					subIface.Name,
 					vlanSite,
@@ -448,7 +453,7 @@ func (fmcs *FMCSource) syncSubInterfaces(
 					return fmt.Errorf("match subiface vlan to group: %s", err)
 				}
 				vlanTenant, err := common.MatchVlanToTenant(
-					fmcs.Ctx,
+					ctx,
 					nbi,
 					subIface.Name,
 					fmcs.SourceConfig.VlanTenantRelations,
@@ -456,7 +461,7 @@ func (fmcs *FMCSource) syncSubInterfaces(
 				if err != nil {
 					return fmt.Errorf("match subiface vlan to tenant: %s", err)
 				}
-				vlan, err := nbi.AddVlan(fmcs.Ctx, &objects.Vlan{
+				vlan, err := nbi.AddVlan(ctx, &objects.Vlan{
 					NetboxObject: objects.NetboxObject{
 						Tags:        fmcs.GetSourceTags(),
 						Description: subIface.Description,
@@ -476,7 +481,7 @@ func (fmcs *FMCSource) syncSubInterfaces(
 
 			parentIface := fmcs.Name2NBInterface[subIface.ParentName]
 
-			NBIface, err := nbi.AddInterface(fmcs.Ctx, &objects.Interface{
+			NBIface, err := nbi.AddInterface(ctx, &objects.Interface{
 				NetboxObject: objects.NetboxObject{
 					Description: subIface.Description,
 					Tags:        fmcs.GetSourceTags(),
@@ -503,7 +508,7 @@ func (fmcs *FMCSource) syncSubInterfaces(
 					fmcs.SourceConfig.IgnoredSubnets,
 				) {
 					dnsName := utils.ReverseLookup(subIface.IPv4.Static.Address)
-					_, err := nbi.AddIPAddress(fmcs.Ctx, &objects.IPAddress{
+					_, err := nbi.AddIPAddress(ctx, &objects.IPAddress{
 						NetboxObject: objects.NetboxObject{
 							Tags: fmcs.GetSourceTags(),
 							CustomFields: map[string]interface{}{
@@ -521,7 +526,7 @@ func (fmcs *FMCSource) syncSubInterfaces(
 					// Also add prefix
 					prefix, mask, err := utils.GetPrefixAndMaskFromIPAddress(ipAddress)
 					if err != nil {
-						fmcs.Logger.Debugf(fmcs.Ctx, "extract prefix from address: %s", err)
+						fmcs.Logger.Debugf(ctx, "extract prefix from address: %s", err)
 					} else if mask != constants.MaxIPv4MaskBits {
 						var prefixTenant *objects.Tenant
 						var prefixVlan *objects.Vlan
@@ -529,7 +534,7 @@ func (fmcs *FMCSource) syncSubInterfaces(
 							prefixVlan = ifaceTaggedVlans[0]
 							prefixTenant = prefixVlan.Tenant
 						}
-						_, err = nbi.AddPrefix(fmcs.Ctx, &objects.Prefix{
+						_, err = nbi.AddPrefix(ctx, &objects.Prefix{
 							Prefix: prefix,
 							Tenant: prefixTenant,
 							Vlan:   prefixVlan,
@@ -1 +1,83 @@
 package fmc
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox/sourcetest"
+	"github.com/src-doo/netbox-ssot/internal/netbox/inventory"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/parser"
+	"github.com/src-doo/netbox-ssot/internal/source/common"
+	"github.com/src-doo/netbox-ssot/internal/source/fmc/client"
+)
+
+// runFMC runs netbox-ssot with a fmc source, that manages firewall fw1
+// with the given physical interfaces.
+func runFMC(t *testing.T, netboxServer *fakenetbox.Server, ifaces []*client.PhysicalInterfaceInfo) {
+	t.Helper()
+	sourceConfig := &parser.SourceConfig{
+		Name: "fmc",
+		Type: constants.FMC,
+		// Devices must be assigned to a site
+		HostSiteRelations: map[string]string{"^fw": "HQ"},
+	}
+	sourcetest.Run(t, netboxServer, sourceConfig,
+		func(ctx context.Context, nbi *inventory.NetboxInventory, config common.Config) error {
+			device := &client.DeviceInfo{Name: "fw1", Model: "Cisco Firepower 1120", SWVersion: "7.2.5"}
+			device.Metadata.SerialNumber = "JAD00000001"
+			fmcs := &FMCSource{
+				Config:               config,
+				Devices:              map[string]*client.DeviceInfo{"fw1-uuid": device},
+				DevicePhysicalIfaces: map[string][]*client.PhysicalInterfaceInfo{"fw1-uuid": ifaces},
+				NBDevices:            make(map[string]*objects.Device),
+				Name2NBInterface:     make(map[string]*objects.Interface),
+			}
+			return fmcs.Sync(ctx, nbi)
+		})
+}
+
+// interfaceNames returns sorted names of all interfaces in Netbox.
+func interfaceNames(t *testing.T, netboxServer *fakenetbox.Server) []string {
+	t.Helper()
+	ifaces, err := fakenetbox.Objects[objects.Interface](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(ifaces))
+	for _, iface := range ifaces {
+		names = append(names, iface.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestFMCSource_EndToEnd(t *testing.T) {
+	netboxServer := fakenetbox.NewServer()
+	defer netboxServer.Close()
+	ifaces := []*client.PhysicalInterfaceInfo{
+		{ID: "port1-uuid", Name: "Ethernet1/1", Enabled: true, MTU: 1500},
+		{ID: "port2-uuid", Name: "Ethernet1/2", Enabled: true, MTU: 1500},
+	}
+
+	runFMC(t, netboxServer, ifaces)
+	devices, err := fakenetbox.Objects[objects.Device](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 1 || devices[0].Name != "fw1" || devices[0].SerialNumber != "JAD00000001" {
+		t.Fatalf("devices = %v, want firewall fw1", devices)
+	}
+	if got := interfaceNames(t, netboxServer); !slices.Equal(got, []string{"Ethernet1/1", "Ethernet1/2"}) {
+		t.Errorf("interfaces = %v, want [Ethernet1/1 Ethernet1/2]", got)
+	}
+
+	// Interface removed from the firewall is removed from Netbox
+	runFMC(t, netboxServer, ifaces[:1])
+	if got := interfaceNames(t, netboxServer); !slices.Equal(got, []string{"Ethernet1/1"}) {
+		t.Errorf("interfaces = %v, want [Ethernet1/1]", got)
+	}
+}
@@ -58,13 +58,13 @@ func (c FortiClient) MakeRequest(
 	return c.HTTPClient.Do(req)
 }
 
-func (fs *FortigateSource) Init() error {
-	httpClient, err := utils.NewHTTPClient(fs.SourceConfig.ValidateCert, fs.CAFile)
+func (fs *FortigateSource) Init(ctx context.Context) error {
+	httpClient, err := utils.NewHTTPClient(fs.SourceConfig.HTTPClientConfig())
 	if err != nil {
 		return fmt.Errorf("create new http client: %s", err)
 	}
 	c := NewAPIClient(
-		fs.SourceConfig.APIToken,
+		fs.SourceConfig.APIToken.Value(),
 		fmt.Sprintf(
 			"%s://%s:%d/api/v2",
 			fs.SourceConfig.HTTPScheme,
@@ -73,8 +73,6 @@ func (fs *FortigateSource) Init() error {
 		),
 		httpClient,
 	)
-	ctx := context.Background()
-	defer ctx.Done()
 
 	initFunctions := []func(context.Context, *FortiClient) error{
 		fs.initSystemInfo,
@@ -87,7 +85,7 @@ func (fs *FortigateSource) Init() error {
 		}
 		duration := time.Since(startTime)
 		fs.Logger.Infof(
-			fs.Ctx,
+			ctx,
 			"Successfully initialized %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(initFunc, "init"),
 			duration.Seconds(),
@@ -96,21 +94,21 @@ func (fs *FortigateSource) Init() error {
 	return nil
 }
 
-func (fs *FortigateSource) Sync(nbi *inventory.NetboxInventory) error {
-	syncFunctions := []func(*inventory.NetboxInventory) error{
+func (fs *FortigateSource) Sync(ctx context.Context, nbi *inventory.NetboxInventory) error {
+	syncFunctions := []func(context.Context, *inventory.NetboxInventory) error{
 		fs.syncDevice,
 		fs.syncInterfaces,
 	}
 
 	for _, syncFunc := range syncFunctions {
 		startTime := time.Now()
-		err := syncFunc(nbi)
+		err := syncFunc(ctx, nbi)
 		if err != nil {
 			return err
 		}
 		duration := time.Since(startTime)
 		fs.Logger.Infof(
-			fs.Ctx,
+			ctx,
 			"Successfully synced %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(syncFunc, "sync"),
 			duration.Seconds(),
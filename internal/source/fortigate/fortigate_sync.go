@@ -1,6 +1,7 @@
 package fortigate
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -12,7 +13,7 @@ import (
 )
 
 // SyncDevice creates default device in netbox representing Fortigate firewall.
-func (fs *FortigateSource) syncDevice(nbi *inventory.NetboxInventory) error {
+func (fs *FortigateSource) syncDevice(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	deviceName := fs.SystemInfo.Hostname
 	if deviceName == "" {
 		return fmt.Errorf("can't extract hostname from system info")
@@ -24,17 +25,17 @@ func (fs *FortigateSource) syncDevice(nbi *inventory.NetboxInventory) error {
 
 	deviceModel := fs.SystemInfo.Hostname
 	if deviceModel == "" {
-		fs.Logger.Warningf(fs.Ctx, "model field in system info is empty. Using fallback mechanism.")
+		fs.Logger.Warningf(ctx, "model field in system info is empty. Using fallback mechanism.")
 		deviceModel = constants.DefaultModel
 	}
-	deviceManufacturer, err := nbi.AddManufacturer(fs.Ctx, &objects.Manufacturer{
+	deviceManufacturer, err := nbi.AddManufacturer(ctx, &objects.Manufacturer{
 		Name: "Fortinet",
 		Slug: utils.Slugify("Fortinet"),
 	})
 	if err != nil {
 		return fmt.Errorf("failed adding manufacturer: %s", err)
 	}
-	deviceType, err := nbi.AddDeviceType(fs.Ctx, &objects.DeviceType{
+	deviceType, err := nbi.AddDeviceType(ctx, &objects.DeviceType{
 		Manufacturer: deviceManufacturer,
 		Model:        deviceModel,
 		Slug:         utils.Slugify(deviceManufacturer.Name + deviceModel),
@@ -44,7 +45,7 @@ func (fs *FortigateSource) syncDevice(nbi *inventory.NetboxInventory) error {
 	}
 
 	deviceTenant, err := common.MatchHostToTenant(
-		fs.Ctx,
+		ctx,
 		nbi,
 		deviceName,
 		fs.SourceConfig.HostTenantRelations,
@@ -56,7 +57,7 @@ func (fs *FortigateSource) syncDevice(nbi *inventory.NetboxInventory) error {
 	var deviceRole *objects.DeviceRole
 	if len(fs.SourceConfig.HostRoleRelations) > 0 {
 		deviceRole, err = common.MatchHostToRole(
-			fs.Ctx,
+			ctx,
 			nbi,
 			deviceName,
 			fs.SourceConfig.HostRoleRelations,
@@ -66,13 +67,13 @@ func (fs *FortigateSource) syncDevice(nbi *inventory.NetboxInventory) error {
 		}
 	}
 	if deviceRole == nil {
-		deviceRole, err = nbi.AddFirewallDeviceRole(fs.Ctx)
+		deviceRole, err = nbi.AddFirewallDeviceRole(ctx)
 		if err != nil {
 			return fmt.Errorf("add DeviceRole firewall: %s", err)
 		}
 	}
 	deviceSite, err := common.MatchHostToSite(
-		fs.Ctx,
+		ctx,
 		nbi,
 		deviceName,
 		fs.SourceConfig.HostSiteRelations,
@@ -81,7 +82,7 @@ func (fs *FortigateSource) syncDevice(nbi *inventory.NetboxInventory) error {
 		return fmt.Errorf("match host to site: %s", err)
 	}
 	devicePlatformName := fmt.Sprintf("FortiOS %s", fs.SystemInfo.Version)
-	devicePlatform, err := nbi.AddPlatform(fs.Ctx, &objects.Platform{
+	devicePlatform, err := nbi.AddPlatform(ctx, &objects.Platform{
 		Name:         devicePlatformName,
 		Slug:         utils.Slugify(devicePlatformName),
 		Manufacturer: deviceManufacturer,
@@ -89,7 +90,7 @@ func (fs *FortigateSource) syncDevice(nbi *inventory.NetboxInventory) error {
 	if err != nil {
 		return fmt.Errorf("add platform: %s", err)
 	}
-	NBDevice, err := nbi.AddDevice(fs.Ctx, &objects.Device{
+	NBDevice, err := nbi.AddDevice(ctx, &objects.Device{
 		NetboxObject: objects.NetboxObject{
 			Tags: fs.GetSourceTags(),
 		},
@@ -111,7 +112,7 @@ func (fs *FortigateSource) syncDevice(nbi *inventory.NetboxInventory) error {
 }
 
 // syncInterfaces syncs all interfaces for firewall.
-func (fs *FortigateSource) syncInterfaces(nbi *inventory.NetboxInventory) error {
+func (fs *FortigateSource) syncInterfaces(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	for _, iface := range fs.Ifaces {
 		switch iface.Type {
 		case "loopback":
@@ -124,13 +125,13 @@ func (fs *FortigateSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 
 		ifaceName := iface.Name
 		if ifaceName == "" {
-			fs.Logger.Warningf(fs.Ctx, "empty interface name - skipping")
+			fs.Logger.Warningf(ctx, "empty interface name - skipping")
 			continue
 		}
 
 		if utils.FilterInterfaceName(ifaceName, fs.SourceConfig.InterfaceFilter) {
 			fs.Logger.Debugf(
-				fs.Ctx,
+				ctx,
 				"interface %s is filtered out with interfaceFilter %s",
 				ifaceName,
 				fs.SourceConfig.InterfaceFilter,
@@ -147,7 +148,7 @@ func (fs *FortigateSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 
 		var vdcs []*objects.VirtualDeviceContext
 		if iface.Vdom != "" {
-			vdom, err := nbi.AddVirtualDeviceContext(fs.Ctx, &objects.VirtualDeviceContext{
+			vdom, err := nbi.AddVirtualDeviceContext(ctx, &objects.VirtualDeviceContext{
 				NetboxObject: objects.NetboxObject{
 					Tags: fs.GetSourceTags(),
 				},
@@ -160,7 +161,7 @@ func (fs *FortigateSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 			}
 			vdcs = append(vdcs, vdom)
 		}
-		NBIface, err := nbi.AddInterface(fs.Ctx, &objects.Interface{
+		NBIface, err := nbi.AddInterface(ctx, &objects.Interface{
 			NetboxObject: objects.NetboxObject{
 				Tags:        fs.GetSourceTags(),
 				Description: iface.Description,
@@ -177,7 +178,7 @@ func (fs *FortigateSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 		}
 		if interfaceMAC != "" {
 			nbMACAddress, err := common.CreateMACAddressForObjectType(
-				fs.Ctx,
+				ctx,
 				nbi,
 				interfaceMAC,
 				NBIface,
@@ -185,11 +186,11 @@ func (fs *FortigateSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 			if err != nil {
 				return fmt.Errorf("create mac address for object type: %s", err)
 			}
-			if err = common.SetPrimaryMACForInterface(fs.Ctx, nbi, NBIface, nbMACAddress); err != nil {
+			if err = common.SetPrimaryMACForInterface(ctx, nbi, NBIface, nbMACAddress); err != nil {
 				return fmt.Errorf("set primary mac for interface: %s", err)
 			}
 		}
-		NBIPAddress, err := syncInterfaceIPs(fs, nbi, iface, NBIface)
+		NBIPAddress, err := syncInterfaceIPs(ctx, fs, nbi, iface, NBIface)
 		if err != nil {
 			return fmt.Errorf("sync interface ips: %s", err)
 		}
@@ -199,7 +200,7 @@ func (fs *FortigateSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 			vlanID := iface.VlanID
 			vlanName := fmt.Sprintf("Vlan%d", vlanID)
 			vlanSite, err := common.MatchVlanToSite(
-				fs.Ctx,
+				ctx,
 				nbi,
 				vlanName,
 				fs.SourceConfig.VlanSiteRelations,
@@ -208,7 +209,7 @@ func (fs *FortigateSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 				return fmt.Errorf("match vlan to site: %s", err)
 			}
 			vlanGroup, err := common.MatchVlanToGroup(
-				fs.Ctx,
+				ctx,
 				nbi,
 				vlanName,
 				vlanSite,
@@ -219,7 +220,7 @@ func (fs *FortigateSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 				return fmt.Errorf("match vlan to group: %s", err)
 			}
 			vlanTenant, err := common.MatchVlanToTenant(
-				fs.Ctx,
+				ctx,
 				nbi,
 				vlanName,
 				fs.SourceConfig.VlanTenantRelations,
@@ -227,7 +228,7 @@ func (fs *FortigateSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 			if err != nil {
 				return fmt.Errorf("match vlan to tenant: %s", err)
 			}
-			NBVlan, err := nbi.AddVlan(fs.Ctx, &objects.Vlan{
+			NBVlan, err := nbi.AddVlan(ctx, &objects.Vlan{
 				NetboxObject: objects.NetboxObject{
 					Tags: fs.GetSourceTags(),
 				},
@@ -246,7 +247,7 @@ func (fs *FortigateSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 			if NBIPAddress != nil {
 				prefix, mask, err := utils.GetPrefixAndMaskFromIPAddress(NBIPAddress.Address)
 				if err != nil {
-					fs.Logger.Warningf(fs.Ctx, "extract prefix from ip address: %s", err)
+					fs.Logger.Warningf(ctx, "extract prefix from ip address: %s", err)
 				} else if mask != constants.MaxIPv4MaskBits {
 					var scopeID int
 					var scopeType constants.ContentType
@@ -254,7 +255,7 @@ func (fs *FortigateSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 						scopeID = vlanSite.ID
 						scopeType = constants.ContentTypeDcimSite
 					}
-					_, err = nbi.AddPrefix(fs.Ctx, &objects.Prefix{
+					_, err = nbi.AddPrefix(ctx, &objects.Prefix{
 						Prefix:    prefix,
 						Tenant:    NBVlan.Tenant,
 						Vlan:      NBVlan,
@@ -274,6 +275,7 @@ func (fs *FortigateSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 // syncInterfaceIPs is a helper function for syncInterfaces.
 // it synces IPs for an interface.
 func syncInterfaceIPs(
+	ctx context.Context,
 	fs *FortigateSource,
 	nbi *inventory.NetboxInventory,
 	iface InterfaceResponse,
@@ -291,7 +293,7 @@ func syncInterfaceIPs(
 			if err != nil {
 				return nil, fmt.Errorf("mask to bits: %s", err)
 			}
-			NBIPAddress, err = nbi.AddIPAddress(fs.Ctx, &objects.IPAddress{
+			NBIPAddress, err = nbi.AddIPAddress(ctx, &objects.IPAddress{
 				NetboxObject: objects.NetboxObject{
 					Tags: fs.GetSourceTags(),
 					CustomFields: map[string]interface{}{
@@ -320,7 +322,7 @@ func syncInterfaceIPs(
 					if err != nil {
 						return nil, fmt.Errorf("mask to bits: %s", err)
 					}
-					_, err = nbi.AddIPAddress(fs.Ctx, &objects.IPAddress{
+					_, err = nbi.AddIPAddress(ctx, &objects.IPAddress{
 						NetboxObject: objects.NetboxObject{
 							Tags: fs.GetSourceTags(),
 							CustomFields: map[string]interface{}{
@@ -332,7 +334,7 @@ func syncInterfaceIPs(
 						AssignedObjectID:   nbIface.ID,
 					})
 					if err != nil {
-						fs.Logger.Warningf(fs.Ctx, "add secondary ip address: %s", err)
+						fs.Logger.Warningf(ctx, "add secondary ip address: %s", err)
 					}
 				}
 			}
@@ -352,7 +354,7 @@ func syncInterfaceIPs(
 					if err != nil {
 						return nil, fmt.Errorf("mask to bits: %s", err)
 					}
-					_, err = nbi.AddIPAddress(fs.Ctx, &objects.IPAddress{
+					_, err = nbi.AddIPAddress(ctx, &objects.IPAddress{
 						NetboxObject: objects.NetboxObject{
 							Tags: fs.GetSourceTags(),
 							CustomFields: map[string]interface{}{
@@ -365,7 +367,7 @@ func syncInterfaceIPs(
 						Role:               &objects.IPAddressRoleVRRP,
 					})
 					if err != nil {
-						fs.Logger.Warningf(fs.Ctx, "add VRRP ip address: %s", err)
+						fs.Logger.Warningf(ctx, "add VRRP ip address: %s", err)
 					}
 				}
 			}
@@ -1 +1,158 @@
 package fortigate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox/sourcetest"
+	"github.com/src-doo/netbox-ssot/internal/netbox/inventory"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/parser"
+	"github.com/src-doo/netbox-ssot/internal/source/common"
+)
+
+// newFortigateServer returns a fake Fortigate API, that serves
+// system info and the given interfaces.
+func newFortigateServer(t *testing.T, ifaces *[]InterfaceResponse) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/cmdb/system/global/", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(APIResponse[DeviceResponse]{
+			HTTPStatus: http.StatusOK,
+			Serial:     "FGT60F0000000001",
+			Version:    "v7.2.5",
+			Results:    DeviceResponse{Hostname: "fw1"},
+		})
+	})
+	mux.HandleFunc("/api/v2/cmdb/system/interface/", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(APIResponse[[]InterfaceResponse]{
+			HTTPStatus: http.StatusOK,
+			Results:    *ifaces,
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// runFortigate runs a full netbox-ssot run with a single fortigate source.
+func runFortigate(t *testing.T, netboxServer *fakenetbox.Server, fortigateURL string) {
+	t.Helper()
+	serverURL, _ := url.Parse(fortigateURL)
+	port, _ := strconv.Atoi(serverURL.Port())
+	sourceConfig := &parser.SourceConfig{
+		Name:       "fortigate",
+		Type:       constants.Fortigate,
+		HTTPScheme: parser.HTTP,
+		Hostname:   serverURL.Hostname(),
+		Port:       port,
+		APIToken:   "token",
+		// Devices must be assigned to a site
+		HostSiteRelations: map[string]string{"^fw": "HQ"},
+	}
+	sourcetest.Run(t, netboxServer, sourceConfig,
+		func(ctx context.Context, nbi *inventory.NetboxInventory, config common.Config) error {
+			fs := &FortigateSource{Config: config}
+			if err := fs.Init(ctx); err != nil {
+				return err
+			}
+			return fs.Sync(ctx, nbi)
+		})
+}
+
+func TestFortigateSource_EndToEnd(t *testing.T) {
+	ifaces := []InterfaceResponse{
+		{Name: "port1", Type: "physical", Status: "up", IP: "10.0.0.1 255.255.255.0", MAC: "00:09:0F:00:00:01"},
+		{Name: "port2", Type: "vlan", Status: "up", IP: "192.168.10.1 255.255.255.0", VlanID: 10},
+	}
+	fortigateServer := newFortigateServer(t, &ifaces)
+	netboxServer := fakenetbox.NewServer()
+	defer netboxServer.Close()
+
+	runFortigate(t, netboxServer, fortigateServer.URL)
+	devices, err := fakenetbox.Objects[objects.Device](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 1 || devices[0].Name != "fw1" || devices[0].SerialNumber != "FGT60F0000000001" {
+		t.Fatalf("devices = %v, want firewall fw1", devices)
+	}
+	assertNames(t, netboxServer, []string{"port1", "port2"}, []string{"10.0.0.1/24", "192.168.10.1/24"})
+	vlans, err := fakenetbox.Objects[objects.Vlan](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vlans) != 1 || vlans[0].Vid != 10 {
+		t.Errorf("vlans = %v, want vlan 10", vlans)
+	}
+	macAddresses, err := fakenetbox.Objects[objects.MACAddress](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(macAddresses) != 1 || macAddresses[0].MAC != "00:09:0F:00:00:01" {
+		t.Errorf("mac addresses = %v, want mac address of port1", macAddresses)
+	}
+
+	// Running again without changes doesn't change anything
+	changes, err := fakenetbox.Objects[objects.ObjectChange](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runFortigate(t, netboxServer, fortigateServer.URL)
+	newChanges, err := fakenetbox.Objects[objects.ObjectChange](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newChanges) != len(changes) {
+		t.Errorf("second run made changes %v", newChanges[len(changes):])
+	}
+
+	// Interface removed from the firewall is removed from Netbox with its ip address
+	ifaces = ifaces[:1]
+	runFortigate(t, netboxServer, fortigateServer.URL)
+	assertNames(t, netboxServer, []string{"port1"}, []string{"10.0.0.1/24"})
+	devices, err = fakenetbox.Objects[objects.Device](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 1 || devices[0].ID != 1 {
+		t.Errorf("devices = %v, want the same firewall", devices)
+	}
+}
+
+// assertNames checks names of all interfaces and addresses of all ip addresses in Netbox.
+func assertNames(t *testing.T, netboxServer *fakenetbox.Server, wantIfaces []string, wantIPs []string) {
+	t.Helper()
+	ifaces, err := fakenetbox.Objects[objects.Interface](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ifaceNames := make([]string, 0, len(ifaces))
+	for _, iface := range ifaces {
+		ifaceNames = append(ifaceNames, iface.Name)
+	}
+	slices.Sort(ifaceNames)
+	if !slices.Equal(ifaceNames, wantIfaces) {
+		t.Errorf("interfaces = %v, want %v", ifaceNames, wantIfaces)
+	}
+	ipAddresses, err := fakenetbox.Objects[objects.IPAddress](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addresses := make([]string, 0, len(ipAddresses))
+	for _, ipAddress := range ipAddresses {
+		addresses = append(addresses, ipAddress.Address)
+	}
+	slices.Sort(addresses)
+	if !slices.Equal(addresses, wantIPs) {
+		t.Errorf("ip addresses = %v, want %v", addresses, wantIPs)
+	}
+}
@@ -1,6 +1,7 @@
 package iosxe
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -27,11 +28,11 @@ type IOSXESource struct {
 	NBInterfaces map[string]*objects.Interface // interfaceName -> netboxInterface
 }
 
-func (is *IOSXESource) Init() error {
+func (is *IOSXESource) Init(ctx context.Context) error {
 	d, err := netconf.NewDriver(
 		is.SourceConfig.Hostname,
 		options.WithAuthUsername(is.SourceConfig.Username),
-		options.WithAuthPassword(is.SourceConfig.Password),
+		options.WithAuthPassword(is.SourceConfig.Password.Value()),
 		options.WithPort(is.SourceConfig.Port),
 		options.WithAuthNoStrictKey(),
 		// See https://github.com/SRC-doo/netbox-ssot/issues/498
@@ -47,7 +48,7 @@ func (is *IOSXESource) Init() error {
 	defer d.Close()
 
 	// Initialize items from vsphere API to local storage
-	initFunctions := []func(*netconf.Driver) error{
+	initFunctions := []func(context.Context, *netconf.Driver) error{
 		is.initDeviceInfo,
 		is.initDeviceHardwareInfo,
 		is.initInterfaces,
@@ -56,12 +57,12 @@ func (is *IOSXESource) Init() error {
 
 	for _, initFunc := range initFunctions {
 		startTime := time.Now()
-		if err := initFunc(d); err != nil {
+		if err := initFunc(ctx, d); err != nil {
 			return fmt.Errorf("iosxe initialization failure: %v", err)
 		}
 		duration := time.Since(startTime)
 		is.Logger.Infof(
-			is.Ctx,
+			ctx,
 			"Successfully initialized %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(initFunc, "init"),
 			duration.Seconds(),
@@ -70,8 +71,8 @@ func (is *IOSXESource) Init() error {
 	return nil
 }
 
-func (is *IOSXESource) Sync(nbi *inventory.NetboxInventory) error {
-	syncFunctions := []func(*inventory.NetboxInventory) error{
+func (is *IOSXESource) Sync(ctx context.Context, nbi *inventory.NetboxInventory) error {
+	syncFunctions := []func(context.Context, *inventory.NetboxInventory) error{
 		is.syncDevice,
 		is.syncInterfaces,
 		is.syncArpTable,
@@ -79,13 +80,13 @@ func (is *IOSXESource) Sync(nbi *inventory.NetboxInventory) error {
 
 	for _, syncFunc := range syncFunctions {
 		startTime := time.Now()
-		err := syncFunc(nbi)
+		err := syncFunc(ctx, nbi)
 		if err != nil {
 			return err
 		}
 		duration := time.Since(startTime)
 		is.Logger.Infof(
-			is.Ctx,
+			ctx,
 			"Successfully synced %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(syncFunc, "sync"),
 			duration.Seconds(),
@@ -1,13 +1,14 @@
 package iosxe
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 
 	"github.com/scrapli/scrapligo/driver/netconf"
 )
 
-func (is *IOSXESource) initDeviceInfo(d *netconf.Driver) error {
+func (is *IOSXESource) initDeviceInfo(ctx context.Context, d *netconf.Driver) error {
 	r, err := d.Get(systemFilter)
 	if err != nil {
 		return fmt.Errorf("error with system filter: %s", err)
@@ -19,7 +20,7 @@ func (is *IOSXESource) initDeviceInfo(d *netconf.Driver) error {
 	return nil
 }
 
-func (is *IOSXESource) initDeviceHardwareInfo(d *netconf.Driver) error {
+func (is *IOSXESource) initDeviceHardwareInfo(ctx context.Context, d *netconf.Driver) error {
 	r, err := d.Get(hwFilter)
 	if err != nil {
 		return fmt.Errorf("error with hardware filter: %s", err)
@@ -31,7 +32,7 @@ func (is *IOSXESource) initDeviceHardwareInfo(d *netconf.Driver) error {
 	return nil
 }
 
-func (is *IOSXESource) initInterfaces(d *netconf.Driver) error {
+func (is *IOSXESource) initInterfaces(ctx context.Context, d *netconf.Driver) error {
 	var ifaceReply interfaceReply
 	r, err := d.Get(interfaceFilter)
 	if err != nil {
@@ -48,7 +49,7 @@ func (is *IOSXESource) initInterfaces(d *netconf.Driver) error {
 	return nil
 }
 
-func (is *IOSXESource) initArpData(d *netconf.Driver) error {
+func (is *IOSXESource) initArpData(ctx context.Context, d *netconf.Driver) error {
 	var arpReply arpReply
 	r, err := d.Get(arpFilter)
 	if err != nil {
@@ -1,6 +1,7 @@
 package iosxe
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -13,7 +14,7 @@ import (
 )
 
 // Syncs dnac sites to netbox inventory.
-func (is *IOSXESource) syncDevice(nbi *inventory.NetboxInventory) error {
+func (is *IOSXESource) syncDevice(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	var err error
 	deviceName := is.SystemInfo.Hostname
 	if deviceName == "" {
@@ -33,13 +34,13 @@ func (is *IOSXESource) syncDevice(nbi *inventory.NetboxInventory) error {
 	if deviceModel == "" {
 		deviceModel = constants.DefaultModel
 	}
-	deviceManufacturer, err := nbi.AddManufacturer(is.Ctx, &objects.Manufacturer{
+	deviceManufacturer, err := nbi.AddManufacturer(ctx, &objects.Manufacturer{
 		Name: "Cisco",
 		Slug: utils.Slugify("Cisco"),
 	})
 	var deviceType *objects.DeviceType
 	if deviceData, ok := devices.DeviceTypesMap[deviceManufacturer.Name][deviceModel]; ok {
-		deviceType, err = nbi.AddDeviceType(is.Ctx, &objects.DeviceType{
+		deviceType, err = nbi.AddDeviceType(ctx, &objects.DeviceType{
 			Manufacturer: deviceManufacturer,
 			Model:        deviceModel,
 			Slug:         deviceData.Slug,
@@ -51,7 +52,7 @@ func (is *IOSXESource) syncDevice(nbi *inventory.NetboxInventory) error {
 		if err != nil {
 			return fmt.Errorf("failed adding manufacturer: %s", err)
 		}
-		deviceType, err = nbi.AddDeviceType(is.Ctx, &objects.DeviceType{
+		deviceType, err = nbi.AddDeviceType(ctx, &objects.DeviceType{
 			Manufacturer: deviceManufacturer,
 			Model:        deviceModel,
 			Slug:         utils.GenerateDeviceTypeSlug(deviceManufacturer.Name, deviceModel),
@@ -62,7 +63,7 @@ func (is *IOSXESource) syncDevice(nbi *inventory.NetboxInventory) error {
 	}
 
 	deviceTenant, err := common.MatchHostToTenant(
-		is.Ctx,
+		ctx,
 		nbi,
 		deviceName,
 		is.SourceConfig.HostTenantRelations,
@@ -76,7 +77,7 @@ func (is *IOSXESource) syncDevice(nbi *inventory.NetboxInventory) error {
 	var deviceRole *objects.DeviceRole
 	if len(is.SourceConfig.HostRoleRelations) > 0 {
 		deviceRole, err = common.MatchHostToRole(
-			is.Ctx,
+			ctx,
 			nbi,
 			deviceName,
 			is.SourceConfig.HostRoleRelations,
@@ -86,14 +87,14 @@ func (is *IOSXESource) syncDevice(nbi *inventory.NetboxInventory) error {
 		}
 	}
 	if deviceRole == nil {
-		deviceRole, err = nbi.AddSwitchDeviceRole(is.Ctx)
+		deviceRole, err = nbi.AddSwitchDeviceRole(ctx)
 		if err != nil {
 			return fmt.Errorf("add device role: %s", err)
 		}
 	}
 
 	deviceSite, err := common.MatchHostToSite(
-		is.Ctx,
+		ctx,
 		nbi,
 		deviceName,
 		is.SourceConfig.HostSiteRelations,
@@ -103,7 +104,7 @@ func (is *IOSXESource) syncDevice(nbi *inventory.NetboxInventory) error {
 	}
 
 	devicePlatformName := "IOS-XE" // TODO
-	devicePlatform, err := nbi.AddPlatform(is.Ctx, &objects.Platform{
+	devicePlatform, err := nbi.AddPlatform(ctx, &objects.Platform{
 		Name:         devicePlatformName,
 		Slug:         utils.Slugify(devicePlatformName),
 		Manufacturer: deviceManufacturer,
@@ -111,7 +112,7 @@ func (is *IOSXESource) syncDevice(nbi *inventory.NetboxInventory) error {
 	if err != nil {
 		return fmt.Errorf("add platform: %s", err)
 	}
-	NBDevice, err := nbi.AddDevice(is.Ctx, &objects.Device{
+	NBDevice, err := nbi.AddDevice(ctx, &objects.Device{
 		NetboxObject: objects.NetboxObject{
 			Tags:        is.GetSourceTags(),
 			Description: description,
@@ -133,7 +134,7 @@ func (is *IOSXESource) syncDevice(nbi *inventory.NetboxInventory) error {
 	return nil
 }
 
-func (is *IOSXESource) syncInterfaces(nbi *inventory.NetboxInventory) error {
+func (is *IOSXESource) syncInterfaces(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	is.NBInterfaces = make(map[string]*objects.Interface)
 	for ifaceName, iface := range is.Interfaces {
 		ifaceEnabled := iface.State.Enabled
@@ -179,7 +180,7 @@ func (is *IOSXESource) syncInterfaces(nbi *inventory.NetboxInventory) error {
 		default:
 		}
 
-		nbIface, err := nbi.AddInterface(is.Ctx, &objects.Interface{
+		nbIface, err := nbi.AddInterface(ctx, &objects.Interface{
 			NetboxObject: objects.NetboxObject{
 				Tags: is.GetSourceTags(),
 			},
@@ -194,7 +195,7 @@ func (is *IOSXESource) syncInterfaces(nbi *inventory.NetboxInventory) error {
 		}
 		if ifaceMAC != "" {
 			nbMACAddress, err := common.CreateMACAddressForObjectType(
-				is.Ctx,
+				ctx,
 				nbi,
 				ifaceMAC,
 				nbIface,
@@ -202,7 +203,7 @@ func (is *IOSXESource) syncInterfaces(nbi *inventory.NetboxInventory) error {
 			if err != nil {
 				return fmt.Errorf("create mac address for object type: %s", err)
 			}
-			if err = common.SetPrimaryMACForInterface(is.Ctx, nbi, nbIface, nbMACAddress); err != nil {
+			if err = common.SetPrimaryMACForInterface(ctx, nbi, nbIface, nbMACAddress); err != nil {
 				return fmt.Errorf("set primary mac for interface: %s", err)
 			}
 		}
@@ -211,14 +212,14 @@ func (is *IOSXESource) syncInterfaces(nbi *inventory.NetboxInventory) error {
 	return nil
 }
 
-func (is *IOSXESource) syncArpTable(nbi *inventory.NetboxInventory) error {
+func (is *IOSXESource) syncArpTable(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	if !is.SourceConfig.CollectArpData {
-		is.Logger.Info(is.Ctx, "skipping collecting of arp data")
+		is.Logger.Info(ctx, "skipping collecting of arp data")
 		return nil
 	}
 
 	// We tag it with special tag for arp data.
-	arpTag, err := nbi.AddTag(is.Ctx, &objects.Tag{
+	arpTag, err := nbi.AddTag(ctx, &objects.Tag{
 		Name:        constants.DefaultArpTagName,
 		Slug:        utils.Slugify(constants.DefaultArpTagName),
 		Color:       constants.DefaultArpTagColor,
@@ -240,7 +241,7 @@ func (is *IOSXESource) syncArpTable(nbi *inventory.NetboxInventory) error {
 			dnsName := utils.ReverseLookup(arpEntry.Address)
 			defaultMask := 32
 			addressWithMask := fmt.Sprintf("%s/%d", arpEntry.Address, defaultMask)
-			_, err := nbi.AddIPAddress(is.Ctx, &objects.IPAddress{
+			_, err := nbi.AddIPAddress(ctx, &objects.IPAddress{
 				NetboxObject: objects.NetboxObject{
 					Tags: newTags,
 					Description: fmt.Sprintf(
@@ -259,7 +260,7 @@ func (is *IOSXESource) syncArpTable(nbi *inventory.NetboxInventory) error {
 				Status:  &objects.IPAddressStatusActive,
 			})
 			if err != nil {
-				is.Logger.Warningf(is.Ctx, "error creating ip address: %s", err)
+				is.Logger.Warningf(ctx, "error creating ip address: %s", err)
 			}
 		}
 	}
@@ -1 +1,92 @@
 package iosxe
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox/sourcetest"
+	"github.com/src-doo/netbox-ssot/internal/netbox/inventory"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/parser"
+	"github.com/src-doo/netbox-ssot/internal/source/common"
+)
+
+// runIOSXE runs netbox-ssot with an ios-xe source, that is switch sw1
+// with the given interfaces.
+func runIOSXE(t *testing.T, netboxServer *fakenetbox.Server, interfaces map[string]iface) {
+	t.Helper()
+	sourceConfig := &parser.SourceConfig{
+		Name: "ios-xe",
+		Type: constants.IOSXE,
+		// Devices must be assigned to a site
+		HostSiteRelations: map[string]string{"^sw": "HQ"},
+	}
+	sourcetest.Run(t, netboxServer, sourceConfig,
+		func(ctx context.Context, nbi *inventory.NetboxInventory, config common.Config) error {
+			is := &IOSXESource{
+				Config: config,
+				HardwareInfo: hardwareReply{Inventory: []HWInventory{{
+					Type:         "hw-type-chassis",
+					PartNumber:   "C9300-24T",
+					SerialNumber: "FOC00000001",
+				}}},
+				SystemInfo: systemReply{Hostname: "sw1"},
+				Interfaces: interfaces,
+			}
+			return is.Sync(ctx, nbi)
+		})
+}
+
+// interfaceNames returns sorted names of all interfaces in Netbox.
+func interfaceNames(t *testing.T, netboxServer *fakenetbox.Server) []string {
+	t.Helper()
+	ifaces, err := fakenetbox.Objects[objects.Interface](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(ifaces))
+	for _, iface := range ifaces {
+		names = append(names, iface.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestIOSXESource_EndToEnd(t *testing.T) {
+	netboxServer := fakenetbox.NewServer()
+	defer netboxServer.Close()
+	interfaces := map[string]iface{
+		"GigabitEthernet1/0/1": {
+			State:    interfaceState{Enabled: true},
+			Ethernet: ethernetState{MACAddress: "00:11:22:33:44:01", PortSpeed: "SPEED_1GB"},
+		},
+		"GigabitEthernet1/0/2": {
+			State:    interfaceState{Enabled: true},
+			Ethernet: ethernetState{MACAddress: "00:11:22:33:44:02", PortSpeed: "SPEED_1GB"},
+		},
+	}
+
+	runIOSXE(t, netboxServer, interfaces)
+	devices, err := fakenetbox.Objects[objects.Device](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 1 || devices[0].Name != "sw1" || devices[0].SerialNumber != "FOC00000001" {
+		t.Fatalf("devices = %v, want switch sw1", devices)
+	}
+	want := []string{"GigabitEthernet1/0/1", "GigabitEthernet1/0/2"}
+	if got := interfaceNames(t, netboxServer); !slices.Equal(got, want) {
+		t.Errorf("interfaces = %v, want %v", got, want)
+	}
+
+	// Interface removed from the switch is removed from Netbox
+	delete(interfaces, "GigabitEthernet1/0/2")
+	runIOSXE(t, netboxServer, interfaces)
+	want = []string{"GigabitEthernet1/0/1"}
+	if got := interfaceNames(t, netboxServer); !slices.Equal(got, want) {
+		t.Errorf("interfaces = %v, want %v", got, want)
+	}
+}
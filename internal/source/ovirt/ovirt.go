@@ -1,6 +1,7 @@
 package ovirt
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -32,9 +33,9 @@ type NetworkData struct {
 }
 
 // Function that initializes state from ovirt api to local storage.
-func (o *OVirtSource) Init() error {
+func (o *OVirtSource) Init(ctx context.Context) error {
 	// Build the connection
-	o.Logger.Debug(o.Ctx, "Initializing oVirt source ", o.SourceConfig.Name)
+	o.Logger.Debug(ctx, "Initializing oVirt source ", o.SourceConfig.Name)
 	connBuilder := ovirtsdk4.NewConnectionBuilder().
 		URL(fmt.Sprintf(
 			"%s://%s:%d/ovirt-engine/api",
@@ -44,7 +45,7 @@ func (o *OVirtSource) Init() error {
 		),
 		).
 		Username(o.SourceConfig.Username).
-		Password(o.SourceConfig.Password).
+		Password(o.SourceConfig.Password.Value()).
 		Insecure(!o.SourceConfig.ValidateCert).
 		Compress(true).
 		Timeout(time.Second * constants.DefaultAPITimeout).
@@ -62,7 +63,7 @@ func (o *OVirtSource) Init() error {
 	defer conn.Close()
 
 	// Initialize items to local storage
-	initFunctions := []func(*ovirtsdk4.Connection) error{
+	initFunctions := []func(context.Context, *ovirtsdk4.Connection) error{
 		o.initNetworks,
 		o.initDisks,
 		o.initDataCenters,
@@ -73,7 +74,7 @@ func (o *OVirtSource) Init() error {
 
 	for _, initFunc := range initFunctions {
 		startTime := time.Now()
-		if err := initFunc(conn); err != nil {
+		if err := initFunc(ctx, conn); err != nil {
 			return fmt.Errorf(
 				"failed to initialize oVirt %s: %v",
 				strings.TrimPrefix(fmt.Sprintf("%T", initFunc), "*source.OVirtSource.Init"),
@@ -82,7 +83,7 @@ func (o *OVirtSource) Init() error {
 		}
 		duration := time.Since(startTime)
 		o.Logger.Infof(
-			o.Ctx,
+			ctx,
 			"Successfully initialized %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(initFunc, "init"),
 			duration.Seconds(),
@@ -92,8 +93,8 @@ func (o *OVirtSource) Init() error {
 }
 
 // Function that syncs all data from oVirt to Netbox.
-func (o *OVirtSource) Sync(nbi *inventory.NetboxInventory) error {
-	syncFunctions := []func(*inventory.NetboxInventory) error{
+func (o *OVirtSource) Sync(ctx context.Context, nbi *inventory.NetboxInventory) error {
+	syncFunctions := []func(context.Context, *inventory.NetboxInventory) error{
 		o.syncNetworks,
 		o.syncDatacenters,
 		o.syncClusters,
@@ -102,13 +103,13 @@ func (o *OVirtSource) Sync(nbi *inventory.NetboxInventory) error {
 	}
 	for _, syncFunc := range syncFunctions {
 		startTime := time.Now()
-		err := syncFunc(nbi)
+		err := syncFunc(ctx, nbi)
 		if err != nil {
 			return err
 		}
 		duration := time.Since(startTime)
 		o.Logger.Infof(
-			o.Ctx,
+			ctx,
 			"Successfully synced %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(syncFunc, "sync"),
 			duration.Seconds(),
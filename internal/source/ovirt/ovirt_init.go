@@ -1,13 +1,14 @@
 package ovirt
 
 import (
+	"context"
 	"fmt"
 
 	ovirtsdk4 "github.com/ovirt/go-ovirt"
 )
 
 // Fetches networks from ovirt api and stores them to local object.
-func (o *OVirtSource) initNetworks(conn *ovirtsdk4.Connection) error {
+func (o *OVirtSource) initNetworks(ctx context.Context, conn *ovirtsdk4.Connection) error {
 	networksResponse, err := conn.SystemService().
 		NetworksService().
 		List().
@@ -39,14 +40,14 @@ func (o *OVirtSource) initNetworks(conn *ovirtsdk4.Connection) error {
 				}
 			}
 		}
-		o.Logger.Debug(o.Ctx, "Successfully initialized oVirt networks: ", o.Networks)
+		o.Logger.Debug(ctx, "Successfully initialized oVirt networks: ", o.Networks)
 	} else {
-		o.Logger.Warning(o.Ctx, "Error initializing oVirt networks")
+		o.Logger.Warning(ctx, "Error initializing oVirt networks")
 	}
 	return nil
 }
 
-func (o *OVirtSource) initDisks(conn *ovirtsdk4.Connection) error {
+func (o *OVirtSource) initDisks(ctx context.Context, conn *ovirtsdk4.Connection) error {
 	// Get the disks
 	disksResponse, err := conn.SystemService().DisksService().List().Send()
 	if err != nil {
@@ -57,14 +58,14 @@ func (o *OVirtSource) initDisks(conn *ovirtsdk4.Connection) error {
 		for _, disk := range disks.Slice() {
 			o.Disks[disk.MustId()] = disk
 		}
-		o.Logger.Debug(o.Ctx, "Successfully initialized oVirt disks: ", o.Disks)
+		o.Logger.Debug(ctx, "Successfully initialized oVirt disks: ", o.Disks)
 	} else {
-		o.Logger.Warning(o.Ctx, "Error initializing oVirt disks")
+		o.Logger.Warning(ctx, "Error initializing oVirt disks")
 	}
 	return nil
 }
 
-func (o *OVirtSource) initDataCenters(conn *ovirtsdk4.Connection) error {
+func (o *OVirtSource) initDataCenters(ctx context.Context, conn *ovirtsdk4.Connection) error {
 	dataCentersResponse, err := conn.SystemService().DataCentersService().List().Send()
 	if err != nil {
 		return fmt.Errorf("failed to get oVirt data centers: %v", err)
@@ -74,15 +75,15 @@ func (o *OVirtSource) initDataCenters(conn *ovirtsdk4.Connection) error {
 		for _, dataCenter := range dataCenters.Slice() {
 			o.DataCenters[dataCenter.MustId()] = dataCenter
 		}
-		o.Logger.Debug(o.Ctx, "Successfully initialized oVirt data centers: ", o.DataCenters)
+		o.Logger.Debug(ctx, "Successfully initialized oVirt data centers: ", o.DataCenters)
 	} else {
-		o.Logger.Warning(o.Ctx, "Error initializing oVirt data centers")
+		o.Logger.Warning(ctx, "Error initializing oVirt data centers")
 	}
 	return nil
 }
 
 // Function that queries ovirt api for clusters and stores them locally.
-func (o *OVirtSource) initClusters(conn *ovirtsdk4.Connection) error {
+func (o *OVirtSource) initClusters(ctx context.Context, conn *ovirtsdk4.Connection) error {
 	clustersResponse, err := conn.SystemService().ClustersService().List().Send()
 	if err != nil {
 		return fmt.Errorf("failed to get oVirt clusters: %v", err)
@@ -92,15 +93,15 @@ func (o *OVirtSource) initClusters(conn *ovirtsdk4.Connection) error {
 		for _, cluster := range clusters.Slice() {
 			o.Clusters[cluster.MustId()] = cluster
 		}
-		o.Logger.Debug(o.Ctx, "Successfully initialized oVirt clusters: ", o.Clusters)
+		o.Logger.Debug(ctx, "Successfully initialized oVirt clusters: ", o.Clusters)
 	} else {
-		o.Logger.Warning(o.Ctx, "Error initializing oVirt clusters")
+		o.Logger.Warning(ctx, "Error initializing oVirt clusters")
 	}
 	return nil
 }
 
 // Function that queries ovirt api for hosts and stores them locally.
-func (o *OVirtSource) initHosts(conn *ovirtsdk4.Connection) error {
+func (o *OVirtSource) initHosts(ctx context.Context, conn *ovirtsdk4.Connection) error {
 	hostsResponse, err := conn.SystemService().HostsService().List().Follow("nics").Send()
 	if err != nil {
 		return fmt.Errorf("failed to get oVirt hosts: %+v", err)
@@ -110,15 +111,15 @@ func (o *OVirtSource) initHosts(conn *ovirtsdk4.Connection) error {
 		for _, host := range hosts.Slice() {
 			o.Hosts[host.MustId()] = host
 		}
-		o.Logger.Debug(o.Ctx, "Successfully initialized oVirt hosts: ", hosts)
+		o.Logger.Debug(ctx, "Successfully initialized oVirt hosts: ", hosts)
 	} else {
-		o.Logger.Warning(o.Ctx, "Error initializing oVirt hosts")
+		o.Logger.Warning(ctx, "Error initializing oVirt hosts")
 	}
 	return nil
 }
 
 // Function that queries the ovirt api for vms and stores them locally.
-func (o *OVirtSource) initVms(conn *ovirtsdk4.Connection) error {
+func (o *OVirtSource) initVms(ctx context.Context, conn *ovirtsdk4.Connection) error {
 	vmsResponse, err := conn.SystemService().
 		VmsService().
 		List().
@@ -132,9 +133,9 @@ func (o *OVirtSource) initVms(conn *ovirtsdk4.Connection) error {
 		for _, vm := range vms.Slice() {
 			o.Vms[vm.MustId()] = vm
 		}
-		o.Logger.Debug(o.Ctx, "Successfully initialized oVirt vms: ", vms)
+		o.Logger.Debug(ctx, "Successfully initialized oVirt vms: ", vms)
 	} else {
-		o.Logger.Warning(o.Ctx, "Error initializing oVirt vms")
+		o.Logger.Warning(ctx, "Error initializing oVirt vms")
 	}
 	return nil
 }
@@ -1,6 +1,7 @@
 package ovirt
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -15,7 +16,7 @@ import (
 )
 
 // Syncs networks received from oVirt API to the netbox.
-func (o *OVirtSource) syncNetworks(nbi *inventory.NetboxInventory) error {
+func (o *OVirtSource) syncNetworks(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	for _, network := range o.Networks.OVirtNetworks {
 		name, exists := network.Name()
 		if !exists {
@@ -26,7 +27,7 @@ func (o *OVirtSource) syncNetworks(nbi *inventory.NetboxInventory) error {
 		if networkVlan, exists := network.Vlan(); exists {
 			// Get vlanSite from relation
 			vlanSite, err := common.MatchVlanToSite(
-				o.Ctx,
+				ctx,
 				nbi,
 				name,
 				o.SourceConfig.VlanSiteRelations,
@@ -36,7 +37,7 @@ func (o *OVirtSource) syncNetworks(nbi *inventory.NetboxInventory) error {
 			}
 			// Get vlanGroup from relation
 			vlanGroup, err := common.MatchVlanToGroup(
-				o.Ctx,
+				ctx,
 				nbi,
 				name,
 				vlanSite,
@@ -48,7 +49,7 @@ func (o *OVirtSource) syncNetworks(nbi *inventory.NetboxInventory) error {
 			}
 			// Get tenant from relation
 			vlanTenant, err := common.MatchVlanToTenant(
-				o.Ctx,
+				ctx,
 				nbi,
 				name,
 				o.SourceConfig.VlanTenantRelations,
@@ -70,7 +71,7 @@ func (o *OVirtSource) syncNetworks(nbi *inventory.NetboxInventory) error {
 					Tenant:   vlanTenant,
 					Comments: network.MustComment(),
 				}
-				_, err := nbi.AddVlan(o.Ctx, vlanStruct)
+				_, err := nbi.AddVlan(ctx, vlanStruct)
 				if err != nil {
 					return fmt.Errorf("adding vlan %s: %v", vlanStruct, err)
 				}
@@ -80,7 +81,7 @@ func (o *OVirtSource) syncNetworks(nbi *inventory.NetboxInventory) error {
 	return nil
 }
 
-func (o *OVirtSource) syncDatacenters(nbi *inventory.NetboxInventory) error {
+func (o *OVirtSource) syncDatacenters(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	for _, datacenter := range o.DataCenters {
 		dcName, exists := datacenter.Name()
 		if !exists {
@@ -91,7 +92,7 @@ func (o *OVirtSource) syncDatacenters(nbi *inventory.NetboxInventory) error {
 		if mappedClusterGroupName, ok := o.SourceConfig.DatacenterClusterGroupRelations[dcName]; ok {
 			nbClusterGroupName = mappedClusterGroupName
 			o.Logger.Debugf(
-				o.Ctx,
+				ctx,
 				"mapping datacenter name %s to cluster group name %s",
 				dcName,
 				mappedClusterGroupName,
@@ -105,7 +106,7 @@ func (o *OVirtSource) syncDatacenters(nbi *inventory.NetboxInventory) error {
 			Name: nbClusterGroupName,
 			Slug: utils.Slugify(nbClusterGroupName),
 		}
-		_, err := nbi.AddClusterGroup(o.Ctx, clusterGroupStruct)
+		_, err := nbi.AddClusterGroup(ctx, clusterGroupStruct)
 		if err != nil {
 			return fmt.Errorf(
 				"failed to add oVirt data center %+v as Netbox cluster group: %v",
@@ -117,7 +118,7 @@ func (o *OVirtSource) syncDatacenters(nbi *inventory.NetboxInventory) error {
 	return nil
 }
 
-func (o *OVirtSource) syncClusters(nbi *inventory.NetboxInventory) error {
+func (o *OVirtSource) syncClusters(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	clusterTypeStruct := &objects.ClusterType{
 		NetboxObject: objects.NetboxObject{
 			Tags: []*objects.Tag{o.SourceTypeTag},
@@ -125,7 +126,7 @@ func (o *OVirtSource) syncClusters(nbi *inventory.NetboxInventory) error {
 		Name: "oVirt",
 		Slug: "ovirt",
 	}
-	nbClusterType, err := nbi.AddClusterType(o.Ctx, clusterTypeStruct)
+	nbClusterType, err := nbi.AddClusterType(ctx, clusterTypeStruct)
 	if err != nil {
 		return fmt.Errorf("failed to add oVirt cluster type: %v", err)
 	}
@@ -137,14 +138,14 @@ func (o *OVirtSource) syncClusters(nbi *inventory.NetboxInventory) error {
 		}
 		description, exists := cluster.Description()
 		if !exists {
-			o.Logger.Warning(o.Ctx, "description for oVirt cluster ", clusterName, " is empty.")
+			o.Logger.Warning(ctx, "description for oVirt cluster ", clusterName, " is empty.")
 		}
 		var clusterGroup *objects.ClusterGroup
 		var clusterGroupName string
 		if _, ok := o.DataCenters[cluster.MustDataCenter().MustId()]; ok {
 			clusterGroupName = o.DataCenters[cluster.MustDataCenter().MustId()].MustName()
 		} else {
-			o.Logger.Warning(o.Ctx, "failed to get datacenter for oVirt cluster ", clusterName)
+			o.Logger.Warning(ctx, "failed to get datacenter for oVirt cluster ", clusterName)
 		}
 		if clusterGroupName != "" {
 			if mappedName, ok := o.SourceConfig.DatacenterClusterGroupRelations[clusterGroupName]; ok {
@@ -156,7 +157,7 @@ func (o *OVirtSource) syncClusters(nbi *inventory.NetboxInventory) error {
 		var clusterScopeType constants.ContentType
 		var clusterScopeID int
 		clusterSite, err := common.MatchClusterToSite(
-			o.Ctx,
+			ctx,
 			nbi,
 			clusterName,
 			o.SourceConfig.ClusterSiteRelations,
@@ -170,7 +171,7 @@ func (o *OVirtSource) syncClusters(nbi *inventory.NetboxInventory) error {
 		}
 
 		clusterTenant, err := common.MatchClusterToTenant(
-			o.Ctx,
+			ctx,
 			nbi,
 			clusterName,
 			o.SourceConfig.ClusterTenantRelations,
@@ -192,7 +193,7 @@ func (o *OVirtSource) syncClusters(nbi *inventory.NetboxInventory) error {
 			ScopeID:   clusterScopeID,
 			Tenant:    clusterTenant,
 		}
-		_, err = nbi.AddCluster(o.Ctx, nbCluster)
+		_, err = nbi.AddCluster(ctx, nbCluster)
 		if err != nil {
 			return fmt.Errorf(
 				"failed to add oVirt cluster %s as Netbox cluster: %v",
@@ -206,20 +207,20 @@ func (o *OVirtSource) syncClusters(nbi *inventory.NetboxInventory) error {
 
 // syncHosts synces collected hosts from ovirt api to netbox inventory
 // as devices.
-func (o *OVirtSource) syncHosts(nbi *inventory.NetboxInventory) error {
+func (o *OVirtSource) syncHosts(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	for hostID, host := range o.Hosts {
-		hostStruct, err := extractHostData(o, nbi, host, hostID)
+		hostStruct, err := extractHostData(ctx, o, nbi, host, hostID)
 		if err != nil {
 			return fmt.Errorf("extract host data: %s", err)
 		}
 
-		nbHost, err := nbi.AddDevice(o.Ctx, hostStruct)
+		nbHost, err := nbi.AddDevice(ctx, hostStruct)
 		if err != nil {
 			return fmt.Errorf("failed to add oVirt host %+v with error: %v", hostStruct, err)
 		}
 
 		// We also need to sync nics separately, because nic is a separate object in netbox
-		err = o.syncHostNics(nbi, host, nbHost)
+		err = o.syncHostNics(ctx, nbi, host, nbHost)
 		if err != nil {
 			return fmt.Errorf("failed to sync oVirt host %s nics with error: %v", nbHost.Name, err)
 		}
@@ -230,6 +231,7 @@ func (o *OVirtSource) syncHosts(nbi *inventory.NetboxInventory) error {
 // extractHostData is a helper function for syncHosts that extracts host
 // data and returns it as an *objects.Device.
 func extractHostData(
+	ctx context.Context,
 	o *OVirtSource,
 	nbi *inventory.NetboxInventory,
 	host *ovirtsdk4.Host,
@@ -237,16 +239,16 @@ func extractHostData(
 ) (*objects.Device, error) {
 	hostName, exists := host.Name()
 	if !exists {
-		o.Logger.Warningf(o.Ctx, "name of host with id=%s is empty", hostID)
+		o.Logger.Warningf(ctx, "name of host with id=%s is empty", hostID)
 	}
 	hostCluster, _ := nbi.GetCluster(o.Clusters[host.MustCluster().MustId()].MustName())
 
-	hostSite, err := common.MatchHostToSite(o.Ctx, nbi, hostName, o.SourceConfig.HostSiteRelations)
+	hostSite, err := common.MatchHostToSite(ctx, nbi, hostName, o.SourceConfig.HostSiteRelations)
 	if err != nil {
 		return nil, fmt.Errorf("hostSite: %s", err)
 	}
 	hostTenant, err := common.MatchHostToTenant(
-		o.Ctx,
+		ctx,
 		nbi,
 		hostName,
 		o.SourceConfig.HostTenantRelations,
@@ -285,7 +287,7 @@ func extractHostData(
 		Name: hostManufacturerName,
 		Slug: utils.Slugify(hostManufacturerName),
 	}
-	hostManufacturer, err := nbi.AddManufacturer(o.Ctx, hostManufacturerStruct)
+	hostManufacturer, err := nbi.AddManufacturer(ctx, hostManufacturerStruct)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"failed adding oVirt Manufacturer %v with error: %s",
@@ -300,7 +302,7 @@ func extractHostData(
 		Model:        hostModel,
 		Slug:         deviceSlug,
 	}
-	hostDeviceType, err = nbi.AddDeviceType(o.Ctx, hostDeviceTypeStruct)
+	hostDeviceType, err = nbi.AddDeviceType(ctx, hostDeviceTypeStruct)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"failed adding oVirt DeviceType %v with error: %s",
@@ -347,7 +349,7 @@ func extractHostData(
 		Slug:         utils.Slugify(platformName),
 		Manufacturer: hostManufacturer,
 	}
-	hostPlatform, err = nbi.AddPlatform(o.Ctx, hostPlatformStruct)
+	hostPlatform, err = nbi.AddPlatform(ctx, hostPlatformStruct)
 	if err != nil {
 		return nil, fmt.Errorf("failed adding oVirt Platform %v with error: %s", hostPlatform, err)
 	}
@@ -366,7 +368,7 @@ func extractHostData(
 	if cpu, exists := host.Cpu(); exists {
 		hostCPUCores, exists = cpu.Name()
 		if !exists {
-			o.Logger.Warning(o.Ctx, "oVirt hostCpuCores of ", hostName, " is empty.")
+			o.Logger.Warning(ctx, "oVirt hostCpuCores of ", hostName, " is empty.")
 		}
 	}
 
@@ -378,7 +380,7 @@ func extractHostData(
 	var hostRole *objects.DeviceRole
 	if len(o.SourceConfig.HostRoleRelations) > 0 {
 		hostRole, err = common.MatchHostToRole(
-			o.Ctx,
+			ctx,
 			nbi,
 			hostName,
 			o.SourceConfig.HostRoleRelations,
@@ -388,7 +390,7 @@ func extractHostData(
 		}
 	}
 	if hostRole == nil {
-		hostRole, err = nbi.AddServerDeviceRole(o.Ctx)
+		hostRole, err = nbi.AddServerDeviceRole(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("add server device role %s", err)
 		}
@@ -420,6 +422,7 @@ func extractHostData(
 
 // syncHostNics syncs collected host nics from ovirt api to netbox inventory.
 func (o *OVirtSource) syncHostNics(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	ovirtHost *ovirtsdk4.Host,
 	nbHost *objects.Device,
@@ -450,6 +453,7 @@ func (o *OVirtSource) syncHostNics(
 		// Firstly we loop through all the host's nics and collect
 		// all the relevant information from ovirt API.
 		err := o.collectHostNicsData(
+			ctx,
 			nbHost,
 			nbi,
 			nics,
@@ -469,6 +473,7 @@ func (o *OVirtSource) syncHostNics(
 		// Secondly we loop to add relations between interfaces
 		// (e.g. [eno1, eno2] -> bond1).
 		err = o.matchHostMasterAndSlaveNics(
+			ctx,
 			nbi,
 			masterID2slaveIDs,
 			nicID2nbNic,
@@ -482,6 +487,7 @@ func (o *OVirtSource) syncHostNics(
 		// Thirdly we connect children nics with parents
 		// (e.g. [bond1.605, bond1.604, bond1.603] -> bond1).
 		err = o.matchHostParentAndChildNics(
+			ctx,
 			nbi,
 			parentID2childID,
 			nicName2nicID,
@@ -496,7 +502,7 @@ func (o *OVirtSource) syncHostNics(
 		// Fourthly we check if there were any nics that were not processed.
 		// This is needed because some nics might not have any relations.
 		for nicID := range processedNicsIDs {
-			nbNic, err := nbi.AddInterface(o.Ctx, nicID2nbNic[nicID])
+			nbNic, err := nbi.AddInterface(ctx, nicID2nbNic[nicID])
 			if err != nil {
 				return fmt.Errorf(
 					"failed to add oVirt interface %+v with error: %v",
@@ -506,7 +512,7 @@ func (o *OVirtSource) syncHostNics(
 			}
 			if nicID2MAC[nicID] != "" {
 				nbMACAddress, err := common.CreateMACAddressForObjectType(
-					o.Ctx,
+					ctx,
 					nbi,
 					nicID2MAC[nicID],
 					nbNic,
@@ -514,7 +520,7 @@ func (o *OVirtSource) syncHostNics(
 				if err != nil {
 					return fmt.Errorf("create mac address for object type: %s", err)
 				}
-				if err = common.SetPrimaryMACForInterface(o.Ctx, nbi, nbNic, nbMACAddress); err != nil {
+				if err = common.SetPrimaryMACForInterface(ctx, nbi, nbNic, nbMACAddress); err != nil {
 					return fmt.Errorf("set primary mac for interface: %s", err)
 				}
 			}
@@ -543,18 +549,18 @@ func (o *OVirtSource) syncHostNics(
 					AssignedObjectType: constants.ContentTypeDcimInterface,
 					AssignedObjectID:   nbNic.ID,
 				}
-				nbIPAddress, err := nbi.AddIPAddress(o.Ctx, ipAddressStruct)
+				nbIPAddress, err := nbi.AddIPAddress(ctx, ipAddressStruct)
 				if err != nil {
-					o.Logger.Warningf(o.Ctx, "add ipv4 address %+v: %s", ipAddressStruct, err)
+					o.Logger.Warningf(ctx, "add ipv4 address %+v: %s", ipAddressStruct, err)
 					continue
 				}
 				if address == hostIP {
 					hostCopy := *nbHost
 					hostCopy.PrimaryIPv4 = nbIPAddress
-					_, err := nbi.AddDevice(o.Ctx, &hostCopy)
+					_, err := nbi.AddDevice(ctx, &hostCopy)
 					if err != nil {
 						o.Logger.Warningf(
-							o.Ctx,
+							ctx,
 							"adding primary ipv4 address %+v: %s",
 							nbIPAddress,
 							err,
@@ -565,13 +571,13 @@ func (o *OVirtSource) syncHostNics(
 				// Also create prefix if it doesn't exist yet
 				prefix, mask, err := utils.GetPrefixAndMaskFromIPAddress(nbIPAddress.Address)
 				if err != nil {
-					o.Logger.Warningf(o.Ctx, "error extracting prefix from IP address: %s", err)
+					o.Logger.Warningf(ctx, "error extracting prefix from IP address: %s", err)
 				} else if mask != constants.MaxIPv4MaskBits {
-					_, err = nbi.AddPrefix(o.Ctx, &objects.Prefix{
+					_, err = nbi.AddPrefix(ctx, &objects.Prefix{
 						Prefix: prefix,
 					})
 					if err != nil {
-						o.Logger.Warningf(o.Ctx, "adding prefix: %s", err)
+						o.Logger.Warningf(ctx, "adding prefix: %s", err)
 					}
 				}
 			}
@@ -597,7 +603,7 @@ func (o *OVirtSource) syncHostNics(
 					AssignedObjectType: constants.ContentTypeDcimInterface,
 					AssignedObjectID:   nbNic.ID,
 				}
-				nbIPAddress, err := nbi.AddIPAddress(o.Ctx, ipAddressStruct)
+				nbIPAddress, err := nbi.AddIPAddress(ctx, ipAddressStruct)
 				if err != nil {
 					return fmt.Errorf("add ipv6 address %+v: %s", ipAddressStruct, err)
 				}
@@ -605,14 +611,14 @@ func (o *OVirtSource) syncHostNics(
 				// Also create prefix if it doesn't exist yet
 				prefix, mask, err := utils.GetPrefixAndMaskFromIPAddress(nbIPAddress.Address)
 				if err != nil {
-					o.Logger.Warningf(o.Ctx, "error extracting prefix from IP address: %s", err)
+					o.Logger.Warningf(ctx, "error extracting prefix from IP address: %s", err)
 				} else if mask != constants.MaxIPv4MaskBits {
 					prefixStruct := &objects.Prefix{
 						Prefix: prefix,
 					}
-					_, err = nbi.AddPrefix(o.Ctx, prefixStruct)
+					_, err = nbi.AddPrefix(ctx, prefixStruct)
 					if err != nil {
-						o.Logger.Warningf(o.Ctx, "adding prefix %+v: %s", prefixStruct, err)
+						o.Logger.Warningf(ctx, "adding prefix %+v: %s", prefixStruct, err)
 					}
 				}
 			}
@@ -622,6 +628,7 @@ func (o *OVirtSource) syncHostNics(
 }
 
 func (o *OVirtSource) matchHostMasterAndSlaveNics(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	masterID2slaveIDs map[string][]string,
 	nicID2nbNic map[string]*objects.Interface,
@@ -632,7 +639,7 @@ func (o *OVirtSource) matchHostMasterAndSlaveNics(
 		var err error
 		masterInterface := nicID2nbNic[masterID]
 		if _, ok := processedNicsIDs[masterID]; ok {
-			masterInterface, err = nbi.AddInterface(o.Ctx, masterInterface)
+			masterInterface, err = nbi.AddInterface(ctx, masterInterface)
 			if err != nil {
 				return fmt.Errorf(
 					"failed to add oVirt master interface %+v with error: %v",
@@ -642,7 +649,7 @@ func (o *OVirtSource) matchHostMasterAndSlaveNics(
 			}
 			if nicID2MAC[masterID] != "" {
 				nbMacAddress, err := common.CreateMACAddressForObjectType(
-					o.Ctx,
+					ctx,
 					nbi,
 					nicID2MAC[masterID],
 					masterInterface,
@@ -650,7 +657,7 @@ func (o *OVirtSource) matchHostMasterAndSlaveNics(
 				if err != nil {
 					return fmt.Errorf("create mac address for object type: %s", err)
 				}
-				if err = common.SetPrimaryMACForInterface(o.Ctx, nbi, masterInterface, nbMacAddress); err != nil {
+				if err = common.SetPrimaryMACForInterface(ctx, nbi, masterInterface, nbMacAddress); err != nil {
 					return fmt.Errorf("set primary mac for interface: %s", err)
 				}
 			}
@@ -660,7 +667,7 @@ func (o *OVirtSource) matchHostMasterAndSlaveNics(
 		for _, slaveID := range slavesIDs {
 			slaveInterface := nicID2nbNic[slaveID]
 			slaveInterface.LAG = masterInterface
-			slaveInterface, err := nbi.AddInterface(o.Ctx, slaveInterface)
+			slaveInterface, err := nbi.AddInterface(ctx, slaveInterface)
 			if err != nil {
 				return fmt.Errorf(
 					"failed to add oVirt slave interface %+v with error: %v",
@@ -670,7 +677,7 @@ func (o *OVirtSource) matchHostMasterAndSlaveNics(
 			}
 			if nicID2MAC[slaveID] != "" {
 				nbMACAddress, err := common.CreateMACAddressForObjectType(
-					o.Ctx,
+					ctx,
 					nbi,
 					nicID2MAC[slaveID],
 					slaveInterface,
@@ -679,7 +686,7 @@ func (o *OVirtSource) matchHostMasterAndSlaveNics(
 					return fmt.Errorf("create mac address for object type: %s", err)
 				}
 				if nicID2MAC[slaveID] != nicID2MAC[masterID] {
-					if err = common.SetPrimaryMACForInterface(o.Ctx, nbi, slaveInterface, nbMACAddress); err != nil {
+					if err = common.SetPrimaryMACForInterface(ctx, nbi, slaveInterface, nbMACAddress); err != nil {
 						return fmt.Errorf("set primary mac for interface: %s", err)
 					}
 				}
@@ -692,6 +699,7 @@ func (o *OVirtSource) matchHostMasterAndSlaveNics(
 }
 
 func (o *OVirtSource) matchHostParentAndChildNics(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	parentID2childID map[string][]string,
 	nicName2nicID map[string]string,
@@ -703,7 +711,7 @@ func (o *OVirtSource) matchHostParentAndChildNics(
 		parentNicID := nicName2nicID[parent]
 		parentInterface := nicID2nbNic[parentNicID]
 		if _, ok := processedNicsIDs[parentNicID]; ok {
-			parentInterface, err := nbi.AddInterface(o.Ctx, parentInterface)
+			parentInterface, err := nbi.AddInterface(ctx, parentInterface)
 			if err != nil {
 				return fmt.Errorf(
 					"failed to add oVirt parent interface %+v with error: %v",
@@ -713,7 +721,7 @@ func (o *OVirtSource) matchHostParentAndChildNics(
 			}
 			if nicID2MAC[parentNicID] != "" {
 				nbMACAddress, err := common.CreateMACAddressForObjectType(
-					o.Ctx,
+					ctx,
 					nbi,
 					nicID2MAC[parentNicID],
 					parentInterface,
@@ -721,7 +729,7 @@ func (o *OVirtSource) matchHostParentAndChildNics(
 				if err != nil {
 					return fmt.Errorf("create mac address for object type: %s", err)
 				}
-				if err = common.SetPrimaryMACForInterface(o.Ctx, nbi, parentInterface, nbMACAddress); err != nil {
+				if err = common.SetPrimaryMACForInterface(ctx, nbi, parentInterface, nbMACAddress); err != nil {
 					return fmt.Errorf("set primary mac for interface: %s", err)
 				}
 			}
@@ -733,7 +741,7 @@ func (o *OVirtSource) matchHostParentAndChildNics(
 			if nicID2MAC[child] == "" {
 				nicID2MAC[child] = nicID2MAC[parent]
 			}
-			childInterface, err := nbi.AddInterface(o.Ctx, childInterface)
+			childInterface, err := nbi.AddInterface(ctx, childInterface)
 			if err != nil {
 				return fmt.Errorf(
 					"failed to add oVirt child interface %+v with error: %s",
@@ -743,7 +751,7 @@ func (o *OVirtSource) matchHostParentAndChildNics(
 			}
 			if nicID2MAC[child] != "" {
 				nbMACAddress, err := common.CreateMACAddressForObjectType(
-					o.Ctx,
+					ctx,
 					nbi,
 					nicID2MAC[child],
 					childInterface,
@@ -751,7 +759,7 @@ func (o *OVirtSource) matchHostParentAndChildNics(
 				if err != nil {
 					return fmt.Errorf("create mac address for object type: %s", err)
 				}
-				if err = common.SetPrimaryMACForInterface(o.Ctx, nbi, childInterface, nbMACAddress); err != nil {
+				if err = common.SetPrimaryMACForInterface(ctx, nbi, childInterface, nbMACAddress); err != nil {
 					return fmt.Errorf("set primary mac for interface %+v: %s", childInterface, err)
 				}
 			}
@@ -763,6 +771,7 @@ func (o *OVirtSource) matchHostParentAndChildNics(
 }
 
 func (o *OVirtSource) collectHostNicsData(
+	ctx context.Context,
 	nbHost *objects.Device,
 	nbi *inventory.NetboxInventory,
 	nics *ovirtsdk4.HostNicSlice,
@@ -779,7 +788,7 @@ func (o *OVirtSource) collectHostNicsData(
 		nicID, exists := nic.Id()
 		if !exists {
 			o.Logger.Warning(
-				o.Ctx,
+				ctx,
 				"id for oVirt nic with id ",
 				nicID,
 				" is empty. This should not happen! Skipping...",
@@ -788,14 +797,14 @@ func (o *OVirtSource) collectHostNicsData(
 		}
 		nicName, exists := nic.Name()
 		if !exists {
-			o.Logger.Warning(o.Ctx, "name for oVirt nic with id ", nicID, " is empty.")
+			o.Logger.Warning(ctx, "name for oVirt nic with id ", nicID, " is empty.")
 			continue
 		}
 		nicName2nicID[nicName] = nicID
 		// Filter out interfaces with user provided filter
 		if utils.FilterInterfaceName(nicName, o.SourceConfig.InterfaceFilter) {
 			o.Logger.Debugf(
-				o.Ctx,
+				ctx,
 				"interface %s is filtered out with interfaceFilter %s",
 				nicName,
 				o.SourceConfig.InterfaceFilter,
@@ -806,13 +815,13 @@ func (o *OVirtSource) collectHostNicsData(
 		// var nicType *objects.InterfaceType
 		nicSpeedBips, exists := nic.Speed()
 		if !exists {
-			o.Logger.Debugf(o.Ctx, "speed for oVirt nic with id %s is empty", nicID)
+			o.Logger.Debugf(ctx, "speed for oVirt nic with id %s is empty", nicID)
 		}
 		nicSpeedKbps := nicSpeedBips / constants.KB
 
 		nicMtu, exists := nic.Mtu()
 		if !exists {
-			o.Logger.Debugf(o.Ctx, "mtu for oVirt nic with id %s is empty", nicID)
+			o.Logger.Debugf(ctx, "mtu for oVirt nic with id %s is empty", nicID)
 		}
 
 		nicComment, _ := nic.Comment()
@@ -873,7 +882,7 @@ func (o *OVirtSource) collectHostNicsData(
 				vlanName := o.Networks.Vid2Name[int(vlanID)]
 				// Get vlanSite from relation
 				vlanSite, err := common.MatchVlanToSite(
-					o.Ctx,
+					ctx,
 					nbi,
 					vlanName,
 					o.SourceConfig.VlanSiteRelations,
@@ -883,7 +892,7 @@ func (o *OVirtSource) collectHostNicsData(
 				}
 				// Get vlanGroup from relation
 				vlanGroup, err := common.MatchVlanToGroup(
-					o.Ctx,
+					ctx,
 					nbi,
 					vlanName,
 					vlanSite,
@@ -964,7 +973,7 @@ func (o *OVirtSource) collectHostNicsData(
 }
 
 // syncVMs synces ovirt vms into netbox inventory.
-func (o *OVirtSource) syncVMs(nbi *inventory.NetboxInventory) error {
+func (o *OVirtSource) syncVMs(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	const maxGoroutines = 50
 	guard := make(chan struct{}, maxGoroutines)
 	errChan := make(chan error, len(o.Vms))
@@ -978,7 +987,7 @@ func (o *OVirtSource) syncVMs(nbi *inventory.NetboxInventory) error {
 			defer wg.Done()
 			defer func() { <-guard }() // Release one spot in the semaphore
 
-			if err := o.syncVM(nbi, vmID, ovirtVM); err != nil {
+			if err := o.syncVM(ctx, nbi, vmID, ovirtVM); err != nil {
 				errChan <- err
 			}
 		}(vmID, ovirtVM)
@@ -999,26 +1008,27 @@ func (o *OVirtSource) syncVMs(nbi *inventory.NetboxInventory) error {
 
 // syncVM synces a single ovirt vm into netbox inventory.
 func (o *OVirtSource) syncVM(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	vmID string,
 	ovirtVM *ovirtsdk4.Vm,
 ) error {
-	collectedVM, collectedVMDisks, err := o.extractVMData(nbi, vmID, ovirtVM)
+	collectedVM, collectedVMDisks, err := o.extractVMData(ctx, nbi, vmID, ovirtVM)
 	if err != nil {
 		return err
 	}
 
-	nbVM, err := nbi.AddVM(o.Ctx, collectedVM)
+	nbVM, err := nbi.AddVM(ctx, collectedVM)
 	if err != nil {
 		return fmt.Errorf("failed to sync oVirt vm %s: %v", collectedVM.Name, err)
 	}
 
-	err = o.syncVMDisks(nbi, collectedVMDisks, nbVM)
+	err = o.syncVMDisks(ctx, nbi, collectedVMDisks, nbVM)
 	if err != nil {
 		return fmt.Errorf("failed to sync oVirt vm %s's disks: %v", collectedVM.Name, err)
 	}
 
-	err = o.syncVMInterfaces(nbi, ovirtVM, nbVM)
+	err = o.syncVMInterfaces(ctx, nbi, ovirtVM, nbVM)
 	if err != nil {
 		return fmt.Errorf("failed to sync oVirt vm %s's interfaces: %v", collectedVM.Name, err)
 	}
@@ -1027,13 +1037,14 @@ func (o *OVirtSource) syncVM(
 }
 
 func (o *OVirtSource) syncVMDisks(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	collectedVMDisks []*objects.VirtualDisk,
 	nbVM *objects.VM,
 ) error {
 	for _, disk := range collectedVMDisks {
 		disk.VM = nbVM
-		_, err := nbi.AddVirtualDisk(o.Ctx, disk)
+		_, err := nbi.AddVirtualDisk(ctx, disk)
 		if err != nil {
 			return fmt.Errorf("failed to sync oVirt vm %s's disk %s: %v", nbVM.Name, disk.Name, err)
 		}
@@ -1044,6 +1055,7 @@ func (o *OVirtSource) syncVMDisks(
 //
 //nolint:gocyclo
 func (o *OVirtSource) extractVMData(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	vmID string,
 	vm *ovirtsdk4.Vm,
@@ -1053,7 +1065,7 @@ func (o *OVirtSource) extractVMData(
 	vmName, exists := vm.Name()
 	if !exists {
 		o.Logger.Warning(
-			o.Ctx,
+			ctx,
 			"name for oVirt vm with id ",
 			vmID,
 			" is empty. VM has to have unique name to be synced to netbox. Skipping...",
@@ -1188,13 +1200,13 @@ func (o *OVirtSource) extractVMData(
 	}
 	var vmRole *objects.DeviceRole
 	if len(o.SourceConfig.VMRoleRelations) > 0 {
-		vmRole, err = common.MatchVMToRole(o.Ctx, nbi, vmName, o.SourceConfig.VMRoleRelations)
+		vmRole, err = common.MatchVMToRole(ctx, nbi, vmName, o.SourceConfig.VMRoleRelations)
 		if err != nil {
 			return nil, nil, fmt.Errorf("match vm to role: %s", err)
 		}
 	}
 	if vmRole == nil {
-		vmRole, err = nbi.AddVMDeviceRole(o.Ctx)
+		vmRole, err = nbi.AddVMDeviceRole(ctx)
 		if err != nil {
 			return nil, nil, fmt.Errorf("add vm device role: %s", err)
 		}
@@ -1205,7 +1217,7 @@ func (o *OVirtSource) extractVMData(
 		Name: platformName,
 		Slug: utils.Slugify(platformName),
 	}
-	vmPlatform, err = nbi.AddPlatform(o.Ctx, platformStruct)
+	vmPlatform, err = nbi.AddPlatform(ctx, platformStruct)
 	if err != nil {
 		return nil, nil, fmt.Errorf(
 			"failed adding oVirt vm's Platform %v with error: %s",
@@ -1218,7 +1230,8 @@ func (o *OVirtSource) extractVMData(
 		NetboxObject: objects.NetboxObject{
 			Tags: o.GetSourceTags(),
 			CustomFields: map[string]interface{}{
-				constants.CustomFieldSourceName: o.SourceConfig.Name,
+				constants.CustomFieldSourceName:   o.SourceConfig.Name,
+				constants.CustomFieldSourceIDName: vmID,
 			},
 		},
 		Name:        vmName,
@@ -1238,11 +1251,12 @@ func (o *OVirtSource) extractVMData(
 
 // syncVMInterfaces is a helper function for syncVMS. It syncs all interfaces from a VM to netbox.
 func (o *OVirtSource) syncVMInterfaces(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	ovirtVM *ovirtsdk4.Vm,
 	netboxVM *objects.VM,
 ) error {
-	err := o.syncVMNics(nbi, ovirtVM, netboxVM)
+	err := o.syncVMNics(ctx, nbi, ovirtVM, netboxVM)
 	if err != nil {
 		return fmt.Errorf("sync VMNics %s", err)
 	}
@@ -1265,7 +1279,7 @@ func (o *OVirtSource) syncVMInterfaces(
 							o.SourceConfig.InterfaceFilter,
 						) {
 							o.Logger.Debugf(
-								o.Ctx,
+								ctx,
 								"interface %s is filtered out with interfaceFilter %s",
 								reportedDeviceName,
 								o.SourceConfig.InterfaceFilter,
@@ -1281,7 +1295,7 @@ func (o *OVirtSource) syncVMInterfaces(
 							Name:    reportedDeviceName,
 							Enabled: true, // TODO
 						}
-						vmInterface, err = nbi.AddVMInterface(o.Ctx, vmInterfaceStruct)
+						vmInterface, err = nbi.AddVMInterface(ctx, vmInterfaceStruct)
 						if err != nil {
 							return fmt.Errorf(
 								"failed to sync oVirt vm %s's interface %+v: %v",
@@ -1292,7 +1306,7 @@ func (o *OVirtSource) syncVMInterfaces(
 						}
 						if vmInterfaceMac != "" {
 							nbMACAddress, err := common.CreateMACAddressForObjectType(
-								o.Ctx,
+								ctx,
 								nbi,
 								vmInterfaceMac,
 								vmInterface,
@@ -1300,15 +1314,15 @@ func (o *OVirtSource) syncVMInterfaces(
 							if err != nil {
 								return fmt.Errorf("create mac address for object type: %s", err)
 							}
-							if err = common.SetPrimaryMACForInterface(o.Ctx, nbi, vmInterface, nbMACAddress); err != nil {
+							if err = common.SetPrimaryMACForInterface(ctx, nbi, vmInterface, nbMACAddress); err != nil {
 								return fmt.Errorf("set primary mac for interface: %s", err)
 							}
 						}
 					} else {
-						o.Logger.Warning(o.Ctx, "name for oVirt vm's reported device is empty. Skipping...")
+						o.Logger.Warning(ctx, "name for oVirt vm's reported device is empty. Skipping...")
 						continue
 					}
-					o.processVMInterfaceIPs(nbi, reportedDevice, netboxVM, vmInterface)
+					o.processVMInterfaceIPs(ctx, nbi, reportedDevice, netboxVM, vmInterface)
 				}
 			}
 		}
@@ -1319,6 +1333,7 @@ func (o *OVirtSource) syncVMInterfaces(
 // processVMInterfaceIPs is a helper function for syncVMInterfaces,
 // that processes IPs of VM interfaces.
 func (o *OVirtSource) processVMInterfaceIPs(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	reportedDevice *ovirtsdk4.ReportedDevice,
 	netboxVM *objects.VM,
@@ -1365,13 +1380,13 @@ func (o *OVirtSource) processVMInterfaceIPs(
 							AssignedObjectID:   vmInterface.ID,
 						}
 						newIPAddress, err := nbi.AddIPAddress(
-							o.Ctx,
+							ctx,
 							ipAddressStruct,
 						)
 
 						if err != nil {
 							o.Logger.Warningf(
-								o.Ctx,
+								ctx,
 								"add ip address %+v: %s",
 								err,
 								ipAddressStruct,
@@ -1386,10 +1401,10 @@ func (o *OVirtSource) processVMInterfaceIPs(
 								netboxVM.PrimaryIPv4 == nil {
 								vmCopy := *netboxVM
 								vmCopy.PrimaryIPv4 = newIPAddress
-								_, err := nbi.AddVM(o.Ctx, &vmCopy)
+								_, err := nbi.AddVM(ctx, &vmCopy)
 								if err != nil {
 									o.Logger.Warningf(
-										o.Ctx,
+										ctx,
 										"adding vm's primary ipv4 address %+v: %s",
 										newIPAddress,
 										err,
@@ -1402,13 +1417,13 @@ func (o *OVirtSource) processVMInterfaceIPs(
 							newIPAddress.Address,
 						)
 						if err != nil {
-							o.Logger.Debugf(o.Ctx, "extract prefix: %s", err)
+							o.Logger.Debugf(ctx, "extract prefix: %s", err)
 						} else if mask != constants.MaxIPv4MaskBits {
-							_, err = nbi.AddPrefix(o.Ctx, &objects.Prefix{
+							_, err = nbi.AddPrefix(ctx, &objects.Prefix{
 								Prefix: prefix,
 							})
 							if err != nil {
-								o.Logger.Errorf(o.Ctx, "add prefix %+v: %s", prefix, err)
+								o.Logger.Errorf(ctx, "add prefix %+v: %s", prefix, err)
 							}
 						}
 					}
@@ -1419,6 +1434,7 @@ func (o *OVirtSource) processVMInterfaceIPs(
 }
 
 func (o *OVirtSource) syncVMNics(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	ovirtVM *ovirtsdk4.Vm,
 	netboxVM *objects.VM,
@@ -1427,12 +1443,12 @@ func (o *OVirtSource) syncVMNics(
 		for _, nic := range nics.Slice() {
 			nicName, ok := nic.Name()
 			if !ok {
-				o.Logger.Debugf(o.Ctx, "skipping nic because it doesn't have name")
+				o.Logger.Debugf(ctx, "skipping nic because it doesn't have name")
 				continue
 			}
 			if utils.FilterInterfaceName(nicName, o.SourceConfig.InterfaceFilter) {
 				o.Logger.Debugf(
-					o.Ctx,
+					ctx,
 					"filtering interface %s with interface filter %s",
 					nicName,
 					o.SourceConfig.InterfaceFilter,
@@ -1471,7 +1487,7 @@ func (o *OVirtSource) syncVMNics(
 						if vlanID, ok := vnicNetworkVlan.Id(); ok {
 							vlanName := o.Networks.Vid2Name[int(vlanID)]
 							vlanSite, err := common.MatchVlanToSite(
-								o.Ctx,
+								ctx,
 								nbi,
 								vlanName,
 								o.SourceConfig.VlanSiteRelations,
@@ -1480,7 +1496,7 @@ func (o *OVirtSource) syncVMNics(
 								return fmt.Errorf("match vlan to site: %s", err)
 							}
 							vlanGroup, err := common.MatchVlanToGroup(
-								o.Ctx,
+								ctx,
 								nbi,
 								vlanName,
 								vlanSite,
@@ -1488,7 +1504,7 @@ func (o *OVirtSource) syncVMNics(
 								o.SourceConfig.VlanGroupSiteRelations,
 							)
 							if err != nil {
-								o.Logger.Warningf(o.Ctx, "match vlan to group: %s", err)
+								o.Logger.Warningf(ctx, "match vlan to group: %s", err)
 								continue
 							}
 							nicVlan, _ := nbi.GetVlan(vlanGroup.ID, int(vlanID))
@@ -1499,7 +1515,7 @@ func (o *OVirtSource) syncVMNics(
 				}
 			}
 
-			nbVMInterface, err := nbi.AddVMInterface(o.Ctx, &objects.VMInterface{
+			nbVMInterface, err := nbi.AddVMInterface(ctx, &objects.VMInterface{
 				NetboxObject: objects.NetboxObject{
 					Tags:        o.GetSourceTags(),
 					Description: nicDescription,
@@ -1518,7 +1534,7 @@ func (o *OVirtSource) syncVMNics(
 			}
 			if nicMAC != "" {
 				nbMACAddress, err := common.CreateMACAddressForObjectType(
-					o.Ctx,
+					ctx,
 					nbi,
 					nicMAC,
 					nbVMInterface,
@@ -1530,7 +1546,7 @@ func (o *OVirtSource) syncVMNics(
 						err,
 					)
 				}
-				if err = common.SetPrimaryMACForInterface(o.Ctx, nbi, nbVMInterface, nbMACAddress); err != nil {
+				if err = common.SetPrimaryMACForInterface(ctx, nbi, nbVMInterface, nbMACAddress); err != nil {
 					return fmt.Errorf(
 						"set primary mac for vm interface %+v: %s",
 						nbVMInterface,
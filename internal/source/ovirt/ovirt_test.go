@@ -1 +1,108 @@
 package ovirt
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	ovirtsdk4 "github.com/ovirt/go-ovirt"
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox/sourcetest"
+	"github.com/src-doo/netbox-ssot/internal/netbox/inventory"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/parser"
+	"github.com/src-doo/netbox-ssot/internal/source/common"
+)
+
+// runOVirt runs netbox-ssot with an ovirt source, that has clusters
+// cluster1 and cluster2 in datacenter dc1, and the given vms.
+func runOVirt(t *testing.T, netboxServer *fakenetbox.Server, vms ...*ovirtsdk4.Vm) {
+	t.Helper()
+	sourceConfig := &parser.SourceConfig{Name: "ovirt", Type: constants.Ovirt}
+	sourcetest.Run(t, netboxServer, sourceConfig,
+		func(ctx context.Context, nbi *inventory.NetboxInventory, config common.Config) error {
+			datacenter := ovirtsdk4.NewDataCenterBuilder().Id("dc1").Name("dc1").MustBuild()
+			o := &OVirtSource{
+				Config:      config,
+				DataCenters: map[string]*ovirtsdk4.DataCenter{"dc1": datacenter},
+				Clusters:    make(map[string]*ovirtsdk4.Cluster),
+				Vms:         make(map[string]*ovirtsdk4.Vm),
+				Networks:    &NetworkData{},
+			}
+			for _, clusterName := range []string{"cluster1", "cluster2"} {
+				o.Clusters[clusterName] = ovirtsdk4.NewClusterBuilder().
+					Id(clusterName).
+					Name(clusterName).
+					DataCenter(datacenter).
+					MustBuild()
+			}
+			for _, vm := range vms {
+				o.Vms[vm.MustId()] = vm
+			}
+			return o.Sync(ctx, nbi)
+		})
+}
+
+// newVM returns an ovirt vm with the id and name in the cluster.
+func newVM(id string, name string, cluster string) *ovirtsdk4.Vm {
+	return ovirtsdk4.NewVmBuilder().
+		Id(id).
+		Name(name).
+		Status(ovirtsdk4.VMSTATUS_UP).
+		Cluster(ovirtsdk4.NewClusterBuilder().Id(cluster).MustBuild()).
+		MustBuild()
+}
+
+// vmClusters returns clusters of all vms in Netbox by vm names.
+func vmClusters(t *testing.T, netboxServer *fakenetbox.Server) map[string]string {
+	t.Helper()
+	vms, err := fakenetbox.Objects[objects.VM](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusters := make(map[string]string, len(vms))
+	for _, vm := range vms {
+		clusters[vm.Name] = ""
+		if vm.Cluster != nil {
+			clusters[vm.Name] = vm.Cluster.Name
+		}
+	}
+	return clusters
+}
+
+func TestOVirtSource_EndToEnd(t *testing.T) {
+	netboxServer := fakenetbox.NewServer()
+	defer netboxServer.Close()
+
+	runOVirt(t, netboxServer, newVM("vm-1", "vm1", "cluster1"), newVM("vm-2", "vm2", "cluster2"))
+	want := map[string]string{"vm1": "cluster1", "vm2": "cluster2"}
+	if got := vmClusters(t, netboxServer); !maps.Equal(got, want) {
+		t.Errorf("vms = %v, want %v", got, want)
+	}
+
+	// VM removed from ovirt is removed from Netbox
+	runOVirt(t, netboxServer, newVM("vm-1", "vm1", "cluster1"))
+	want = map[string]string{"vm1": "cluster1"}
+	if got := vmClusters(t, netboxServer); !maps.Equal(got, want) {
+		t.Errorf("vms = %v, want %v", got, want)
+	}
+}
+
+func TestOVirtSource_EndToEnd_SourceID(t *testing.T) {
+	netboxServer := fakenetbox.NewServer()
+	defer netboxServer.Close()
+	runOVirt(t, netboxServer, newVM("vm-1", "vm1", "cluster1"))
+
+	// oVirt id identifies the vm, so the renamed vm moved to
+	// another cluster is patched instead of created again
+	runOVirt(t, netboxServer, newVM("vm-1", "vm1-renamed", "cluster2"))
+	vms, err := fakenetbox.Objects[objects.VM](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vms) != 1 || vms[0].ID != 1 || vms[0].Name != "vm1-renamed" ||
+		vms[0].Cluster == nil || vms[0].Cluster.Name != "cluster2" {
+		t.Errorf("vms = %v, want vm 1 renamed and moved to cluster2", vms)
+	}
+}
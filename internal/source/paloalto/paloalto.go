@@ -1,8 +1,8 @@
 package paloalto
 
 import (
+	"context"
 	"fmt"
-	"net/http"
 	"time"
 
 	"github.com/PaloAltoNetworks/pango"
@@ -36,19 +36,15 @@ type PaloAltoSource struct {
 	NBFirewall *objects.Device
 }
 
-func (pas *PaloAltoSource) Init() error {
-	var transport *http.Transport
-	var err error
-	if pas.Config.CAFile != "" {
-		transport, err = utils.LoadExtraCertInTransportConfig(pas.Config.CAFile)
-		if err != nil {
-			return fmt.Errorf("load extra cert in transport config: %s", err)
-		}
+func (pas *PaloAltoSource) Init(ctx context.Context) error {
+	transport, err := utils.NewHTTPTransport(pas.SourceConfig.HTTPClientConfig())
+	if err != nil {
+		return fmt.Errorf("create http transport: %s", err)
 	}
 	c := &pango.Firewall{Client: pango.Client{
 		Hostname:          pas.SourceConfig.Hostname,
 		Username:          pas.SourceConfig.Username,
-		Password:          pas.SourceConfig.Password,
+		Password:          pas.SourceConfig.Password.Value(),
 		Logging:           pango.LogAction | pango.LogOp,
 		VerifyCertificate: pas.SourceConfig.ValidateCert,
 		Port:              uint(pas.SourceConfig.Port), //nolint:gosec
@@ -61,7 +57,7 @@ func (pas *PaloAltoSource) Init() error {
 		return fmt.Errorf("paloalto failed to initialize client: %s", err)
 	}
 
-	initFunctions := []func(*pango.Firewall) error{
+	initFunctions := []func(context.Context, *pango.Firewall) error{
 		pas.initArpData,
 		pas.initSystemInfo,
 		pas.initVirtualSystems,
@@ -70,12 +66,12 @@ func (pas *PaloAltoSource) Init() error {
 	}
 	for _, initFunc := range initFunctions {
 		startTime := time.Now()
-		if err := initFunc(c); err != nil {
+		if err := initFunc(ctx, c); err != nil {
 			return fmt.Errorf("paloalto initialization failure: %v", err)
 		}
 		duration := time.Since(startTime)
 		pas.Logger.Infof(
-			pas.Ctx,
+			ctx,
 			"Successfully initialized %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(initFunc, "init"),
 			duration.Seconds(),
@@ -84,8 +80,8 @@ func (pas *PaloAltoSource) Init() error {
 	return nil
 }
 
-func (pas *PaloAltoSource) Sync(nbi *inventory.NetboxInventory) error {
-	syncFunctions := []func(*inventory.NetboxInventory) error{
+func (pas *PaloAltoSource) Sync(ctx context.Context, nbi *inventory.NetboxInventory) error {
+	syncFunctions := []func(context.Context, *inventory.NetboxInventory) error{
 		pas.syncDevice,
 		pas.syncSecurityZones,
 		pas.syncInterfaces,
@@ -94,13 +90,13 @@ func (pas *PaloAltoSource) Sync(nbi *inventory.NetboxInventory) error {
 
 	for _, syncFunc := range syncFunctions {
 		startTime := time.Now()
-		err := syncFunc(nbi)
+		err := syncFunc(ctx, nbi)
 		if err != nil {
 			return err
 		}
 		duration := time.Since(startTime)
 		pas.Logger.Infof(
-			pas.Ctx,
+			ctx,
 			"Successfully synced %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(syncFunc, "sync"),
 			duration.Seconds(),
@@ -1,6 +1,7 @@
 package paloalto
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 
@@ -13,12 +14,12 @@ import (
 )
 
 // Init system info collects system info from paloalto.
-func (pas *PaloAltoSource) initSystemInfo(c *pango.Firewall) error {
+func (pas *PaloAltoSource) initSystemInfo(ctx context.Context, c *pango.Firewall) error {
 	pas.SystemInfo = c.Client.SystemInfo
 	return nil
 }
 
-func (pas *PaloAltoSource) initVirtualSystems(c *pango.Firewall) error {
+func (pas *PaloAltoSource) initVirtualSystems(ctx context.Context, c *pango.Firewall) error {
 	virtualSystems, err := c.Vsys.GetAll()
 	if err != nil {
 		return fmt.Errorf("get all virtual systems: %s", err)
@@ -42,7 +43,7 @@ func (pas *PaloAltoSource) initVirtualSystems(c *pango.Firewall) error {
 	return nil
 }
 
-func (pas *PaloAltoSource) initVirtualRouters(c *pango.Firewall) error {
+func (pas *PaloAltoSource) initVirtualRouters(ctx context.Context, c *pango.Firewall) error {
 	routers, err := c.Network.VirtualRouter.GetAll()
 	if err != nil {
 		return err
@@ -60,7 +61,7 @@ func (pas *PaloAltoSource) initVirtualRouters(c *pango.Firewall) error {
 
 // initInterfaces collects all ethernet interfaces and subinterfaces
 // from paloalto API. It stores them as attribute of the paloalto source.
-func (pas *PaloAltoSource) initInterfaces(c *pango.Firewall) error {
+func (pas *PaloAltoSource) initInterfaces(ctx context.Context, c *pango.Firewall) error {
 	ethInterfaces, err := c.Network.EthernetInterface.GetAll()
 	if err != nil {
 		return err
@@ -108,7 +109,7 @@ type ArpEntry struct {
 
 // initArpData collects all arp entries from the paloalto source.
 // It stores them as attribute of the paloalto source.
-func (pas *PaloAltoSource) initArpData(c *pango.Firewall) error {
+func (pas *PaloAltoSource) initArpData(ctx context.Context, c *pango.Firewall) error {
 	if pas.SourceConfig.CollectArpData {
 		var arpData ArpData
 		arpXMLString := "<show><arp><entry name='all'/></arp></show>"
@@ -1,6 +1,7 @@
 package paloalto
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"sync"
@@ -14,7 +15,7 @@ import (
 )
 
 // Sync device creates default device in netbox representing Paloalto firewall.
-func (pas *PaloAltoSource) syncDevice(nbi *inventory.NetboxInventory) error {
+func (pas *PaloAltoSource) syncDevice(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	deviceName := pas.SystemInfo["devicename"]
 	if deviceName == "" {
 		return fmt.Errorf("can't extract device name from system info")
@@ -26,12 +27,12 @@ func (pas *PaloAltoSource) syncDevice(nbi *inventory.NetboxInventory) error {
 	deviceModel := pas.SystemInfo["model"]
 	if deviceModel == "" {
 		pas.Logger.Warningf(
-			pas.Ctx,
+			ctx,
 			"model field in system info is empty. Using fallback mechanism.",
 		)
 		deviceModel = constants.DefaultModel
 	}
-	deviceManufacturer, err := nbi.AddManufacturer(pas.Ctx, &objects.Manufacturer{
+	deviceManufacturer, err := nbi.AddManufacturer(ctx, &objects.Manufacturer{
 		Name: "Palo Alto",
 		Slug: utils.Slugify("Palo Alto"),
 	})
@@ -43,13 +44,13 @@ func (pas *PaloAltoSource) syncDevice(nbi *inventory.NetboxInventory) error {
 		Model:        deviceModel,
 		Slug:         utils.Slugify(deviceManufacturer.Name + deviceModel),
 	}
-	deviceType, err := nbi.AddDeviceType(pas.Ctx, deviceTypeStruct)
+	deviceType, err := nbi.AddDeviceType(ctx, deviceTypeStruct)
 	if err != nil {
 		return fmt.Errorf("add device type %+v: %s", deviceTypeStruct, err)
 	}
 
 	deviceTenant, err := common.MatchHostToTenant(
-		pas.Ctx,
+		ctx,
 		nbi,
 		deviceName,
 		pas.SourceConfig.HostTenantRelations,
@@ -61,7 +62,7 @@ func (pas *PaloAltoSource) syncDevice(nbi *inventory.NetboxInventory) error {
 	var deviceRole *objects.DeviceRole
 	if len(pas.SourceConfig.HostRoleRelations) > 0 {
 		deviceRole, err = common.MatchHostToRole(
-			pas.Ctx,
+			ctx,
 			nbi,
 			deviceName,
 			pas.SourceConfig.HostRoleRelations,
@@ -71,13 +72,13 @@ func (pas *PaloAltoSource) syncDevice(nbi *inventory.NetboxInventory) error {
 		}
 	}
 	if deviceRole == nil {
-		deviceRole, err = nbi.AddFirewallDeviceRole(pas.Ctx)
+		deviceRole, err = nbi.AddFirewallDeviceRole(ctx)
 		if err != nil {
 			return fmt.Errorf("add DeviceRole firewall: %s", err)
 		}
 	}
 	deviceSite, err := common.MatchHostToSite(
-		pas.Ctx,
+		ctx,
 		nbi,
 		deviceName,
 		pas.SourceConfig.HostSiteRelations,
@@ -91,7 +92,7 @@ func (pas *PaloAltoSource) syncDevice(nbi *inventory.NetboxInventory) error {
 		Slug:         utils.Slugify(devicePlatformName),
 		Manufacturer: deviceManufacturer,
 	}
-	devicePlatform, err := nbi.AddPlatform(pas.Ctx, platformStruct)
+	devicePlatform, err := nbi.AddPlatform(ctx, platformStruct)
 	if err != nil {
 		return fmt.Errorf("add platform: %s", err)
 	}
@@ -108,7 +109,7 @@ func (pas *PaloAltoSource) syncDevice(nbi *inventory.NetboxInventory) error {
 		Platform:     devicePlatform,
 		SerialNumber: deviceSerialNumber,
 	}
-	NBDevice, err := nbi.AddDevice(pas.Ctx, deviceStruct)
+	NBDevice, err := nbi.AddDevice(ctx, deviceStruct)
 	if err != nil {
 		return fmt.Errorf("add device: %s", err)
 	}
@@ -117,15 +118,15 @@ func (pas *PaloAltoSource) syncDevice(nbi *inventory.NetboxInventory) error {
 	return nil
 }
 
-func (pas *PaloAltoSource) syncInterfaces(nbi *inventory.NetboxInventory) error {
+func (pas *PaloAltoSource) syncInterfaces(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	for _, iface := range pas.Ifaces {
 		if iface.Name == "" {
-			pas.Logger.Debugf(pas.Ctx, "empty interface name. Skipping...")
+			pas.Logger.Debugf(ctx, "empty interface name. Skipping...")
 			continue
 		}
 		if utils.FilterInterfaceName(iface.Name, pas.SourceConfig.InterfaceFilter) {
 			pas.Logger.Debugf(
-				pas.Ctx,
+				ctx,
 				"interface %s is filtered out with interface filter %s",
 				iface.Name,
 				pas.SourceConfig.InterfaceFilter,
@@ -152,7 +153,7 @@ func (pas *PaloAltoSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 				ifaceDuplex = &objects.DuplexHalf
 			case "":
 			default:
-				pas.Logger.Debugf(pas.Ctx, "not implemented duplex value %s", iface.LinkDuplex)
+				pas.Logger.Debugf(ctx, "not implemented duplex value %s", iface.LinkDuplex)
 			}
 		}
 
@@ -160,7 +161,7 @@ func (pas *PaloAltoSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 		if vdc := pas.getVirtualDeviceContext(nbi, iface.Name); vdc != nil {
 			ifaceVdcs = []*objects.VirtualDeviceContext{vdc}
 		}
-		nbIface, err := nbi.AddInterface(pas.Ctx, &objects.Interface{
+		nbIface, err := nbi.AddInterface(ctx, &objects.Interface{
 			NetboxObject: objects.NetboxObject{
 				Tags:        pas.GetSourceTags(),
 				Description: iface.Comment,
@@ -178,7 +179,7 @@ func (pas *PaloAltoSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 		}
 
 		if len(iface.StaticIps) > 0 {
-			pas.syncIPs(nbi, nbIface, iface.StaticIps, nil)
+			pas.syncIPs(ctx, nbi, nbIface, iface.StaticIps, nil)
 		}
 
 		for _, subIface := range pas.Iface2SubIfaces[iface.Name] {
@@ -193,7 +194,7 @@ func (pas *PaloAltoSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 				// Extract Vlan
 				vlanName := fmt.Sprintf("Vlan%d", subIface.Tag)
 				vlanSite, err := common.MatchVlanToSite(
-					pas.Ctx,
+					ctx,
 					nbi,
 					vlanName,
 					pas.SourceConfig.VlanSiteRelations,
@@ -202,7 +203,7 @@ func (pas *PaloAltoSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 					return fmt.Errorf("match vlan to site: %s", err)
 				}
 				vlanGroup, err := common.MatchVlanToGroup(
-					pas.Ctx,
+					ctx,
 					nbi,
 					vlanName,
 					vlanSite,
@@ -213,7 +214,7 @@ func (pas *PaloAltoSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 					return fmt.Errorf("match vlan to group: %s", err)
 				}
 				vlanTenant, err := common.MatchVlanToTenant(
-					pas.Ctx,
+					ctx,
 					nbi,
 					vlanName,
 					pas.SourceConfig.VlanTenantRelations,
@@ -233,7 +234,7 @@ func (pas *PaloAltoSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 					Tenant: vlanTenant,
 					Group:  vlanGroup,
 				}
-				subIfaceVlan, err = nbi.AddVlan(pas.Ctx, vlanStruct)
+				subIfaceVlan, err = nbi.AddVlan(ctx, vlanStruct)
 				if err != nil {
 					return fmt.Errorf("add vlan %+v: %s", vlanStruct, err)
 				}
@@ -258,12 +259,12 @@ func (pas *PaloAltoSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 				MTU:             subIface.Mtu,
 				Vdcs:            vdcs,
 			}
-			nbSubIface, err := nbi.AddInterface(pas.Ctx, interfaceStruct)
+			nbSubIface, err := nbi.AddInterface(ctx, interfaceStruct)
 			if err != nil {
 				return fmt.Errorf("add subinterface +%v: %s", interfaceStruct, err)
 			}
 			if len(subIface.StaticIps) > 0 {
-				pas.syncIPs(nbi, nbSubIface, subIface.StaticIps, subIfaceVlan)
+				pas.syncIPs(ctx, nbi, nbSubIface, subIface.StaticIps, subIfaceVlan)
 			}
 		}
 	}
@@ -273,6 +274,7 @@ func (pas *PaloAltoSource) syncInterfaces(nbi *inventory.NetboxInventory) error
 // syncIPs adds all of the given ips to the given nbIface. It also
 // Extracts prefixes from ips and connect them with prefix vlan.
 func (pas *PaloAltoSource) syncIPs(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	nbIface *objects.Interface,
 	ips []string,
@@ -285,7 +287,7 @@ func (pas *PaloAltoSource) syncIPs(
 			pas.SourceConfig.IgnoredSubnets,
 		) {
 			dnsName := utils.ReverseLookup(ipAddress)
-			_, err := nbi.AddIPAddress(pas.Ctx, &objects.IPAddress{
+			_, err := nbi.AddIPAddress(ctx, &objects.IPAddress{
 				NetboxObject: objects.NetboxObject{
 					Tags: pas.GetSourceTags(),
 					CustomFields: map[string]interface{}{
@@ -299,7 +301,7 @@ func (pas *PaloAltoSource) syncIPs(
 			})
 			if err != nil {
 				pas.Logger.Errorf(
-					pas.Ctx,
+					ctx,
 					"adding ip address %s failed with error: %s",
 					ipAddress,
 					err,
@@ -308,7 +310,7 @@ func (pas *PaloAltoSource) syncIPs(
 			}
 			prefix, mask, err := utils.GetPrefixAndMaskFromIPAddress(ipAddress)
 			if err != nil {
-				pas.Logger.Warningf(pas.Ctx, "extract prefix from address: %s", err)
+				pas.Logger.Warningf(ctx, "extract prefix from address: %s", err)
 			} else if mask != constants.MaxIPv4MaskBits {
 				var prefixTenant *objects.Tenant
 				if prefixVlan != nil {
@@ -319,9 +321,9 @@ func (pas *PaloAltoSource) syncIPs(
 					Tenant: prefixTenant,
 					Vlan:   prefixVlan,
 				}
-				_, err = nbi.AddPrefix(pas.Ctx, prefixStruct)
+				_, err = nbi.AddPrefix(ctx, prefixStruct)
 				if err != nil {
-					pas.Logger.Errorf(pas.Ctx, "adding prefix %+v: %s", prefixStruct, err)
+					pas.Logger.Errorf(ctx, "adding prefix %+v: %s", prefixStruct, err)
 				}
 			}
 		}
@@ -330,7 +332,7 @@ func (pas *PaloAltoSource) syncIPs(
 
 // syncSecurityZones syncs all security zones from palo alto as virtual device context in netbox.
 // They are all added as part of main paloalto firewall device.
-func (pas *PaloAltoSource) syncSecurityZones(nbi *inventory.NetboxInventory) error {
+func (pas *PaloAltoSource) syncSecurityZones(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	for _, securityZone := range pas.SecurityZones {
 		virtualDeviceContextStruct := &objects.VirtualDeviceContext{
 			NetboxObject: objects.NetboxObject{
@@ -340,7 +342,7 @@ func (pas *PaloAltoSource) syncSecurityZones(nbi *inventory.NetboxInventory) err
 			Device: pas.NBFirewall,
 			Status: &objects.VDCStatusActive,
 		}
-		_, err := nbi.AddVirtualDeviceContext(pas.Ctx, virtualDeviceContextStruct)
+		_, err := nbi.AddVirtualDeviceContext(ctx, virtualDeviceContextStruct)
 		if err != nil {
 			return fmt.Errorf("add VirtualDeviceContext %+v: %s", virtualDeviceContextStruct, err)
 		}
@@ -361,14 +363,14 @@ func (pas *PaloAltoSource) getVirtualDeviceContext(
 	return virtualDeviceContext
 }
 
-func (pas *PaloAltoSource) syncArpTable(nbi *inventory.NetboxInventory) error {
+func (pas *PaloAltoSource) syncArpTable(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	if !pas.SourceConfig.CollectArpData {
-		pas.Logger.Info(pas.Ctx, "skipping collecting of arp data")
+		pas.Logger.Info(ctx, "skipping collecting of arp data")
 		return nil
 	}
 
 	// We tag it with special tag for arp data.
-	arpTag, err := nbi.AddTag(pas.Ctx, &objects.Tag{
+	arpTag, err := nbi.AddTag(ctx, &objects.Tag{
 		Name:        constants.DefaultArpTagName,
 		Slug:        utils.Slugify(constants.DefaultArpTagName),
 		Color:       constants.DefaultArpTagColor,
@@ -392,7 +394,7 @@ func (pas *PaloAltoSource) syncArpTable(nbi *inventory.NetboxInventory) error {
 				defer wg.Done()
 				defer func() { <-guard }() // Release one spot in the semaphore
 
-				err := pas.syncArpEntry(nbi, entry, arpTag)
+				err := pas.syncArpEntry(ctx, nbi, entry, arpTag)
 				if err != nil {
 					errChan <- err
 				}
@@ -414,6 +416,7 @@ func (pas *PaloAltoSource) syncArpTable(nbi *inventory.NetboxInventory) error {
 }
 
 func (pas *PaloAltoSource) syncArpEntry(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	entry ArpEntry,
 	arpTag *objects.Tag,
@@ -445,7 +448,7 @@ func (pas *PaloAltoSource) syncArpEntry(
 			DNSName: dnsName,
 			Status:  &objects.IPAddressStatusActive,
 		}
-		_, err := nbi.AddIPAddress(pas.Ctx, ipAddressStruct)
+		_, err := nbi.AddIPAddress(ctx, ipAddressStruct)
 		if err != nil {
 			return fmt.Errorf("add arp ip address: %s", err)
 		}
@@ -1 +1,115 @@
 package paloalto
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/PaloAltoNetworks/pango/netw/interface/eth"
+	"github.com/PaloAltoNetworks/pango/netw/zone"
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox/sourcetest"
+	"github.com/src-doo/netbox-ssot/internal/netbox/inventory"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/parser"
+	"github.com/src-doo/netbox-ssot/internal/source/common"
+)
+
+// runPaloAlto runs netbox-ssot with a paloalto source, that is firewall
+// fw1 with the given security zones and interfaces, that are in the zones
+// in iface2Zone.
+func runPaloAlto(
+	t *testing.T,
+	netboxServer *fakenetbox.Server,
+	zones []string,
+	ifaces []string,
+	iface2Zone map[string]string,
+) {
+	t.Helper()
+	sourceConfig := &parser.SourceConfig{
+		Name: "paloalto",
+		Type: constants.PaloAlto,
+		// Devices must be assigned to a site
+		HostSiteRelations: map[string]string{"^fw": "HQ"},
+	}
+	sourcetest.Run(t, netboxServer, sourceConfig,
+		func(ctx context.Context, nbi *inventory.NetboxInventory, config common.Config) error {
+			pas := &PaloAltoSource{
+				Config: config,
+				SystemInfo: map[string]string{
+					"devicename": "fw1",
+					"serial":     "013201000001",
+					"model":      "PA-440",
+					"sw-version": "11.0.3",
+				},
+				SecurityZones:      make(map[string]zone.Entry),
+				Iface2SecurityZone: iface2Zone,
+				Ifaces:             make(map[string]eth.Entry),
+			}
+			for _, zoneName := range zones {
+				pas.SecurityZones[zoneName] = zone.Entry{Name: zoneName}
+			}
+			for _, ifaceName := range ifaces {
+				pas.Ifaces[ifaceName] = eth.Entry{Name: ifaceName, LinkSpeed: "1000", LinkDuplex: "full"}
+			}
+			return pas.Sync(ctx, nbi)
+		})
+}
+
+// objectNames returns sorted names of all interfaces and all virtual
+// device contexts in Netbox.
+func objectNames(t *testing.T, netboxServer *fakenetbox.Server) ([]string, []string) {
+	t.Helper()
+	ifaces, err := fakenetbox.Objects[objects.Interface](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vdcs, err := fakenetbox.Objects[objects.VirtualDeviceContext](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ifaceNames := make([]string, 0, len(ifaces))
+	for _, iface := range ifaces {
+		ifaceNames = append(ifaceNames, iface.Name)
+	}
+	vdcNames := make([]string, 0, len(vdcs))
+	for _, vdc := range vdcs {
+		vdcNames = append(vdcNames, vdc.Name)
+	}
+	slices.Sort(ifaceNames)
+	slices.Sort(vdcNames)
+	return ifaceNames, vdcNames
+}
+
+func TestPaloAltoSource_EndToEnd(t *testing.T) {
+	netboxServer := fakenetbox.NewServer()
+	defer netboxServer.Close()
+	iface2Zone := map[string]string{"ethernet1/1": "trust", "ethernet1/2": "untrust"}
+
+	runPaloAlto(t, netboxServer, []string{"trust", "untrust"}, []string{"ethernet1/1", "ethernet1/2"}, iface2Zone)
+	devices, err := fakenetbox.Objects[objects.Device](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 1 || devices[0].Name != "fw1" || devices[0].SerialNumber != "013201000001" {
+		t.Fatalf("devices = %v, want firewall fw1", devices)
+	}
+	ifaceNames, vdcNames := objectNames(t, netboxServer)
+	if want := []string{"ethernet1/1", "ethernet1/2"}; !slices.Equal(ifaceNames, want) {
+		t.Errorf("interfaces = %v, want %v", ifaceNames, want)
+	}
+	if want := []string{"trust", "untrust"}; !slices.Equal(vdcNames, want) {
+		t.Errorf("virtual device contexts = %v, want %v", vdcNames, want)
+	}
+
+	// Zone and its interface removed from the firewall are removed from Netbox
+	runPaloAlto(t, netboxServer, []string{"trust"}, []string{"ethernet1/1"}, iface2Zone)
+	ifaceNames, vdcNames = objectNames(t, netboxServer)
+	if want := []string{"ethernet1/1"}; !slices.Equal(ifaceNames, want) {
+		t.Errorf("interfaces = %v, want %v", ifaceNames, want)
+	}
+	if want := []string{"trust"}; !slices.Equal(vdcNames, want) {
+		t.Errorf("virtual device contexts = %v, want %v", vdcNames, want)
+	}
+}
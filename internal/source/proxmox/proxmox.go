@@ -31,15 +31,15 @@ type ProxmoxSource struct {
 }
 
 // Function that collects all data from Proxmox API and stores it in ProxmoxSource struct.
-func (ps *ProxmoxSource) Init() error {
+func (ps *ProxmoxSource) Init(ctx context.Context) error {
 	// Setup credentials for proxmox
 	credentials := proxmox.Credentials{
 		Username: ps.SourceConfig.Username,
-		Password: ps.SourceConfig.Password,
+		Password: ps.SourceConfig.Password.Value(),
 	}
 
 	// Create http client depending on ssl configuration
-	HTTPClient, err := utils.NewHTTPClient(ps.SourceConfig.ValidateCert, ps.SourceConfig.CAFile)
+	HTTPClient, err := utils.NewHTTPClient(ps.SourceConfig.HTTPClientConfig())
 	if err != nil {
 		return fmt.Errorf("error creating new HTTP client: %s", err)
 	}
@@ -51,7 +51,7 @@ func (ps *ProxmoxSource) Init() error {
 		proxmox.WithHTTPClient(HTTPClient),
 	)
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	initFuncs := []func(context.Context, *proxmox.Client) error{
@@ -66,7 +66,7 @@ func (ps *ProxmoxSource) Init() error {
 		}
 		duration := time.Since(startTime)
 		ps.Logger.Infof(
-			ps.Ctx,
+			ctx,
 			"Successfully initialized %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(initFunc, "init"),
 			duration.Seconds(),
@@ -77,8 +77,8 @@ func (ps *ProxmoxSource) Init() error {
 }
 
 // Function that syncs all collected data to Netbox inventory.
-func (ps *ProxmoxSource) Sync(nbi *inventory.NetboxInventory) error {
-	syncFunctions := []func(*inventory.NetboxInventory) error{
+func (ps *ProxmoxSource) Sync(ctx context.Context, nbi *inventory.NetboxInventory) error {
+	syncFunctions := []func(context.Context, *inventory.NetboxInventory) error{
 		ps.syncCluster,
 		ps.syncNodes,
 		ps.syncVMs,
@@ -86,13 +86,13 @@ func (ps *ProxmoxSource) Sync(nbi *inventory.NetboxInventory) error {
 	}
 	for _, syncFunc := range syncFunctions {
 		startTime := time.Now()
-		err := syncFunc(nbi)
+		err := syncFunc(ctx, nbi)
 		if err != nil {
 			return err
 		}
 		duration := time.Since(startTime)
 		ps.Logger.Infof(
-			ps.Ctx,
+			ctx,
 			"Successfully synced %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(syncFunc, "sync"),
 			duration.Seconds(),
@@ -1,6 +1,7 @@
 package proxmox
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -13,11 +14,11 @@ import (
 	"github.com/src-doo/netbox-ssot/internal/utils"
 )
 
-func (ps *ProxmoxSource) syncCluster(nbi *inventory.NetboxInventory) error {
+func (ps *ProxmoxSource) syncCluster(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	var clusterScopeType constants.ContentType
 	var clusterScopeID int
 	clusterSite, err := common.MatchClusterToSite(
-		ps.Ctx,
+		ctx,
 		nbi,
 		ps.Cluster.Name,
 		ps.SourceConfig.ClusterSiteRelations,
@@ -30,7 +31,7 @@ func (ps *ProxmoxSource) syncCluster(nbi *inventory.NetboxInventory) error {
 		clusterScopeID = clusterSite.ID
 	}
 	clusterTenant, err := common.MatchClusterToTenant(
-		ps.Ctx,
+		ctx,
 		nbi,
 		ps.Cluster.Name,
 		ps.SourceConfig.ClusterTenantRelations,
@@ -45,7 +46,7 @@ func (ps *ProxmoxSource) syncCluster(nbi *inventory.NetboxInventory) error {
 		Name: "Proxmox",
 		Slug: utils.Slugify("Proxmox"),
 	}
-	clusterType, err := nbi.AddClusterType(ps.Ctx, clusterTypeStruct)
+	clusterType, err := nbi.AddClusterType(ctx, clusterTypeStruct)
 	if err != nil {
 		return fmt.Errorf("add cluster type %+v: %s", clusterTypeStruct, err)
 	}
@@ -66,7 +67,7 @@ func (ps *ProxmoxSource) syncCluster(nbi *inventory.NetboxInventory) error {
 		ScopeID:   clusterScopeID,
 		Tenant:    clusterTenant,
 	}
-	nbCluster, err := nbi.AddCluster(ps.Ctx, clusterStruct)
+	nbCluster, err := nbi.AddCluster(ctx, clusterStruct)
 	if err != nil {
 		return fmt.Errorf("add cluster %+v: %s", clusterStruct, err)
 	}
@@ -74,7 +75,7 @@ func (ps *ProxmoxSource) syncCluster(nbi *inventory.NetboxInventory) error {
 	return nil
 }
 
-func (ps *ProxmoxSource) syncNodes(nbi *inventory.NetboxInventory) error {
+func (ps *ProxmoxSource) syncNodes(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	ps.NetboxNodes = make(map[string]*objects.Device, len(ps.Nodes))
 	for _, node := range ps.Nodes {
 		var hostSite *objects.Site
@@ -84,7 +85,7 @@ func (ps *ProxmoxSource) syncNodes(nbi *inventory.NetboxInventory) error {
 		var err error
 		if hostSite == nil {
 			hostSite, err = common.MatchHostToSite(
-				ps.Ctx,
+				ctx,
 				nbi,
 				node.Name,
 				ps.SourceConfig.HostSiteRelations,
@@ -94,7 +95,7 @@ func (ps *ProxmoxSource) syncNodes(nbi *inventory.NetboxInventory) error {
 			}
 		}
 		hostTenant, err := common.MatchHostToTenant(
-			ps.Ctx,
+			ctx,
 			nbi,
 			node.Name,
 			ps.SourceConfig.HostTenantRelations,
@@ -110,7 +111,7 @@ func (ps *ProxmoxSource) syncNodes(nbi *inventory.NetboxInventory) error {
 			Name: constants.DefaultManufacturer,
 			Slug: utils.Slugify(constants.DefaultManufacturer),
 		}
-		hostManufacturer, err := nbi.AddManufacturer(ps.Ctx, manufacturerStruct)
+		hostManufacturer, err := nbi.AddManufacturer(ctx, manufacturerStruct)
 		if err != nil {
 			return fmt.Errorf("adding host manufacturer %+v: %s", manufacturerStruct, err)
 		}
@@ -122,7 +123,7 @@ func (ps *ProxmoxSource) syncNodes(nbi *inventory.NetboxInventory) error {
 			Model:        constants.DefaultModel,
 			Slug:         utils.Slugify(hostManufacturer.Name + constants.DefaultModel),
 		}
-		hostDeviceType, err := nbi.AddDeviceType(ps.Ctx, deviceTypeStruct)
+		hostDeviceType, err := nbi.AddDeviceType(ctx, deviceTypeStruct)
 		if err != nil {
 			return fmt.Errorf("adding host device type %+v: %s", deviceTypeStruct, err)
 		}
@@ -132,7 +133,7 @@ func (ps *ProxmoxSource) syncNodes(nbi *inventory.NetboxInventory) error {
 		var hostRole *objects.DeviceRole
 		if len(ps.SourceConfig.HostRoleRelations) > 0 {
 			hostRole, err = common.MatchHostToRole(
-				ps.Ctx,
+				ctx,
 				nbi,
 				node.Name,
 				ps.SourceConfig.HostRoleRelations,
@@ -142,13 +143,13 @@ func (ps *ProxmoxSource) syncNodes(nbi *inventory.NetboxInventory) error {
 			}
 		}
 		if hostRole == nil {
-			hostRole, err = nbi.AddServerDeviceRole(ps.Ctx)
+			hostRole, err = nbi.AddServerDeviceRole(ctx)
 			if err != nil {
 				return fmt.Errorf("add server device role %s", err)
 			}
 		}
 
-		nbHost, err := nbi.AddDevice(ps.Ctx, &objects.Device{
+		nbHost, err := nbi.AddDevice(ctx, &objects.Device{
 			NetboxObject: objects.NetboxObject{
 				Tags: ps.GetSourceTags(),
 				CustomFields: map[string]interface{}{
@@ -171,7 +172,7 @@ func (ps *ProxmoxSource) syncNodes(nbi *inventory.NetboxInventory) error {
 		}
 		ps.NetboxNodes[node.Name] = nbHost
 
-		err = ps.syncNodeNetworks(nbi, node)
+		err = ps.syncNodeNetworks(ctx, nbi, node)
 		if err != nil {
 			return fmt.Errorf("sync node networks: %s", err)
 		}
@@ -180,6 +181,7 @@ func (ps *ProxmoxSource) syncNodes(nbi *inventory.NetboxInventory) error {
 }
 
 func (ps *ProxmoxSource) syncNodeNetworks(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	node *proxmox.Node,
 ) error {
@@ -193,14 +195,14 @@ func (ps *ProxmoxSource) syncNodeNetworks(
 		nbHost := ps.NetboxNodes[node.Name]
 		if utils.FilterInterfaceName(nodeNetwork.Iface, ps.SourceConfig.InterfaceFilter) {
 			ps.Logger.Debugf(
-				ps.Ctx,
+				ctx,
 				"interface %s is filtered out with interfaceFilter %s",
 				nodeNetwork.Iface,
 				ps.SourceConfig.InterfaceFilter,
 			)
 			continue
 		}
-		_, err := nbi.AddInterface(ps.Ctx, &objects.Interface{
+		_, err := nbi.AddInterface(ctx, &objects.Interface{
 			NetboxObject: objects.NetboxObject{
 				Tags: ps.GetSourceTags(),
 			},
@@ -220,7 +222,7 @@ func (ps *ProxmoxSource) syncNodeNetworks(
 }
 
 // Function that synces proxmox vms to the netbox inventory.
-func (ps *ProxmoxSource) syncVMs(nbi *inventory.NetboxInventory) error {
+func (ps *ProxmoxSource) syncVMs(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	const maxGoroutines = 50
 	guard := make(chan struct{}, maxGoroutines)
 	errChan := make(chan error, len(ps.Vms))
@@ -236,7 +238,7 @@ func (ps *ProxmoxSource) syncVMs(nbi *inventory.NetboxInventory) error {
 				defer wg.Done()
 				defer func() { <-guard }() // Release one spot in the semaphore
 
-				err := ps.syncVM(nbi, vm, nbHost)
+				err := ps.syncVM(ctx, nbi, vm, nbHost)
 				if err != nil {
 					errChan <- err
 				}
@@ -258,6 +260,7 @@ func (ps *ProxmoxSource) syncVMs(nbi *inventory.NetboxInventory) error {
 }
 
 func (ps *ProxmoxSource) syncVM(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	vm *proxmox.VirtualMachine,
 	nbHost *objects.Device,
@@ -278,20 +281,20 @@ func (ps *ProxmoxSource) syncVM(
 	}
 
 	// Determine VM tenant
-	vmTenant, err := common.MatchVMToTenant(ps.Ctx, nbi, vm.Name, ps.SourceConfig.VMTenantRelations)
+	vmTenant, err := common.MatchVMToTenant(ctx, nbi, vm.Name, ps.SourceConfig.VMTenantRelations)
 	if err != nil {
 		return fmt.Errorf("match vm to tenant: %s", err)
 	}
 
 	var vmRole *objects.DeviceRole
 	if len(ps.SourceConfig.VMRoleRelations) > 0 {
-		vmRole, err = common.MatchVMToRole(ps.Ctx, nbi, vm.Name, ps.SourceConfig.VMRoleRelations)
+		vmRole, err = common.MatchVMToRole(ctx, nbi, vm.Name, ps.SourceConfig.VMRoleRelations)
 		if err != nil {
 			return fmt.Errorf("match vm to role: %s", err)
 		}
 	}
 	if vmRole == nil {
-		vmRole, err = nbi.AddVMDeviceRole(ps.Ctx)
+		vmRole, err = nbi.AddVMDeviceRole(ctx)
 		if err != nil {
 			return fmt.Errorf("add vm device role: %s", err)
 		}
@@ -317,13 +320,13 @@ func (ps *ProxmoxSource) syncVM(
 		Name:    vm.Name,
 		Status:  vmStatus,
 	}
-	nbVM, err := nbi.AddVM(ps.Ctx, vmStruct)
+	nbVM, err := nbi.AddVM(ctx, vmStruct)
 	if err != nil {
 		return fmt.Errorf("add vm: %s", err)
 	}
 
 	// Sync VM networks
-	err = ps.syncVMNetworks(nbi, nbVM)
+	err = ps.syncVMNetworks(ctx, nbi, nbVM)
 	if err != nil {
 		return fmt.Errorf("sync vm networks: %s", err)
 	}
@@ -331,13 +334,13 @@ func (ps *ProxmoxSource) syncVM(
 	return nil
 }
 
-func (ps *ProxmoxSource) syncVMNetworks(nbi *inventory.NetboxInventory, nbVM *objects.VM) error {
+func (ps *ProxmoxSource) syncVMNetworks(ctx context.Context, nbi *inventory.NetboxInventory, nbVM *objects.VM) error {
 	vmIPv4Addresses := make([]*objects.IPAddress, 0)
 	vmIPv6Addresses := make([]*objects.IPAddress, 0)
 	for _, vmNetwork := range ps.VMIfaces[nbVM.Name] {
 		if utils.FilterInterfaceName(vmNetwork.Name, ps.SourceConfig.InterfaceFilter) {
 			ps.Logger.Debugf(
-				ps.Ctx,
+				ctx,
 				"interface %s is filtered out with interface filter %s",
 				vmNetwork.Name,
 				ps.SourceConfig.InterfaceFilter,
@@ -351,14 +354,14 @@ func (ps *ProxmoxSource) syncVMNetworks(nbi *inventory.NetboxInventory, nbVM *ob
 			Name: vmNetwork.Name,
 			VM:   nbVM,
 		}
-		nbVMIface, err := nbi.AddVMInterface(ps.Ctx, vmInterfaceStruct)
+		nbVMIface, err := nbi.AddVMInterface(ctx, vmInterfaceStruct)
 		if err != nil {
 			return fmt.Errorf("add vm interface %+v: %s", vmInterfaceStruct, err)
 		}
 		vmIfaceMAC := strings.ToUpper(vmNetwork.HardwareAddress)
 		if vmIfaceMAC != "" {
 			nbMACAddress, err := common.CreateMACAddressForObjectType(
-				ps.Ctx,
+				ctx,
 				nbi,
 				vmIfaceMAC,
 				nbVMIface,
@@ -366,7 +369,7 @@ func (ps *ProxmoxSource) syncVMNetworks(nbi *inventory.NetboxInventory, nbVM *ob
 			if err != nil {
 				return fmt.Errorf("create mac address for object type: %s", err)
 			}
-			if err = common.SetPrimaryMACForInterface(ps.Ctx, nbi, nbVMIface, nbMACAddress); err != nil {
+			if err = common.SetPrimaryMACForInterface(ctx, nbi, nbVMIface, nbMACAddress); err != nil {
 				return fmt.Errorf("set primary mac for interface %+v: %s", nbVMIface, err)
 			}
 		}
@@ -392,10 +395,10 @@ func (ps *ProxmoxSource) syncVMNetworks(nbi *inventory.NetboxInventory, nbVM *ob
 					AssignedObjectID:   nbVMIface.ID,
 					Status:             &objects.IPAddressStatusActive, //TODO: this is hardcoded
 				}
-				nbIPAddress, err := nbi.AddIPAddress(ps.Ctx, ipAddressStruct)
+				nbIPAddress, err := nbi.AddIPAddress(ctx, ipAddressStruct)
 				if err != nil {
 					ps.Logger.Warningf(
-						ps.Ctx,
+						ctx,
 						"failed adding ip address %s with: %s",
 						ipAddressStruct,
 						err,
@@ -409,7 +412,7 @@ func (ps *ProxmoxSource) syncVMNetworks(nbi *inventory.NetboxInventory, nbVM *ob
 					vmIPv6Addresses = append(vmIPv6Addresses, nbIPAddress)
 				default:
 					ps.Logger.Warningf(
-						ps.Ctx,
+						ctx,
 						"wrong IP type: %s for ip %s",
 						ipAddress.IPAddressType,
 						ipAddress.IPAddress,
@@ -418,18 +421,18 @@ func (ps *ProxmoxSource) syncVMNetworks(nbi *inventory.NetboxInventory, nbVM *ob
 				prefix, mask, err := utils.GetPrefixAndMaskFromIPAddress(nbIPAddress.Address)
 				if err != nil {
 					ps.Logger.Warningf(
-						ps.Ctx,
+						ctx,
 						"failed extracting prefix from ip address %s with: %s",
 						nbIPAddress.Address,
 						err,
 					)
 				} else if (ipAddress.IPAddressType == "ipv4" && mask != constants.MaxIPv4MaskBits) ||
 					(ipAddress.IPAddressType == "ipv6" && mask != constants.MaxIPv6MaskBits) {
-					_, err = nbi.AddPrefix(ps.Ctx, &objects.Prefix{
+					_, err = nbi.AddPrefix(ctx, &objects.Prefix{
 						Prefix: prefix,
 					})
 					if err != nil {
-						ps.Logger.Errorf(ps.Ctx, "adding prefix: %s", err)
+						ps.Logger.Errorf(ctx, "adding prefix: %s", err)
 					}
 				}
 			}
@@ -446,7 +449,7 @@ func (ps *ProxmoxSource) syncVMNetworks(nbi *inventory.NetboxInventory, nbVM *ob
 			// TODO add criteria for primary IPv6
 			nbVMCopy.PrimaryIPv6 = vmIPv6Addresses[0]
 		}
-		_, err := nbi.AddVM(ps.Ctx, &nbVMCopy)
+		_, err := nbi.AddVM(ctx, &nbVMCopy)
 		if err != nil {
 			return fmt.Errorf("updating vm primary ip: %s", err)
 		}
@@ -455,10 +458,10 @@ func (ps *ProxmoxSource) syncVMNetworks(nbi *inventory.NetboxInventory, nbVM *ob
 }
 
 // Function that synces proxmox containers to the netbox inventory.
-func (ps *ProxmoxSource) syncContainers(nbi *inventory.NetboxInventory) error {
+func (ps *ProxmoxSource) syncContainers(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	if len(ps.Containers) > 0 {
 		// Create container role
-		containerRole, err := nbi.AddContainerDeviceRole(ps.Ctx)
+		containerRole, err := nbi.AddContainerDeviceRole(ctx)
 		if err != nil {
 			return fmt.Errorf("create container role: %s", err)
 		}
@@ -472,7 +475,7 @@ func (ps *ProxmoxSource) syncContainers(nbi *inventory.NetboxInventory) error {
 				}
 				// Determine Container tenant
 				vmTenant, err := common.MatchVMToTenant(
-					ps.Ctx,
+					ctx,
 					nbi,
 					container.Name,
 					ps.SourceConfig.VMTenantRelations,
@@ -480,7 +483,7 @@ func (ps *ProxmoxSource) syncContainers(nbi *inventory.NetboxInventory) error {
 				if err != nil {
 					return fmt.Errorf("match vm to tenant: %s", err)
 				}
-				nbContainer, err := nbi.AddVM(ps.Ctx, &objects.VM{
+				nbContainer, err := nbi.AddVM(ctx, &objects.VM{
 					NetboxObject: objects.NetboxObject{
 						Tags: ps.GetSourceTags(),
 						CustomFields: map[string]interface{}{
@@ -502,7 +505,7 @@ func (ps *ProxmoxSource) syncContainers(nbi *inventory.NetboxInventory) error {
 					return fmt.Errorf("new vm: %s", err)
 				}
 
-				err = ps.syncContainerNetworks(nbi, nbContainer)
+				err = ps.syncContainerNetworks(ctx, nbi, nbContainer)
 				if err != nil {
 					return fmt.Errorf("sync container networks: %s", err)
 				}
@@ -513,6 +516,7 @@ func (ps *ProxmoxSource) syncContainers(nbi *inventory.NetboxInventory) error {
 }
 
 func (ps *ProxmoxSource) syncContainerNetworks(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	nbContainer *objects.VM,
 ) error {
@@ -521,7 +525,7 @@ func (ps *ProxmoxSource) syncContainerNetworks(
 	for _, containerIface := range ps.ContainerIfaces[nbContainer.Name] {
 		if utils.FilterInterfaceName(containerIface.Name, ps.SourceConfig.InterfaceFilter) {
 			ps.Logger.Debugf(
-				ps.Ctx,
+				ctx,
 				"interface %s is filtered out with interface filter %s",
 				containerIface.Name,
 				ps.SourceConfig.InterfaceFilter,
@@ -535,14 +539,14 @@ func (ps *ProxmoxSource) syncContainerNetworks(
 			Name: containerIface.Name,
 			VM:   nbContainer,
 		}
-		nbVMIface, err := nbi.AddVMInterface(ps.Ctx, vmIfaceStruct)
+		nbVMIface, err := nbi.AddVMInterface(ctx, vmIfaceStruct)
 		if err != nil {
 			return fmt.Errorf("add vm interface: %s", err)
 		}
 		vmIfaceMAC := strings.ToUpper(containerIface.HWAddr)
 		if vmIfaceMAC != "" {
 			nbMACAddress, err := common.CreateMACAddressForObjectType(
-				ps.Ctx,
+				ctx,
 				nbi,
 				vmIfaceMAC,
 				nbVMIface,
@@ -550,7 +554,7 @@ func (ps *ProxmoxSource) syncContainerNetworks(
 			if err != nil {
 				return fmt.Errorf("create mac address for container iface: %s", err)
 			}
-			if err = common.SetPrimaryMACForInterface(ps.Ctx, nbi, nbVMIface, nbMACAddress); err != nil {
+			if err = common.SetPrimaryMACForInterface(ctx, nbi, nbVMIface, nbMACAddress); err != nil {
 				return fmt.Errorf("set primary mac for container iface %+v: %s", nbVMIface, err)
 			}
 		}
@@ -563,7 +567,7 @@ func (ps *ProxmoxSource) syncContainerNetworks(
 		) {
 			// Check if IPv4 address is present
 			if containerIface.Inet != "" {
-				nbIPAddress, err := nbi.AddIPAddress(ps.Ctx, &objects.IPAddress{
+				nbIPAddress, err := nbi.AddIPAddress(ctx, &objects.IPAddress{
 					NetboxObject: objects.NetboxObject{
 						Tags: ps.GetSourceTags(),
 						CustomFields: map[string]interface{}{
@@ -578,18 +582,18 @@ func (ps *ProxmoxSource) syncContainerNetworks(
 					Status:             &objects.IPAddressStatusActive, //TODO: this is hardcoded
 				})
 				if err != nil {
-					ps.Logger.Warningf(ps.Ctx, "add ip address: %s", err)
+					ps.Logger.Warningf(ctx, "add ip address: %s", err)
 				} else {
 					vmIPv4Addresses = append(vmIPv4Addresses, nbIPAddress)
 					prefix, mask, err := utils.GetPrefixAndMaskFromIPAddress(nbIPAddress.Address)
 					if err != nil {
-						ps.Logger.Warningf(ps.Ctx, "extract prefix from ip address: %s", err)
+						ps.Logger.Warningf(ctx, "extract prefix from ip address: %s", err)
 					} else if mask != constants.MaxIPv4MaskBits {
-						_, err = nbi.AddPrefix(ps.Ctx, &objects.Prefix{
+						_, err = nbi.AddPrefix(ctx, &objects.Prefix{
 							Prefix: prefix,
 						})
 						if err != nil {
-							ps.Logger.Errorf(ps.Ctx, "adding prefix: %s", err)
+							ps.Logger.Errorf(ctx, "adding prefix: %s", err)
 						}
 					}
 				}
@@ -603,7 +607,7 @@ func (ps *ProxmoxSource) syncContainerNetworks(
 		) {
 			if containerIface.Inet6 != "" {
 				containerIface.Inet6 = utils.RemoveZoneIndexFromIPAddress(containerIface.Inet6)
-				nbIPAddress, err := nbi.AddIPAddress(ps.Ctx, &objects.IPAddress{
+				nbIPAddress, err := nbi.AddIPAddress(ctx, &objects.IPAddress{
 					NetboxObject: objects.NetboxObject{
 						Tags: ps.GetSourceTags(),
 						CustomFields: map[string]interface{}{
@@ -618,7 +622,7 @@ func (ps *ProxmoxSource) syncContainerNetworks(
 					Status:             &objects.IPAddressStatusActive, //TODO: this is hardcoded
 				})
 				if err != nil {
-					ps.Logger.Warningf(ps.Ctx, "add ipv6 address: %s", err)
+					ps.Logger.Warningf(ctx, "add ipv6 address: %s", err)
 				} else {
 					vmIPv6Addresses = append(vmIPv6Addresses, nbIPAddress)
 				}
@@ -636,7 +640,7 @@ func (ps *ProxmoxSource) syncContainerNetworks(
 			// TODO add criteria for primary IPv6
 			nbContainerCopy.PrimaryIPv6 = vmIPv6Addresses[0]
 		}
-		_, err := nbi.AddVM(ps.Ctx, &nbContainerCopy)
+		_, err := nbi.AddVM(ctx, &nbContainerCopy)
 		if err != nil {
 			return fmt.Errorf("updating vm primary ip: %s", err)
 		}
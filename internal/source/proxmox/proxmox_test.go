@@ -1 +1,94 @@
 package proxmox
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	"github.com/luthermonson/go-proxmox"
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox/sourcetest"
+	"github.com/src-doo/netbox-ssot/internal/netbox/inventory"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/parser"
+	"github.com/src-doo/netbox-ssot/internal/source/common"
+)
+
+// runProxmox runs netbox-ssot with a proxmox source, that is cluster pve
+// with node pve1 running the given vms.
+func runProxmox(t *testing.T, netboxServer *fakenetbox.Server, vms ...*proxmox.VirtualMachine) {
+	t.Helper()
+	sourceConfig := &parser.SourceConfig{
+		Name: "proxmox",
+		Type: constants.Proxmox,
+		// Devices must be assigned to a site
+		HostSiteRelations: map[string]string{"^pve": "HQ"},
+	}
+	sourcetest.Run(t, netboxServer, sourceConfig,
+		func(ctx context.Context, nbi *inventory.NetboxInventory, config common.Config) error {
+			ps := &ProxmoxSource{
+				Config:  config,
+				Cluster: &proxmox.Cluster{Name: "pve"},
+				Nodes:   []*proxmox.Node{{Name: "pve1"}},
+				Vms:     map[string][]*proxmox.VirtualMachine{"pve1": vms},
+			}
+			return ps.Sync(ctx, nbi)
+		})
+}
+
+// vmHosts returns hosts of all vms in Netbox by vm names.
+func vmHosts(t *testing.T, netboxServer *fakenetbox.Server) map[string]string {
+	t.Helper()
+	vms, err := fakenetbox.Objects[objects.VM](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := make(map[string]string, len(vms))
+	for _, vm := range vms {
+		hosts[vm.Name] = ""
+		if vm.Host != nil {
+			hosts[vm.Name] = vm.Host.Name
+		}
+	}
+	return hosts
+}
+
+func TestProxmoxSource_EndToEnd(t *testing.T) {
+	netboxServer := fakenetbox.NewServer()
+	defer netboxServer.Close()
+	vms := []*proxmox.VirtualMachine{
+		{VMID: 100, Name: "vm1", Status: "running"},
+		{VMID: 101, Name: "vm2", Status: "stopped"},
+	}
+
+	runProxmox(t, netboxServer, vms...)
+	want := map[string]string{"vm1": "pve1", "vm2": "pve1"}
+	if got := vmHosts(t, netboxServer); !maps.Equal(got, want) {
+		t.Errorf("vms = %v, want %v", got, want)
+	}
+
+	// VM removed from proxmox is removed from Netbox
+	runProxmox(t, netboxServer, vms[:1]...)
+	want = map[string]string{"vm1": "pve1"}
+	if got := vmHosts(t, netboxServer); !maps.Equal(got, want) {
+		t.Errorf("vms = %v, want %v", got, want)
+	}
+}
+
+func TestProxmoxSource_EndToEnd_SourceID(t *testing.T) {
+	netboxServer := fakenetbox.NewServer()
+	defer netboxServer.Close()
+	runProxmox(t, netboxServer, &proxmox.VirtualMachine{VMID: 100, Name: "vm1", Status: "running"})
+
+	// VMID identifies the vm, so the renamed vm is patched instead of
+	// created again
+	runProxmox(t, netboxServer, &proxmox.VirtualMachine{VMID: 100, Name: "vm1-renamed", Status: "running"})
+	vms, err := fakenetbox.Objects[objects.VM](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vms) != 1 || vms[0].ID != 1 || vms[0].Name != "vm1-renamed" {
+		t.Errorf("vms = %v, want vm 1 renamed", vms)
+	}
+}
@@ -59,7 +59,6 @@ func NewSource(
 		SourceConfig:  config,
 		SourceNameTag: sourceNameTag,
 		SourceTypeTag: sourceTypeTag,
-		Ctx:           ctx,
 		CAFile:        config.CAFile,
 	}
 
@@ -84,15 +84,33 @@ type HostPortgroupData struct {
 	nics    []string
 }
 
-func (vc *VmwareSource) Init() error {
+// configureSoapTransport sets the client certificate and the proxy from the
+// config on the transport of the soap client. Soap client builds its own
+// transport, which uses proxy from the environment (e.g. HTTPS_PROXY), so
+// the proxy is only replaced when it is configured.
+func configureSoapTransport(soapClient *soap.Client, config utils.HTTPClientConfig) error {
+	transport, err := utils.NewHTTPTransport(config)
+	if err != nil {
+		return fmt.Errorf("create http transport: %s", err)
+	}
+	if config.ProxyURL != "" {
+		soapClient.DefaultTransport().Proxy = transport.Proxy
+	}
+	for _, clientCert := range transport.TLSClientConfig.Certificates {
+		soapClient.SetCertificate(clientCert)
+	}
+	return nil
+}
+
+func (vc *VmwareSource) Init(ctx context.Context) error {
 	// Initialize the connection
-	vc.Logger.Debug(vc.Ctx, "vmware source ", vc.SourceConfig.Name)
-	ctx, cancel := context.WithCancel(context.Background())
+	vc.Logger.Debug(ctx, "vmware source ", vc.SourceConfig.Name)
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	// Correctly handle backslashes in username and password
 	escapedUsername := url.PathEscape(vc.SourceConfig.Username)
-	escapedPassword := url.PathEscape(vc.SourceConfig.Password)
+	escapedPassword := url.PathEscape(vc.SourceConfig.Password.Value())
 
 	vcURL := fmt.Sprintf(
 		"%s://%s:%s@%s:%d/sdk",
@@ -116,6 +134,9 @@ func (vc *VmwareSource) Init() error {
 			return fmt.Errorf("set root CAs: %s", err)
 		}
 	}
+	if err := configureSoapTransport(soapClient, vc.SourceConfig.HTTPClientConfig()); err != nil {
+		return err
+	}
 	vim25Client, err := vim25.NewClient(ctx, soapClient)
 	if err != nil {
 		return fmt.Errorf("failed creating a govmomi client with an error: %s", err)
@@ -152,7 +173,7 @@ func (vc *VmwareSource) Init() error {
 	}
 
 	vc.Logger.Debug(
-		vc.Ctx,
+		ctx,
 		"Connection to vmware source ",
 		vc.SourceConfig.Hostname,
 		" established successfully",
@@ -195,7 +216,7 @@ func (vc *VmwareSource) Init() error {
 		}
 		duration := time.Since(startTime)
 		vc.Logger.Infof(
-			vc.Ctx,
+			ctx,
 			"Successfully initialized %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(initFunc, "init"),
 			duration.Seconds(),
@@ -205,7 +226,7 @@ func (vc *VmwareSource) Init() error {
 	// Ensure the containerView is destroyed after we are done with it
 	err = containerView.Destroy(ctx)
 	if err != nil {
-		vc.Logger.Errorf(vc.Ctx, "failed destroying containerView: %s", err)
+		vc.Logger.Errorf(ctx, "failed destroying containerView: %s", err)
 	}
 
 	err = sessionManager.Logout(ctx)
@@ -218,7 +239,7 @@ func (vc *VmwareSource) Init() error {
 	}
 
 	vc.Logger.Debug(
-		vc.Ctx,
+		ctx,
 		"Successfully closed connection to vmware host: ",
 		vc.SourceConfig.Hostname,
 	)
@@ -227,8 +248,8 @@ func (vc *VmwareSource) Init() error {
 }
 
 // Function that syncs all data from oVirt to Netbox.
-func (vc *VmwareSource) Sync(nbi *inventory.NetboxInventory) error {
-	syncFunctions := []func(*inventory.NetboxInventory) error{
+func (vc *VmwareSource) Sync(ctx context.Context, nbi *inventory.NetboxInventory) error {
+	syncFunctions := []func(context.Context, *inventory.NetboxInventory) error{
 		vc.syncTags,
 		vc.syncNetworks,
 		vc.syncDatacenters,
@@ -238,13 +259,13 @@ func (vc *VmwareSource) Sync(nbi *inventory.NetboxInventory) error {
 	}
 	for _, syncFunc := range syncFunctions {
 		startTime := time.Now()
-		err := syncFunc(nbi)
+		err := syncFunc(ctx, nbi)
 		if err != nil {
 			return err
 		}
 		duration := time.Since(startTime)
 		vc.Logger.Infof(
-			vc.Ctx,
+			ctx,
 			"Successfully synced %s in %f seconds",
 			utils.ExtractFunctionNameWithTrimPrefix(syncFunc, "sync"),
 			duration.Seconds(),
@@ -330,7 +351,7 @@ func (vc *VmwareSource) CreateObjectTagsRelation(
 	defer func() {
 		err := restClient.Logout(ctx)
 		if err != nil {
-			vc.Logger.Errorf(vc.Ctx, "failed logging out from rest client: %s", err)
+			vc.Logger.Errorf(ctx, "failed logging out from rest client: %s", err)
 		}
 	}()
 
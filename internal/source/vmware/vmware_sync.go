@@ -1,6 +1,7 @@
 package vmware
 
 import (
+	"context"
 	"fmt"
 	"slices"
 	"strconv"
@@ -17,7 +18,7 @@ import (
 	"github.com/vmware/govmomi/vim25/types"
 )
 
-func (vc *VmwareSource) syncTags(nbi *inventory.NetboxInventory) error {
+func (vc *VmwareSource) syncTags(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	objectNames2NBTags := make(map[string][]*objects.Tag)
 	for objectName, tags := range vc.Object2Tags {
 		for _, tag := range tags {
@@ -27,7 +28,7 @@ func (vc *VmwareSource) syncTags(nbi *inventory.NetboxInventory) error {
 			} else {
 				description = "Tag synced from vmware"
 			}
-			nbTag, err := nbi.AddTag(vc.Ctx, &objects.Tag{
+			nbTag, err := nbi.AddTag(ctx, &objects.Tag{
 				Name:        tag.Name,
 				Slug:        utils.Slugify(tag.Name),
 				Color:       constants.ColorGreen,
@@ -43,12 +44,12 @@ func (vc *VmwareSource) syncTags(nbi *inventory.NetboxInventory) error {
 	return nil
 }
 
-func (vc *VmwareSource) syncNetworks(nbi *inventory.NetboxInventory) error {
+func (vc *VmwareSource) syncNetworks(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	for dvpgID, dvpg := range vc.Networks.DistributedVirtualPortgroups {
 		// TODO: currently we are syncing only vlans
 		// Get vlanGroup from relations
 		vlanSite, err := common.MatchVlanToSite(
-			vc.Ctx,
+			ctx,
 			nbi,
 			dvpg.Name,
 			vc.SourceConfig.VlanSiteRelations,
@@ -57,7 +58,7 @@ func (vc *VmwareSource) syncNetworks(nbi *inventory.NetboxInventory) error {
 			return fmt.Errorf("match vlan to site: %s", err)
 		}
 		vlanGroup, err := common.MatchVlanToGroup(
-			vc.Ctx,
+			ctx,
 			nbi,
 			dvpg.Name,
 			vlanSite,
@@ -69,7 +70,7 @@ func (vc *VmwareSource) syncNetworks(nbi *inventory.NetboxInventory) error {
 		}
 		// Get tenant from relations
 		vlanTenant, err := common.MatchVlanToTenant(
-			vc.Ctx,
+			ctx,
 			nbi,
 			dvpg.Name,
 			vc.SourceConfig.VlanTenantRelations,
@@ -97,7 +98,7 @@ func (vc *VmwareSource) syncNetworks(nbi *inventory.NetboxInventory) error {
 				Status: &objects.VlanStatusActive,
 				Tenant: vlanTenant,
 			}
-			_, err := nbi.AddVlan(vc.Ctx, vlanStruct)
+			_, err := nbi.AddVlan(ctx, vlanStruct)
 			if err != nil {
 				return fmt.Errorf("add vlan %+v: %s", vlanStruct, err)
 			}
@@ -106,13 +107,13 @@ func (vc *VmwareSource) syncNetworks(nbi *inventory.NetboxInventory) error {
 	return nil
 }
 
-func (vc *VmwareSource) syncDatacenters(nbi *inventory.NetboxInventory) error {
+func (vc *VmwareSource) syncDatacenters(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	for dcID, dc := range vc.DataCenters {
 		netboxClusterGroupName := dc.Name
 		if mappedClusterGroupName, ok := vc.SourceConfig.DatacenterClusterGroupRelations[netboxClusterGroupName]; ok {
 			netboxClusterGroupName = mappedClusterGroupName
 			vc.Logger.Debugf(
-				vc.Ctx,
+				ctx,
 				"mapping datacenter name %s to cluster group name %s",
 				dc.Name,
 				mappedClusterGroupName,
@@ -129,7 +130,7 @@ func (vc *VmwareSource) syncDatacenters(nbi *inventory.NetboxInventory) error {
 			Name: netboxClusterGroupName,
 			Slug: utils.Slugify(netboxClusterGroupName),
 		}
-		_, err := nbi.AddClusterGroup(vc.Ctx, clusterGroupStruct)
+		_, err := nbi.AddClusterGroup(ctx, clusterGroupStruct)
 		if err != nil {
 			return fmt.Errorf(
 				"failed to add vmware datacenter %+v as Netbox ClusterGroup: %v",
@@ -141,8 +142,8 @@ func (vc *VmwareSource) syncDatacenters(nbi *inventory.NetboxInventory) error {
 	return nil
 }
 
-func (vc *VmwareSource) syncClusters(nbi *inventory.NetboxInventory) error {
-	clusterType, err := vc.createVmwareClusterType(nbi)
+func (vc *VmwareSource) syncClusters(ctx context.Context, nbi *inventory.NetboxInventory) error {
+	clusterType, err := vc.createVmwareClusterType(ctx, nbi)
 	if err != nil {
 		return fmt.Errorf("failed to add vmware ClusterType: %v", err)
 	}
@@ -162,7 +163,7 @@ func (vc *VmwareSource) syncClusters(nbi *inventory.NetboxInventory) error {
 		var clusterScopeType constants.ContentType
 		var clusterScopeID int
 		clusterSite, err := common.MatchClusterToSite(
-			vc.Ctx,
+			ctx,
 			nbi,
 			clusterName,
 			vc.SourceConfig.ClusterSiteRelations,
@@ -176,7 +177,7 @@ func (vc *VmwareSource) syncClusters(nbi *inventory.NetboxInventory) error {
 		}
 
 		clusterTenant, err := common.MatchClusterToTenant(
-			vc.Ctx,
+			ctx,
 			nbi,
 			clusterName,
 			vc.SourceConfig.ClusterTenantRelations,
@@ -200,7 +201,7 @@ func (vc *VmwareSource) syncClusters(nbi *inventory.NetboxInventory) error {
 			ScopeID:   clusterScopeID,
 			Tenant:    clusterTenant,
 		}
-		_, err = nbi.AddCluster(vc.Ctx, clusterStruct)
+		_, err = nbi.AddCluster(ctx, clusterStruct)
 		if err != nil {
 			return fmt.Errorf(
 				"failed to add vmware cluster %+v as Netbox cluster: %v",
@@ -214,13 +215,13 @@ func (vc *VmwareSource) syncClusters(nbi *inventory.NetboxInventory) error {
 
 // Host in vmware is a represented as device in netbox with a
 // custom role Server.
-func (vc *VmwareSource) syncHosts(nbi *inventory.NetboxInventory) error {
+func (vc *VmwareSource) syncHosts(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	for hostID, host := range vc.Hosts {
 		var err error
 		hostName := host.Name
 
 		hostSite, err := common.MatchHostToSite(
-			vc.Ctx,
+			ctx,
 			nbi,
 			hostName,
 			vc.SourceConfig.HostSiteRelations,
@@ -230,7 +231,7 @@ func (vc *VmwareSource) syncHosts(nbi *inventory.NetboxInventory) error {
 		}
 
 		hostTenant, err := common.MatchHostToTenant(
-			vc.Ctx,
+			ctx,
 			nbi,
 			hostName,
 			vc.SourceConfig.HostTenantRelations,
@@ -242,7 +243,7 @@ func (vc *VmwareSource) syncHosts(nbi *inventory.NetboxInventory) error {
 		hostCluster, _ := nbi.GetCluster(vc.Clusters[vc.Host2Cluster[hostID]].Name)
 		if hostCluster == nil {
 			// Create a hypothetical cluster https://github.com/src-doo/netbox-ssot/issues/141
-			hostCluster, err = vc.createHypotheticalCluster(nbi, hostName, hostSite, hostTenant)
+			hostCluster, err = vc.createHypotheticalCluster(ctx, nbi, hostName, hostSite, hostTenant)
 			if err != nil {
 				return fmt.Errorf("add hypothetical cluster: %s", err)
 			}
@@ -282,7 +283,7 @@ func (vc *VmwareSource) syncHosts(nbi *inventory.NetboxInventory) error {
 			Name: hostManufacturerName,
 			Slug: utils.Slugify(hostManufacturerName),
 		}
-		hostManufacturer, err := nbi.AddManufacturer(vc.Ctx, manufacturerStruct)
+		hostManufacturer, err := nbi.AddManufacturer(ctx, manufacturerStruct)
 		if err != nil {
 			return fmt.Errorf(
 				"failed adding vmware Manufacturer %v with error: %s",
@@ -297,7 +298,7 @@ func (vc *VmwareSource) syncHosts(nbi *inventory.NetboxInventory) error {
 			Model:        hostModel,
 			Slug:         deviceSlug,
 		}
-		hostDeviceType, err := nbi.AddDeviceType(vc.Ctx, deviceTypeStruct)
+		hostDeviceType, err := nbi.AddDeviceType(ctx, deviceTypeStruct)
 		if err != nil {
 			return fmt.Errorf(
 				"failed adding vmware DeviceType %+v with error: %s",
@@ -350,7 +351,7 @@ func (vc *VmwareSource) syncHosts(nbi *inventory.NetboxInventory) error {
 			Name: platformName,
 			Slug: utils.Slugify(platformName),
 		}
-		hostPlatform, err = nbi.AddPlatform(vc.Ctx, platformStruct)
+		hostPlatform, err = nbi.AddPlatform(ctx, platformStruct)
 		if err != nil {
 			return fmt.Errorf(
 				"failed adding vmware Platform %+v with error: %s",
@@ -364,7 +365,7 @@ func (vc *VmwareSource) syncHosts(nbi *inventory.NetboxInventory) error {
 		var hostRole *objects.DeviceRole
 		if len(vc.SourceConfig.HostRoleRelations) > 0 {
 			hostRole, err = common.MatchHostToRole(
-				vc.Ctx,
+				ctx,
 				nbi,
 				hostName,
 				vc.SourceConfig.HostRoleRelations,
@@ -374,7 +375,7 @@ func (vc *VmwareSource) syncHosts(nbi *inventory.NetboxInventory) error {
 			}
 		}
 		if hostRole == nil {
-			hostRole, err = nbi.AddServerDeviceRole(vc.Ctx)
+			hostRole, err = nbi.AddServerDeviceRole(ctx)
 			if err != nil {
 				return fmt.Errorf("add server device role %s", err)
 			}
@@ -403,13 +404,13 @@ func (vc *VmwareSource) syncHosts(nbi *inventory.NetboxInventory) error {
 			AssetTag:     assetTag,
 			DeviceType:   hostDeviceType,
 		}
-		nbHost, err := nbi.AddDevice(vc.Ctx, hostStruct)
+		nbHost, err := nbi.AddDevice(ctx, hostStruct)
 		if err != nil {
 			return fmt.Errorf("failed to add vmware host %+v with error: %v", hostStruct, err)
 		}
 
 		// We also need to sync nics separately, because nic is a separate object in netbox
-		err = vc.syncHostNics(nbi, host, nbHost, deviceData)
+		err = vc.syncHostNics(ctx, nbi, host, nbHost, deviceData)
 		if err != nil {
 			return fmt.Errorf("failed to sync vmware host %s nics with error: %v", host.Name, err)
 		}
@@ -418,6 +419,7 @@ func (vc *VmwareSource) syncHosts(nbi *inventory.NetboxInventory) error {
 }
 
 func (vc *VmwareSource) syncHostNics(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	vcHost mo.HostSystem,
 	nbHost *objects.Device,
@@ -429,19 +431,19 @@ func (vc *VmwareSource) syncHostNics(
 	hostIPv6Addresses := []*objects.IPAddress{}
 
 	// Sync host's physical interfaces
-	err := vc.syncHostPhysicalNics(nbi, vcHost, nbHost, deviceData)
+	err := vc.syncHostPhysicalNics(ctx, nbi, vcHost, nbHost, deviceData)
 	if err != nil {
 		return fmt.Errorf("physical interfaces sync: %s", err)
 	}
 
 	// Sync host's virtual interfaces
-	err = vc.syncHostVirtualNics(nbi, vcHost, nbHost, hostIPv4Addresses, hostIPv6Addresses)
+	err = vc.syncHostVirtualNics(ctx, nbi, vcHost, nbHost, hostIPv4Addresses, hostIPv6Addresses)
 	if err != nil {
 		return fmt.Errorf("virtual interfaces sync: %s", err)
 	}
 
 	// Set host's private ip address from collected ips
-	err = vc.setHostPrimaryIPAddress(nbi, nbHost, hostIPv4Addresses, hostIPv6Addresses)
+	err = vc.setHostPrimaryIPAddress(ctx, nbi, nbHost, hostIPv4Addresses, hostIPv6Addresses)
 	if err != nil {
 		return fmt.Errorf("adding host primary ip addresses: %s", err)
 	}
@@ -450,6 +452,7 @@ func (vc *VmwareSource) syncHostNics(
 }
 
 func (vc *VmwareSource) syncHostPhysicalNics(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	vcHost mo.HostSystem,
 	nbHost *objects.Device,
@@ -459,7 +462,7 @@ func (vc *VmwareSource) syncHostPhysicalNics(
 	if vcHost.Config != nil && vcHost.Config.Network != nil && vcHost.Config.Network.Pnic != nil {
 		for _, pnic := range vcHost.Config.Network.Pnic {
 			// Fetch host pnic data
-			hostPnic, macAddress, err := vc.collectHostPhysicalNicData(nbi, nbHost, pnic, deviceData)
+			hostPnic, macAddress, err := vc.collectHostPhysicalNicData(ctx, nbi, nbHost, pnic, deviceData)
 			if err != nil {
 				return err
 			}
@@ -467,7 +470,7 @@ func (vc *VmwareSource) syncHostPhysicalNics(
 			// Filter host pnic
 			if utils.FilterInterfaceName(hostPnic.Name, vc.SourceConfig.InterfaceFilter) {
 				vc.Logger.Debugf(
-					vc.Ctx,
+					ctx,
 					"interface %s is filtered out with interfaceFilter %s",
 					hostPnic.Name,
 					vc.SourceConfig.InterfaceFilter,
@@ -476,7 +479,7 @@ func (vc *VmwareSource) syncHostPhysicalNics(
 			}
 
 			// After collecting all of the data add interface to nbi
-			nbHostPnic, err := nbi.AddInterface(vc.Ctx, hostPnic)
+			nbHostPnic, err := nbi.AddInterface(ctx, hostPnic)
 			if err != nil {
 				return fmt.Errorf("failed adding physical interface %+v: %s", hostPnic, err)
 			}
@@ -484,7 +487,7 @@ func (vc *VmwareSource) syncHostPhysicalNics(
 			// Create MAC address
 			if macAddress != "" {
 				nbMACAddress, err := common.CreateMACAddressForObjectType(
-					vc.Ctx,
+					ctx,
 					nbi,
 					macAddress,
 					nbHostPnic,
@@ -492,7 +495,7 @@ func (vc *VmwareSource) syncHostPhysicalNics(
 				if err != nil {
 					return fmt.Errorf("create mac address for object type: %s", err)
 				}
-				if err = common.SetPrimaryMACForInterface(vc.Ctx, nbi, nbHostPnic, nbMACAddress); err != nil {
+				if err = common.SetPrimaryMACForInterface(ctx, nbi, nbHostPnic, nbMACAddress); err != nil {
 					return fmt.Errorf("set primary mac for interface %+v: %s", nbHostPnic, err)
 				}
 			}
@@ -504,6 +507,7 @@ func (vc *VmwareSource) syncHostPhysicalNics(
 //
 //nolint:gocyclo
 func (vc *VmwareSource) collectHostPhysicalNicData(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	nbHost *objects.Device,
 	pnic types.PhysicalNic,
@@ -561,7 +565,7 @@ func (vc *VmwareSource) collectHostPhysicalNicData(
 			// Check if vlan with this vid already exists, else create it
 			if vlanName, ok := vc.Networks.Vid2Name[portgroupData.vlanID]; ok {
 				vlanSite, err := common.MatchVlanToSite(
-					vc.Ctx,
+					ctx,
 					nbi,
 					vlanName,
 					vc.SourceConfig.VlanSiteRelations,
@@ -570,7 +574,7 @@ func (vc *VmwareSource) collectHostPhysicalNicData(
 					return nil, "", fmt.Errorf("match vlan to site: %s", err)
 				}
 				vlanGroup, err := common.MatchVlanToGroup(
-					vc.Ctx,
+					ctx,
 					nbi,
 					vlanName,
 					vlanSite,
@@ -589,12 +593,12 @@ func (vc *VmwareSource) collectHostPhysicalNicData(
 				if !strings.HasPrefix(vlanName, "VLAN") {
 					vlanName = fmt.Sprintf("VLAN%04d_%s", portgroupData.vlanID, vlanName)
 				}
-				vlanSite, err := common.MatchVlanToSite(vc.Ctx, nbi, vlanName, vc.SourceConfig.VlanSiteRelations)
+				vlanSite, err := common.MatchVlanToSite(ctx, nbi, vlanName, vc.SourceConfig.VlanSiteRelations)
 				if err != nil {
 					return nil, "", fmt.Errorf("match vlan to site: %s", err)
 				}
 				vlanGroup, err := common.MatchVlanToGroup(
-					vc.Ctx,
+					ctx,
 					nbi,
 					vlanName,
 					vlanSite,
@@ -604,7 +608,7 @@ func (vc *VmwareSource) collectHostPhysicalNicData(
 				if err != nil {
 					return nil, "", fmt.Errorf("match vlan to group: %s", err)
 				}
-				vlanTenant, err := common.MatchVlanToTenant(vc.Ctx, nbi, vlanName, vc.SourceConfig.VlanTenantRelations)
+				vlanTenant, err := common.MatchVlanToTenant(ctx, nbi, vlanName, vc.SourceConfig.VlanTenantRelations)
 				if err != nil {
 					return nil, "", fmt.Errorf("match vlan to tenant: %s", err)
 				}
@@ -621,7 +625,7 @@ func (vc *VmwareSource) collectHostPhysicalNicData(
 						Tenant: vlanTenant,
 						Group:  vlanGroup,
 					}
-					newVlan, err = nbi.AddVlan(vc.Ctx, vlanStruct)
+					newVlan, err = nbi.AddVlan(ctx, vlanStruct)
 					if err != nil {
 						return nil, "", fmt.Errorf("add vlan %+v: %s", vlanStruct, err)
 					}
@@ -679,6 +683,7 @@ func (vc *VmwareSource) collectHostPhysicalNicData(
 }
 
 func (vc *VmwareSource) syncHostVirtualNics(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	vcHost mo.HostSystem,
 	nbHost *objects.Device,
@@ -689,7 +694,7 @@ func (vc *VmwareSource) syncHostVirtualNics(
 	if vcHost.Config != nil && vcHost.Config.Network != nil && vcHost.Config.Network.Vnic != nil {
 		for _, vnic := range vcHost.Config.Network.Vnic {
 			// Fetch host vnic data
-			hostVnic, macAddress, err := vc.collectHostVirtualNicData(nbi, nbHost, vcHost, vnic)
+			hostVnic, macAddress, err := vc.collectHostVirtualNicData(ctx, nbi, nbHost, vcHost, vnic)
 			if err != nil {
 				return err
 			}
@@ -697,7 +702,7 @@ func (vc *VmwareSource) syncHostVirtualNics(
 			// Filter host vnic
 			if utils.FilterInterfaceName(hostVnic.Name, vc.SourceConfig.InterfaceFilter) {
 				vc.Logger.Debugf(
-					vc.Ctx,
+					ctx,
 					"interface %s is filtered out with interfaceFilter %s",
 					hostVnic.Name,
 					vc.SourceConfig.InterfaceFilter,
@@ -706,7 +711,7 @@ func (vc *VmwareSource) syncHostVirtualNics(
 			}
 
 			// After collecting all of the data add interface to nbi
-			nbHostVnic, err := nbi.AddInterface(vc.Ctx, hostVnic)
+			nbHostVnic, err := nbi.AddInterface(ctx, hostVnic)
 			if err != nil {
 				return fmt.Errorf("failed adding virtual interface %+v: %s", hostVnic, err)
 			}
@@ -714,7 +719,7 @@ func (vc *VmwareSource) syncHostVirtualNics(
 			// Create MAC address
 			if macAddress != "" {
 				nbMACAddress, err := common.CreateMACAddressForObjectType(
-					vc.Ctx,
+					ctx,
 					nbi,
 					macAddress,
 					nbHostVnic,
@@ -722,7 +727,7 @@ func (vc *VmwareSource) syncHostVirtualNics(
 				if err != nil {
 					return fmt.Errorf("create mac address for object type: %s", err)
 				}
-				if err = common.SetPrimaryMACForInterface(vc.Ctx, nbi, nbHostVnic, nbMACAddress); err != nil {
+				if err = common.SetPrimaryMACForInterface(ctx, nbi, nbHostVnic, nbMACAddress); err != nil {
 					return fmt.Errorf("set primary mac for interface %+v: %s", nbHostVnic, err)
 				}
 			}
@@ -739,7 +744,7 @@ func (vc *VmwareSource) syncHostVirtualNics(
 					return fmt.Errorf("mask to bits: %s", err)
 				}
 				ipv4DNS := utils.ReverseLookup(ipv4Address)
-				nbIPv4Address, err := nbi.AddIPAddress(vc.Ctx, &objects.IPAddress{
+				nbIPv4Address, err := nbi.AddIPAddress(ctx, &objects.IPAddress{
 					NetboxObject: objects.NetboxObject{
 						Tags: vc.Config.GetSourceTags(),
 						CustomFields: map[string]interface{}{
@@ -754,20 +759,20 @@ func (vc *VmwareSource) syncHostVirtualNics(
 					AssignedObjectID:   nbHostVnic.ID,
 				})
 				if err != nil {
-					vc.Logger.Errorf(vc.Ctx, "add ipv4 address: %s", err)
+					vc.Logger.Errorf(ctx, "add ipv4 address: %s", err)
 					continue
 				}
 				hostIPv4Addresses = append(hostIPv4Addresses, nbIPv4Address)
 
 				prefix, mask, err := utils.GetPrefixAndMaskFromIPAddress(nbIPv4Address.Address)
 				if err != nil {
-					vc.Logger.Warningf(vc.Ctx, "extract prefix from ip address: %s", err)
+					vc.Logger.Warningf(ctx, "extract prefix from ip address: %s", err)
 				} else if mask != constants.MaxIPv4MaskBits {
-					_, err = nbi.AddPrefix(vc.Ctx, &objects.Prefix{
+					_, err = nbi.AddPrefix(ctx, &objects.Prefix{
 						Prefix: prefix,
 					})
 					if err != nil {
-						vc.Logger.Errorf(vc.Ctx, "add prefix: %s", err)
+						vc.Logger.Errorf(ctx, "add prefix: %s", err)
 					}
 				}
 			}
@@ -782,7 +787,7 @@ func (vc *VmwareSource) syncHostVirtualNics(
 						vc.SourceConfig.PermittedSubnets,
 						vc.SourceConfig.IgnoredSubnets,
 					) {
-						nbIPv6Address, err := nbi.AddIPAddress(vc.Ctx, &objects.IPAddress{
+						nbIPv6Address, err := nbi.AddIPAddress(ctx, &objects.IPAddress{
 							NetboxObject: objects.NetboxObject{
 								Tags: vc.Config.GetSourceTags(),
 								CustomFields: map[string]interface{}{
@@ -796,7 +801,7 @@ func (vc *VmwareSource) syncHostVirtualNics(
 							AssignedObjectID:   nbHostVnic.ID,
 						})
 						if err != nil {
-							vc.Logger.Errorf(vc.Ctx, "add ipv6 address: %s", err)
+							vc.Logger.Errorf(ctx, "add ipv6 address: %s", err)
 							continue
 						}
 						hostIPv6Addresses = append(hostIPv6Addresses, nbIPv6Address)
@@ -809,6 +814,7 @@ func (vc *VmwareSource) syncHostVirtualNics(
 }
 
 func (vc *VmwareSource) setHostPrimaryIPAddress(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	nbHost *objects.Device,
 	hostIPv4Addresses []*objects.IPAddress,
@@ -830,7 +836,7 @@ func (vc *VmwareSource) setHostPrimaryIPAddress(
 		newHost := *nbHost
 		newHost.PrimaryIPv4 = hostPrimaryIPv4
 		newHost.PrimaryIPv6 = hostPrimaryIPv6
-		_, err := nbi.AddDevice(vc.Ctx, &newHost)
+		_, err := nbi.AddDevice(ctx, &newHost)
 		if err != nil {
 			return fmt.Errorf("updating host's primary ip: %s", err)
 		}
@@ -840,6 +846,7 @@ func (vc *VmwareSource) setHostPrimaryIPAddress(
 }
 
 func (vc *VmwareSource) collectHostVirtualNicData(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	nbHost *objects.Device,
 	vcHost mo.HostSystem,
@@ -896,7 +903,7 @@ func (vc *VmwareSource) collectHostVirtualNicData(
 	var vnicTaggedVlans []*objects.Vlan
 	if vnicPortgroupData != nil && vnicPortgroupVlanID != 0 {
 		vnicUntaggedVlanSite, err := common.MatchVlanToSite(
-			vc.Ctx,
+			ctx,
 			nbi,
 			vc.Networks.Vid2Name[vnicPortgroupVlanID],
 			vc.SourceConfig.VlanSiteRelations,
@@ -905,7 +912,7 @@ func (vc *VmwareSource) collectHostVirtualNicData(
 			return nil, "", fmt.Errorf("vlan site: %s", err)
 		}
 		vnicUntaggedVlanGroup, err := common.MatchVlanToGroup(
-			vc.Ctx,
+			ctx,
 			nbi,
 			vc.Networks.Vid2Name[vnicPortgroupVlanID],
 			vnicUntaggedVlanSite,
@@ -931,7 +938,7 @@ func (vc *VmwareSource) collectHostVirtualNicData(
 				continue
 			}
 			vnicTaggedVlanSite, err := common.MatchVlanToSite(
-				vc.Ctx,
+				ctx,
 				nbi,
 				vc.Networks.Vid2Name[vnicDvPortgroupDataVlanID],
 				vc.SourceConfig.VlanSiteRelations,
@@ -940,7 +947,7 @@ func (vc *VmwareSource) collectHostVirtualNicData(
 				return nil, "", fmt.Errorf("match vlan to site: %s", err)
 			}
 			vnicTaggedVlanGroup, err := common.MatchVlanToGroup(
-				vc.Ctx,
+				ctx,
 				nbi,
 				vc.Networks.Vid2Name[vnicDvPortgroupDataVlanID],
 				vnicTaggedVlanSite,
@@ -978,7 +985,7 @@ func (vc *VmwareSource) collectHostVirtualNicData(
 }
 
 // syncVMs syncs VMs from the source to Netbox.
-func (vc *VmwareSource) syncVMs(nbi *inventory.NetboxInventory) error {
+func (vc *VmwareSource) syncVMs(ctx context.Context, nbi *inventory.NetboxInventory) error {
 	const maxGoroutines = 50 // Maximum number of goroutines to run concurrently
 	// Use a guard channel as semaphore to limit the number of goroutines
 	guard := make(chan struct{}, maxGoroutines)
@@ -996,7 +1003,7 @@ func (vc *VmwareSource) syncVMs(nbi *inventory.NetboxInventory) error {
 			defer wg.Done()
 			defer func() { <-guard }() // Release one spot in the semaphore
 
-			err := vc.syncVM(nbi, vmKey, vm)
+			err := vc.syncVM(ctx, nbi, vmKey, vm)
 			if err != nil {
 				errChan <- err
 			}
@@ -1021,6 +1028,7 @@ func (vc *VmwareSource) syncVMs(nbi *inventory.NetboxInventory) error {
 //
 //nolint:gocyclo
 func (vc *VmwareSource) syncVM(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	vmKey string,
 	vm mo.VirtualMachine,
@@ -1041,19 +1049,19 @@ func (vc *VmwareSource) syncVM(
 	var vmRole *objects.DeviceRole
 	var err error
 	if len(vc.SourceConfig.VMRoleRelations) > 0 {
-		vmRole, err = common.MatchVMToRole(vc.Ctx, nbi, vmHostName, vc.SourceConfig.VMRoleRelations)
+		vmRole, err = common.MatchVMToRole(ctx, nbi, vmHostName, vc.SourceConfig.VMRoleRelations)
 		if err != nil {
 			return fmt.Errorf("match vm to role: %s", err)
 		}
 	}
 	if vmRole == nil {
 		if isTemplate {
-			vmRole, err = nbi.AddVMTemplateDeviceRole(vc.Ctx)
+			vmRole, err = nbi.AddVMTemplateDeviceRole(ctx)
 			if err != nil {
 				return fmt.Errorf("add template device role: %s", err)
 			}
 		} else {
-			vmRole, err = nbi.AddVMDeviceRole(vc.Ctx)
+			vmRole, err = nbi.AddVMDeviceRole(ctx)
 			if err != nil {
 				return fmt.Errorf("get vm device role: %s", err)
 			}
@@ -1061,14 +1069,14 @@ func (vc *VmwareSource) syncVM(
 	}
 
 	// Tenant is received from VmTenantRelations
-	vmTenant, err := common.MatchVMToTenant(vc.Ctx, nbi, vmName, vc.SourceConfig.VMTenantRelations)
+	vmTenant, err := common.MatchVMToTenant(ctx, nbi, vmName, vc.SourceConfig.VMTenantRelations)
 	if err != nil {
 		return fmt.Errorf("vm's Tenant: %s", err)
 	}
 
 	// Site is the same as the Host
 	vmSite, err := common.MatchHostToSite(
-		vc.Ctx,
+		ctx,
 		nbi,
 		vmHostName,
 		vc.SourceConfig.HostSiteRelations,
@@ -1128,7 +1136,7 @@ func (vc *VmwareSource) syncVM(
 		Name: platformName,
 		Slug: utils.Slugify(platformName),
 	}
-	vmPlatform, err := nbi.AddPlatform(vc.Ctx, platformStruct)
+	vmPlatform, err := nbi.AddPlatform(ctx, platformStruct)
 	if err != nil {
 		return fmt.Errorf(
 			"failed adding vmware vm's Platform %+v with error: %s",
@@ -1166,7 +1174,7 @@ func (vc *VmwareSource) syncVM(
 							CustomFieldUIEditable: &objects.CustomFieldUIEditableYes,
 							ObjectTypes:           []constants.ContentType{constants.ContentTypeVirtualizationVirtualMachine},
 						}
-						_, err := nbi.AddCustomField(vc.Ctx, customFieldStruct)
+						_, err := nbi.AddCustomField(ctx, customFieldStruct)
 						if err != nil {
 							return fmt.Errorf("vm's custom field %s: %s", fieldName, err)
 						}
@@ -1177,6 +1185,7 @@ func (vc *VmwareSource) syncVM(
 		}
 	}
 	vmCustomFields[constants.CustomFieldSourceName] = vc.SourceConfig.Name
+	vmCustomFields[constants.CustomFieldSourceIDName] = vmKey
 
 	// netbox description has constraint <= len(200 characters)
 	// In this case we make a comment
@@ -1205,26 +1214,26 @@ func (vc *VmwareSource) syncVM(
 		Comments: vmComments,
 		Role:     vmRole,
 	}
-	newVM, err := nbi.AddVM(vc.Ctx, vmStruct)
+	newVM, err := nbi.AddVM(ctx, vmStruct)
 	if err != nil {
 		return fmt.Errorf("failed to sync vmware VM %s: %v", vmName, err)
 	}
 
 	// For non template VMS also sync contacts and their network interfaces
 	if !isTemplate {
-		err = vc.addVMContact(nbi, newVM, vmOwners, vmOwnerEmails)
+		err = vc.addVMContact(ctx, nbi, newVM, vmOwners, vmOwnerEmails)
 		if err != nil {
 			return fmt.Errorf("adding %s's contact: %s", newVM, err)
 		}
 
 		// Sync vm interfaces
-		err = vc.syncVMInterfaces(nbi, vm, newVM)
+		err = vc.syncVMInterfaces(ctx, nbi, vm, newVM)
 		if err != nil {
 			return fmt.Errorf("failed to sync vmware %s's interfaces: %v", newVM, err)
 		}
 
 		// Sync vm disks
-		err := vc.syncVMDisks(nbi, newVM, vmDisks)
+		err := vc.syncVMDisks(ctx, nbi, newVM, vmDisks)
 		if err != nil {
 			return fmt.Errorf("failed to sync vm's %+v disks: %s", newVM, err)
 		}
@@ -1234,13 +1243,14 @@ func (vc *VmwareSource) syncVM(
 
 // syncVMDisks syncs VM's disks to Netbox.
 func (vc *VmwareSource) syncVMDisks(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	vm *objects.VM,
 	vmDisks []*objects.VirtualDisk,
 ) error {
 	for _, disk := range vmDisks {
 		disk.VM = vm
-		_, err := nbi.AddVirtualDisk(vc.Ctx, disk)
+		_, err := nbi.AddVirtualDisk(ctx, disk)
 		if err != nil {
 			return fmt.Errorf("adding VirtualDisk %+v: %s", disk, err)
 		}
@@ -1250,6 +1260,7 @@ func (vc *VmwareSource) syncVMDisks(
 
 // Syncs VM's interfaces to Netbox.
 func (vc *VmwareSource) syncVMInterfaces(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	vmwareVM mo.VirtualMachine,
 	netboxVM *objects.VM,
@@ -1311,6 +1322,7 @@ func (vc *VmwareSource) syncVMInterfaces(
 
 		if vmEthernetCard != nil {
 			nicIPv4Addresses, nicIPv6Addresses, collectedVMIface, macAddress, err := vc.collectVMInterfaceData(
+				ctx,
 				nbi,
 				netboxVM,
 				vmwareVM,
@@ -1323,7 +1335,7 @@ func (vc *VmwareSource) syncVMInterfaces(
 			// Apply filter to VMIface name
 			if utils.FilterInterfaceName(collectedVMIface.Name, vc.SourceConfig.InterfaceFilter) {
 				vc.Logger.Debugf(
-					vc.Ctx,
+					ctx,
 					"interface %s is filtered out with interfaceFilter %s",
 					collectedVMIface.Name,
 					vc.SourceConfig.InterfaceFilter,
@@ -1331,13 +1343,13 @@ func (vc *VmwareSource) syncVMInterfaces(
 				continue
 			}
 
-			nbVMInterface, err := nbi.AddVMInterface(vc.Ctx, collectedVMIface)
+			nbVMInterface, err := nbi.AddVMInterface(ctx, collectedVMIface)
 			if err != nil {
 				return fmt.Errorf("adding VmInterface %+v: %s", collectedVMIface, err)
 			}
 			if macAddress != "" {
 				nbMACAddress, err := common.CreateMACAddressForObjectType(
-					vc.Ctx,
+					ctx,
 					nbi,
 					macAddress,
 					nbVMInterface,
@@ -1345,12 +1357,13 @@ func (vc *VmwareSource) syncVMInterfaces(
 				if err != nil {
 					return fmt.Errorf("creating MAC address for %+v: %s", collectedVMIface, err)
 				}
-				if err = common.SetPrimaryMACForInterface(vc.Ctx, nbi, nbVMInterface, nbMACAddress); err != nil {
+				if err = common.SetPrimaryMACForInterface(ctx, nbi, nbVMInterface, nbMACAddress); err != nil {
 					return fmt.Errorf("setting primary MAC for %+v: %s", collectedVMIface, err)
 				}
 			}
 
 			vmIPv4Addresses, vmIPv6Addresses = vc.addVMInterfaceIPs(
+				ctx,
 				nbi,
 				netboxVM,
 				nbVMInterface,
@@ -1362,6 +1375,7 @@ func (vc *VmwareSource) syncVMInterfaces(
 		}
 	}
 	vc.setVMPrimaryIPAddress(
+		ctx,
 		nbi,
 		netboxVM,
 		vmDefaultGatewayIpv4,
@@ -1373,6 +1387,7 @@ func (vc *VmwareSource) syncVMInterfaces(
 }
 
 func (vc *VmwareSource) collectVMInterfaceData(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	netboxVM *objects.VM,
 	vmwareVM mo.VirtualMachine,
@@ -1485,7 +1500,7 @@ func (vc *VmwareSource) collectVMInterfaceData(
 		if len(intNetworkVlanIDs) == 1 && intNetworkVlanIDs[0] != 0 {
 			vidID := intNetworkVlanIDs[0]
 			nicUntaggedVlanSite, err := common.MatchVlanToSite(
-				vc.Ctx,
+				ctx,
 				nbi,
 				vc.Networks.Vid2Name[vidID],
 				vc.SourceConfig.VlanSiteRelations,
@@ -1497,7 +1512,7 @@ func (vc *VmwareSource) collectVMInterfaceData(
 				)
 			}
 			nicUntaggedVlanGroup, err := common.MatchVlanToGroup(
-				vc.Ctx,
+				ctx,
 				nbi,
 				vc.Networks.Vid2Name[vidID],
 				nicUntaggedVlanSite,
@@ -1538,6 +1553,7 @@ func (vc *VmwareSource) collectVMInterfaceData(
 
 // Function that adds all collected IPs for the vm's interface to netbox.
 func (vc *VmwareSource) addVMInterfaceIPs(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	netboxVM *objects.VM,
 	nbVMInterface *objects.VMInterface,
@@ -1566,22 +1582,22 @@ func (vc *VmwareSource) addVMInterfaceIPs(
 				AssignedObjectID:   nbVMInterface.ID,
 				Tenant:             netboxVM.Tenant,
 			}
-			nbIPv4Address, err := nbi.AddIPAddress(vc.Ctx, ipAddressStruct)
+			nbIPv4Address, err := nbi.AddIPAddress(ctx, ipAddressStruct)
 			if err != nil {
-				vc.Logger.Warningf(vc.Ctx, "adding ipv4 address %s: %s", ipAddressStruct, err)
+				vc.Logger.Warningf(ctx, "adding ipv4 address %s: %s", ipAddressStruct, err)
 				continue
 			}
 			vmIPv4Addresses = append(vmIPv4Addresses, nbIPv4Address)
 			prefix, mask, err := utils.GetPrefixAndMaskFromIPAddress(nbIPv4Address.Address)
 			if err != nil {
-				vc.Logger.Warningf(vc.Ctx, "extract prefix from ip address: %s", err)
+				vc.Logger.Warningf(ctx, "extract prefix from ip address: %s", err)
 			} else if mask != constants.MaxIPv4MaskBits {
 				prefixStruct := &objects.Prefix{
 					Prefix: prefix,
 				}
-				_, err = nbi.AddPrefix(vc.Ctx, prefixStruct)
+				_, err = nbi.AddPrefix(ctx, prefixStruct)
 				if err != nil {
-					vc.Logger.Errorf(vc.Ctx, "add prefix %+v: %s", prefixStruct, err)
+					vc.Logger.Errorf(ctx, "add prefix %+v: %s", prefixStruct, err)
 				}
 			}
 		}
@@ -1594,7 +1610,7 @@ func (vc *VmwareSource) addVMInterfaceIPs(
 			vc.SourceConfig.PermittedSubnets,
 			vc.SourceConfig.IgnoredSubnets,
 		) {
-			nbIPv6Address, err := nbi.AddIPAddress(vc.Ctx, &objects.IPAddress{
+			nbIPv6Address, err := nbi.AddIPAddress(ctx, &objects.IPAddress{
 				NetboxObject: objects.NetboxObject{
 					Tags: vc.Config.GetSourceTags(),
 					CustomFields: map[string]interface{}{
@@ -1607,20 +1623,20 @@ func (vc *VmwareSource) addVMInterfaceIPs(
 				AssignedObjectID:   nbVMInterface.ID,
 			})
 			if err != nil {
-				vc.Logger.Warningf(vc.Ctx, "adding ipv6 address: %s", err)
+				vc.Logger.Warningf(ctx, "adding ipv6 address: %s", err)
 				continue
 			}
 			vmIPv6Addresses = append(vmIPv6Addresses, nbIPv6Address)
 			prefix, mask, err := utils.GetPrefixAndMaskFromIPAddress(nbIPv6Address.Address)
 			if err != nil {
-				vc.Logger.Warningf(vc.Ctx, "extract prefix from ip address: %s", err)
+				vc.Logger.Warningf(ctx, "extract prefix from ip address: %s", err)
 			} else if mask != constants.MaxIPv6MaskBits {
 				prefixStruct := &objects.Prefix{
 					Prefix: prefix,
 				}
-				_, err = nbi.AddPrefix(vc.Ctx, prefixStruct)
+				_, err = nbi.AddPrefix(ctx, prefixStruct)
 				if err != nil {
-					vc.Logger.Errorf(vc.Ctx, "add prefix: %s", err)
+					vc.Logger.Errorf(ctx, "add prefix: %s", err)
 				}
 			}
 		}
@@ -1633,6 +1649,7 @@ func (vc *VmwareSource) addVMInterfaceIPs(
 // If any of the ips is in the same subnet as the default gateway, we choose it.
 // If there is no ip in the subnet of the default gateway, we choose the first one.
 func (vc *VmwareSource) setVMPrimaryIPAddress(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	netboxVM *objects.VM,
 	vmDefaultGatewayIpv4 string,
@@ -1658,14 +1675,15 @@ func (vc *VmwareSource) setVMPrimaryIPAddress(
 		newNetboxVM := *netboxVM
 		newNetboxVM.PrimaryIPv4 = vmIPv4PrimaryAddress
 		newNetboxVM.PrimaryIPv6 = vmIPv6PrimaryAddress
-		_, err := nbi.AddVM(vc.Ctx, &newNetboxVM)
+		_, err := nbi.AddVM(ctx, &newNetboxVM)
 		if err != nil {
-			vc.Logger.Warningf(vc.Ctx, "updating vm's primary ip: %s", err)
+			vc.Logger.Warningf(ctx, "updating vm's primary ip: %s", err)
 		}
 	}
 }
 
 func (vc *VmwareSource) addVMContact(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	nbVM *objects.VM,
 	vmOwners []string,
@@ -1675,12 +1693,12 @@ func (vc *VmwareSource) addVMContact(
 	var vmMailMapFallback bool
 	if len(vmOwners) > 0 && len(vmOwnerEmails) > 0 && len(vmOwners) != len(vmOwnerEmails) {
 		vc.Logger.Debugf(
-			vc.Ctx,
+			ctx,
 			"vm owner names and emails mismatch len(vmOwnerEmails) != len(vmOwners), using fallback mechanism",
 		)
 		vmMailMapFallback = true
 	}
-	vmOwner2Email := utils.MatchNamesWithEmails(vc.Ctx, vmOwners, vmOwnerEmails, vc.Logger)
+	vmOwner2Email := utils.MatchNamesWithEmails(ctx, vmOwners, vmOwnerEmails, vc.Logger)
 	for i, vmOwnerName := range vmOwners {
 		if vmOwnerName != "" {
 			var vmOwnerEmail string
@@ -1698,7 +1716,7 @@ func (vc *VmwareSource) addVMContact(
 				Email: vmOwnerEmail,
 			}
 			contact, err := nbi.AddContact(
-				vc.Ctx,
+				ctx,
 				contactStruct,
 			)
 			if err != nil {
@@ -1712,7 +1730,7 @@ func (vc *VmwareSource) addVMContact(
 				Contact:   contact,
 				Role:      contactRole,
 			}
-			_, err = nbi.AddContactAssignment(vc.Ctx, contactAssignmentSturct)
+			_, err = nbi.AddContactAssignment(ctx, contactAssignmentSturct)
 			if err != nil {
 				return fmt.Errorf("add contact assignment for vm: %s", err)
 			}
@@ -1725,6 +1743,7 @@ func (vc *VmwareSource) addVMContact(
 // It takes a NetboxInventory object as input and returns the created
 // ClusterType object and an error, if any.
 func (vc *VmwareSource) createVmwareClusterType(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 ) (*objects.ClusterType, error) {
 	clusterType := &objects.ClusterType{
@@ -1734,7 +1753,7 @@ func (vc *VmwareSource) createVmwareClusterType(
 		Name: "VMware ESXi",
 		Slug: utils.Slugify("VMware ESXi"),
 	}
-	clusterType, err := nbi.AddClusterType(vc.Ctx, clusterType)
+	clusterType, err := nbi.AddClusterType(ctx, clusterType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add vmware ClusterType %+v: %v", clusterType, err)
 	}
@@ -1745,12 +1764,13 @@ func (vc *VmwareSource) createVmwareClusterType(
 // for all hosts that are not assigned to cluster so we can assign them to hypotheticalCluster.
 // for more see: https://github.com/src-doo/netbox-ssot/issues/141
 func (vc *VmwareSource) createHypotheticalCluster(
+	ctx context.Context,
 	nbi *inventory.NetboxInventory,
 	hostName string,
 	hostSite *objects.Site,
 	hostTenant *objects.Tenant,
 ) (*objects.Cluster, error) {
-	clusterType, err := vc.createVmwareClusterType(nbi)
+	clusterType, err := vc.createVmwareClusterType(ctx, nbi)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add vmware ClusterType: %v", err)
 	}
@@ -1771,7 +1791,7 @@ func (vc *VmwareSource) createHypotheticalCluster(
 		ScopeID:   clusterScopeID,
 		Tenant:    hostTenant,
 	}
-	nbCluster, err := nbi.AddCluster(vc.Ctx, clusterStruct)
+	nbCluster, err := nbi.AddCluster(ctx, clusterStruct)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"failed to add vmware hypothetical cluster %+v: %v",
@@ -1 +1,192 @@
 package vmware
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/src-doo/netbox-ssot/internal/constants"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox"
+	"github.com/src-doo/netbox-ssot/internal/netbox/fakenetbox/sourcetest"
+	"github.com/src-doo/netbox-ssot/internal/netbox/inventory"
+	"github.com/src-doo/netbox-ssot/internal/netbox/objects"
+	"github.com/src-doo/netbox-ssot/internal/parser"
+	"github.com/src-doo/netbox-ssot/internal/source/common"
+	"github.com/src-doo/netbox-ssot/internal/utils"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestConfigureSoapTransport(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://envproxy.example.com:3128")
+	tests := []struct {
+		name      string
+		config    utils.HTTPClientConfig
+		wantProxy string
+	}{
+		{
+			name:      "Proxy from environment is kept",
+			wantProxy: "http://envproxy.example.com:3128",
+		},
+		{
+			name:      "Configured proxy",
+			config:    utils.HTTPClientConfig{ProxyURL: "http://proxy.example.com:8080"},
+			wantProxy: "http://proxy.example.com:8080",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcURL, _ := url.Parse("https://vcenter.example.com/sdk")
+			soapClient := soap.NewClient(vcURL, true)
+			if err := configureSoapTransport(soapClient, tt.config); err != nil {
+				t.Fatalf("configureSoapTransport() error = %v", err)
+			}
+			proxy := soapClient.DefaultTransport().Proxy
+			if proxy == nil {
+				t.Fatalf("transport has no proxy, want %s", tt.wantProxy)
+			}
+			proxyURL, err := proxy(&http.Request{URL: vcURL})
+			if err != nil {
+				t.Fatalf("Proxy() error = %v", err)
+			}
+			if proxyURL == nil || proxyURL.String() != tt.wantProxy {
+				t.Errorf("Proxy() = %v, want %s", proxyURL, tt.wantProxy)
+			}
+		})
+	}
+}
+
+// runVmware runs netbox-ssot with a vmware source, that has hosts host-1
+// (esx1) and host-2 (esx2) in clusters cluster1 and cluster2, and the
+// given vms on hosts in vm2Host.
+func runVmware(
+	t *testing.T,
+	netboxServer *fakenetbox.Server,
+	vms map[string]mo.VirtualMachine,
+	vm2Host map[string]string,
+) {
+	t.Helper()
+	sourceConfig := &parser.SourceConfig{
+		Name: "vmware",
+		Type: constants.Vmware,
+		// Devices must be assigned to a site
+		HostSiteRelations: map[string]string{"^esx": "HQ"},
+	}
+	sourcetest.Run(t, netboxServer, sourceConfig,
+		func(ctx context.Context, nbi *inventory.NetboxInventory, config common.Config) error {
+			vc := &VmwareSource{
+				Config:             config,
+				DataCenters:        map[string]mo.Datacenter{"dc-1": {ManagedEntity: mo.ManagedEntity{Name: "dc1"}}},
+				Clusters:           make(map[string]mo.ClusterComputeResource),
+				Hosts:              make(map[string]mo.HostSystem),
+				Vms:                vms,
+				Cluster2Datacenter: make(map[string]string),
+				Host2Cluster:       make(map[string]string),
+				VM2Host:            vm2Host,
+			}
+			for i, hostName := range []string{"esx1", "esx2"} {
+				clusterID := fmt.Sprintf("domain-c%d", i+1)
+				hostID := fmt.Sprintf("host-%d", i+1)
+				vc.Clusters[clusterID] = mo.ClusterComputeResource{ComputeResource: mo.ComputeResource{
+					ManagedEntity: mo.ManagedEntity{Name: fmt.Sprintf("cluster%d", i+1)},
+				}}
+				vc.Cluster2Datacenter[clusterID] = "dc-1"
+				vc.Hosts[hostID] = mo.HostSystem{
+					ManagedEntity: mo.ManagedEntity{Name: hostName},
+					Summary: types.HostListSummary{
+						Hardware: &types.HostHardwareSummary{Vendor: "Dell Inc.", Model: "PowerEdge R650"},
+						Runtime:  &types.HostRuntimeInfo{ConnectionState: types.HostSystemConnectionStateConnected},
+						Config:   types.HostConfigSummary{Product: &types.AboutInfo{Name: "VMware ESXi", Version: "8.0.2"}},
+					},
+				}
+				vc.Host2Cluster[hostID] = clusterID
+			}
+			return vc.Sync(ctx, nbi)
+		})
+}
+
+// newVM returns a running vmware vm with the name.
+func newVM(name string) mo.VirtualMachine {
+	return mo.VirtualMachine{
+		ManagedEntity: mo.ManagedEntity{Name: name},
+		Config:        &types.VirtualMachineConfigInfo{GuestFullName: "Ubuntu Linux (64-bit)"},
+		Guest:         &types.GuestInfo{},
+		Runtime:       types.VirtualMachineRuntimeInfo{PowerState: types.VirtualMachinePowerStatePoweredOn},
+	}
+}
+
+// vmClusters returns clusters of all vms in Netbox by vm names.
+func vmClusters(t *testing.T, netboxServer *fakenetbox.Server) map[string]string {
+	t.Helper()
+	vms, err := fakenetbox.Objects[objects.VM](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusters := make(map[string]string, len(vms))
+	for _, vm := range vms {
+		clusters[vm.Name] = ""
+		if vm.Cluster != nil {
+			clusters[vm.Name] = vm.Cluster.Name
+		}
+	}
+	return clusters
+}
+
+func TestVmwareSource_EndToEnd(t *testing.T) {
+	netboxServer := fakenetbox.NewServer()
+	defer netboxServer.Close()
+	vm2Host := map[string]string{"vm-1": "host-1", "vm-2": "host-2"}
+
+	runVmware(t, netboxServer, map[string]mo.VirtualMachine{"vm-1": newVM("vm1"), "vm-2": newVM("vm2")}, vm2Host)
+	hosts, err := fakenetbox.Objects[objects.Device](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostNames := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		hostNames = append(hostNames, host.Name)
+	}
+	slices.Sort(hostNames)
+	if !slices.Equal(hostNames, []string{"esx1", "esx2"}) {
+		t.Errorf("hosts = %v, want [esx1 esx2]", hostNames)
+	}
+	want := map[string]string{"vm1": "cluster1", "vm2": "cluster2"}
+	if got := vmClusters(t, netboxServer); !maps.Equal(got, want) {
+		t.Errorf("vms = %v, want %v", got, want)
+	}
+
+	// VM removed from vmware is removed from Netbox
+	runVmware(t, netboxServer, map[string]mo.VirtualMachine{"vm-1": newVM("vm1")}, vm2Host)
+	want = map[string]string{"vm1": "cluster1"}
+	if got := vmClusters(t, netboxServer); !maps.Equal(got, want) {
+		t.Errorf("vms = %v, want %v", got, want)
+	}
+}
+
+func TestVmwareSource_EndToEnd_SourceID(t *testing.T) {
+	netboxServer := fakenetbox.NewServer()
+	defer netboxServer.Close()
+	runVmware(t, netboxServer, map[string]mo.VirtualMachine{"vm-1": newVM("vm1")}, map[string]string{"vm-1": "host-1"})
+
+	// MoRef identifies the vm, so the renamed vm migrated to another
+	// cluster is patched instead of created again
+	runVmware(
+		t,
+		netboxServer,
+		map[string]mo.VirtualMachine{"vm-1": newVM("vm1-renamed")},
+		map[string]string{"vm-1": "host-2"},
+	)
+	vms, err := fakenetbox.Objects[objects.VM](netboxServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vms) != 1 || vms[0].ID != 1 || vms[0].Name != "vm1-renamed" ||
+		vms[0].Cluster == nil || vms[0].Cluster.Name != "cluster2" {
+		t.Errorf("vms = %v, want vm 1 renamed and moved to cluster2", vms)
+	}
+}
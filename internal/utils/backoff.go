@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+const backoffFactor = 2.0
+
+// Backoff calculates exponentially growing waits between retries of
+// requests, that start at Initial and are limited to Max.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+	// Jitter randomizes waits between their half and their full duration,
+	// so retries of concurrent requests are spread out.
+	Jitter bool
+}
+
+// Duration returns the wait before the retry of the given attempt,
+// starting with attempt 0.
+func (b Backoff) Duration(attempt int) time.Duration {
+	backoff := time.Duration(float64(b.Initial) * math.Pow(backoffFactor, float64(attempt)))
+	if backoff > b.Max || backoff <= 0 {
+		backoff = b.Max
+	}
+	if !b.Jitter {
+		return backoff
+	}
+	return backoff/2 + rand.N(backoff/2+1) //nolint:gosec
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff_Duration(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff Backoff
+	}{
+		{
+			name:    "Backoff without jitter",
+			backoff: Backoff{Initial: 500 * time.Millisecond, Max: 16 * time.Second},
+		},
+		{
+			name:    "Backoff with jitter",
+			backoff: Backoff{Initial: 500 * time.Millisecond, Max: 30 * time.Second, Jitter: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for attempt := 0; attempt < 10; attempt++ {
+				want := min(tt.backoff.Initial<<attempt, tt.backoff.Max)
+				minWant := want
+				if tt.backoff.Jitter {
+					minWant = want / 2
+				}
+				if got := tt.backoff.Duration(attempt); got < minWant || got > want {
+					t.Errorf("Duration(%d) = %s, want between %s and %s", attempt, got, minWant, want)
+				}
+			}
+			if got := tt.backoff.Duration(100); got > tt.backoff.Max {
+				t.Errorf("Duration(100) = %s, want at most %s", got, tt.backoff.Max)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/src-doo/netbox-ssot/internal/constants"
@@ -78,6 +79,49 @@ func hasPriorityOver(newObj, existingObj reflect.Value, source2priority map[stri
 	return true
 }
 
+// sourceOf returns the source custom field of the object, or "" if it has none.
+func sourceOf(obj reflect.Value) string {
+	customFields := obj.FieldByName("CustomFields")
+	if !customFields.IsValid() {
+		return ""
+	}
+	if customFieldsMap, ok := customFields.Interface().(map[string]interface{}); ok {
+		if source, ok := customFieldsMap[constants.CustomFieldSourceName].(string); ok {
+			return source
+		}
+	}
+	return ""
+}
+
+// ownsField returns true if newSource can overwrite the field with the given owners
+// (sources in order of priority, or manual). Values of existing fields are attributed
+// to existingSource, so owners can overwrite values of sources, that are not owners,
+// and of owners with lower priority.
+func ownsField(owners []string, newSource, existingSource string) bool {
+	if slices.Contains(owners, constants.FieldOwnerManual) {
+		return false
+	}
+	newRank := slices.Index(owners, newSource)
+	if newRank < 0 {
+		return false
+	}
+	existingRank := slices.Index(owners, existingSource)
+	return existingRank < 0 || newRank <= existingRank
+}
+
+// isEmptyValue returns true if the field is not set, or it is an empty slice or map.
+func isEmptyValue(field reflect.Value) bool {
+	if !field.IsValid() || field.IsZero() {
+		return true
+	}
+	switch field.Kind() {
+	case reflect.Slice, reflect.Map:
+		return field.Len() == 0
+	default:
+		return false
+	}
+}
+
 // JSONDiffMapExceptID compares two objects and returns a map of fields
 // (represented by their JSON tag names) that are different with their
 // values from newObj.
@@ -85,10 +129,14 @@ func hasPriorityOver(newObj, existingObj reflect.Value, source2priority map[stri
 // that are empty in newObj but might have a value in existingObj.
 // Also we check for priority, if newObject has priority over existingObject
 // we use the fields from newObject, otherwise we use the fields from exisingObject.
+// Priority of fields in fieldOwners (json tags mapped to their owners, see ownsField)
+// is determined per field instead: fields are overwritten only by their owners,
+// and other sources can only fill them when they are empty.
 func JSONDiffMapExceptID(
 	newObj, existingObj interface{},
 	resetFields bool,
 	source2priority map[string]int,
+	fieldOwners map[string][]string,
 ) (map[string]interface{}, error) {
 	diff := make(map[string]interface{})
 
@@ -113,6 +161,7 @@ func JSONDiffMapExceptID(
 
 	// Check for priority
 	hasPriority := hasPriorityOver(newObject, existingObject, source2priority)
+	newSource, existingSource := sourceOf(newObject), sourceOf(existingObject)
 
 	for i := 0; i < newObject.NumField(); i++ {
 		fieldName := newObject.Type().Field(i).Name
@@ -129,6 +178,7 @@ func JSONDiffMapExceptID(
 				existingObject.Field(i).Interface(),
 				resetFields,
 				source2priority,
+				fieldOwners,
 			)
 			if err != nil {
 				return nil, fmt.Errorf(
@@ -162,6 +212,16 @@ func JSONDiffMapExceptID(
 			existingObjectField = existingObjectField.Elem()
 		}
 
+		fieldHasPriority := hasPriority
+		if owners, ok := fieldOwners[jsonTag]; ok {
+			// Fields owned by other sources, or maintained manually, can only be filled
+			if !ownsField(owners, newSource, existingSource) &&
+				(!isEmptyValue(existingObjectField) || isEmptyValue(newObjectField)) {
+				continue
+			}
+			fieldHasPriority = true
+		}
+
 		// If reset is set to false and the newObjectField is empty,
 		// we don't do anything (like omitempty), see tests
 		if !resetFields && (!newObjectField.IsValid() || newObjectField.IsZero()) {
@@ -177,7 +237,7 @@ func JSONDiffMapExceptID(
 			}
 
 		case reflect.Slice:
-			err := addSliceDiff(newObjectField, existingObjectField, jsonTag, hasPriority, diff)
+			err := addSliceDiff(newObjectField, existingObjectField, jsonTag, fieldHasPriority, diff)
 			if err != nil {
 				return nil, fmt.Errorf(
 					"error processing JsonDiffMapExceptID when processing slice %s",
@@ -186,7 +246,7 @@ func JSONDiffMapExceptID(
 			}
 
 		case reflect.Struct:
-			err := addStructDiff(newObjectField, existingObjectField, jsonTag, hasPriority, diff)
+			err := addStructDiff(newObjectField, existingObjectField, jsonTag, fieldHasPriority, diff)
 			if err != nil {
 				return nil, fmt.Errorf(
 					"error processing JsonDiffMapExceptID when processing struct %s",
@@ -195,7 +255,7 @@ func JSONDiffMapExceptID(
 			}
 
 		case reflect.Map:
-			err := addMapDiff(newObjectField, existingObjectField, jsonTag, hasPriority, diff)
+			err := addMapDiff(newObjectField, existingObjectField, jsonTag, fieldHasPriority, diff)
 			if err != nil {
 				return nil, fmt.Errorf(
 					"error processing JsonDiffMapExceptID when processing map %s",
@@ -204,7 +264,7 @@ func JSONDiffMapExceptID(
 			}
 
 		default:
-			addPrimaryDiff(newObjectField, existingObjectField, jsonTag, hasPriority, diff)
+			addPrimaryDiff(newObjectField, existingObjectField, jsonTag, fieldHasPriority, diff)
 		}
 	}
 
@@ -334,7 +394,8 @@ func addStructDiff(
 
 	// We check if struct is a objects.Choice (special netbox struct)
 	if isChoiceEmbedded(newObj) {
-		if !existingObj.IsValid() || newObj.Interface() != existingObj.Interface() {
+		// Only values are compared, because labels are not always collected
+		if !existingObj.IsValid() || choiceValue(newObj) != choiceValue(existingObj) {
 			diffMap[jsonTag] = choiceValue(newObj)
 		}
 		return nil
@@ -88,6 +88,7 @@ func TestPrimaryAttributesDiff(t *testing.T) {
 				tt.existingStruct,
 				tt.resetFields,
 				nil,
+				nil,
 			)
 			if err != nil {
 				t.Errorf("JsonDiffMapExceptID() error = %v", err)
@@ -160,6 +161,7 @@ func TestChoicesAttributesDiff(t *testing.T) {
 				tt.existingStruct,
 				tt.resetFields,
 				nil,
+				nil,
 			)
 			if err != nil {
 				t.Errorf("JsonDiffMapExceptID() error = %v", err)
@@ -252,6 +254,7 @@ func TestStructAttributeDiff(t *testing.T) {
 				tt.existingStruct,
 				tt.resetFields,
 				nil,
+				nil,
 			)
 			if err != nil {
 				t.Errorf("JsonDiffMapExceptID() error = %v", err)
@@ -339,6 +342,7 @@ func TestSliceAttributeDiff(t *testing.T) {
 				tt.existingStruct,
 				tt.resetFields,
 				nil,
+				nil,
 			)
 			if err != nil {
 				t.Errorf("JsonDiffMapExceptID() error = %v", err)
@@ -449,6 +453,7 @@ func TestMapAttributeDiff(t *testing.T) {
 				tt.existingStruct,
 				tt.resetFields,
 				nil,
+				nil,
 			)
 			if err != nil {
 				t.Errorf("JsonDiffMapExceptID() error = %v", err)
@@ -557,6 +562,7 @@ func TestPriorityMergeDiff(t *testing.T) {
 				tt.existingStruct,
 				tt.resetFields,
 				tt.sourcePriority,
+				nil,
 			)
 			if err != nil {
 				t.Errorf("JsonDiffMapExceptID() error = %v", err)
@@ -769,6 +775,10 @@ func TestJSONDiffMapExceptID(t *testing.T) {
 		existingObj     interface{}
 		resetFields     bool
 		source2priority map[string]int
+		fieldOwners     map[string][]string
+	}
+	sourceFields := func(source string) map[string]interface{} {
+		return map[string]interface{}{constants.CustomFieldSourceName: source}
 	}
 	tests := []struct {
 		name    string
@@ -869,6 +879,79 @@ func TestJSONDiffMapExceptID(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Owner overwrites field of source with higher priority",
+			args: args{
+				newObj: &objects.VM{
+					NetboxObject: objects.NetboxObject{CustomFields: sourceFields("vmware")},
+					VCPUs:        4,
+					Comments:     "vmware comment",
+				},
+				existingObj: &objects.VM{
+					NetboxObject: objects.NetboxObject{CustomFields: sourceFields("dnac")},
+					VCPUs:        2,
+					Comments:     "dnac comment",
+				},
+				source2priority: map[string]int{"dnac": 0, "vmware": 1},
+				fieldOwners:     map[string][]string{"vcpus": {"vmware"}},
+			},
+			want: map[string]interface{}{"vcpus": float32(4)},
+		},
+		{
+			name: "Source with higher priority doesn't overwrite owned field",
+			args: args{
+				newObj: &objects.Device{
+					NetboxObject: objects.NetboxObject{CustomFields: sourceFields("vmware")},
+					SerialNumber: "vmware-serial",
+					Comments:     "vmware comment",
+				},
+				existingObj: &objects.Device{
+					NetboxObject: objects.NetboxObject{CustomFields: sourceFields("dnac")},
+					SerialNumber: "dnac-serial",
+					Comments:     "dnac comment",
+				},
+				source2priority: map[string]int{"vmware": 0, "dnac": 1},
+				fieldOwners:     map[string][]string{"serial": {"dnac"}},
+			},
+			want: map[string]interface{}{
+				"comments":      "vmware comment",
+				"custom_fields": map[string]interface{}{constants.CustomFieldSourceName: "vmware"},
+			},
+		},
+		{
+			name: "Source fills empty owned field",
+			args: args{
+				newObj: &objects.Device{
+					NetboxObject: objects.NetboxObject{CustomFields: sourceFields("vmware")},
+					SerialNumber: "vmware-serial",
+				},
+				existingObj: &objects.Device{
+					NetboxObject: objects.NetboxObject{CustomFields: sourceFields("vmware")},
+				},
+				fieldOwners: map[string][]string{"serial": {"dnac"}},
+			},
+			want: map[string]interface{}{"serial": "vmware-serial"},
+		},
+		{
+			name: "Manual field is not overwritten or reset",
+			args: args{
+				newObj: &objects.Device{
+					NetboxObject: objects.NetboxObject{CustomFields: sourceFields("vmware")},
+					Comments:     "vmware comment",
+				},
+				existingObj: &objects.Device{
+					NetboxObject: objects.NetboxObject{CustomFields: sourceFields("vmware")},
+					Comments:     "manual comment",
+					Tenant:       &objects.Tenant{NetboxObject: objects.NetboxObject{ID: 1}},
+				},
+				resetFields: true,
+				fieldOwners: map[string][]string{
+					"comments": {constants.FieldOwnerManual},
+					"tenant":   {constants.FieldOwnerManual},
+				},
+			},
+			want: map[string]interface{}{},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -877,6 +960,7 @@ func TestJSONDiffMapExceptID(t *testing.T) {
 				tt.args.existingObj,
 				tt.args.resetFields,
 				tt.args.source2priority,
+				tt.args.fieldOwners,
 			)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("JSONDiffMapExceptID() error = %v, wantErr %v", err, tt.wantErr)
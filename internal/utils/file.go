@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes the file at path with write. Content is written to
+// a temporary file in the same directory, which then replaces the file, so
+// readers never see partially written files.
+func WriteFileAtomic(path string, write func(io.Writer) error) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temporary file: %s", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	if err := write(tmpFile); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("write temporary file: %s", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("close temporary file: %s", err)
+	}
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return fmt.Errorf("replace file: %s", err)
+	}
+	return nil
+}
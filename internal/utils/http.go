@@ -4,28 +4,61 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
-// NewHTTPClient creates an http client with tls config depending on validateCert
-// and caFile parameter.
-func NewHTTPClient(validateCert bool, caFile string) (*http.Client, error) {
-	httpClient := &http.Client{}
-	if validateCert {
-		customCertPool, err := LoadExtraCert(caFile)
+// HTTPClientConfig holds tls and proxy options of http clients.
+type HTTPClientConfig struct {
+	// ValidateCert enables validation of server certificates.
+	ValidateCert bool
+	// CAFile is the path of additional CA certificates, that are trusted
+	// when server certificates are validated.
+	CAFile string
+	// ClientCert and ClientKey are paths of the client certificate and its
+	// key, used for mutual TLS. Client certificate is not used when empty.
+	ClientCert string
+	ClientKey  string
+	// ProxyURL is the URL of the proxy, that requests are sent through.
+	// Requests are sent directly when empty.
+	ProxyURL string
+}
+
+// NewHTTPTransport creates an http transport with tls and proxy
+// settings from the config.
+func NewHTTPTransport(config HTTPClientConfig) (*http.Transport, error) {
+	tlsConfig := &tls.Config{}
+	if config.ValidateCert {
+		customCertPool, err := LoadExtraCert(config.CAFile)
 		if err != nil {
 			return nil, fmt.Errorf("load extra cert: %s", err)
 		}
-		httpClient.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				RootCAs: customCertPool,
-			},
-		}
+		tlsConfig.RootCAs = customCertPool
 	} else {
-		httpClient.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
+		tlsConfig.InsecureSkipVerify = true
+	}
+	if config.ClientCert != "" {
+		clientCert, err := tls.LoadX509KeyPair(config.ClientCert, config.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("load client cert: %s", err)
 		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+	transport := &http.Transport{TLSClientConfig: tlsConfig}
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy url: %s", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	return transport, nil
+}
+
+// NewHTTPClient creates an http client with tls and proxy settings from the config.
+func NewHTTPClient(config HTTPClientConfig) (*http.Client, error) {
+	transport, err := NewHTTPTransport(config)
+	if err != nil {
+		return nil, err
 	}
-	return httpClient, nil
+	return &http.Client{Transport: transport}, nil
 }
@@ -6,19 +6,19 @@ import (
 )
 
 func TestNewHTTPClient(t *testing.T) {
-	_, err := NewHTTPClient(false, "")
+	_, err := NewHTTPClient(HTTPClientConfig{})
 	if err != nil {
 		t.Errorf("not expecting error, but got: %s", err)
 	}
 
 	// wrong path
-	_, err = NewHTTPClient(true, "\\//")
+	_, err = NewHTTPClient(HTTPClientConfig{ValidateCert: true, CAFile: "\\//"})
 	if err == nil {
 		t.Error("expected error but got none")
 	}
 
 	// Check if `InsecureSkipVerify` is set correctly
-	insecureClient, err := NewHTTPClient(false, "")
+	insecureClient, err := NewHTTPClient(HTTPClientConfig{})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -28,7 +28,7 @@ func TestNewHTTPClient(t *testing.T) {
 	}
 
 	// Check if RootCAs is set when expected
-	certClient, err := NewHTTPClient(true, "")
+	certClient, err := NewHTTPClient(HTTPClientConfig{ValidateCert: true})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -37,3 +37,70 @@ func TestNewHTTPClient(t *testing.T) {
 		t.Errorf("expected RootCAs to be set, got nil")
 	}
 }
+
+func TestNewHTTPTransport(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    HTTPClientConfig
+		wantErr   bool
+		wantCert  bool
+		wantProxy string
+	}{
+		{
+			name:   "Defaults",
+			config: HTTPClientConfig{},
+		},
+		{
+			name: "Client certificate",
+			config: HTTPClientConfig{
+				ClientCert: "../../testdata/certificate/client_cert.pem",
+				ClientKey:  "../../testdata/certificate/client_key.pem",
+			},
+			wantCert: true,
+		},
+		{
+			name: "Client certificate with wrong key",
+			config: HTTPClientConfig{
+				ClientCert: "../../testdata/certificate/client_cert.pem",
+				ClientKey:  "../../testdata/certificate/cert.pem",
+			},
+			wantErr: true,
+		},
+		{
+			name:      "Proxy",
+			config:    HTTPClientConfig{ProxyURL: "http://proxy.example.com:3128"},
+			wantProxy: "http://proxy.example.com:3128",
+		},
+		{
+			name:    "Invalid proxy",
+			config:  HTTPClientConfig{ProxyURL: "http://proxy:port"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := NewHTTPTransport(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewHTTPTransport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if gotCert := len(transport.TLSClientConfig.Certificates) > 0; gotCert != tt.wantCert {
+				t.Errorf("client certificate set = %t, want %t", gotCert, tt.wantCert)
+			}
+			request, _ := http.NewRequest(http.MethodGet, "https://netbox.example.com", nil)
+			gotProxy := ""
+			if transport.Proxy != nil {
+				proxyURL, err := transport.Proxy(request)
+				if err != nil {
+					t.Fatal(err)
+				}
+				gotProxy = proxyURL.String()
+			}
+			if gotProxy != tt.wantProxy {
+				t.Errorf("proxy = %q, want %q", gotProxy, tt.wantProxy)
+			}
+		})
+	}
+}
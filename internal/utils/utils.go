@@ -10,6 +10,7 @@ import (
 	"reflect"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -52,6 +53,20 @@ func ConvertStringsToRegexPairs(input []string) map[string]string {
 	return output
 }
 
+// Converts map of regex pairs back to an array of strings of form
+// "regex = value", sorted by regex.
+func ConvertRegexPairsToStrings(input map[string]string) []string {
+	if len(input) == 0 {
+		return nil
+	}
+	output := make([]string, 0, len(input))
+	for regex, value := range input {
+		output = append(output, fmt.Sprintf("%s = %s", regex, value))
+	}
+	sort.Strings(output)
+	return output
+}
+
 // Matches input string to a regex from input map patterns,
 // and returns the value. If there is no match, it returns an empty string.
 func MatchStringToValue(input string, patterns map[string]string) (string, error) {
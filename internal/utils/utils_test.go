@@ -224,6 +224,39 @@ func TestConvertStringsToRegexPairs(t *testing.T) {
 	}
 }
 
+func TestConvertRegexPairsToStrings(t *testing.T) {
+	type args struct {
+		input map[string]string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "Test conversion of regex pairs to strings",
+			args: args{
+				input: map[string]string{"regex2": "value2", "regex1": "value1", "regex3": "value3"},
+			},
+			want: []string{"regex1 = value1", "regex2 = value2", "regex3 = value3"},
+		},
+		{
+			name: "Test conversion of empty regex pairs",
+			args: args{
+				input: map[string]string{},
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConvertRegexPairsToStrings(tt.args.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ConvertRegexPairsToStrings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestMatchStringToValue(t *testing.T) {
 	type args struct {
 		input    string